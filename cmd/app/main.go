@@ -2,24 +2,52 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"flag"
 	"log/slog"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/kerim-dauren/rkn-checker/internal/application"
 	"github.com/kerim-dauren/rkn-checker/internal/delivery/grpc"
 	"github.com/kerim-dauren/rkn-checker/internal/delivery/rest"
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
 	"github.com/kerim-dauren/rkn-checker/internal/domain/services"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/auth"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/changelog"
 	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/config"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/feed"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/history"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/iplookup"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/metrics"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/proxy"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/ratelimit"
 	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/registry"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/search"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/snapshot"
 	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/storage"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/tlsconfig"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/tracing"
 	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/updater"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/watchlist"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/webhook"
 )
 
+// configPollInterval is how often the configuration file is checked for
+// changes
+const configPollInterval = 10 * time.Second
+
+// logLevel is the level of the default logger, changed on reload
+var logLevel slog.LevelVar
+
 func main() {
-	cfg, err := config.LoadConfig()
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML or JSON configuration file")
+	flag.Parse()
+
+	cfg, err := config.Load(*configFile)
 	if err != nil {
 		slog.Error("Failed to load configuration", "error", err)
 		os.Exit(1)
@@ -34,14 +62,29 @@ func main() {
 
 	slog.Info("Starting Roskomnadzor URL Blocking Service")
 
+	shutdownTracing := setupTracing(cfg.Tracing)
+
+	snapshotStore, err := snapshot.NewStore(snapshot.Retention{
+		MaxSnapshots: cfg.Storage.SnapshotRetentionCount,
+		MaxAge:       cfg.Storage.SnapshotRetentionAge,
+		MaxLoaded:    cfg.Storage.SnapshotCacheSize,
+	}, cfg.Storage.SnapshotDir)
+	if err != nil {
+		slog.Error("Failed to create snapshot store", "error", err)
+		os.Exit(1)
+	}
+
 	normalizer := services.NewURLNormalizer()
 	store := storage.NewMemoryStore()
-	blockingService := application.NewBlockingService(normalizer, store)
+	blockingService := application.NewBlockingService(normalizer, store,
+		application.WithSnapshots(snapshotStore))
 
 	registryClientConfig := registry.ClientConfig{
 		Sources:       cfg.Registry.Sources,
 		MaxConcurrent: cfg.Registry.MaxConcurrent,
 		Timeout:       cfg.Registry.Timeout,
+
+		TrustBundlePath: cfg.Registry.TrustBundlePath,
 	}
 
 	registryClient, err := registry.NewClient(registryClientConfig)
@@ -50,7 +93,85 @@ func main() {
 		os.Exit(1)
 	}
 
+	changelogStore, err := changelog.NewStore(cfg.Storage.ChangelogLimit, cfg.Storage.SnapshotDir)
+	if err != nil {
+		slog.Error("Failed to create changelog store", "error", err)
+		os.Exit(1)
+	}
+
+	if cfg.Storage.SnapshotDir != "" {
+		// Keeps rknctl backfill from rewriting the history while it is recorded
+		releaseHistory, err := history.Lock(cfg.Storage.SnapshotDir, false)
+		if err != nil {
+			slog.Error("Failed to lock history", "error", err)
+			os.Exit(1)
+		}
+		defer releaseHistory()
+	}
+
+	historyStore, err := history.NewStore(cfg.Storage.SnapshotDir)
+	if err != nil {
+		slog.Error("Failed to create history store", "error", err)
+		os.Exit(1)
+	}
+
 	scheduler := updater.NewScheduler(registryClient, store, cfg.Registry.UpdateConfig)
+	scheduler.AddListener(changelogStore)
+	scheduler.AddListener(historyStore)
+	scheduler.AddListener(snapshotStore)
+
+	searchIndex := search.NewIndex(search.DefaultBudget)
+	scheduler.AddListener(searchIndex)
+
+	// Registered after the changelog store, whose diffs it publishes
+	registryFeed := feed.NewFeed(cfg.Events.Backlog, feed.WithChangelogs(changelogStore))
+	scheduler.AddListener(registryFeed)
+
+	ipIndex := iplookup.NewIndex()
+	scheduler.AddListener(ipIndex)
+
+	collateralService := application.NewCollateralService(normalizer, store, ipIndex)
+
+	webhookStore, err := webhook.NewStore(cfg.Storage.SnapshotDir)
+	if err != nil {
+		slog.Error("Failed to create webhook store", "error", err)
+		os.Exit(1)
+	}
+	webhooks := webhook.NewDispatcher(webhookStore, webhook.Config{
+		Timeout:        cfg.Webhooks.Timeout,
+		MaxAttempts:    cfg.Webhooks.MaxAttempts,
+		InitialBackoff: cfg.Webhooks.InitialBackoff,
+		MaxBackoff:     cfg.Webhooks.MaxBackoff,
+		Workers:        cfg.Webhooks.Workers,
+
+		AllowPrivateNetworks: cfg.Webhooks.AllowPrivateNetworks,
+	})
+	scheduler.AddListener(webhooks)
+	scheduler.AddEventListener(webhooks)
+
+	watchlistStore, err := watchlist.NewStore(cfg.Storage.SnapshotDir)
+	if err != nil {
+		slog.Error("Failed to create watchlist store", "error", err)
+		os.Exit(1)
+	}
+	// Watch callbacks are delivered with the webhook retries and dead letters
+	watchlistService := application.NewWatchlistService(blockingService, watchlistStore, webhooks, cfg.Watchlist.CallbackURL)
+	scheduler.AddListener(watchlistService)
+
+	healthService := application.NewHealthService(store, scheduler, cfg.Health.MaxRegistryAge)
+
+	metrics.RegisterStore(func() metrics.StoreStats {
+		stats := store.Stats()
+		return metrics.StoreStats{
+			CountsByType:           stats.CountsByType,
+			LastUpdate:             stats.LastUpdate,
+			BloomFalsePositiveRate: stats.BloomFalsePositiveRate,
+		}
+	})
+
+	authenticator := setupAuth(cfg)
+	proxies := setupTrustedProxies(cfg.Server)
+	certificates := setupTLS(cfg.TLS)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -62,11 +183,69 @@ func main() {
 		}
 	}()
 
-	grpcServer := grpc.NewServer(blockingService, cfg.Server.GRPCPort)
-	restServer := rest.NewServer(blockingService, cfg.Server.RESTPort)
+	go webhooks.Run(ctx)
+
+	var grpcTLS, restTLS *tls.Config
+	if certificates != nil {
+		go certificates.Run(ctx)
+		grpcTLS = certificates.ServerConfig("h2")
+		restTLS = certificates.ServerConfig("h2", "http/1.1")
+	}
 
 	var wg sync.WaitGroup
 
+	var rateLimiter application.RateLimiter
+	if limiter := setupRateLimiter(cfg); limiter != nil {
+		rateLimiter = limiter
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limiter.Run(ctx)
+		}()
+		go reloadRateLimits(ctx, cfg.RateLimit, limiter)
+	}
+
+	go reloadConfig(ctx, *configFile, cfg, registryClient, scheduler)
+
+	grpcServer := grpc.NewServer(blockingService, cfg.Server.GRPCPort,
+		grpc.WithChangelog(changelogStore),
+		grpc.WithHistory(historyStore),
+		grpc.WithEntryBrowser(store),
+		grpc.WithSearcher(searchIndex),
+		grpc.WithRegistryFeed(registryFeed, cfg.Events.HeartbeatInterval),
+		grpc.WithScheduler(scheduler),
+		grpc.WithAuthenticator(authenticator),
+		grpc.WithRateLimiter(rateLimiter),
+		grpc.WithTrustedProxies(proxies, cfg.Server.ProxyProtocol),
+		grpc.WithHost(cfg.Server.Host),
+		grpc.WithSocket(cfg.Server.GRPCSocket),
+		grpc.WithTLS(grpcTLS),
+		grpc.WithDrainPeriod(cfg.Server.GRPCDrainPeriod),
+		grpc.WithReadiness(healthService))
+	restServer := rest.NewServer(blockingService, cfg.Server.RESTPort,
+		rest.WithIngestReporter(scheduler),
+		rest.WithQuarantineManager(scheduler),
+		rest.WithChangelog(changelogStore),
+		rest.WithHistory(historyStore),
+		rest.WithEntryBrowser(store),
+		rest.WithSearcher(searchIndex),
+		rest.WithIPLookup(ipIndex),
+		rest.WithCollateral(collateralService),
+		rest.WithWatchlist(watchlistService),
+		rest.WithWebhooks(webhooks),
+		rest.WithRegistryFeed(registryFeed, cfg.Events.HeartbeatInterval),
+		rest.WithScheduler(scheduler),
+		rest.WithAuthenticator(authenticator),
+		rest.WithRateLimiter(rateLimiter),
+		rest.WithTrustedProxies(proxies, cfg.Server.ProxyProtocol),
+		rest.WithCORSOrigins(cfg.Server.CORSAllowedOrigins),
+		rest.WithHost(cfg.Server.Host),
+		rest.WithSocket(cfg.Server.RESTSocket),
+		rest.WithTLS(restTLS),
+		rest.WithReadiness(healthService),
+		rest.WithMetrics(metrics.Handler()))
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -94,31 +273,258 @@ func main() {
 	slog.Info("Waiting for servers to shut down...")
 	wg.Wait()
 
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+
 	slog.Info("Service stopped")
 }
 
 func setupLogging(cfg config.LoggingConfig) {
-	var level slog.Level
-	switch cfg.Level {
-	case "debug":
-		level = slog.LevelDebug
-	case "info":
-		level = slog.LevelInfo
-	case "warn":
-		level = slog.LevelWarn
-	case "error":
-		level = slog.LevelError
-	default:
+	if err := logLevel.UnmarshalText([]byte(cfg.Level)); err != nil {
 		slog.Error("Invalid log level", "level", cfg.Level)
 		os.Exit(1)
 	}
 
 	var handler slog.Handler
 	if cfg.Format == "json" {
-		handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level})
+		handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: &logLevel})
 	} else {
-		handler = slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: level})
+		handler = slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: &logLevel})
 	}
 
 	slog.SetDefault(slog.New(handler))
 }
+
+// setupTracing exports traces when enabled and returns the function flushing
+// them on shutdown. W3C trace context is propagated either way.
+func setupTracing(cfg config.TracingConfig) func(context.Context) error {
+	if !cfg.Enabled {
+		tracing.SetPropagator()
+		return func(context.Context) error { return nil }
+	}
+
+	shutdown, err := tracing.Setup(context.Background(), tracing.Config{
+		Endpoint:    cfg.Endpoint,
+		Insecure:    cfg.Insecure,
+		SampleRatio: cfg.SampleRatio,
+	})
+	if err != nil {
+		slog.Error("Failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+
+	slog.Info("Exporting traces", "endpoint", cfg.Endpoint, "sample_ratio", cfg.SampleRatio)
+	return shutdown
+}
+
+// setupAuth builds the authenticator of the REST and gRPC APIs. With auth
+// disabled, callers without credentials keep every scope but admin. When no
+// credential is configured there is no authenticator at all, and the admin
+// APIs are not served.
+func setupAuth(cfg *config.Config) application.Authenticator {
+	if !cfg.HasCredentials() {
+		slog.Warn("No credentials configured, the API is open to any caller and the admin API is disabled")
+		return nil
+	}
+
+	anonymousScopes := cfg.Auth.AnonymousScopes
+	if !cfg.Auth.Enabled {
+		anonymousScopes = []domain.Scope{domain.ScopeCheck, domain.ScopeStats, domain.ScopeRegistry, domain.ScopeWatchlist}
+	}
+
+	authenticator, err := auth.New(auth.Config{
+		Keys:              cfg.Auth.APIKeys,
+		KeysFile:          cfg.Auth.APIKeysFile,
+		AdminToken:        cfg.Admin.Token,
+		JWKSFile:          cfg.Auth.JWKSFile,
+		Issuer:            cfg.Auth.JWTIssuer,
+		Audience:          cfg.Auth.JWTAudience,
+		CertificateScopes: cfg.Auth.ClientCertScopes,
+		AnonymousScopes:   anonymousScopes,
+	})
+	if err != nil {
+		slog.Error("Failed to set up authentication", "error", err)
+		os.Exit(1)
+	}
+
+	slog.Info("Authentication configured", "required", cfg.Auth.Enabled)
+	return authenticator
+}
+
+// setupTrustedProxies builds the resolver of client addresses forwarded by
+// trusted proxies, or returns nil when no proxy is trusted
+func setupTrustedProxies(cfg config.ServerConfig) *proxy.Resolver {
+	if len(cfg.TrustedProxies) == 0 {
+		return nil
+	}
+
+	resolver, err := proxy.NewResolver(cfg.TrustedProxies, cfg.TrustedProxyHeader)
+	if err != nil {
+		slog.Error("Failed to set up trusted proxies", "error", err)
+		os.Exit(1)
+	}
+
+	slog.Info("Trusting proxies", "proxies", cfg.TrustedProxies, "header", resolver.Header(),
+		"proxy_protocol", cfg.ProxyProtocol)
+	return resolver
+}
+
+// setupTLS loads the certificates of the REST and gRPC listeners, or returns
+// nil when they serve plaintext
+func setupTLS(cfg config.TLSConfig) *tlsconfig.Reloader {
+	if !cfg.Enabled() {
+		return nil
+	}
+
+	certificates, err := tlsconfig.New(tlsconfig.Config{
+		CertFile:          cfg.CertFile,
+		KeyFile:           cfg.KeyFile,
+		ClientCAFile:      cfg.ClientCAFile,
+		RequireClientCert: cfg.RequireClientCert,
+		MinVersion:        cfg.MinVersion,
+	})
+	if err != nil {
+		slog.Error("Failed to set up TLS", "error", err)
+		os.Exit(1)
+	}
+
+	slog.Info("Serving TLS", "cert_file", cfg.CertFile, "mutual", cfg.ClientCAFile != "",
+		"min_version", cfg.MinVersion)
+	return certificates
+}
+
+// setupRateLimiter builds the limiter of the REST and gRPC APIs, or returns
+// nil when nothing is limited
+func setupRateLimiter(cfg *config.Config) *ratelimit.Limiter {
+	limits, err := loadRateLimits(cfg.RateLimit)
+	if err != nil {
+		slog.Error("Failed to load rate limits", "error", err)
+		os.Exit(1)
+	}
+	if cfg.RateLimit.File == "" && limits.Unlimited() {
+		return nil
+	}
+
+	limiter, err := ratelimit.NewLimiter(limits, cfg.Storage.SnapshotDir)
+	if err != nil {
+		slog.Error("Failed to set up rate limiting", "error", err)
+		os.Exit(1)
+	}
+
+	slog.Info("Rate limiting configured", "file", cfg.RateLimit.File)
+	return limiter
+}
+
+// loadRateLimits reads the limits file, falling back to the default rule
+// configured through the environment
+func loadRateLimits(cfg config.RateLimitConfig) (ratelimit.Limits, error) {
+	fallback := ratelimit.Rule{Rate: cfg.Rate, Burst: cfg.Burst, DailyQuota: cfg.DailyQuota}
+	if cfg.File == "" {
+		return ratelimit.Limits{Default: fallback}, nil
+	}
+	return ratelimit.LoadLimits(cfg.File, fallback)
+}
+
+// reloadRateLimits reloads the limits file on SIGHUP until ctx is done,
+// keeping the current limits when the file is invalid
+func reloadRateLimits(ctx context.Context, cfg config.RateLimitConfig, limiter *ratelimit.Limiter) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			limits, err := loadRateLimits(cfg)
+			if err == nil {
+				err = limiter.Reload(limits)
+			}
+			if err != nil {
+				slog.Error("Failed to reload rate limits, keeping the current ones", "error", err)
+				continue
+			}
+			slog.Info("Rate limits reloaded", "file", cfg.File)
+		}
+	}
+}
+
+// reloadConfig reloads the configuration on SIGHUP and whenever its file
+// changes, until ctx is done. The registry sources, update interval and log
+// level are applied to the running service; other changes are reported as
+// requiring a restart.
+func reloadConfig(ctx context.Context, path string, cfg *config.Config,
+	registryClient *registry.Client, scheduler *updater.Scheduler) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	var poll <-chan time.Time
+	var stamp os.FileInfo
+	if path != "" {
+		ticker := time.NewTicker(configPollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+		stamp, _ = os.Stat(path)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+		case <-poll:
+			info, err := os.Stat(path)
+			if err != nil || (stamp != nil && info.ModTime().Equal(stamp.ModTime()) && info.Size() == stamp.Size()) {
+				continue
+			}
+			stamp = info
+		}
+
+		next, err := config.Load(path)
+		if err == nil {
+			err = next.Validate()
+		}
+		if err != nil {
+			slog.Error("Failed to reload configuration, keeping the current one", "file", path, "error", err)
+			continue
+		}
+
+		changes := config.Diff(cfg, next)
+		if changes.Empty() {
+			slog.Info("Configuration reloaded without changes", "file", path)
+			continue
+		}
+
+		var applied []string
+		for _, setting := range changes.Reloadable {
+			switch setting {
+			case config.SourcesSetting:
+				if err := registryClient.SetSources(next.Registry.Sources); err != nil {
+					slog.Error("Failed to apply registry sources", "error", err)
+					continue
+				}
+				cfg.Registry.Sources = next.Registry.Sources
+			case config.UpdateIntervalSetting:
+				scheduler.SetInterval(next.Registry.UpdateConfig.Interval)
+				cfg.Registry.UpdateConfig.Interval = next.Registry.UpdateConfig.Interval
+			case config.LogLevelSetting:
+				// Validate only accepts levels slog parses
+				logLevel.UnmarshalText([]byte(next.Logging.Level))
+				cfg.Logging.Level = next.Logging.Level
+			}
+			applied = append(applied, setting)
+		}
+
+		if len(applied) > 0 {
+			slog.Info("Configuration reloaded", "file", path, "applied", applied)
+		}
+		if len(changes.RestartRequired) > 0 {
+			slog.Warn("Configuration changes require a restart", "file", path, "settings", changes.RestartRequired)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/history"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/registry"
+)
+
+// runBackfill parses the backfill flags and replays the dumps
+func runBackfill(args []string) error {
+	flags := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	dumpDir := flags.String("dir", "", "directory of archived registry dumps (CSV or ZIP)")
+	snapshotDir := flags.String("snapshot-dir", os.Getenv("SNAPSHOT_DIR"), "snapshot directory the service persists its history to")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *dumpDir == "" {
+		return errors.New("-dir is required")
+	}
+	if *snapshotDir == "" {
+		return errors.New("-snapshot-dir or SNAPSHOT_DIR is required")
+	}
+
+	release, err := history.Lock(*snapshotDir, true)
+	if errors.Is(err, history.ErrLocked) {
+		return errors.New("the history is in use, stop the service before backfilling")
+	}
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	store, err := history.NewStore(*snapshotDir)
+	if err != nil {
+		return err
+	}
+
+	return backfill(*dumpDir, store, os.Stdout)
+}
+
+// backfill replays the dumps in dir through the parser, oldest first by
+// modification time, and records each as a registry version at its place in
+// the history, so dumps older than the versions the service recorded are
+// merged in. Dumps whose version was already recorded are skipped, so the
+// command can be rerun as more dumps are archived.
+func backfill(dir string, store *history.Store, out io.Writer) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("reading dump directory: %w", err)
+	}
+
+	type dump struct {
+		path string
+		info os.FileInfo
+	}
+
+	var dumps []dump
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", entry.Name(), err)
+		}
+		dumps = append(dumps, dump{path: filepath.Join(dir, entry.Name()), info: info})
+	}
+	sort.Slice(dumps, func(i, j int) bool {
+		return dumps[i].info.ModTime().Before(dumps[j].info.ModTime())
+	})
+
+	parser := registry.NewParser()
+	replayed := 0
+
+	for _, d := range dumps {
+		modTime := d.info.ModTime().UTC()
+		version := modTime.Format(registry.VersionLayout)
+		if store.Recorded(version) {
+			fmt.Fprintf(out, "skip   %s: version %s is already recorded\n", d.path, version)
+			continue
+		}
+
+		file, err := os.Open(d.path)
+		if err != nil {
+			return err
+		}
+		reg, report, err := parser.Parse(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", d.path, err)
+		}
+
+		reg.Version = version
+		added, removed := store.Insert(reg, modTime)
+		replayed++
+
+		fmt.Fprintf(out, "replay %s: version %s, %d entries, %d rejected, %d added, %d removed\n",
+			d.path, reg.Version, report.Accepted, report.RejectedCount, added, removed)
+	}
+
+	if replayed == 0 {
+		return nil
+	}
+	if err := store.Save(); err != nil {
+		return fmt.Errorf("saving history: %w", err)
+	}
+
+	fmt.Fprintf(out, "replayed %d of %d dumps\n", replayed, len(dumps))
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/history"
+)
+
+// writeDump writes a CSV dump with the given modification time
+func writeDump(t *testing.T, dir, name, data string, modTime time.Time) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBackfill(t *testing.T) {
+	dumps := t.TempDir()
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	// Written out of order to check dumps are replayed by modification time
+	writeDump(t, dumps, "b.csv", "id;url;date\n1;example.com;2024-06-02", start.Add(24*time.Hour))
+	writeDump(t, dumps, "a.csv", "id;url;date\n1;example.com;2024-06-01\n2;*.gone.com;2024-06-01", start)
+
+	snapshots := t.TempDir()
+	store, err := history.NewStore(snapshots)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := backfill(dumps, store, io.Discard); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reloaded, err := history.NewStore(snapshots)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	version, at := reloaded.LastVersion()
+	if version != "20240602T000000.000Z" || !at.Equal(start.Add(24*time.Hour)) {
+		t.Errorf("unexpected last version %q at %v", version, at)
+	}
+
+	histories, _ := reloaded.History("sub.gone.com")
+	if len(histories) != 1 || len(histories[0].Events) != 2 || histories[0].Blocked() {
+		t.Errorf("expected wildcard to be added and removed, got %+v", histories)
+	}
+
+	// Rerunning only replays dumps whose version is not recorded yet
+	writeDump(t, dumps, "c.csv", "id;url;date\n1;new.com;2024-06-03", start.Add(48*time.Hour))
+	if err := backfill(dumps, reloaded, io.Discard); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	histories, _ = reloaded.History("example.com")
+	if len(histories) != 1 || len(histories[0].Events) != 2 {
+		t.Errorf("expected example.com to be removed once, got %+v", histories)
+	}
+
+	// Dumps older than the recorded history are merged in before it
+	writeDump(t, dumps, "old.csv", "id;url;date\n1;old.com;2024-05-31", start.Add(-24*time.Hour))
+	if err := backfill(dumps, reloaded, io.Discard); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	histories, _ = reloaded.History("old.com")
+	if len(histories) != 1 || len(histories[0].Events) != 2 ||
+		histories[0].Events[0].Version != "20240531T000000.000Z" || histories[0].Events[1].Version != "20240601T000000.000Z" {
+		t.Errorf("expected old.com to be added by the older dump and removed by the next, got %+v", histories)
+	}
+	histories, _ = reloaded.History("example.com")
+	if len(histories) != 1 || histories[0].Events[0].Version != "20240601T000000.000Z" {
+		t.Errorf("expected example.com to still be added by the first dump that had it, got %+v", histories)
+	}
+	if version, _ := reloaded.LastVersion(); version != "20240603T000000.000Z" {
+		t.Errorf("expected the last version to stay the newest dump, got %s", version)
+	}
+}
+
+func TestRunBackfill_RefusesLockedHistory(t *testing.T) {
+	snapshots := t.TempDir()
+	release, err := history.Lock(snapshots, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer release()
+
+	if err := runBackfill([]string{"-dir", t.TempDir(), "-snapshot-dir", snapshots}); err == nil {
+		t.Error("expected backfill to refuse a history the service holds")
+	}
+}
+
+func TestRunBackfill_RequiresFlags(t *testing.T) {
+	t.Setenv("SNAPSHOT_DIR", "")
+
+	if err := runBackfill([]string{"-snapshot-dir", t.TempDir()}); err == nil {
+		t.Error("expected error without -dir")
+	}
+	if err := runBackfill([]string{"-dir", t.TempDir()}); err == nil {
+		t.Error("expected error without -snapshot-dir")
+	}
+}
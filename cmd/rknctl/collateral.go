@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kerim-dauren/rkn-checker/internal/application"
+	"github.com/kerim-dauren/rkn-checker/internal/domain/services"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/collateral"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/iplookup"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/registry"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/storage"
+)
+
+// runCollateral parses the collateral flags and writes the report
+func runCollateral(args []string) error {
+	flags := flag.NewFlagSet("collateral", flag.ContinueOnError)
+	dumpPath := flags.String("dump", "", "registry dump to analyse against (CSV or ZIP)")
+	hostedPath := flags.String("hosted", "-", `CSV or JSON list of hosted domains and their IPs, "-" for stdin`)
+	format := flags.String("format", collateral.FormatJSON, "report format, json or csv")
+	outPath := flags.String("o", "", "write the report to a file instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *dumpPath == "" {
+		return errors.New("-dump is required")
+	}
+	if *format != collateral.FormatJSON && *format != collateral.FormatCSV {
+		return fmt.Errorf("unsupported format %q", *format)
+	}
+
+	var hosted io.Reader = os.Stdin
+	if *hostedPath != "-" {
+		file, err := os.Open(*hostedPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		hosted = file
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "" {
+		file, err := os.Create(*outPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	return collateralReport(*dumpPath, hosted, *format, out)
+}
+
+// collateralReport analyses the hosted domains against a registry dump and
+// writes the report in the given format
+func collateralReport(dumpPath string, hostedList io.Reader, format string, out io.Writer) error {
+	hosted, err := collateral.ParseHosted(hostedList)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(dumpPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reg, _, err := registry.NewParser().Parse(file)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", dumpPath, err)
+	}
+	if info, err := file.Stat(); err == nil {
+		reg.Version = info.ModTime().UTC().Format(registry.VersionLayout)
+	}
+
+	store := storage.NewMemoryStore()
+	if err := store.Update(reg); err != nil {
+		return err
+	}
+	ips := iplookup.NewIndex()
+	ips.Build(reg)
+
+	analyzer := application.NewCollateralService(services.NewURLNormalizer(), store, ips)
+	report, err := analyzer.Analyze(context.Background(), hosted)
+	if err != nil {
+		return err
+	}
+
+	return collateral.Write(out, report, format)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/collateral"
+)
+
+func TestCollateralReport(t *testing.T) {
+	dir := t.TempDir()
+	writeDump(t, dir, "dump.csv", `Updated: 2024-06-01 10:00:00 +0000
+203.0.113.7;casino.example;;Court;2-1;2024-05-01
+198.51.100.0/24;;;Agency;3;2024-05-02
+192.0.2.1;ours.example;;Court;4;2024-05-03`, time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC))
+
+	hosted := `domain,ip
+shop.example,203.0.113.7
+blog.example,198.51.100.20
+ours.example,192.0.2.1
+clean.example,192.0.2.99
+`
+
+	var out bytes.Buffer
+	if err := collateralReport(dir+"/dump.csv", strings.NewReader(hosted), collateral.FormatJSON, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc collateral.Document
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+
+	if doc.Version != "20240601T100000.000Z" {
+		t.Errorf("expected the dump version, got %s", doc.Version)
+	}
+	expected := map[string]string{
+		"shop.example":  "collateral",
+		"blog.example":  "collateral",
+		"ours.example":  "listed",
+		"clean.example": "clear",
+	}
+	for _, d := range doc.Domains {
+		if d.Status != expected[d.Domain] {
+			t.Errorf("%s: expected %s, got %s", d.Domain, expected[d.Domain], d.Status)
+		}
+	}
+
+	out.Reset()
+	if err := collateralReport(dir+"/dump.csv", strings.NewReader(hosted), collateral.FormatCSV, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "domain,status,") {
+		t.Errorf("expected a CSV report, got %q", out.String())
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/auth"
+)
+
+// keyBytes is the entropy of generated API keys
+const keyBytes = 32
+
+// runHashKey parses the hash-key flags and prints a new API key with its
+// AUTH_API_KEYS entry
+func runHashKey(args []string) error {
+	flags := flag.NewFlagSet("hash-key", flag.ContinueOnError)
+	name := flags.String("name", "", "client name the key identifies in logs")
+	scopes := flags.String("scopes", string(domain.ScopeCheck), "comma-separated scopes granted to the key")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *name == "" {
+		return errors.New("-name is required")
+	}
+
+	return hashKey(*name, strings.Split(*scopes, ","), rand.Reader, os.Stdout)
+}
+
+// hashKey generates a key from random and writes it, to hand to the client,
+// followed by the entry configuring its hash
+func hashKey(name string, scopes []string, random io.Reader, out io.Writer) error {
+	raw := make([]byte, keyBytes)
+	if _, err := io.ReadFull(random, raw); err != nil {
+		return fmt.Errorf("generating key: %w", err)
+	}
+	key := base64.RawURLEncoding.EncodeToString(raw)
+
+	for i := range scopes {
+		scopes[i] = strings.TrimSpace(scopes[i])
+	}
+	entry := name + ":" + auth.HashKey(key) + ":" + strings.Join(scopes, "+")
+	if _, err := auth.ParseKeys(entry); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(out, "key:   %s\nentry: %s\n", key, entry)
+	return err
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/auth"
+)
+
+func TestHashKey(t *testing.T) {
+	random := bytes.NewReader(bytes.Repeat([]byte{7}, keyBytes))
+
+	var out bytes.Buffer
+	if err := hashKey("billing", []string{"check", " stats"}, random, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected the key and its entry, got %q", out.String())
+	}
+	key := strings.TrimSpace(strings.TrimPrefix(lines[0], "key:"))
+	entry := strings.TrimSpace(strings.TrimPrefix(lines[1], "entry:"))
+
+	if strings.Contains(entry, key) {
+		t.Error("expected the entry to hold the hash of the key, not the key")
+	}
+	if want := "billing:" + auth.HashKey(key) + ":check+stats"; entry != want {
+		t.Errorf("expected entry %q, got %q", want, entry)
+	}
+
+	if err := hashKey("billing", []string{"everything"}, random, &out); err == nil {
+		t.Error("expected an error for an unknown scope")
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `Usage: rknctl <command> [flags]
+
+Commands:
+  backfill    Seed the domain history from a directory of archived registry dumps
+  collateral  Report hosted domains affected by IP and subnet rules issued for other domains
+  hash-key    Generate an API key and the AUTH_API_KEYS entry storing its hash
+
+Run "rknctl <command> -h" for the flags of a command.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "backfill":
+		err = runBackfill(os.Args[2:])
+	case "collateral":
+		err = runCollateral(os.Args[2:])
+	case "hash-key":
+		err = runHashKey(os.Args[2:])
+	case "-h", "--help", "help":
+		fmt.Fprint(os.Stdout, usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rknctl %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
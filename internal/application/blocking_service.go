@@ -4,22 +4,173 @@ import (
 	"context"
 	"time"
 
+	"go.opentelemetry.io/otel"
+
 	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/metrics"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/tracing"
 )
 
+// tracer records URL checks
+var tracer = otel.Tracer("github.com/kerim-dauren/rkn-checker/internal/application")
+
 type BlockingService struct {
 	normalizer URLNormalizer
 	store      RegistryStore
+	snapshots  SnapshotProvider
+}
+
+// Option configures optional BlockingService dependencies
+type Option func(*BlockingService)
+
+// WithSnapshots answers point-in-time checks from retained registry snapshots
+func WithSnapshots(snapshots SnapshotProvider) Option {
+	return func(bs *BlockingService) {
+		bs.snapshots = snapshots
+	}
 }
 
-func NewBlockingService(normalizer URLNormalizer, store RegistryStore) *BlockingService {
-	return &BlockingService{
+func NewBlockingService(normalizer URLNormalizer, store RegistryStore, opts ...Option) *BlockingService {
+	bs := &BlockingService{
 		normalizer: normalizer,
 		store:      store,
 	}
+	for _, opt := range opts {
+		opt(bs)
+	}
+	return bs
 }
 
+// CheckURL checks a URL against the current registry. Until a registry is
+// loaded it returns domain.ErrRegistryNotReady rather than reporting every
+// URL as allowed.
 func (bs *BlockingService) CheckURL(ctx context.Context, rawURL string) (*domain.BlockingResult, error) {
+	ctx, span := tracer.Start(ctx, "BlockingService.CheckURL")
+	defer span.End()
+
+	url, err := bs.normalize(ctx, rawURL)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	if !bs.RegistryLoaded() {
+		tracing.RecordError(span, domain.ErrRegistryNotReady)
+		return nil, domain.ErrRegistryNotReady
+	}
+
+	result := isBlocked(ctx, bs.store, url.Normalized())
+
+	if result == nil {
+		result = domain.NewBlockingResult(false, url.Normalized(), nil)
+	}
+
+	metrics.ObserveCheck(result)
+	span.SetAttributes(tracing.ResultAttributes(result)...)
+	return result, nil
+}
+
+// LookupURL looks a URL up in the current registry on behalf of the service
+// itself, e.g. to re-evaluate watched targets. Unlike CheckURL it is neither
+// counted in the check metrics nor traced as a check.
+func (bs *BlockingService) LookupURL(rawURL string) (*domain.BlockingResult, error) {
+	url, err := bs.parseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bs.RegistryLoaded() {
+		return nil, domain.ErrRegistryNotReady
+	}
+
+	result := bs.store.IsBlocked(url.Normalized())
+	if result == nil {
+		result = domain.NewBlockingResult(false, url.Normalized(), nil)
+	}
+	return result, nil
+}
+
+// CheckURLAt checks a URL against the registry selected by point. A point
+// outside the retained snapshots returns domain.ErrSnapshotNotRetained rather
+// than falling back to the current registry.
+func (bs *BlockingService) CheckURLAt(ctx context.Context, rawURL string, point domain.RegistryPoint) (*domain.BlockingResult, error) {
+	if point.IsCurrent() {
+		return bs.CheckURL(ctx, rawURL)
+	}
+
+	ctx, span := tracer.Start(ctx, "BlockingService.CheckURLAt")
+	defer span.End()
+
+	result, err := bs.checkSnapshot(ctx, rawURL, point)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	metrics.ObserveCheck(result)
+	span.SetAttributes(tracing.ResultAttributes(result)...)
+	return result, nil
+}
+
+// checkSnapshot checks a URL against the retained snapshot selected by point
+func (bs *BlockingService) checkSnapshot(ctx context.Context, rawURL string, point domain.RegistryPoint) (*domain.BlockingResult, error) {
+	url, err := bs.normalize(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if bs.snapshots == nil {
+		return nil, domain.ErrSnapshotNotRetained
+	}
+
+	var snapshot RegistryLookup
+	if point.Version != "" {
+		snapshot, err = bs.snapshots.SnapshotByVersion(point.Version)
+	} else {
+		snapshot, err = bs.snapshots.SnapshotAt(point.At)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := isBlocked(ctx, snapshot, url.Normalized())
+	if result == nil {
+		result = domain.NewBlockingResult(false, url.Normalized(), nil)
+	}
+	result.RegistryVersion = snapshot.Stats().Version
+
+	return result, nil
+}
+
+// contextLookup is a RegistryLookup that traces its lookups under ctx
+type contextLookup interface {
+	IsBlockedContext(ctx context.Context, normalizedURL string) *domain.BlockingResult
+}
+
+// isBlocked looks a normalized URL up, tracing the lookup when supported
+func isBlocked(ctx context.Context, lookup RegistryLookup, normalizedURL string) *domain.BlockingResult {
+	if traced, ok := lookup.(contextLookup); ok {
+		return traced.IsBlockedContext(ctx, normalizedURL)
+	}
+	return lookup.IsBlocked(normalizedURL)
+}
+
+// normalize parses and normalizes a URL for lookup, counting and tracing
+// failures
+func (bs *BlockingService) normalize(ctx context.Context, rawURL string) (*domain.URL, error) {
+	_, span := tracer.Start(ctx, "normalize")
+	defer span.End()
+
+	url, err := bs.parseURL(rawURL)
+	if err != nil {
+		metrics.ObserveNormalizationFailure(err)
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return url, nil
+}
+
+func (bs *BlockingService) parseURL(rawURL string) (*domain.URL, error) {
 	if rawURL == "" {
 		return nil, domain.ErrEmptyURL
 	}
@@ -37,13 +188,12 @@ func (bs *BlockingService) CheckURL(ctx context.Context, rawURL string) (*domain
 		return nil, domain.ErrInvalidURL
 	}
 
-	result := bs.store.IsBlocked(url.Normalized())
-
-	if result == nil {
-		return domain.NewBlockingResult(false, url.Normalized(), nil), nil
-	}
+	return url, nil
+}
 
-	return result, nil
+// RegistryLoaded reports whether the store holds a registry
+func (bs *BlockingService) RegistryLoaded() bool {
+	return bs.store.Size() > 0
 }
 
 func (bs *BlockingService) GetStats(ctx context.Context) (*BlockingStats, error) {
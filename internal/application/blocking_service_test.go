@@ -2,11 +2,20 @@ package application
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 
 	"github.com/kerim-dauren/rkn-checker/internal/domain"
 	"github.com/kerim-dauren/rkn-checker/internal/domain/services"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/metrics"
 	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/storage"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/tracing"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/tracing/tracingtest"
 )
 
 func TestNewBlockingService(t *testing.T) {
@@ -89,6 +98,147 @@ func TestBlockingService_CheckURL(t *testing.T) {
 	}
 }
 
+func TestBlockingService_RegistryLoaded(t *testing.T) {
+	if NewBlockingService(services.NewURLNormalizer(), storage.NewMemoryStore()).RegistryLoaded() {
+		t.Error("RegistryLoaded() = true for an empty store")
+	}
+
+	if !createTestBlockingService().RegistryLoaded() {
+		t.Error("RegistryLoaded() = false for a loaded store")
+	}
+}
+
+func TestBlockingService_CheckURL_NotReady(t *testing.T) {
+	service := NewBlockingService(services.NewURLNormalizer(), storage.NewMemoryStore())
+	allowed := testutil.ToFloat64(metrics.Checks.WithLabelValues("allowed", "none"))
+
+	if _, err := service.CheckURL(context.Background(), "https://example.com"); !errors.Is(err, domain.ErrRegistryNotReady) {
+		t.Errorf("expected ErrRegistryNotReady before a registry is loaded, got %v", err)
+	}
+	if _, err := service.CheckURLAt(context.Background(), "https://example.com", domain.RegistryPoint{}); !errors.Is(err, domain.ErrRegistryNotReady) {
+		t.Errorf("expected ErrRegistryNotReady for the current point, got %v", err)
+	}
+	if _, err := service.LookupURL("https://example.com"); !errors.Is(err, domain.ErrRegistryNotReady) {
+		t.Errorf("expected ErrRegistryNotReady for lookups, got %v", err)
+	}
+	if _, err := service.CheckURL(context.Background(), ""); !errors.Is(err, domain.ErrEmptyURL) {
+		t.Errorf("expected invalid URLs to be reported first, got %v", err)
+	}
+	if got := testutil.ToFloat64(metrics.Checks.WithLabelValues("allowed", "none")); got != allowed {
+		t.Error("expected checks refused before a registry is loaded not to be counted as allowed")
+	}
+}
+
+// mockSnapshots serves a single retained snapshot
+type mockSnapshots struct {
+	version string
+	store   *storage.MemoryStore
+}
+
+func (m *mockSnapshots) SnapshotAt(at time.Time) (RegistryLookup, error) {
+	if at.Before(m.store.GetLastUpdateTime().Add(-time.Hour)) {
+		return nil, domain.ErrSnapshotNotRetained
+	}
+	return m.store, nil
+}
+
+func (m *mockSnapshots) SnapshotByVersion(version string) (RegistryLookup, error) {
+	if version != m.version {
+		return nil, domain.ErrSnapshotNotRetained
+	}
+	return m.store, nil
+}
+
+func TestBlockingService_CheckURL_Spans(t *testing.T) {
+	exporter := tracingtest.Exporter(t)
+	service := createTestBlockingService()
+
+	if _, err := service.CheckURL(context.Background(), "https://blocked.com/page"); err != nil {
+		t.Fatalf("CheckURL() unexpected error: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	check := tracingtest.Find(spans, "BlockingService.CheckURL")
+	if check == nil {
+		t.Fatalf("expected a check span, got %d spans", len(spans))
+	}
+	attrs := attribute.NewSet(check.Attributes...)
+	if v, _ := attrs.Value(tracing.BlockedKey); !v.AsBool() {
+		t.Error("expected the check span to record the block")
+	}
+	if v, _ := attrs.Value(tracing.MatchTypeKey); v.AsString() != "domain" {
+		t.Errorf("expected match type domain, got %q", v.AsString())
+	}
+
+	for _, name := range []string{"normalize", "MemoryStore.IsBlocked"} {
+		span := tracingtest.Find(spans, name)
+		if span == nil {
+			t.Errorf("expected a %s span", name)
+			continue
+		}
+		if span.Parent.SpanID() != check.SpanContext.SpanID() {
+			t.Errorf("expected the %s span under the check span", name)
+		}
+	}
+}
+
+func TestBlockingService_CheckURL_NormalizationErrorSpan(t *testing.T) {
+	exporter := tracingtest.Exporter(t)
+	service := createTestBlockingService()
+
+	if _, err := service.CheckURL(context.Background(), ""); err == nil {
+		t.Fatal("expected an error for an empty URL")
+	}
+
+	spans := exporter.GetSpans()
+	for _, name := range []string{"normalize", "BlockingService.CheckURL"} {
+		span := tracingtest.Find(spans, name)
+		if span == nil || span.Status.Code != codes.Error {
+			t.Errorf("expected the %s span to record the error", name)
+		}
+	}
+	if tracingtest.Find(spans, "MemoryStore.IsBlocked") != nil {
+		t.Error("expected no lookup for a URL that failed normalization")
+	}
+}
+
+func TestBlockingService_CheckURLAt(t *testing.T) {
+	ctx := context.Background()
+
+	registry := domain.NewRegistry()
+	registry.Version = "v1"
+	entry, _ := domain.NewRegistryEntry(domain.BlockingTypeDomain, "old-blocked.com")
+	registry.AddEntry(entry)
+
+	snapshot := storage.NewMemoryStore()
+	snapshot.Update(registry)
+
+	service := createTestBlockingService()
+	if _, err := service.CheckURLAt(ctx, "https://old-blocked.com", domain.RegistryPoint{Version: "v1"}); !errors.Is(err, domain.ErrSnapshotNotRetained) {
+		t.Errorf("expected ErrSnapshotNotRetained without snapshots, got %v", err)
+	}
+
+	service.snapshots = &mockSnapshots{version: "v1", store: snapshot}
+
+	result, err := service.CheckURLAt(ctx, "https://old-blocked.com", domain.RegistryPoint{Version: "v1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsBlocked || result.RegistryVersion != "v1" {
+		t.Errorf("expected blocked result from v1, got %+v", result)
+	}
+
+	result, _ = service.CheckURLAt(ctx, "https://old-blocked.com", domain.RegistryPoint{})
+	if result.IsBlocked || result.RegistryVersion != "" {
+		t.Errorf("expected the zero point to check the current registry, got %+v", result)
+	}
+
+	_, err = service.CheckURLAt(ctx, "https://old-blocked.com", domain.RegistryPoint{At: time.Now().Add(-48 * time.Hour)})
+	if !errors.Is(err, domain.ErrSnapshotNotRetained) {
+		t.Errorf("expected ErrSnapshotNotRetained, got %v", err)
+	}
+}
+
 func TestBlockingService_GetStats(t *testing.T) {
 	service := createTestBlockingService()
 	ctx := context.Background()
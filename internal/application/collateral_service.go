@@ -0,0 +1,297 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+const (
+	// CollateralJobRetention is how long finished collateral jobs are kept
+	CollateralJobRetention = time.Hour
+	// CollateralJobTimeout bounds how long a single analysis may run
+	CollateralJobTimeout = 10 * time.Minute
+	// DefaultCollateralWorkers is the number of analyses run at once
+	DefaultCollateralWorkers = 2
+	// DefaultCollateralMaxPending is the number of jobs waiting for a worker
+	// before submissions are refused
+	DefaultCollateralMaxPending = 16
+)
+
+// CollateralService finds hosted domains that are affected only through IP
+// and subnet rules issued for other domains, and runs that analysis as
+// background jobs
+type CollateralService struct {
+	normalizer URLNormalizer
+	store      RegistryLookup
+	ips        IPLookup
+
+	queue chan collateralTask
+
+	mu   sync.Mutex
+	jobs map[string]*domain.CollateralJob
+}
+
+// collateralTask is a submitted job waiting for a worker
+type collateralTask struct {
+	id     string
+	hosted []domain.HostedDomain
+}
+
+// CollateralOption configures a CollateralService
+type CollateralOption func(*collateralLimits)
+
+type collateralLimits struct {
+	workers    int
+	maxPending int
+}
+
+// WithJobLimits sets how many analyses run at once and how many submitted
+// jobs may wait for them
+func WithJobLimits(workers, maxPending int) CollateralOption {
+	return func(limits *collateralLimits) {
+		if workers > 0 {
+			limits.workers = workers
+		}
+		if maxPending > 0 {
+			limits.maxPending = maxPending
+		}
+	}
+}
+
+func NewCollateralService(normalizer URLNormalizer, store RegistryLookup, ips IPLookup, opts ...CollateralOption) *CollateralService {
+	limits := collateralLimits{workers: DefaultCollateralWorkers, maxPending: DefaultCollateralMaxPending}
+	for _, opt := range opts {
+		opt(&limits)
+	}
+
+	cs := &CollateralService{
+		normalizer: normalizer,
+		store:      store,
+		ips:        ips,
+		queue:      make(chan collateralTask, limits.maxPending),
+		jobs:       make(map[string]*domain.CollateralJob),
+	}
+	for range limits.workers {
+		go cs.work()
+	}
+	return cs
+}
+
+// Analyze checks every hosted domain against the current registry. A domain
+// is listed when a rule or an IP record names it, collateral when it is only
+// reached through IP and subnet records naming other domains, and clear
+// otherwise.
+func (cs *CollateralService) Analyze(ctx context.Context, hosted []domain.HostedDomain) (*domain.CollateralReport, error) {
+	if !cs.registryLoaded() {
+		return nil, domain.ErrRegistryNotReady
+	}
+
+	report := &domain.CollateralReport{
+		Version:     cs.store.Stats().Version,
+		GeneratedAt: time.Now(),
+		Findings:    make([]*domain.CollateralFinding, 0, len(hosted)),
+	}
+
+	for _, host := range hosted {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		finding, err := cs.analyzeDomain(host)
+		if err != nil {
+			return nil, err
+		}
+		report.Findings = append(report.Findings, finding)
+	}
+
+	return report, nil
+}
+
+// registryLoaded reports whether the registry holds any entry; until then
+// every domain would be reported clear
+func (cs *CollateralService) registryLoaded() bool {
+	return cs.store.Stats().TotalEntries > 0
+}
+
+func (cs *CollateralService) analyzeDomain(host domain.HostedDomain) (*domain.CollateralFinding, error) {
+	normalized, err := cs.normalizer.Normalize(host.Domain)
+	if err != nil {
+		return nil, fmt.Errorf("%w: domain %q: %v", domain.ErrInvalidHostedList, host.Domain, err)
+	}
+
+	finding := &domain.CollateralFinding{
+		Domain: normalized,
+		IPs:    host.IPs,
+		Status: domain.CollateralStatusClear,
+	}
+
+	if result := cs.store.IsBlocked(normalized); result != nil && result.IsBlocked {
+		finding.Status = domain.CollateralStatusListed
+		finding.Rule = result.Rule
+	}
+
+	named := make(map[string]bool)
+	for _, pattern := range domain.CoveringPatterns(normalized) {
+		named[pattern] = true
+	}
+
+	for _, ip := range host.IPs {
+		lookup, err := cs.ips.LookupIP(ip)
+		if err != nil {
+			return nil, fmt.Errorf("%w: domain %q: %v", domain.ErrInvalidHostedList, host.Domain, err)
+		}
+
+		for _, record := range lookup.Records {
+			if recordNames(record, named) {
+				finding.Status = domain.CollateralStatusListed
+				continue
+			}
+			finding.Hits = append(finding.Hits, domain.CollateralHit{IP: lookup.IP, Record: record})
+		}
+	}
+
+	if finding.Status == domain.CollateralStatusClear && len(finding.Hits) > 0 {
+		finding.Status = domain.CollateralStatusCollateral
+	}
+
+	return finding, nil
+}
+
+// recordNames reports whether a record lists one of the named patterns as a
+// domain or as the host of a URL
+func recordNames(record *domain.IPRecord, named map[string]bool) bool {
+	for _, value := range record.Domains {
+		if named[value] {
+			return true
+		}
+	}
+	for _, value := range record.URLs {
+		if i := strings.Index(value, "://"); i >= 0 {
+			value = value[i+3:]
+		}
+		if i := strings.IndexAny(value, "/?#"); i >= 0 {
+			value = value[:i]
+		}
+		if named[value] {
+			return true
+		}
+	}
+	return false
+}
+
+// Submit queues a collateral analysis for the background workers and
+// returns the pending job. It fails with domain.ErrTooManyJobs when the
+// queue is full.
+func (cs *CollateralService) Submit(hosted []domain.HostedDomain) (*domain.CollateralJob, error) {
+	if len(hosted) == 0 {
+		return nil, fmt.Errorf("%w: no domains", domain.ErrInvalidHostedList)
+	}
+	if !cs.registryLoaded() {
+		return nil, domain.ErrRegistryNotReady
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	job := &domain.CollateralJob{
+		ID:          id,
+		Status:      domain.JobStatusPending,
+		Domains:     len(hosted),
+		SubmittedAt: time.Now(),
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.pruneJobs(job.SubmittedAt)
+	select {
+	case cs.queue <- collateralTask{id: id, hosted: hosted}:
+	default:
+		return nil, fmt.Errorf("%w: %d collateral jobs are waiting", domain.ErrTooManyJobs, cap(cs.queue))
+	}
+	cs.jobs[id] = job
+	submitted := *job
+
+	return &submitted, nil
+}
+
+// Job returns a copy of the job with the given ID
+func (cs *CollateralService) Job(id string) (*domain.CollateralJob, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	job, ok := cs.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrJobNotFound, id)
+	}
+	copied := *job
+	return &copied, nil
+}
+
+// work runs queued jobs one at a time
+func (cs *CollateralService) work() {
+	for task := range cs.queue {
+		cs.run(task.id, task.hosted)
+	}
+}
+
+func (cs *CollateralService) run(id string, hosted []domain.HostedDomain) {
+	cs.update(id, func(job *domain.CollateralJob) {
+		job.Status = domain.JobStatusRunning
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), CollateralJobTimeout)
+	defer cancel()
+	report, err := cs.Analyze(ctx, hosted)
+
+	cs.update(id, func(job *domain.CollateralJob) {
+		job.FinishedAt = time.Now()
+		if err != nil {
+			job.Status = domain.JobStatusFailed
+			job.Error = err.Error()
+			slog.Warn("Collateral analysis failed", "job", id, "error", err)
+			return
+		}
+		job.Status = domain.JobStatusDone
+		job.Report = report
+		slog.Info("Collateral analysis finished", "job", id, "domains", len(hosted),
+			"collateral", report.Count(domain.CollateralStatusCollateral))
+	})
+}
+
+func (cs *CollateralService) update(id string, fn func(*domain.CollateralJob)) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if job, ok := cs.jobs[id]; ok {
+		fn(job)
+	}
+}
+
+// pruneJobs drops jobs that finished more than CollateralJobRetention ago.
+// The caller must hold the lock.
+func (cs *CollateralService) pruneJobs(now time.Time) {
+	for id, job := range cs.jobs {
+		if !job.FinishedAt.IsZero() && now.Sub(job.FinishedAt) > CollateralJobRetention {
+			delete(cs.jobs, id)
+		}
+	}
+}
+
+// newID returns a random identifier for jobs and watch items
+func newID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("generating ID: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
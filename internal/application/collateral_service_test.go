@@ -0,0 +1,181 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/domain/services"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/iplookup"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/storage"
+)
+
+func createTestCollateralService() *CollateralService {
+	store, ips := createTestCollateralRegistry()
+	return NewCollateralService(services.NewURLNormalizer(), store, ips)
+}
+
+func createTestCollateralRegistry() (*storage.MemoryStore, *iplookup.Index) {
+	registry := domain.NewRegistry()
+	registry.Version = "v1"
+	add := func(recordID string, entryType domain.BlockingType, value string) {
+		entry, _ := domain.NewRegistryEntry(entryType, value)
+		entry.RecordID = recordID
+		registry.AddEntry(entry)
+	}
+	add("1", domain.BlockingTypeIP, "1.1.1.1")
+	add("1", domain.BlockingTypeDomain, "other.com")
+	add("2", domain.BlockingTypeSubnet, "2.2.0.0/16")
+	add("3", domain.BlockingTypeIP, "3.3.3.3")
+	add("3", domain.BlockingTypeURLPath, "ours.com/page")
+	add("4", domain.BlockingTypeDomain, "listed.com")
+
+	store := storage.NewMemoryStore()
+	store.Update(registry)
+	ips := iplookup.NewIndex()
+	ips.Build(registry)
+
+	return store, ips
+}
+
+func TestCollateralService_Analyze(t *testing.T) {
+	service := createTestCollateralService()
+
+	hosted := []domain.HostedDomain{
+		{Domain: "shared.com", IPs: []string{"1.1.1.1"}},
+		{Domain: "Subnetted.com", IPs: []string{"8.8.8.8", "2.2.5.5"}},
+		{Domain: "ours.com", IPs: []string{"3.3.3.3"}},
+		{Domain: "listed.com", IPs: []string{"9.9.9.9"}},
+		{Domain: "clean.com", IPs: []string{"8.8.8.8"}},
+	}
+
+	report, err := service.Analyze(context.Background(), hosted)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Version != "v1" {
+		t.Errorf("expected version v1, got %s", report.Version)
+	}
+
+	expected := []struct {
+		domain string
+		status domain.CollateralStatus
+		hits   int
+	}{
+		{"shared.com", domain.CollateralStatusCollateral, 1},
+		{"subnetted.com", domain.CollateralStatusCollateral, 1},
+		{"ours.com", domain.CollateralStatusListed, 0},
+		{"listed.com", domain.CollateralStatusListed, 0},
+		{"clean.com", domain.CollateralStatusClear, 0},
+	}
+	if len(report.Findings) != len(expected) {
+		t.Fatalf("expected %d findings, got %d", len(expected), len(report.Findings))
+	}
+	for i, want := range expected {
+		got := report.Findings[i]
+		if got.Domain != want.domain || got.Status != want.status || len(got.Hits) != want.hits {
+			t.Errorf("finding %d: expected %s %s with %d hits, got %s %s with %d hits",
+				i, want.domain, want.status, want.hits, got.Domain, got.Status, len(got.Hits))
+		}
+	}
+
+	if rule := report.Findings[3].Rule; rule == nil || rule.Pattern != "listed.com" {
+		t.Errorf("expected the listing rule for listed.com, got %+v", rule)
+	}
+	if count := report.Count(domain.CollateralStatusCollateral); count != 2 {
+		t.Errorf("expected 2 collateral domains, got %d", count)
+	}
+}
+
+func TestCollateralService_AnalyzeInvalidIP(t *testing.T) {
+	service := createTestCollateralService()
+
+	_, err := service.Analyze(context.Background(), []domain.HostedDomain{{Domain: "a.com", IPs: []string{"nope"}}})
+	if !errors.Is(err, domain.ErrInvalidHostedList) {
+		t.Errorf("expected ErrInvalidHostedList, got %v", err)
+	}
+}
+
+func TestCollateralService_Jobs(t *testing.T) {
+	service := createTestCollateralService()
+
+	if _, err := service.Submit(nil); !errors.Is(err, domain.ErrInvalidHostedList) {
+		t.Errorf("expected ErrInvalidHostedList for an empty list, got %v", err)
+	}
+	if _, err := service.Job("missing"); !errors.Is(err, domain.ErrJobNotFound) {
+		t.Errorf("expected ErrJobNotFound, got %v", err)
+	}
+
+	job, err := service.Submit([]domain.HostedDomain{{Domain: "shared.com", IPs: []string{"1.1.1.1"}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for job.Status != domain.JobStatusDone && job.Status != domain.JobStatusFailed {
+		if time.Now().After(deadline) {
+			t.Fatalf("job did not finish, status %s", job.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+		if job, err = service.Job(job.ID); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if job.Status != domain.JobStatusDone || job.Report.Count(domain.CollateralStatusCollateral) != 1 {
+		t.Errorf("expected a finished job with one collateral domain, got %s %q", job.Status, job.Error)
+	}
+}
+
+// gatedLookup holds analyses until the gate is closed
+type gatedLookup struct {
+	RegistryLookup
+	gate chan struct{}
+}
+
+func (g *gatedLookup) IsBlocked(normalizedURL string) *domain.BlockingResult {
+	<-g.gate
+	return g.RegistryLookup.IsBlocked(normalizedURL)
+}
+
+func TestCollateralService_RegistryNotReady(t *testing.T) {
+	service := NewCollateralService(services.NewURLNormalizer(), storage.NewMemoryStore(), nil)
+	hosted := []domain.HostedDomain{{Domain: "shared.com", IPs: []string{"1.1.1.1"}}}
+
+	if _, err := service.Submit(hosted); !errors.Is(err, domain.ErrRegistryNotReady) {
+		t.Errorf("expected Submit to return ErrRegistryNotReady, got %v", err)
+	}
+	if _, err := service.Analyze(context.Background(), hosted); !errors.Is(err, domain.ErrRegistryNotReady) {
+		t.Errorf("expected Analyze to return ErrRegistryNotReady, got %v", err)
+	}
+}
+
+func TestCollateralService_JobLimits(t *testing.T) {
+	store, ips := createTestCollateralRegistry()
+	gate := make(chan struct{})
+	service := NewCollateralService(services.NewURLNormalizer(), &gatedLookup{RegistryLookup: store, gate: gate}, ips, WithJobLimits(1, 1))
+	hosted := []domain.HostedDomain{{Domain: "shared.com", IPs: []string{"1.1.1.1"}}}
+
+	running, err := service.Submit(hosted)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for job, _ := service.Job(running.ID); job.Status != domain.JobStatusRunning; job, _ = service.Job(running.ID) {
+		if time.Now().After(deadline) {
+			t.Fatalf("job was not picked up, status %s", job.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := service.Submit(hosted); err != nil {
+		t.Fatalf("expected a job to wait for the worker, got %v", err)
+	}
+	if _, err := service.Submit(hosted); !errors.Is(err, domain.ErrTooManyJobs) {
+		t.Errorf("expected ErrTooManyJobs once the queue is full, got %v", err)
+	}
+
+	close(gate)
+}
@@ -0,0 +1,96 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// Readiness check names
+const (
+	CheckRegistryLoaded = "registry_loaded"
+	CheckRegistryFresh  = "registry_fresh"
+	CheckScheduler      = "scheduler"
+)
+
+// HealthService decides whether the service is ready to answer checks: a
+// registry must be loaded, recent enough, and kept up to date by a healthy
+// scheduler
+type HealthService struct {
+	store        RegistryLookup
+	scheduler    SchedulerHealth
+	maxStaleness time.Duration
+}
+
+// NewHealthService creates a health service. A non-positive maxStaleness
+// disables the registry age check.
+func NewHealthService(store RegistryLookup, scheduler SchedulerHealth, maxStaleness time.Duration) *HealthService {
+	return &HealthService{
+		store:        store,
+		scheduler:    scheduler,
+		maxStaleness: maxStaleness,
+	}
+}
+
+// Readiness runs every readiness check
+func (hs *HealthService) Readiness(ctx context.Context) *domain.HealthReport {
+	stats := hs.store.Stats()
+
+	return domain.NewHealthReport(
+		hs.checkLoaded(stats.TotalEntries),
+		hs.checkFresh(stats.TotalEntries, stats.LastUpdate),
+		hs.checkScheduler(),
+	)
+}
+
+func (hs *HealthService) checkLoaded(entries int64) domain.HealthCheck {
+	if entries == 0 {
+		return domain.HealthCheck{Name: CheckRegistryLoaded, Message: "registry is empty"}
+	}
+	return domain.HealthCheck{
+		Name:    CheckRegistryLoaded,
+		Healthy: true,
+		Message: fmt.Sprintf("%d entries loaded", entries),
+	}
+}
+
+func (hs *HealthService) checkFresh(entries int64, lastUpdate time.Time) domain.HealthCheck {
+	if entries == 0 || lastUpdate.IsZero() {
+		return domain.HealthCheck{Name: CheckRegistryFresh, Message: "no registry has been loaded"}
+	}
+
+	age := time.Since(lastUpdate).Truncate(time.Second)
+	if hs.maxStaleness > 0 && age > hs.maxStaleness {
+		return domain.HealthCheck{
+			Name:    CheckRegistryFresh,
+			Message: fmt.Sprintf("registry is %s old, limit is %s", age, hs.maxStaleness),
+		}
+	}
+	return domain.HealthCheck{
+		Name:    CheckRegistryFresh,
+		Healthy: true,
+		Message: fmt.Sprintf("registry is %s old", age),
+	}
+}
+
+func (hs *HealthService) checkScheduler() domain.HealthCheck {
+	status := hs.scheduler.GetStatus()
+	if hs.scheduler.IsHealthy() {
+		message := "updates are succeeding"
+		if status.Paused {
+			message = "updates are paused"
+		}
+		return domain.HealthCheck{Name: CheckScheduler, Healthy: true, Message: message}
+	}
+
+	message := "no successful update within twice the update interval"
+	if status.ConsecutiveFailures > 0 {
+		message = fmt.Sprintf("%d consecutive update failures", status.ConsecutiveFailures)
+	}
+	if status.LastError != nil {
+		message += ", last error: " + status.LastError.Error()
+	}
+	return domain.HealthCheck{Name: CheckScheduler, Message: message}
+}
@@ -0,0 +1,128 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/storage"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/updater"
+)
+
+type mockRegistryLookup struct {
+	stats storage.StoreStats
+}
+
+func (m *mockRegistryLookup) IsBlocked(normalizedURL string) *domain.BlockingResult {
+	return &domain.BlockingResult{}
+}
+
+func (m *mockRegistryLookup) Stats() storage.StoreStats {
+	return m.stats
+}
+
+type mockSchedulerHealth struct {
+	healthy bool
+	status  updater.Status
+}
+
+func (m *mockSchedulerHealth) IsHealthy() bool {
+	return m.healthy
+}
+
+func (m *mockSchedulerHealth) GetStatus() updater.Status {
+	return m.status
+}
+
+func TestHealthService_Readiness(t *testing.T) {
+	fresh := storage.StoreStats{TotalEntries: 100, LastUpdate: time.Now().Add(-time.Hour)}
+
+	tests := []struct {
+		name      string
+		stats     storage.StoreStats
+		scheduler *mockSchedulerHealth
+		failing   map[string]string
+	}{
+		{
+			name:      "Ready",
+			stats:     fresh,
+			scheduler: &mockSchedulerHealth{healthy: true},
+		},
+		{
+			name:      "Empty registry",
+			stats:     storage.StoreStats{LastUpdate: time.Now()},
+			scheduler: &mockSchedulerHealth{healthy: true},
+			failing: map[string]string{
+				CheckRegistryLoaded: "registry is empty",
+				CheckRegistryFresh:  "no registry has been loaded",
+			},
+		},
+		{
+			name:      "Stale registry",
+			stats:     storage.StoreStats{TotalEntries: 100, LastUpdate: time.Now().Add(-5 * 24 * time.Hour)},
+			scheduler: &mockSchedulerHealth{healthy: true},
+			failing:   map[string]string{CheckRegistryFresh: "limit is 96h0m0s"},
+		},
+		{
+			name:  "Failing scheduler",
+			stats: fresh,
+			scheduler: &mockSchedulerHealth{status: updater.Status{
+				ConsecutiveFailures: 20,
+				LastError:           errors.New("fetching registry: timeout"),
+			}},
+			failing: map[string]string{CheckScheduler: "20 consecutive update failures, last error: fetching registry: timeout"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewHealthService(&mockRegistryLookup{stats: tt.stats}, tt.scheduler, 96*time.Hour)
+
+			report := service.Readiness(context.Background())
+			if report.Healthy != (len(tt.failing) == 0) {
+				t.Errorf("expected healthy=%v, got %+v", len(tt.failing) == 0, report)
+			}
+			if len(report.Checks) != 3 {
+				t.Fatalf("expected 3 checks, got %d", len(report.Checks))
+			}
+
+			for _, check := range report.Checks {
+				reason, shouldFail := tt.failing[check.Name]
+				if check.Healthy == shouldFail {
+					t.Errorf("check %s: expected healthy=%v, got %+v", check.Name, !shouldFail, check)
+				}
+				if shouldFail && !strings.Contains(check.Message, reason) {
+					t.Errorf("check %s: expected message to contain %q, got %q", check.Name, reason, check.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestHealthService_ReadyWhilePaused(t *testing.T) {
+	stats := storage.StoreStats{TotalEntries: 100, LastUpdate: time.Now().Add(-48 * time.Hour)}
+	scheduler := &mockSchedulerHealth{healthy: true, status: updater.Status{Paused: true}}
+	service := NewHealthService(&mockRegistryLookup{stats: stats}, scheduler, 96*time.Hour)
+
+	report := service.Readiness(context.Background())
+	if !report.Healthy {
+		t.Fatalf("expected paused updates not to fail readiness, got %+v", report)
+	}
+	for _, check := range report.Checks {
+		if check.Name == CheckScheduler && check.Message != "updates are paused" {
+			t.Errorf("expected the scheduler check to report the pause, got %q", check.Message)
+		}
+	}
+}
+
+func TestHealthService_StalenessDisabled(t *testing.T) {
+	stats := storage.StoreStats{TotalEntries: 100, LastUpdate: time.Now().Add(-365 * 24 * time.Hour)}
+	service := NewHealthService(&mockRegistryLookup{stats: stats}, &mockSchedulerHealth{healthy: true}, 0)
+
+	if report := service.Readiness(context.Background()); !report.Healthy {
+		t.Errorf("expected an old registry to be ready without a staleness bound, got %+v", report)
+	}
+}
@@ -2,9 +2,12 @@ package application
 
 import (
 	"context"
+	"crypto/x509"
+	"time"
 
 	"github.com/kerim-dauren/rkn-checker/internal/domain"
 	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/storage"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/updater"
 )
 
 type URLNormalizer interface {
@@ -16,14 +19,168 @@ type RegistryStore interface {
 	IsBlocked(normalizedURL string) *domain.BlockingResult
 	Update(registry *domain.Registry) error
 	Stats() storage.StoreStats
+	Size() int
 	Clear()
 }
 
+// RegistryLookup answers checks against a single registry version
+type RegistryLookup interface {
+	IsBlocked(normalizedURL string) *domain.BlockingResult
+	Stats() storage.StoreStats
+}
+
+// SnapshotProvider resolves retained registry snapshots. Both methods return
+// domain.ErrSnapshotNotRetained when no retained snapshot matches.
+type SnapshotProvider interface {
+	SnapshotAt(at time.Time) (RegistryLookup, error)
+	SnapshotByVersion(version string) (RegistryLookup, error)
+}
+
 type BlockingChecker interface {
 	CheckURL(ctx context.Context, rawURL string) (*domain.BlockingResult, error)
+	CheckURLAt(ctx context.Context, rawURL string, point domain.RegistryPoint) (*domain.BlockingResult, error)
 	GetStats(ctx context.Context) (*BlockingStats, error)
 }
 
+// URLLookup looks URLs up in the current registry for the service's own
+// evaluations, which are not counted as checks
+type URLLookup interface {
+	LookupURL(rawURL string) (*domain.BlockingResult, error)
+	GetStats(ctx context.Context) (*BlockingStats, error)
+}
+
+// IngestReporter provides the ingest report of the last applied registry update
+type IngestReporter interface {
+	LastIngestReport() *domain.IngestReport
+}
+
+// QuarantineManager exposes a registry update held back by a guardrail and
+// lets an operator force-apply it
+type QuarantineManager interface {
+	Quarantined() *domain.QuarantinedUpdate
+	ApplyQuarantined() error
+}
+
+// ChangelogReader lists the changes between registry versions
+type ChangelogReader interface {
+	ChangesSince(version string) ([]*domain.Changelog, error)
+	CurrentVersion() string
+}
+
+// HistoryReader returns the timeline of the registry patterns covering a domain
+type HistoryReader interface {
+	History(target string) ([]*domain.PatternHistory, error)
+}
+
+// EntryBrowser looks up and pages through the entries of the current registry
+type EntryBrowser interface {
+	GetEntry(id string) (*domain.RegistryEntry, error)
+	ListEntries(query domain.EntryQuery) (*domain.EntryPage, error)
+}
+
+// RegistrySearcher searches the patterns of the current registry
+type RegistrySearcher interface {
+	Search(ctx context.Context, query domain.SearchQuery) (*domain.SearchResult, error)
+}
+
+// IPLookup traces an IP address back to the registry records referencing it
+type IPLookup interface {
+	LookupIP(addr string) (*domain.IPLookupResult, error)
+}
+
+// CollateralJobRunner runs collateral-damage analyses as background jobs
+type CollateralJobRunner interface {
+	Submit(hosted []domain.HostedDomain) (*domain.CollateralJob, error)
+	Job(id string) (*domain.CollateralJob, error)
+}
+
+// WatchlistStore persists watch items and the events raised for them
+type WatchlistStore interface {
+	Items() []*domain.WatchItem
+	Item(id string) (*domain.WatchItem, error)
+	PutItems(items ...*domain.WatchItem) error
+	DeleteItem(id string) error
+	AddEvents(events []*domain.WatchEvent) error
+	Events(query domain.WatchEventQuery) []*domain.WatchEvent
+}
+
+// WatchNotifier delivers watch events to callback URLs
+type WatchNotifier interface {
+	// CheckCallback rejects callback URLs events would not be delivered to
+	CheckCallback(callbackURL string) error
+	// Notify queues events for delivery to their callback URLs
+	Notify(ctx context.Context, callbacks []domain.WatchCallback) error
+}
+
+// WatchlistManager manages watched targets and lists their events
+type WatchlistManager interface {
+	AddWatch(ctx context.Context, target, callbackURL string) (*domain.WatchItem, error)
+	UpdateWatch(ctx context.Context, id, target, callbackURL string) (*domain.WatchItem, error)
+	GetWatch(id string) (*domain.WatchItem, error)
+	ListWatches() []*domain.WatchItem
+	DeleteWatch(id string) error
+	WatchEvents(query domain.WatchEventQuery) []*domain.WatchEvent
+}
+
+// RegistryFeed streams events for registries swapped into the store. Subscribe
+// returns the events after the since version, a channel of later events and
+// a function ending the subscription.
+type RegistryFeed interface {
+	Subscribe(since string) ([]*domain.RegistryEvent, <-chan *domain.RegistryEvent, func())
+}
+
+// WebhookManager manages webhook subscriptions and dead-lettered deliveries
+type WebhookManager interface {
+	Subscriptions() []*domain.WebhookSubscription
+	AddSubscription(url, secret string, events []domain.WebhookEventType) (*domain.WebhookSubscription, error)
+	DeleteSubscription(id string) error
+	DeadLetters() []*domain.WebhookDelivery
+	Replay(id string) (*domain.WebhookDelivery, error)
+}
+
+// SchedulerController controls registry updates and reports their status
+type SchedulerController interface {
+	TriggerUpdate()
+	Pause()
+	Resume()
+	GetStatus() updater.Status
+	ClearRegistry()
+	SourceHealth(ctx context.Context) map[string]bool
+}
+
+// SchedulerHealth reports whether registry updates are succeeding
+type SchedulerHealth interface {
+	IsHealthy() bool
+	GetStatus() updater.Status
+}
+
+// ReadinessChecker decides whether the service is ready to serve checks
+type ReadinessChecker interface {
+	Readiness(ctx context.Context) *domain.HealthReport
+}
+
+// Authenticator resolves the identity of callers from the credentials they
+// present. Both methods return domain.ErrInvalidCredentials for credentials
+// that are not accepted.
+type Authenticator interface {
+	// AuthenticateToken resolves a bearer token: an API key, the admin token
+	// or a JWT
+	AuthenticateToken(token string) (*domain.Identity, error)
+	// AuthenticateCertificate resolves a client certificate verified during
+	// the TLS handshake
+	AuthenticateCertificate(cert *x509.Certificate) (*domain.Identity, error)
+	// Anonymous is the identity of callers presenting no credentials
+	Anonymous() *domain.Identity
+}
+
+// RateLimiter admits or rejects the calls of a client to a route, named by
+// its REST route pattern or gRPC full method name. Peek reports the decision
+// Allow would make without counting the call.
+type RateLimiter interface {
+	Allow(client, route string) domain.RateLimitDecision
+	Peek(client, route string) domain.RateLimitDecision
+}
+
 type BlockingStats struct {
 	TotalEntries    int64  `json:"total_entries"`
 	DomainEntries   int64  `json:"domain_entries"`
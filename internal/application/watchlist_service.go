@@ -0,0 +1,256 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// WatchlistService keeps watched domains, URLs and IPs and raises an event
+// whenever one of them becomes blocked or unblocked by a registry update
+type WatchlistService struct {
+	checker         URLLookup
+	store           WatchlistStore
+	notifier        WatchNotifier
+	defaultCallback string
+
+	// mu serializes edits with the evaluation of a registry update
+	mu sync.Mutex
+}
+
+// NewWatchlistService creates a watchlist service. Events are queued for
+// the callback URL of their item, or for defaultCallback when the item has
+// none; a nil notifier disables delivery.
+func NewWatchlistService(checker URLLookup, store WatchlistStore, notifier WatchNotifier, defaultCallback string) *WatchlistService {
+	return &WatchlistService{
+		checker:         checker,
+		store:           store,
+		notifier:        notifier,
+		defaultCallback: defaultCallback,
+	}
+}
+
+// AddWatch starts watching a target. Its current state is recorded without
+// raising an event, so targets cannot be added before a registry is loaded.
+func (ws *WatchlistService) AddWatch(ctx context.Context, target, callbackURL string) (*domain.WatchItem, error) {
+	if err := ws.validateCallbackURL(callbackURL); err != nil {
+		return nil, err
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	now := time.Now()
+	item := &domain.WatchItem{
+		ID:          id,
+		CallbackURL: callbackURL,
+		CreatedAt:   now,
+	}
+	if err := ws.resetTarget(ctx, item, target, now); err != nil {
+		return nil, err
+	}
+
+	if err := ws.store.PutItems(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// UpdateWatch changes the target or callback URL of a watch item. A changed
+// target has its state recorded again without raising an event.
+func (ws *WatchlistService) UpdateWatch(ctx context.Context, id, target, callbackURL string) (*domain.WatchItem, error) {
+	if err := ws.validateCallbackURL(callbackURL); err != nil {
+		return nil, err
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	item, err := ws.store.Item(id)
+	if err != nil {
+		return nil, err
+	}
+
+	item.CallbackURL = callbackURL
+	if strings.TrimSpace(target) != item.Target {
+		if err := ws.resetTarget(ctx, item, target, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := ws.store.PutItems(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// GetWatch returns the watch item with the given ID
+func (ws *WatchlistService) GetWatch(id string) (*domain.WatchItem, error) {
+	return ws.store.Item(id)
+}
+
+// ListWatches returns all watch items
+func (ws *WatchlistService) ListWatches() []*domain.WatchItem {
+	return ws.store.Items()
+}
+
+// DeleteWatch stops watching a target
+func (ws *WatchlistService) DeleteWatch(id string) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	return ws.store.DeleteItem(id)
+}
+
+// WatchEvents lists the events raised for watch items
+func (ws *WatchlistService) WatchEvents(query domain.WatchEventQuery) []*domain.WatchEvent {
+	return ws.store.Events(query)
+}
+
+// OnRegistryUpdate checks every watch item against the newly applied
+// registry and raises an event for each item whose state changed
+func (ws *WatchlistService) OnRegistryUpdate(previous, current *domain.Registry, report *domain.IngestReport) {
+	ctx := context.Background()
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	now := time.Now()
+	items := ws.store.Items()
+	var events []*domain.WatchEvent
+	callbacks := make(map[string]string)
+
+	for _, item := range items {
+		result, err := ws.checker.LookupURL(item.Target)
+		if err != nil {
+			slog.Warn("Failed to check watched target", "id", item.ID, "target", item.Target, "error", err)
+			continue
+		}
+
+		wasBlocked, previousRule, previousType := item.Blocked, item.Rule, item.RuleType
+		applyResult(item, result)
+		item.Version = current.Version
+		if item.Blocked == wasBlocked {
+			continue
+		}
+
+		item.ChangedAt = now
+		event := &domain.WatchEvent{
+			ItemID:   item.ID,
+			Target:   item.Target,
+			Kind:     domain.WatchEventBecameBlocked,
+			Rule:     item.Rule,
+			RuleType: item.RuleType,
+			Version:  current.Version,
+			At:       now,
+		}
+		if !item.Blocked {
+			event.Kind = domain.WatchEventBecameUnblocked
+			event.Rule, event.RuleType = previousRule, previousType
+		}
+		events = append(events, event)
+		callbacks[item.ID] = item.CallbackURL
+	}
+
+	if err := ws.store.PutItems(items...); err != nil {
+		slog.Error("Failed to persist watchlist", "version", current.Version, "error", err)
+	}
+	if err := ws.store.AddEvents(events); err != nil {
+		slog.Error("Failed to persist watch events", "version", current.Version, "error", err)
+	}
+
+	if len(events) > 0 {
+		slog.Info("Watchlist evaluated", "version", current.Version, "items", len(items), "events", len(events))
+		ws.deliver(ctx, events, callbacks)
+	}
+}
+
+// deliver queues events for their callback URLs
+func (ws *WatchlistService) deliver(ctx context.Context, events []*domain.WatchEvent, callbacks map[string]string) {
+	if ws.notifier == nil {
+		return
+	}
+
+	var queued []domain.WatchCallback
+	for _, event := range events {
+		callback := callbacks[event.ItemID]
+		if callback == "" {
+			callback = ws.defaultCallback
+		}
+		if callback == "" {
+			continue
+		}
+		queued = append(queued, domain.WatchCallback{URL: callback, Event: event})
+	}
+
+	if err := ws.notifier.Notify(ctx, queued); err != nil {
+		slog.Error("Failed to queue watch events", "events", len(queued), "error", err)
+	}
+}
+
+// resetTarget sets the target of an item and records its current state
+func (ws *WatchlistService) resetTarget(ctx context.Context, item *domain.WatchItem, target string, now time.Time) error {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return fmt.Errorf("%w: target is required", domain.ErrInvalidWatchItem)
+	}
+
+	result, err := ws.checker.LookupURL(target)
+	if errors.Is(err, domain.ErrRegistryNotReady) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidWatchItem, err)
+	}
+
+	item.Target = target
+	item.Normalized = result.NormalizedURL
+	item.ChangedAt = now
+	applyResult(item, result)
+
+	if stats, err := ws.checker.GetStats(ctx); err == nil {
+		item.Version = stats.Version
+	}
+	return nil
+}
+
+// applyResult copies the state of a check onto a watch item
+func applyResult(item *domain.WatchItem, result *domain.BlockingResult) {
+	item.Blocked = result.IsBlocked
+	item.Rule = ""
+	item.RuleType = domain.BlockingTypeUnknown
+	if result.IsBlocked && result.Rule != nil {
+		item.Rule = result.Rule.Pattern
+		item.RuleType = result.Rule.Type
+	}
+}
+
+// validateCallbackURL accepts an empty URL or an absolute HTTP(S) URL the
+// notifier delivers to
+func (ws *WatchlistService) validateCallbackURL(callbackURL string) error {
+	if callbackURL == "" {
+		return nil
+	}
+	parsed, err := url.Parse(callbackURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w: callback URL must be an absolute http or https URL", domain.ErrInvalidWatchItem)
+	}
+	if ws.notifier != nil {
+		if err := ws.notifier.CheckCallback(callbackURL); err != nil {
+			return fmt.Errorf("%w: %v", domain.ErrInvalidWatchItem, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/domain/services"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/metrics"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/storage"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/watchlist"
+)
+
+type recordingNotifier struct {
+	mu     sync.Mutex
+	events map[string][]*domain.WatchEvent
+}
+
+func (n *recordingNotifier) CheckCallback(callbackURL string) error {
+	if strings.Contains(callbackURL, "127.0.0.1") {
+		return errors.New("loopback callback")
+	}
+	return nil
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, callbacks []domain.WatchCallback) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, callback := range callbacks {
+		n.events[callback.URL] = append(n.events[callback.URL], callback.Event)
+	}
+	return nil
+}
+
+func (n *recordingNotifier) count(callbackURL string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.events[callbackURL])
+}
+
+// applyRegistry updates the store with a registry of the given patterns and
+// notifies the watchlist, as the scheduler does
+func applyRegistry(t *testing.T, store *storage.MemoryStore, ws *WatchlistService, version string, patterns map[string]domain.BlockingType) {
+	t.Helper()
+	registry := domain.NewRegistry()
+	registry.Version = version
+	for pattern, blockingType := range patterns {
+		entry, err := domain.NewRegistryEntry(blockingType, pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		registry.AddEntry(entry)
+	}
+	store.Update(registry)
+	ws.OnRegistryUpdate(nil, registry, nil)
+}
+
+func TestWatchlistService_OnRegistryUpdate(t *testing.T) {
+	store := storage.NewMemoryStore()
+	checker := NewBlockingService(services.NewURLNormalizer(), store)
+	items, _ := watchlist.NewStore("")
+	notifier := &recordingNotifier{events: make(map[string][]*domain.WatchEvent)}
+	ws := NewWatchlistService(checker, items, notifier, "http://default.example/hook")
+	applyRegistry(t, store, ws, "v0", map[string]domain.BlockingType{"unrelated.org": domain.BlockingTypeDomain})
+
+	ctx := context.Background()
+	shop, err := ws.AddWatch(ctx, "https://shop.example.com/cart", "http://customer.example/hook")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ip, err := ws.AddWatch(ctx, "203.0.113.7", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if shop.Blocked || shop.Normalized != "shop.example.com" {
+		t.Errorf("unexpected initial state %+v", shop)
+	}
+
+	// A wildcard blocks the shop and a subnet blocks the IP
+	applyRegistry(t, store, ws, "v1", map[string]domain.BlockingType{
+		"*.example.com":  domain.BlockingTypeWildcard,
+		"203.0.113.0/24": domain.BlockingTypeSubnet,
+	})
+	// Nothing changes
+	applyRegistry(t, store, ws, "v2", map[string]domain.BlockingType{
+		"*.example.com":  domain.BlockingTypeWildcard,
+		"203.0.113.0/24": domain.BlockingTypeSubnet,
+	})
+	// The wildcard is lifted
+	applyRegistry(t, store, ws, "v3", map[string]domain.BlockingType{
+		"203.0.113.0/24": domain.BlockingTypeSubnet,
+	})
+
+	events := ws.WatchEvents(domain.WatchEventQuery{})
+	expected := []struct {
+		item    string
+		kind    domain.WatchEventKind
+		rule    string
+		version string
+	}{
+		{shop.ID, domain.WatchEventBecameBlocked, "*.example.com", "v1"},
+		{ip.ID, domain.WatchEventBecameBlocked, "203.0.113.0/24", "v1"},
+		{shop.ID, domain.WatchEventBecameUnblocked, "*.example.com", "v3"},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d", len(expected), len(events))
+	}
+	for i, want := range expected {
+		got := events[i]
+		if got.ItemID != want.item || got.Kind != want.kind || got.Rule != want.rule || got.Version != want.version {
+			t.Errorf("event %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+
+	current, _ := ws.GetWatch(shop.ID)
+	if current.Blocked || current.Version != "v3" {
+		t.Errorf("unexpected shop state %+v", current)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for notifier.count("http://customer.example/hook") < 2 || notifier.count("http://default.example/hook") < 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("events were not delivered to their callback URLs")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWatchlistService_NotCountedAsChecks(t *testing.T) {
+	store := storage.NewMemoryStore()
+	items, _ := watchlist.NewStore("")
+	ws := NewWatchlistService(NewBlockingService(services.NewURLNormalizer(), store), items, nil, "")
+	applyRegistry(t, store, ws, "v1", map[string]domain.BlockingType{"unrelated.org": domain.BlockingTypeDomain})
+
+	allowed := metrics.Checks.WithLabelValues("allowed", "none")
+	blocked := metrics.Checks.WithLabelValues("blocked", domain.BlockingTypeDomain.String())
+	before := testutil.ToFloat64(allowed) + testutil.ToFloat64(blocked)
+
+	if _, err := ws.AddWatch(context.Background(), "example.com", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	applyRegistry(t, store, ws, "v2", map[string]domain.BlockingType{"example.com": domain.BlockingTypeDomain})
+	if len(ws.WatchEvents(domain.WatchEventQuery{})) != 1 {
+		t.Fatal("expected the watched target to become blocked")
+	}
+
+	if after := testutil.ToFloat64(allowed) + testutil.ToFloat64(blocked); after != before {
+		t.Errorf("expected watchlist evaluations not to be counted as checks, got %v more", after-before)
+	}
+}
+
+func TestWatchlistService_CRUD(t *testing.T) {
+	store := storage.NewMemoryStore()
+	items, _ := watchlist.NewStore("")
+	ws := NewWatchlistService(NewBlockingService(services.NewURLNormalizer(), store), items, nil, "")
+	ctx := context.Background()
+
+	if _, err := ws.AddWatch(ctx, "example.com", ""); !errors.Is(err, domain.ErrRegistryNotReady) {
+		t.Errorf("expected ErrRegistryNotReady before a registry is loaded, got %v", err)
+	}
+	applyRegistry(t, store, ws, "v1", map[string]domain.BlockingType{"unrelated.org": domain.BlockingTypeDomain})
+
+	if _, err := ws.AddWatch(ctx, "", ""); !errors.Is(err, domain.ErrInvalidWatchItem) {
+		t.Errorf("expected ErrInvalidWatchItem for an empty target, got %v", err)
+	}
+	if _, err := ws.AddWatch(ctx, "example.com", "ftp://hook"); !errors.Is(err, domain.ErrInvalidWatchItem) {
+		t.Errorf("expected ErrInvalidWatchItem for a non-HTTP callback, got %v", err)
+	}
+	guarded := NewWatchlistService(NewBlockingService(services.NewURLNormalizer(), store), items,
+		&recordingNotifier{events: make(map[string][]*domain.WatchEvent)}, "")
+	if _, err := guarded.AddWatch(ctx, "example.com", "http://127.0.0.1/hook"); !errors.Is(err, domain.ErrInvalidWatchItem) {
+		t.Errorf("expected ErrInvalidWatchItem for a callback the notifier rejects, got %v", err)
+	}
+
+	item, err := ws.AddWatch(ctx, "example.com", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	updated, err := ws.UpdateWatch(ctx, item.ID, "other.example.com", "https://hook.example/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Normalized != "other.example.com" || updated.CallbackURL != "https://hook.example/" {
+		t.Errorf("unexpected updated item %+v", updated)
+	}
+	if len(ws.ListWatches()) != 1 {
+		t.Errorf("expected one watch item, got %d", len(ws.ListWatches()))
+	}
+
+	if err := ws.DeleteWatch(item.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := ws.UpdateWatch(ctx, item.ID, "example.com", ""); !errors.Is(err, domain.ErrWatchItemNotFound) {
+		t.Errorf("expected ErrWatchItemNotFound, got %v", err)
+	}
+}
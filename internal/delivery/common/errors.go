@@ -1,41 +1,178 @@
+// Package common holds the error model shared by the REST and gRPC APIs:
+// stable error codes, their classification from domain errors and their
+// encoding as problem details and gRPC statuses.
 package common
 
 import (
+	"errors"
 	"net/http"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
 
 	"github.com/kerim-dauren/rkn-checker/internal/domain"
 )
 
-func MapDomainErrorToHTTP(err error) int {
-	switch err {
-	case domain.ErrEmptyURL:
-		return http.StatusBadRequest
-	case domain.ErrInvalidURL:
-		return http.StatusBadRequest
-	case domain.ErrRegistryEntryInvalid:
-		return http.StatusBadRequest
-	default:
-		return http.StatusInternalServerError
+// ErrorDomain is the domain of the gRPC ErrorInfo details carrying codes
+const ErrorDomain = "rkn-checker"
+
+// Code is a stable, machine-readable error code shared by the REST and gRPC
+// APIs. Clients should branch on codes rather than on messages.
+type Code string
+
+const (
+	CodeURLEmpty            Code = "URL_EMPTY"
+	CodeURLInvalid          Code = "URL_INVALID"
+	CodeProtocolUnsupported Code = "PROTOCOL_UNSUPPORTED"
+	CodeInvalidArgument     Code = "INVALID_ARGUMENT"
+	CodePayloadTooLarge     Code = "PAYLOAD_TOO_LARGE"
+	CodeUnauthenticated     Code = "UNAUTHENTICATED"
+	CodePermissionDenied    Code = "PERMISSION_DENIED"
+	CodeNotFound            Code = "NOT_FOUND"
+	CodeMethodNotAllowed    Code = "METHOD_NOT_ALLOWED"
+	CodeConflict            Code = "CONFLICT"
+	CodeSnapshotNotRetained Code = "SNAPSHOT_NOT_RETAINED"
+	CodeCursorExpired       Code = "CURSOR_EXPIRED"
+	CodeRateLimited         Code = "RATE_LIMITED"
+	CodeQuotaExceeded       Code = "QUOTA_EXCEEDED"
+	CodeStreamFellBehind    Code = "STREAM_FELL_BEHIND"
+	CodeRegistryNotReady    Code = "REGISTRY_NOT_READY"
+	CodeNotEnabled          Code = "NOT_ENABLED"
+	CodeUnavailable         Code = "UNAVAILABLE"
+	CodeInternal            Code = "INTERNAL"
+)
+
+// codeInfo describes how a code is reported on each transport
+type codeInfo struct {
+	status int
+	grpc   codes.Code
+	title  string
+}
+
+var codeInfos = map[Code]codeInfo{
+	CodeURLEmpty:            {http.StatusBadRequest, codes.InvalidArgument, "URL is empty"},
+	CodeURLInvalid:          {http.StatusBadRequest, codes.InvalidArgument, "URL is invalid"},
+	CodeProtocolUnsupported: {http.StatusBadRequest, codes.InvalidArgument, "URL protocol is not supported"},
+	CodeInvalidArgument:     {http.StatusBadRequest, codes.InvalidArgument, "Invalid argument"},
+	CodePayloadTooLarge:     {http.StatusRequestEntityTooLarge, codes.InvalidArgument, "Payload too large"},
+	CodeUnauthenticated:     {http.StatusUnauthorized, codes.Unauthenticated, "Valid credentials required"},
+	CodePermissionDenied:    {http.StatusForbidden, codes.PermissionDenied, "Permission denied"},
+	CodeNotFound:            {http.StatusNotFound, codes.NotFound, "Not found"},
+	CodeMethodNotAllowed:    {http.StatusMethodNotAllowed, codes.Unimplemented, "Method not allowed"},
+	CodeConflict:            {http.StatusConflict, codes.FailedPrecondition, "Conflict"},
+	CodeSnapshotNotRetained: {http.StatusGone, codes.OutOfRange, "Registry version is not retained"},
+	CodeCursorExpired:       {http.StatusGone, codes.OutOfRange, "Pagination cursor expired"},
+	CodeRateLimited:         {http.StatusTooManyRequests, codes.ResourceExhausted, "Rate limit exceeded"},
+	CodeQuotaExceeded:       {http.StatusTooManyRequests, codes.ResourceExhausted, "Daily quota exceeded"},
+	CodeStreamFellBehind:    {http.StatusServiceUnavailable, codes.ResourceExhausted, "Stream fell behind"},
+	CodeRegistryNotReady:    {http.StatusServiceUnavailable, codes.Unavailable, "Registry is not loaded yet"},
+	CodeNotEnabled:          {http.StatusNotImplemented, codes.Unimplemented, "Feature is not enabled"},
+	CodeUnavailable:         {http.StatusServiceUnavailable, codes.Unavailable, "Service unavailable"},
+	CodeInternal:            {http.StatusInternalServerError, codes.Internal, "Internal server error"},
+}
+
+// Codes lists every error code
+func Codes() []Code {
+	return []Code{
+		CodeURLEmpty, CodeURLInvalid, CodeProtocolUnsupported, CodeInvalidArgument,
+		CodePayloadTooLarge, CodeUnauthenticated, CodePermissionDenied, CodeNotFound,
+		CodeMethodNotAllowed, CodeConflict, CodeSnapshotNotRetained, CodeCursorExpired,
+		CodeRateLimited, CodeQuotaExceeded, CodeStreamFellBehind, CodeRegistryNotReady,
+		CodeNotEnabled, CodeUnavailable, CodeInternal,
+	}
+}
+
+// HTTPStatus is the HTTP status code reports are sent with
+func (c Code) HTTPStatus() int {
+	return c.info().status
+}
+
+// GRPCCode is the gRPC status code reports are sent with
+func (c Code) GRPCCode() codes.Code {
+	return c.info().grpc
+}
+
+// Title is a short summary of the code that does not change between
+// occurrences
+func (c Code) Title() string {
+	return c.info().title
+}
+
+func (c Code) info() codeInfo {
+	if info, ok := codeInfos[c]; ok {
+		return info
+	}
+	return codeInfos[CodeInternal]
+}
+
+// domainCodes classifies domain errors, the more specific ones first
+var domainCodes = []struct {
+	err  error
+	code Code
+}{
+	{domain.ErrEmptyURL, CodeURLEmpty},
+	{domain.ErrUnsupportedProtocol, CodeProtocolUnsupported},
+	{domain.ErrInvalidURL, CodeURLInvalid},
+	{domain.ErrInvalidDomain, CodeURLInvalid},
+	{domain.ErrInvalidIP, CodeURLInvalid},
+	{domain.ErrNormalizationFailed, CodeURLInvalid},
+	{domain.ErrRegistryNotReady, CodeRegistryNotReady},
+	{domain.ErrSnapshotNotRetained, CodeSnapshotNotRetained},
+	{domain.ErrUnknownRegistryVersion, CodeSnapshotNotRetained},
+	{domain.ErrCursorExpired, CodeCursorExpired},
+	{domain.ErrInvalidCursor, CodeInvalidArgument},
+	{domain.ErrInvalidSearchQuery, CodeInvalidArgument},
+	{domain.ErrInvalidHostedList, CodeInvalidArgument},
+	{domain.ErrInvalidWatchItem, CodeInvalidArgument},
+	{domain.ErrInvalidWebhook, CodeInvalidArgument},
+	{domain.ErrRegistryEntryInvalid, CodeInvalidArgument},
+	{domain.ErrBlockingRuleInvalid, CodeInvalidArgument},
+	{domain.ErrEntryNotFound, CodeNotFound},
+	{domain.ErrJobNotFound, CodeNotFound},
+	{domain.ErrWatchItemNotFound, CodeNotFound},
+	{domain.ErrWebhookNotFound, CodeNotFound},
+	{domain.ErrDeliveryNotFound, CodeNotFound},
+	{domain.ErrNoQuarantinedUpdate, CodeNotFound},
+	{domain.ErrInvalidCredentials, CodeUnauthenticated},
+	{domain.ErrTooManyJobs, CodeRateLimited},
+}
+
+// CodeOf classifies err, wrapped or not, by the domain error it matches.
+// Errors matching none are internal.
+func CodeOf(err error) Code {
+	for _, known := range domainCodes {
+		if errors.Is(err, known.err) {
+			return known.code
+		}
+	}
+	return CodeInternal
+}
+
+// Detail returns the message reported for err: its own for classified
+// errors, fallback for internal ones, whose message may leak internals
+func Detail(err error, fallback string) string {
+	if CodeOf(err) == CodeInternal {
+		return fallback
 	}
+	return err.Error()
 }
 
-func MapDomainErrorToGRPC(err error) codes.Code {
-	switch err {
-	case domain.ErrEmptyURL:
-		return codes.InvalidArgument
-	case domain.ErrInvalidURL:
-		return codes.InvalidArgument
-	case domain.ErrRegistryEntryInvalid:
-		return codes.InvalidArgument
-	default:
-		return codes.Internal
+// GRPCError returns a gRPC status error for code carrying it in an
+// ErrorInfo detail, followed by details
+func GRPCError(code Code, message string, details ...protoadapt.MessageV1) error {
+	info := &errdetails.ErrorInfo{Reason: string(code), Domain: ErrorDomain}
+	st, err := status.New(code.GRPCCode(), message).
+		WithDetails(append([]protoadapt.MessageV1{info}, details...)...)
+	if err != nil {
+		return status.Error(code.GRPCCode(), message)
 	}
+	return st.Err()
 }
 
-func NewGRPCError(err error, message string) error {
-	code := MapDomainErrorToGRPC(err)
-	return status.Error(code, message)
+// GRPCDomainError returns the gRPC status error of err, classified by
+// CodeOf, with fallback as the message of internal errors
+func GRPCDomainError(err error, fallback string) error {
+	return GRPCError(CodeOf(err), Detail(err, fallback))
 }
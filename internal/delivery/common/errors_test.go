@@ -0,0 +1,115 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+func TestCodes_Described(t *testing.T) {
+	seen := make(map[Code]bool)
+	for _, code := range Codes() {
+		if seen[code] {
+			t.Errorf("code %s is listed twice", code)
+		}
+		seen[code] = true
+
+		if _, ok := codeInfos[code]; !ok {
+			t.Errorf("code %s has no description", code)
+		}
+	}
+	if len(seen) != len(codeInfos) {
+		t.Errorf("expected %d codes to be listed, got %d", len(codeInfos), len(seen))
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{"empty URL", domain.ErrEmptyURL, CodeURLEmpty},
+		{"wrapped invalid URL", fmt.Errorf("parse: %w", domain.ErrInvalidURL), CodeURLInvalid},
+		{"unsupported protocol", fmt.Errorf("%w: gopher", domain.ErrUnsupportedProtocol), CodeProtocolUnsupported},
+		{"registry not ready", domain.ErrRegistryNotReady, CodeRegistryNotReady},
+		{"snapshot not retained", fmt.Errorf("%w: version v0", domain.ErrSnapshotNotRetained), CodeSnapshotNotRetained},
+		{"unknown registry version", domain.ErrUnknownRegistryVersion, CodeSnapshotNotRetained},
+		{"cursor expired", domain.ErrCursorExpired, CodeCursorExpired},
+		{"entry not found", fmt.Errorf("entry 42: %w", domain.ErrEntryNotFound), CodeNotFound},
+		{"invalid search query", domain.ErrInvalidSearchQuery, CodeInvalidArgument},
+		{"unclassified", errors.New("disk on fire"), CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CodeOf(tt.err); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDetail(t *testing.T) {
+	err := fmt.Errorf("%w: gopher", domain.ErrUnsupportedProtocol)
+	if got := Detail(err, "Failed"); got != err.Error() {
+		t.Errorf("expected the message of classified errors, got %q", got)
+	}
+
+	if got := Detail(errors.New("connection string leaked"), "Failed"); got != "Failed" {
+		t.Errorf("expected the fallback for internal errors, got %q", got)
+	}
+}
+
+func TestCode_UnknownIsInternal(t *testing.T) {
+	code := Code("NO_SUCH_CODE")
+	if code.HTTPStatus() != http.StatusInternalServerError || code.GRPCCode() != codes.Internal {
+		t.Errorf("expected unknown codes to be reported as internal, got %d and %v", code.HTTPStatus(), code.GRPCCode())
+	}
+}
+
+func TestGRPCError(t *testing.T) {
+	err := GRPCError(CodeRateLimited, "Rate limit exceeded",
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(3 * time.Second)})
+
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Errorf("expected ResourceExhausted, got %v", st.Code())
+	}
+	if st.Message() != "Rate limit exceeded" {
+		t.Errorf("expected the message to be kept, got %q", st.Message())
+	}
+
+	details := st.Details()
+	if len(details) != 2 {
+		t.Fatalf("expected ErrorInfo and RetryInfo details, got %v", details)
+	}
+	info, ok := details[0].(*errdetails.ErrorInfo)
+	if !ok || info.GetReason() != string(CodeRateLimited) || info.GetDomain() != ErrorDomain {
+		t.Errorf("expected ErrorInfo with reason %s first, got %v", CodeRateLimited, details[0])
+	}
+	if _, ok := details[1].(*errdetails.RetryInfo); !ok {
+		t.Errorf("expected RetryInfo after ErrorInfo, got %v", details[1])
+	}
+}
+
+func TestGRPCDomainError(t *testing.T) {
+	st := status.Convert(GRPCDomainError(fmt.Errorf("check: %w", domain.ErrRegistryNotReady), "Failed to check URL"))
+	if st.Code() != codes.Unavailable {
+		t.Errorf("expected Unavailable, got %v", st.Code())
+	}
+
+	st = status.Convert(GRPCDomainError(errors.New("boom"), "Failed to check URL"))
+	if st.Code() != codes.Internal || st.Message() != "Failed to check URL" {
+		t.Errorf("expected Internal with the fallback message, got %v %q", st.Code(), st.Message())
+	}
+}
@@ -0,0 +1,46 @@
+package common
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix prefixes the code in the type URI of problems
+const problemTypePrefix = "urn:rkn-checker:error:"
+
+// Problem is an RFC 7807 problem details object extended with the error
+// code
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Code   Code   `json:"code"`
+}
+
+// NewProblem describes an occurrence of code
+func NewProblem(code Code, detail string) Problem {
+	return Problem{
+		Type:   problemTypePrefix + string(code),
+		Title:  code.Title(),
+		Status: code.HTTPStatus(),
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// WriteProblem writes an occurrence of code as problem details, with the
+// HTTP status of code
+func WriteProblem(w http.ResponseWriter, code Code, detail string) {
+	problem := NewProblem(code, detail)
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+
+	if err := json.NewEncoder(w).Encode(problem); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,36 @@
+package common
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteProblem(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteProblem(w, CodeURLEmpty, "URL is required")
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != ProblemContentType {
+		t.Errorf("expected content type %s, got %s", ProblemContentType, contentType)
+	}
+
+	var problem Problem
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+		t.Fatalf("failed to decode problem: %v", err)
+	}
+
+	want := Problem{
+		Type:   "urn:rkn-checker:error:URL_EMPTY",
+		Title:  "URL is empty",
+		Status: http.StatusBadRequest,
+		Detail: "URL is required",
+		Code:   CodeURLEmpty,
+	}
+	if problem != want {
+		t.Errorf("expected %+v, got %+v", want, problem)
+	}
+}
@@ -0,0 +1,84 @@
+package grpc
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/application"
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/grpc/proto"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/updater"
+)
+
+// AdminHandler serves the AdminService
+type AdminHandler struct {
+	proto.UnimplementedAdminServiceServer
+	scheduler application.SchedulerController
+}
+
+func NewAdminHandler(scheduler application.SchedulerController) *AdminHandler {
+	return &AdminHandler{
+		scheduler: scheduler,
+	}
+}
+
+func (h *AdminHandler) TriggerUpdate(ctx context.Context, req *proto.TriggerUpdateRequest) (*proto.TriggerUpdateResponse, error) {
+	h.scheduler.TriggerUpdate()
+	return &proto.TriggerUpdateResponse{}, nil
+}
+
+func (h *AdminHandler) PauseUpdates(ctx context.Context, req *proto.PauseUpdatesRequest) (*proto.SchedulerStatus, error) {
+	h.scheduler.Pause()
+	return toProtoSchedulerStatus(h.scheduler.GetStatus()), nil
+}
+
+func (h *AdminHandler) ResumeUpdates(ctx context.Context, req *proto.ResumeUpdatesRequest) (*proto.SchedulerStatus, error) {
+	h.scheduler.Resume()
+	return toProtoSchedulerStatus(h.scheduler.GetStatus()), nil
+}
+
+func (h *AdminHandler) GetSchedulerStatus(ctx context.Context, req *proto.GetSchedulerStatusRequest) (*proto.SchedulerStatus, error) {
+	return toProtoSchedulerStatus(h.scheduler.GetStatus()), nil
+}
+
+func (h *AdminHandler) ClearRegistry(ctx context.Context, req *proto.ClearRegistryRequest) (*proto.ClearRegistryResponse, error) {
+	h.scheduler.ClearRegistry()
+	return &proto.ClearRegistryResponse{}, nil
+}
+
+func (h *AdminHandler) GetSourceHealth(ctx context.Context, req *proto.GetSourceHealthRequest) (*proto.GetSourceHealthResponse, error) {
+	health := h.scheduler.SourceHealth(ctx)
+
+	response := &proto.GetSourceHealthResponse{Sources: make([]*proto.SourceHealth, 0, len(health))}
+	for name, healthy := range health {
+		response.Sources = append(response.Sources, &proto.SourceHealth{Name: name, Healthy: healthy})
+	}
+	sort.Slice(response.Sources, func(i, j int) bool {
+		return response.Sources[i].Name < response.Sources[j].Name
+	})
+
+	return response, nil
+}
+
+func toProtoSchedulerStatus(status updater.Status) *proto.SchedulerStatus {
+	response := &proto.SchedulerStatus{
+		Running:             status.Running,
+		Paused:              status.Paused,
+		ConsecutiveFailures: int32(status.ConsecutiveFailures),
+		TotalUpdates:        int32(status.TotalUpdates),
+		SuccessfulUpdates:   int32(status.SuccessfulUpdates),
+		SuccessRate:         status.SuccessRate(),
+		RegistrySize:        int64(status.RegistrySize),
+		Quarantined:         status.Quarantined != nil,
+	}
+	if !status.LastUpdate.IsZero() {
+		response.LastUpdate = status.LastUpdate.Format(time.RFC3339)
+	}
+	if !status.NextUpdate.IsZero() {
+		response.NextUpdate = status.NextUpdate.Format(time.RFC3339)
+	}
+	if status.LastError != nil {
+		response.LastError = status.LastError.Error()
+	}
+	return response
+}
@@ -0,0 +1,90 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/grpc/proto"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/updater"
+)
+
+type mockSchedulerController struct {
+	status    updater.Status
+	health    map[string]bool
+	triggered int
+	cleared   int
+}
+
+func (m *mockSchedulerController) TriggerUpdate() {
+	m.triggered++
+}
+
+func (m *mockSchedulerController) Pause() {
+	m.status.Paused = true
+}
+
+func (m *mockSchedulerController) Resume() {
+	m.status.Paused = false
+}
+
+func (m *mockSchedulerController) GetStatus() updater.Status {
+	return m.status
+}
+
+func (m *mockSchedulerController) ClearRegistry() {
+	m.cleared++
+}
+
+func (m *mockSchedulerController) SourceHealth(ctx context.Context) map[string]bool {
+	return m.health
+}
+
+func TestAdminHandler_SchedulerControls(t *testing.T) {
+	scheduler := &mockSchedulerController{
+		status: updater.Status{
+			Running:             true,
+			LastUpdate:          time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC),
+			LastError:           errors.New("fetching registry: timeout"),
+			ConsecutiveFailures: 2,
+			TotalUpdates:        4,
+			SuccessfulUpdates:   2,
+			RegistrySize:        42,
+		},
+		health: map[string]bool{"official": false, "mirror": true},
+	}
+	handler := NewAdminHandler(scheduler)
+	ctx := context.Background()
+
+	paused, err := handler.PauseUpdates(ctx, &proto.PauseUpdatesRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !paused.Paused || paused.NextUpdate != "" {
+		t.Errorf("expected a paused status, got %v", paused)
+	}
+
+	resumed, _ := handler.ResumeUpdates(ctx, &proto.ResumeUpdatesRequest{})
+	if resumed.Paused {
+		t.Error("expected updates to be resumed")
+	}
+
+	current, _ := handler.GetSchedulerStatus(ctx, &proto.GetSchedulerStatusRequest{})
+	if current.LastError != "fetching registry: timeout" || current.ConsecutiveFailures != 2 ||
+		current.RegistrySize != 42 || current.SuccessRate != 50 || current.LastUpdate != "2024-06-01T09:00:00Z" {
+		t.Errorf("unexpected status %v", current)
+	}
+
+	handler.TriggerUpdate(ctx, &proto.TriggerUpdateRequest{})
+	handler.ClearRegistry(ctx, &proto.ClearRegistryRequest{})
+	if scheduler.triggered != 1 || scheduler.cleared != 1 {
+		t.Errorf("expected one trigger and one clear, got %d/%d", scheduler.triggered, scheduler.cleared)
+	}
+
+	sources, _ := handler.GetSourceHealth(ctx, &proto.GetSourceHealthRequest{})
+	if len(sources.Sources) != 2 || sources.Sources[0].Name != "mirror" || !sources.Sources[0].Healthy ||
+		sources.Sources[1].Name != "official" || sources.Sources[1].Healthy {
+		t.Errorf("unexpected source health %v", sources.Sources)
+	}
+}
@@ -2,11 +2,14 @@ package grpc
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"google.golang.org/grpc"
 
 	"github.com/kerim-dauren/rkn-checker/internal/application"
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/common"
 	"github.com/kerim-dauren/rkn-checker/internal/delivery/grpc/proto"
 	"github.com/kerim-dauren/rkn-checker/internal/domain"
 )
@@ -14,6 +17,14 @@ import (
 type Handler struct {
 	proto.UnimplementedBlockingServiceServer
 	blockingService application.BlockingChecker
+	changelog       application.ChangelogReader
+	history         application.HistoryReader
+	entries         application.EntryBrowser
+	searcher        application.RegistrySearcher
+	feed            application.RegistryFeed
+	heartbeat       time.Duration
+	stop            <-chan struct{}
+	readiness       application.ReadinessChecker
 }
 
 func NewHandler(blockingService application.BlockingChecker) *Handler {
@@ -24,26 +35,33 @@ func NewHandler(blockingService application.BlockingChecker) *Handler {
 
 func (h *Handler) CheckURL(ctx context.Context, req *proto.CheckURLRequest) (*proto.CheckURLResponse, error) {
 	if req.Url == "" {
-		return nil, status.Error(codes.InvalidArgument, "URL is required")
+		return nil, common.GRPCError(common.CodeURLEmpty, "URL is required")
 	}
 
-	result, err := h.blockingService.CheckURL(ctx, req.Url)
-	if err != nil {
-		switch err {
-		case domain.ErrEmptyURL:
-			return nil, status.Error(codes.InvalidArgument, "URL is empty")
-		case domain.ErrInvalidURL:
-			return nil, status.Error(codes.InvalidArgument, "Invalid URL format")
-		default:
-			return nil, status.Error(codes.Internal, "Internal server error")
+	if req.At != "" && req.Version != "" {
+		return nil, common.GRPCError(common.CodeInvalidArgument, "Only one of at and version may be set")
+	}
+
+	point := domain.RegistryPoint{Version: req.Version}
+	if req.At != "" {
+		at, err := time.Parse(time.RFC3339, req.At)
+		if err != nil {
+			return nil, common.GRPCError(common.CodeInvalidArgument, "Invalid at timestamp, expected RFC3339")
 		}
+		point.At = at
+	}
+
+	result, err := h.blockingService.CheckURLAt(ctx, req.Url, point)
+	if err != nil {
+		return nil, common.GRPCDomainError(err, "Failed to check URL")
 	}
 
 	response := &proto.CheckURLResponse{
-		Blocked:       result.IsBlocked,
-		NormalizedUrl: result.NormalizedURL,
-		Reason:        "",
-		Match:         "",
+		Blocked:         result.IsBlocked,
+		NormalizedUrl:   result.NormalizedURL,
+		Reason:          "",
+		Match:           "",
+		RegistryVersion: result.RegistryVersion,
 	}
 
 	if result.IsBlocked {
@@ -59,7 +77,7 @@ func (h *Handler) CheckURL(ctx context.Context, req *proto.CheckURLRequest) (*pr
 func (h *Handler) GetStats(ctx context.Context, req *proto.GetStatsRequest) (*proto.GetStatsResponse, error) {
 	stats, err := h.blockingService.GetStats(ctx)
 	if err != nil {
-		return nil, status.Error(codes.Internal, "Failed to get statistics")
+		return nil, common.GRPCError(common.CodeInternal, "Failed to get statistics")
 	}
 
 	return &proto.GetStatsResponse{
@@ -73,9 +91,311 @@ func (h *Handler) GetStats(ctx context.Context, req *proto.GetStatsRequest) (*pr
 	}, nil
 }
 
+// HealthCheck answers the readiness probe by default, with every check and
+// the reason of each failing one. The liveness probe always reports SERVING.
 func (h *Handler) HealthCheck(ctx context.Context, req *proto.HealthCheckRequest) (*proto.HealthCheckResponse, error) {
-	return &proto.HealthCheckResponse{
+	if req.Probe == proto.HealthCheckRequest_LIVENESS || h.readiness == nil {
+		return &proto.HealthCheckResponse{
+			Status:  proto.HealthCheckResponse_SERVING,
+			Message: "Service is healthy",
+		}, nil
+	}
+
+	report := h.readiness.Readiness(ctx)
+
+	response := &proto.HealthCheckResponse{
 		Status:  proto.HealthCheckResponse_SERVING,
-		Message: "Service is healthy",
-	}, nil
+		Message: "Service is ready",
+		Checks:  make([]*proto.HealthCheckDetail, 0, len(report.Checks)),
+	}
+
+	var failing []string
+	for _, check := range report.Checks {
+		response.Checks = append(response.Checks, &proto.HealthCheckDetail{
+			Name:    check.Name,
+			Healthy: check.Healthy,
+			Message: check.Message,
+		})
+		if !check.Healthy {
+			failing = append(failing, fmt.Sprintf("%s (%s)", check.Name, check.Message))
+		}
+	}
+	if !report.Healthy {
+		response.Status = proto.HealthCheckResponse_NOT_SERVING
+		response.Message = "Service is not ready: " + strings.Join(failing, "; ")
+	}
+
+	return response, nil
+}
+
+func (h *Handler) ListChanges(ctx context.Context, req *proto.ListChangesRequest) (*proto.ListChangesResponse, error) {
+	if h.changelog == nil {
+		return nil, common.GRPCError(common.CodeNotEnabled, "Changelog is not enabled")
+	}
+
+	changelogs, err := h.changelog.ChangesSince(req.Since)
+	if err != nil {
+		return nil, common.GRPCDomainError(err, "Failed to list changes")
+	}
+
+	response := &proto.ListChangesResponse{
+		CurrentVersion: h.changelog.CurrentVersion(),
+		Changelogs:     make([]*proto.Changelog, 0, len(changelogs)),
+	}
+	for _, changelog := range changelogs {
+		response.Changelogs = append(response.Changelogs, toProtoChangelog(changelog))
+	}
+
+	return response, nil
+}
+
+func (h *Handler) GetHistory(ctx context.Context, req *proto.GetHistoryRequest) (*proto.GetHistoryResponse, error) {
+	if h.history == nil {
+		return nil, common.GRPCError(common.CodeNotEnabled, "History is not enabled")
+	}
+
+	histories, err := h.history.History(req.Domain)
+	if err != nil {
+		return nil, common.GRPCDomainError(err, "Failed to get history")
+	}
+
+	response := &proto.GetHistoryResponse{
+		Patterns: make([]*proto.PatternHistory, 0, len(histories)),
+	}
+	for _, history := range histories {
+		pattern := &proto.PatternHistory{
+			Pattern: history.Pattern,
+			Blocked: history.Blocked(),
+			Events:  make([]*proto.HistoryEvent, 0, len(history.Events)),
+		}
+		for _, event := range history.Events {
+			pattern.Events = append(pattern.Events, &proto.HistoryEvent{
+				Kind:    toProtoChangeKind(event.Kind),
+				Type:    event.Type.String(),
+				Version: event.Version,
+				At:      event.At.Format(time.RFC3339),
+			})
+		}
+		response.Blocked = response.Blocked || pattern.Blocked
+		response.Patterns = append(response.Patterns, pattern)
+	}
+
+	return response, nil
+}
+
+func (h *Handler) GetEntry(ctx context.Context, req *proto.GetEntryRequest) (*proto.RegistryEntry, error) {
+	if h.entries == nil {
+		return nil, common.GRPCError(common.CodeNotEnabled, "Entry browsing is not enabled")
+	}
+
+	entry, err := h.entries.GetEntry(req.Id)
+	if err != nil {
+		return nil, common.GRPCDomainError(err, "Failed to get registry entry")
+	}
+
+	return toProtoEntry(entry), nil
+}
+
+func (h *Handler) ListEntries(ctx context.Context, req *proto.ListEntriesRequest) (*proto.ListEntriesResponse, error) {
+	if h.entries == nil {
+		return nil, common.GRPCError(common.CodeNotEnabled, "Entry browsing is not enabled")
+	}
+
+	query := domain.EntryQuery{
+		Query:  req.Q,
+		Cursor: req.Cursor,
+		Limit:  int(req.Limit),
+	}
+	if req.Type != "" {
+		entryType, ok := domain.ParseBlockingType(req.Type)
+		if !ok {
+			return nil, common.GRPCError(common.CodeInvalidArgument, "Invalid entry type")
+		}
+		query.Type = entryType
+	}
+
+	page, err := h.entries.ListEntries(query)
+	if err != nil {
+		return nil, common.GRPCDomainError(err, "Failed to list registry entries")
+	}
+
+	response := &proto.ListEntriesResponse{
+		Version:    page.Version,
+		Entries:    make([]*proto.RegistryEntry, 0, len(page.Entries)),
+		NextCursor: page.NextCursor,
+	}
+	for _, entry := range page.Entries {
+		response.Entries = append(response.Entries, toProtoEntry(entry))
+	}
+
+	return response, nil
+}
+
+func (h *Handler) Search(ctx context.Context, req *proto.SearchRequest) (*proto.SearchResponse, error) {
+	if h.searcher == nil {
+		return nil, common.GRPCError(common.CodeNotEnabled, "Search is not enabled")
+	}
+
+	query := domain.SearchQuery{
+		Mode:    domain.SearchModeSuffix,
+		Pattern: req.Q,
+		Limit:   int(req.Limit),
+	}
+	if req.Mode != "" {
+		mode, ok := domain.ParseSearchMode(req.Mode)
+		if !ok {
+			return nil, common.GRPCError(common.CodeInvalidArgument, "Invalid search mode")
+		}
+		query.Mode = mode
+	}
+
+	result, err := h.searcher.Search(ctx, query)
+	if err != nil {
+		return nil, common.GRPCDomainError(err, "Failed to search registry")
+	}
+
+	response := &proto.SearchResponse{
+		Version:   result.Version,
+		Entries:   make([]*proto.RegistryEntry, 0, len(result.Entries)),
+		Truncated: result.Truncated,
+		TimedOut:  result.TimedOut,
+	}
+	for _, entry := range result.Entries {
+		response.Entries = append(response.Entries, toProtoEntry(entry))
+	}
+
+	return response, nil
+}
+
+// WatchRegistry streams an event each time a new registry is swapped into
+// the store, resuming after since_version, with heartbeats while idle
+func (h *Handler) WatchRegistry(req *proto.WatchRegistryRequest, stream grpc.ServerStreamingServer[proto.WatchRegistryResponse]) error {
+	if h.feed == nil {
+		return common.GRPCError(common.CodeNotEnabled, "Registry feed is not enabled")
+	}
+
+	missed, events, cancel := h.feed.Subscribe(req.SinceVersion)
+	defer cancel()
+
+	for _, event := range missed {
+		if err := stream.Send(toProtoWatchEvent(event)); err != nil {
+			return err
+		}
+	}
+
+	interval := h.heartbeat
+	if interval <= 0 {
+		interval = DefaultHeartbeatInterval
+	}
+	heartbeat := time.NewTicker(interval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-h.stop:
+			return common.GRPCError(common.CodeUnavailable, "Server is shutting down, resume with since_version")
+		case event, ok := <-events:
+			if !ok {
+				return common.GRPCError(common.CodeStreamFellBehind, "Stream fell behind, resume with since_version")
+			}
+			if err := stream.Send(toProtoWatchEvent(event)); err != nil {
+				return err
+			}
+		case at := <-heartbeat.C:
+			frame := &proto.WatchRegistryResponse{
+				Frame: &proto.WatchRegistryResponse_Heartbeat{
+					Heartbeat: &proto.Heartbeat{At: at.UTC().Format(time.RFC3339)},
+				},
+			}
+			if err := stream.Send(frame); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toProtoWatchEvent(event *domain.RegistryEvent) *proto.WatchRegistryResponse {
+	result := &proto.RegistryEvent{
+		Version: event.Version,
+		Size:    int32(event.Size),
+		Counts:  make(map[string]int32, len(event.Counts)),
+	}
+	if !event.AppliedAt.IsZero() {
+		result.AppliedAt = event.AppliedAt.Format(time.RFC3339)
+	}
+	for blockingType, count := range event.Counts {
+		result.Counts[blockingType.String()] = int32(count)
+	}
+	if event.Diff != nil {
+		result.Diff = &proto.DiffSummary{
+			FromVersion: event.Diff.FromVersion,
+			Added:       int32(event.Diff.Added),
+			Removed:     int32(event.Diff.Removed),
+			Changed:     int32(event.Diff.Changed),
+		}
+	}
+	return &proto.WatchRegistryResponse{Frame: &proto.WatchRegistryResponse_Event{Event: result}}
+}
+
+func toProtoEntry(entry *domain.RegistryEntry) *proto.RegistryEntry {
+	result := &proto.RegistryEntry{
+		Id:          entry.ID,
+		Type:        entry.Type.String(),
+		Pattern:     entry.Value(),
+		Paths:       entry.Paths,
+		Decision:    entry.Decision,
+		DecisionOrg: entry.DecisionOrg,
+	}
+	if !entry.AddedDate.IsZero() {
+		result.AddedDate = entry.AddedDate.Format(time.RFC3339)
+	}
+	if !entry.BlockedDate.IsZero() {
+		result.BlockedDate = entry.BlockedDate.Format(time.RFC3339)
+	}
+	return result
+}
+
+func toProtoChangelog(changelog *domain.Changelog) *proto.Changelog {
+	result := &proto.Changelog{
+		FromVersion: changelog.FromVersion,
+		ToVersion:   changelog.ToVersion,
+		AppliedAt:   changelog.AppliedAt.Format(time.RFC3339),
+		Added:       int32(changelog.Added),
+		Removed:     int32(changelog.Removed),
+		Changed:     int32(changelog.Changed),
+		Changes:     make([]*proto.EntryChange, 0, len(changelog.Changes)),
+	}
+
+	for _, change := range changelog.Changes {
+		entry := &proto.EntryChange{
+			Kind:        toProtoChangeKind(change.Kind),
+			Value:       change.Value,
+			Type:        change.Type.String(),
+			Decision:    change.Decision,
+			DecisionOrg: change.DecisionOrg,
+		}
+		if change.Kind == domain.ChangeKindChanged {
+			entry.PreviousType = change.PreviousType.String()
+			entry.PreviousDecision = change.PreviousDecision
+			entry.PreviousDecisionOrg = change.PreviousDecisionOrg
+		}
+		result.Changes = append(result.Changes, entry)
+	}
+
+	return result
+}
+
+func toProtoChangeKind(kind domain.ChangeKind) proto.EntryChange_Kind {
+	switch kind {
+	case domain.ChangeKindAdded:
+		return proto.EntryChange_ADDED
+	case domain.ChangeKindRemoved:
+		return proto.EntryChange_REMOVED
+	case domain.ChangeKindChanged:
+		return proto.EntryChange_CHANGED
+	default:
+		return proto.EntryChange_UNKNOWN
+	}
 }
@@ -2,16 +2,27 @@ package grpc
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/kerim-dauren/rkn-checker/internal/application"
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/common"
 	"github.com/kerim-dauren/rkn-checker/internal/delivery/grpc/proto"
 	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/feed"
 )
 
 type mockBlockingService struct {
-	checkURLFunc func(ctx context.Context, rawURL string) (*domain.BlockingResult, error)
-	getStatsFunc func(ctx context.Context) (*application.BlockingStats, error)
+	checkURLFunc   func(ctx context.Context, rawURL string) (*domain.BlockingResult, error)
+	checkURLAtFunc func(ctx context.Context, rawURL string, point domain.RegistryPoint) (*domain.BlockingResult, error)
+	getStatsFunc   func(ctx context.Context) (*application.BlockingStats, error)
 }
 
 func (m *mockBlockingService) CheckURL(ctx context.Context, rawURL string) (*domain.BlockingResult, error) {
@@ -21,6 +32,13 @@ func (m *mockBlockingService) CheckURL(ctx context.Context, rawURL string) (*dom
 	return domain.NewBlockingResult(false, rawURL, nil), nil
 }
 
+func (m *mockBlockingService) CheckURLAt(ctx context.Context, rawURL string, point domain.RegistryPoint) (*domain.BlockingResult, error) {
+	if m.checkURLAtFunc != nil {
+		return m.checkURLAtFunc(ctx, rawURL, point)
+	}
+	return m.CheckURL(ctx, rawURL)
+}
+
 func (m *mockBlockingService) GetStats(ctx context.Context) (*application.BlockingStats, error) {
 	if m.getStatsFunc != nil {
 		return m.getStatsFunc(ctx)
@@ -120,6 +138,84 @@ func TestHandler_CheckURL(t *testing.T) {
 	}
 }
 
+func TestHandler_CheckURL_ErrorInfo(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		err    error
+		code   codes.Code
+		reason common.Code
+	}{
+		{"empty URL", "", nil, codes.InvalidArgument, common.CodeURLEmpty},
+		{"wrapped invalid URL", "http://", fmt.Errorf("normalize: %w", domain.ErrInvalidURL), codes.InvalidArgument, common.CodeURLInvalid},
+		{"unsupported protocol", "gopher://example.com", fmt.Errorf("%w: gopher", domain.ErrUnsupportedProtocol), codes.InvalidArgument, common.CodeProtocolUnsupported},
+		{"registry not loaded", "https://example.com", domain.ErrRegistryNotReady, codes.Unavailable, common.CodeRegistryNotReady},
+		{"unclassified error", "https://example.com", errors.New("store unavailable"), codes.Internal, common.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &mockBlockingService{}
+			if tt.err != nil {
+				service.checkURLFunc = func(ctx context.Context, rawURL string) (*domain.BlockingResult, error) {
+					return nil, tt.err
+				}
+			}
+
+			_, err := NewHandler(service).CheckURL(context.Background(), &proto.CheckURLRequest{Url: tt.url})
+			st := status.Convert(err)
+			if st.Code() != tt.code {
+				t.Errorf("Expected code %v, but got %v", tt.code, st.Code())
+			}
+			if reason := errorReason(st); reason != string(tt.reason) {
+				t.Errorf("Expected reason %s, but got %q", tt.reason, reason)
+			}
+		})
+	}
+}
+
+// errorReason is the reason of the ErrorInfo detail of st
+func errorReason(st *status.Status) string {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.GetReason()
+		}
+	}
+	return ""
+}
+
+func TestHandler_CheckURL_PointInTime(t *testing.T) {
+	mockService := &mockBlockingService{
+		checkURLAtFunc: func(ctx context.Context, rawURL string, point domain.RegistryPoint) (*domain.BlockingResult, error) {
+			if point.Version != "v1" {
+				return nil, domain.ErrSnapshotNotRetained
+			}
+			result := domain.NewBlockingResult(false, "example.com", nil)
+			result.RegistryVersion = point.Version
+			return result, nil
+		},
+	}
+	handler := NewHandler(mockService)
+
+	resp, err := handler.CheckURL(context.Background(), &proto.CheckURLRequest{Url: "https://example.com", Version: "v1"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.RegistryVersion != "v1" {
+		t.Errorf("Expected registry version v1, got %q", resp.RegistryVersion)
+	}
+
+	_, err = handler.CheckURL(context.Background(), &proto.CheckURLRequest{Url: "https://example.com", At: "2020-01-01T00:00:00Z"})
+	if status.Code(err) != codes.OutOfRange {
+		t.Errorf("Expected OutOfRange outside retention, got %v", err)
+	}
+
+	_, err = handler.CheckURL(context.Background(), &proto.CheckURLRequest{Url: "https://example.com", At: "yesterday"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for invalid timestamp, got %v", err)
+	}
+}
+
 func TestHandler_GetStats(t *testing.T) {
 	mockService := &mockBlockingService{}
 	handler := NewHandler(mockService)
@@ -169,3 +265,308 @@ func TestHandler_HealthCheck(t *testing.T) {
 		t.Errorf("Expected message='Service is healthy', but got %s", resp.Message)
 	}
 }
+
+type mockReadinessChecker struct {
+	report *domain.HealthReport
+}
+
+func (m *mockReadinessChecker) Readiness(ctx context.Context) *domain.HealthReport {
+	return m.report
+}
+
+func TestHandler_HealthCheck_Readiness(t *testing.T) {
+	handler := NewHandler(&mockBlockingService{})
+	handler.readiness = &mockReadinessChecker{report: domain.NewHealthReport(
+		domain.HealthCheck{Name: "registry_loaded", Message: "registry is empty"},
+		domain.HealthCheck{Name: "scheduler", Healthy: true, Message: "updates are succeeding"},
+	)}
+
+	resp, err := handler.HealthCheck(context.Background(), &proto.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Status != proto.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("expected NOT_SERVING, got %v", resp.Status)
+	}
+	if resp.Message != "Service is not ready: registry_loaded (registry is empty)" {
+		t.Errorf("unexpected message %q", resp.Message)
+	}
+	if len(resp.Checks) != 2 || resp.Checks[0].Healthy || !resp.Checks[1].Healthy {
+		t.Errorf("expected both checks with their outcome, got %v", resp.Checks)
+	}
+
+	// Liveness does not depend on the readiness checks
+	resp, _ = handler.HealthCheck(context.Background(), &proto.HealthCheckRequest{
+		Probe: proto.HealthCheckRequest_LIVENESS,
+	})
+	if resp.Status != proto.HealthCheckResponse_SERVING {
+		t.Errorf("expected the liveness probe to be SERVING, got %v", resp.Status)
+	}
+}
+
+type mockChangelogReader struct {
+	changelogs []*domain.Changelog
+	current    string
+}
+
+func (m *mockChangelogReader) ChangesSince(version string) ([]*domain.Changelog, error) {
+	if version == "" {
+		return m.changelogs, nil
+	}
+	return nil, domain.ErrUnknownRegistryVersion
+}
+
+func (m *mockChangelogReader) CurrentVersion() string {
+	return m.current
+}
+
+func TestHandler_ListChanges(t *testing.T) {
+	handler := NewHandler(&mockBlockingService{})
+
+	_, err := handler.ListChanges(context.Background(), &proto.ListChangesRequest{})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected Unimplemented without changelog, got %v", err)
+	}
+
+	handler.changelog = &mockChangelogReader{
+		current: "v2",
+		changelogs: []*domain.Changelog{{
+			FromVersion: "v1",
+			ToVersion:   "v2",
+			AppliedAt:   time.Now(),
+			Removed:     1,
+			Changes: []domain.EntryChange{
+				{Kind: domain.ChangeKindRemoved, Value: "gone.com", Type: domain.BlockingTypeDomain},
+			},
+		}},
+	}
+
+	resp, err := handler.ListChanges(context.Background(), &proto.ListChangesRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.CurrentVersion != "v2" || len(resp.Changelogs) != 1 {
+		t.Fatalf("Unexpected response: %v", resp)
+	}
+	change := resp.Changelogs[0].Changes[0]
+	if change.Kind != proto.EntryChange_REMOVED || change.Value != "gone.com" || change.Type != "domain" {
+		t.Errorf("Unexpected change: %v", change)
+	}
+
+	_, err = handler.ListChanges(context.Background(), &proto.ListChangesRequest{Since: "v0"})
+	if status.Code(err) != codes.OutOfRange {
+		t.Errorf("Expected OutOfRange for unknown version, got %v", err)
+	}
+}
+
+type mockHistoryReader struct {
+	histories []*domain.PatternHistory
+}
+
+func (m *mockHistoryReader) History(target string) ([]*domain.PatternHistory, error) {
+	if target == "" {
+		return nil, domain.ErrInvalidDomain
+	}
+	return m.histories, nil
+}
+
+func TestHandler_GetHistory(t *testing.T) {
+	handler := NewHandler(&mockBlockingService{})
+
+	_, err := handler.GetHistory(context.Background(), &proto.GetHistoryRequest{Domain: "example.com"})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected Unimplemented without history, got %v", err)
+	}
+
+	handler.history = &mockHistoryReader{
+		histories: []*domain.PatternHistory{{
+			Pattern: "example.com",
+			Events: []domain.HistoryEvent{
+				{Kind: domain.ChangeKindAdded, Type: domain.BlockingTypeDomain, Version: "v1", At: time.Now()},
+			},
+		}},
+	}
+
+	resp, err := handler.GetHistory(context.Background(), &proto.GetHistoryRequest{Domain: "example.com"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !resp.Blocked || len(resp.Patterns) != 1 {
+		t.Fatalf("Unexpected response: %v", resp)
+	}
+	event := resp.Patterns[0].Events[0]
+	if event.Kind != proto.EntryChange_ADDED || event.Version != "v1" || event.Type != "domain" {
+		t.Errorf("Unexpected event: %v", event)
+	}
+
+	_, err = handler.GetHistory(context.Background(), &proto.GetHistoryRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for empty domain, got %v", err)
+	}
+}
+
+type mockEntryBrowser struct {
+	entry *domain.RegistryEntry
+}
+
+func (m *mockEntryBrowser) GetEntry(id string) (*domain.RegistryEntry, error) {
+	if id != m.entry.ID {
+		return nil, domain.ErrEntryNotFound
+	}
+	return m.entry, nil
+}
+
+func (m *mockEntryBrowser) ListEntries(query domain.EntryQuery) (*domain.EntryPage, error) {
+	if query.Cursor == "stale" {
+		return nil, domain.ErrCursorExpired
+	}
+	return &domain.EntryPage{Version: "v1", Entries: []*domain.RegistryEntry{m.entry}, NextCursor: "next"}, nil
+}
+
+func TestHandler_Entries(t *testing.T) {
+	handler := NewHandler(&mockBlockingService{})
+
+	_, err := handler.GetEntry(context.Background(), &proto.GetEntryRequest{Id: "1-1"})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected Unimplemented without entry browser, got %v", err)
+	}
+
+	entry, _ := domain.NewRegistryEntry(domain.BlockingTypeIP, "10.0.0.1")
+	entry.ID = "1-1"
+	handler.entries = &mockEntryBrowser{entry: entry}
+
+	resp, err := handler.GetEntry(context.Background(), &proto.GetEntryRequest{Id: "1-1"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.Pattern != "10.0.0.1" || resp.Type != "ip" || resp.BlockedDate != "" {
+		t.Errorf("Unexpected entry: %v", resp)
+	}
+
+	_, err = handler.GetEntry(context.Background(), &proto.GetEntryRequest{Id: "2-1"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+
+	page, err := handler.ListEntries(context.Background(), &proto.ListEntriesRequest{Type: "ip"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(page.Entries) != 1 || page.NextCursor != "next" || page.Version != "v1" {
+		t.Errorf("Unexpected page: %v", page)
+	}
+
+	_, err = handler.ListEntries(context.Background(), &proto.ListEntriesRequest{Type: "regex"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for unknown type, got %v", err)
+	}
+
+	_, err = handler.ListEntries(context.Background(), &proto.ListEntriesRequest{Cursor: "stale"})
+	if status.Code(err) != codes.OutOfRange {
+		t.Errorf("Expected OutOfRange for expired cursor, got %v", err)
+	}
+}
+
+type mockSearcher struct{}
+
+func (m *mockSearcher) Search(ctx context.Context, query domain.SearchQuery) (*domain.SearchResult, error) {
+	if query.Mode != domain.SearchModeSuffix {
+		return nil, domain.ErrInvalidSearchQuery
+	}
+	entry, _ := domain.NewRegistryEntry(domain.BlockingTypeWildcard, "*."+query.Pattern)
+	return &domain.SearchResult{Version: "v1", Entries: []*domain.RegistryEntry{entry}}, nil
+}
+
+func TestHandler_Search(t *testing.T) {
+	handler := NewHandler(&mockBlockingService{})
+
+	_, err := handler.Search(context.Background(), &proto.SearchRequest{Q: "example.com"})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected Unimplemented without searcher, got %v", err)
+	}
+
+	handler.searcher = &mockSearcher{}
+
+	resp, err := handler.Search(context.Background(), &proto.SearchRequest{Q: "example.com"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resp.Entries) != 1 || resp.Entries[0].Pattern != "*.example.com" || resp.Entries[0].Type != "wildcard" {
+		t.Errorf("Unexpected response: %v", resp)
+	}
+
+	for _, req := range []*proto.SearchRequest{{Mode: "fuzzy", Q: "x"}, {Mode: "regex", Q: "x"}} {
+		if _, err := handler.Search(context.Background(), req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for %v, got %v", req, err)
+		}
+	}
+}
+
+// recordingStream is a WatchRegistry stream that hands sent frames to a
+// channel
+type recordingStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	frames chan *proto.WatchRegistryResponse
+}
+
+func (s *recordingStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *recordingStream) Send(frame *proto.WatchRegistryResponse) error {
+	s.frames <- frame
+	return nil
+}
+
+func TestHandler_WatchRegistry(t *testing.T) {
+	handler := NewHandler(&mockBlockingService{})
+	if err := handler.WatchRegistry(&proto.WatchRegistryRequest{}, nil); status.Code(err) != codes.Unimplemented {
+		t.Errorf("expected Unimplemented without a feed, got %v", err)
+	}
+
+	registryFeed := feed.NewFeed(0)
+	registryFeed.Publish(&domain.RegistryEvent{Version: "v1"})
+	registryFeed.Publish(&domain.RegistryEvent{
+		Version: "v2",
+		Size:    2,
+		Counts:  map[domain.BlockingType]int{domain.BlockingTypeIP: 2},
+		Diff:    &domain.DiffSummary{FromVersion: "v1", Removed: 1},
+	})
+
+	stop := make(chan struct{})
+	handler.feed = registryFeed
+	handler.heartbeat = 20 * time.Millisecond
+	handler.stop = stop
+
+	stream := &recordingStream{ctx: context.Background(), frames: make(chan *proto.WatchRegistryResponse, 16)}
+	done := make(chan error, 1)
+	go func() {
+		done <- handler.WatchRegistry(&proto.WatchRegistryRequest{SinceVersion: "v1"}, stream)
+	}()
+
+	event := (<-stream.frames).GetEvent()
+	if event == nil || event.Version != "v2" || event.Counts["ip"] != 2 || event.Diff.GetRemoved() != 1 {
+		t.Fatalf("expected the missed v2 event, got %+v", event)
+	}
+
+	if heartbeat := (<-stream.frames).GetHeartbeat(); heartbeat == nil || heartbeat.At == "" {
+		t.Fatalf("expected a heartbeat on an idle stream, got %+v", heartbeat)
+	}
+
+	registryFeed.Publish(&domain.RegistryEvent{Version: "v3"})
+	for frame := range stream.frames {
+		if frame.GetHeartbeat() != nil {
+			continue
+		}
+		if frame.GetEvent().GetVersion() != "v3" {
+			t.Fatalf("expected the live v3 event, got %+v", frame)
+		}
+		break
+	}
+
+	close(stop)
+	if err := <-done; status.Code(err) != codes.Unavailable {
+		t.Errorf("expected Unavailable when the server stops, got %v", err)
+	}
+}
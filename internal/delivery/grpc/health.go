@@ -0,0 +1,113 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/common"
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/grpc/proto"
+)
+
+// DefaultReadinessInterval is how often the grpc.health.v1 serving status is
+// re-evaluated from the readiness checks
+const DefaultReadinessInterval = 5 * time.Second
+
+// healthServer serves grpc.health.v1 and ends Watch streams when the server
+// stops, so they do not hold up a graceful stop
+type healthServer struct {
+	*health.Server
+	stop <-chan struct{}
+}
+
+func newHealthServer(stop <-chan struct{}) *healthServer {
+	return &healthServer{
+		Server: health.NewServer(),
+		stop:   stop,
+	}
+}
+
+// Watch streams serving status changes. On shutdown the stream receives a
+// final NOT_SERVING and ends with Unavailable.
+func (h *healthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	watch := &watchStream{Health_WatchServer: stream, ctx: ctx}
+	done := make(chan error, 1)
+	go func() {
+		done <- h.Server.Watch(req, watch)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-h.stop:
+		cancel()
+		<-done
+		if watch.last != healthpb.HealthCheckResponse_NOT_SERVING {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}); err != nil {
+				return err
+			}
+		}
+		return common.GRPCError(common.CodeUnavailable, "Server is shutting down")
+	}
+}
+
+// watchStream replaces the context of a Watch stream and records the last
+// status sent on it
+type watchStream struct {
+	healthpb.Health_WatchServer
+	ctx  context.Context
+	last healthpb.HealthCheckResponse_ServingStatus
+}
+
+func (s *watchStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *watchStream) Send(resp *healthpb.HealthCheckResponse) error {
+	if err := s.Health_WatchServer.Send(resp); err != nil {
+		return err
+	}
+	s.last = resp.Status
+	return nil
+}
+
+// watchReadiness keeps the serving status in line with the readiness checks
+// until ctx is done or the server stops
+func (s *Server) watchReadiness(ctx context.Context) {
+	ticker := time.NewTicker(s.readinessInterval)
+	defer ticker.Stop()
+
+	serving := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		next := healthpb.HealthCheckResponse_SERVING
+		if s.readiness != nil && !s.readiness.Readiness(ctx).Healthy {
+			next = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+
+		if next != serving {
+			slog.Info("gRPC serving status changed", "status", next.String())
+			serving = next
+		}
+		s.setServingStatus(next)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// setServingStatus sets the status of the server and of BlockingService
+func (s *Server) setServingStatus(serving healthpb.HealthCheckResponse_ServingStatus) {
+	s.health.SetServingStatus("", serving)
+	s.health.SetServingStatus(proto.BlockingService_ServiceDesc.ServiceName, serving)
+}
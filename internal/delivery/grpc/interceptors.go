@@ -2,12 +2,29 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"net"
+	"net/netip"
+	"strings"
 	"time"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/stats"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/kerim-dauren/rkn-checker/internal/application"
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/common"
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/grpc/proto"
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/metrics"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/proxy"
 )
 
 func loggingInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
@@ -30,17 +47,53 @@ func loggingInterceptor(ctx context.Context, req interface{}, info *grpc.UnarySe
 			"method", info.FullMethod,
 			"duration", duration.String(),
 			"code", code.String(),
-			"error", err.Error())
+			"error", err.Error(),
+			"remote_ip", remoteIP(ctx),
+			clientAttr(ctx))
 	} else {
 		slog.Info("gRPC request completed",
 			"method", info.FullMethod,
 			"duration", duration.String(),
-			"code", code.String())
+			"code", code.String(),
+			"remote_ip", remoteIP(ctx),
+			clientAttr(ctx))
 	}
 
 	return resp, err
 }
 
+// metricsInterceptor counts calls and observes their latency by full method
+func metricsInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+
+	resp, err := handler(ctx, req)
+
+	observeCall(info.FullMethod, start, err)
+	return resp, err
+}
+
+// streamMetricsInterceptor counts streams and observes their lifetime by full
+// method
+func streamMetricsInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+
+	err := handler(srv, ss)
+
+	observeCall(info.FullMethod, start, err)
+	return err
+}
+
+func observeCall(method string, start time.Time, err error) {
+	metrics.GRPCRequests.WithLabelValues(method, status.Code(err).String()).Inc()
+	metrics.GRPCRequestDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// traced keeps health checks and reflection out of traces
+func traced(info *stats.RPCTagInfo) bool {
+	return !strings.HasPrefix(info.FullMethodName, "/grpc.health.v1.Health/") &&
+		!strings.HasPrefix(info.FullMethodName, "/grpc.reflection.")
+}
+
 func recoveryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -48,9 +101,331 @@ func recoveryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryS
 				"method", info.FullMethod,
 				"panic", r)
 
-			err = status.Error(codes.Internal, "Internal server error")
+			err = common.GRPCError(common.CodeInternal, "Internal server error")
 		}
 	}()
 
 	return handler(ctx, req)
 }
+
+func streamLoggingInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+
+	err := handler(srv, ss)
+
+	duration := time.Since(start)
+	code := status.Code(err)
+
+	if err != nil && code != codes.Canceled {
+		slog.Error("gRPC stream failed",
+			"method", info.FullMethod,
+			"duration", duration.String(),
+			"code", code.String(),
+			"error", err.Error(),
+			"remote_ip", remoteIP(ss.Context()),
+			clientAttr(ss.Context()))
+	} else {
+		slog.Info("gRPC stream completed",
+			"method", info.FullMethod,
+			"duration", duration.String(),
+			"code", code.String(),
+			"remote_ip", remoteIP(ss.Context()),
+			clientAttr(ss.Context()))
+	}
+
+	return err
+}
+
+func streamRecoveryInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("gRPC stream panicked",
+				"method", info.FullMethod,
+				"panic", r)
+
+			err = common.GRPCError(common.CodeInternal, "Internal server error")
+		}
+	}()
+
+	return handler(srv, ss)
+}
+
+// clientIPInterceptor replaces the peer address of calls received from the
+// trusted proxies of resolver with the client address they forwarded
+func clientIPInterceptor(resolver *proxy.Resolver) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(resolveClientIP(ctx, resolver), req)
+	}
+}
+
+// streamClientIPInterceptor is clientIPInterceptor for streams
+func streamClientIPInterceptor(resolver *proxy.Resolver) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextStream{ServerStream: ss, ctx: resolveClientIP(ss.Context(), resolver)})
+	}
+}
+
+// resolveClientIP returns ctx with the peer address replaced by the client
+// address forwarded in the metadata, when the peer is a trusted proxy
+func resolveClientIP(ctx context.Context, resolver *proxy.Resolver) context.Context {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ctx
+	}
+	addr := proxy.ParseHost(p.Addr.String())
+	if !addr.IsValid() {
+		return ctx
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	client := resolver.ClientIP(addr, md.Get(resolver.Header()))
+	if client == addr {
+		return ctx
+	}
+
+	resolved := *p
+	resolved.Addr = net.TCPAddrFromAddrPort(netip.AddrPortFrom(client, 0))
+	return peer.NewContext(ctx, &resolved)
+}
+
+// remoteIP is the client address of ctx, as resolved by clientIPInterceptor
+// when it came through a trusted proxy
+func remoteIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "unknown"
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// methodScopes is the scope each RPC requires. RPCs missing from it require
+// the admin scope, except the unauthenticated ones.
+var methodScopes = map[string]domain.Scope{
+	proto.BlockingService_CheckURL_FullMethodName:      domain.ScopeCheck,
+	proto.BlockingService_GetStats_FullMethodName:      domain.ScopeStats,
+	proto.BlockingService_ListChanges_FullMethodName:   domain.ScopeRegistry,
+	proto.BlockingService_GetHistory_FullMethodName:    domain.ScopeRegistry,
+	proto.BlockingService_GetEntry_FullMethodName:      domain.ScopeRegistry,
+	proto.BlockingService_ListEntries_FullMethodName:   domain.ScopeRegistry,
+	proto.BlockingService_Search_FullMethodName:        domain.ScopeRegistry,
+	proto.BlockingService_WatchRegistry_FullMethodName: domain.ScopeRegistry,
+}
+
+// unauthenticated reports whether method is served to any caller: the
+// health checks and reflection
+func unauthenticated(method string) bool {
+	return method == proto.BlockingService_HealthCheck_FullMethodName ||
+		strings.HasPrefix(method, "/grpc.health.v1.Health/") ||
+		strings.HasPrefix(method, "/grpc.reflection.")
+}
+
+// authInterceptor resolves the caller of every call, carries its identity in
+// the call context and rejects callers missing the scope of the method
+func authInterceptor(authenticator application.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authorize(ctx, authenticator, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// streamAuthInterceptor is authInterceptor for streams
+func streamAuthInterceptor(authenticator application.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), authenticator, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// contextStream carries a context derived by an interceptor, e.g. holding
+// the caller identity, as the stream context
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// authorize resolves the caller of method and checks it was granted the
+// scope of method, returning ctx carrying its identity
+func authorize(ctx context.Context, authenticator application.Authenticator, method string) (context.Context, error) {
+	if unauthenticated(method) {
+		return ctx, nil
+	}
+
+	identity, err := authenticate(ctx, authenticator)
+	if err != nil {
+		slog.Warn("Rejected credentials", "method", method, "error", err)
+		return nil, common.GRPCError(common.CodeUnauthenticated, "Invalid credentials")
+	}
+
+	scope, ok := methodScopes[method]
+	if !ok {
+		scope = domain.ScopeAdmin
+	}
+	if !identity.HasScope(scope) {
+		if identity.IsAnonymous() {
+			return nil, common.GRPCError(common.CodeUnauthenticated, "Valid credentials required")
+		}
+		slog.Warn("Forbidden call", "method", method, "client", identity.String(), "scope", scope)
+		return nil, common.GRPCError(common.CodePermissionDenied, "Missing scope "+string(scope))
+	}
+
+	return domain.ContextWithIdentity(ctx, identity), nil
+}
+
+// authenticate resolves the caller from the API key or bearer token
+// metadata, then from a verified client certificate
+func authenticate(ctx context.Context, authenticator application.Authenticator) (*domain.Identity, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("x-api-key"); len(values) > 0 {
+		return authenticator.AuthenticateToken(values[0])
+	}
+	if values := md.Get("authorization"); len(values) > 0 {
+		token, ok := strings.CutPrefix(values[0], "Bearer ")
+		if !ok {
+			return nil, errors.New("unsupported authorization scheme")
+		}
+		return authenticator.AuthenticateToken(token)
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) > 0 {
+			return authenticator.AuthenticateCertificate(info.State.VerifiedChains[0][0])
+		}
+	}
+	return authenticator.Anonymous(), nil
+}
+
+// failedAuthenticationLimitInterceptor charges the calls whose credentials
+// are rejected to the address of their client under
+// domain.FailedAuthenticationRoute, and turns away the calls presenting
+// credentials from an address over that rule before they are checked, so
+// credentials cannot be guessed faster than the rule allows
+func failedAuthenticationLimitInterceptor(limiter application.RateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var resp interface{}
+		err := limitFailedAuthentication(ctx, limiter, info.FullMethod, func() (err error) {
+			resp, err = handler(ctx, req)
+			return err
+		})
+		return resp, err
+	}
+}
+
+// streamFailedAuthenticationLimitInterceptor is
+// failedAuthenticationLimitInterceptor for streams
+func streamFailedAuthenticationLimitInterceptor(limiter application.RateLimiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return limitFailedAuthentication(ss.Context(), limiter, info.FullMethod, func() error {
+			return handler(srv, ss)
+		})
+	}
+}
+
+// limitFailedAuthentication runs call unless its address is over its failed
+// authentication attempts, charging the attempt when call rejects the
+// credentials presented
+func limitFailedAuthentication(ctx context.Context, limiter application.RateLimiter, method string, call func() error) error {
+	if unauthenticated(method) || !presentsCredentials(ctx) {
+		return call()
+	}
+
+	client := "ip:" + remoteIP(ctx)
+	if decision := limiter.Peek(client, domain.FailedAuthenticationRoute); !decision.Allowed {
+		return rateLimited(decision)
+	}
+
+	err := call()
+	if status.Code(err) == codes.Unauthenticated {
+		limiter.Allow(client, domain.FailedAuthenticationRoute)
+	}
+	return err
+}
+
+// presentsCredentials reports whether the caller of ctx presented
+// credentials for authenticate to check
+func presentsCredentials(ctx context.Context) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if len(md.Get("x-api-key")) > 0 || len(md.Get("authorization")) > 0 {
+		return true
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	return ok && len(info.State.VerifiedChains) > 0
+}
+
+// rateLimitInterceptor charges every call to the identity of its caller, or
+// to the address of anonymous callers, so every key has buckets of its own,
+// even when keys share an address
+func rateLimitInterceptor(limiter application.RateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := rateLimit(limiter, rateLimitClient(ctx), info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// streamRateLimitInterceptor is rateLimitInterceptor for streams
+func streamRateLimitInterceptor(limiter application.RateLimiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := rateLimit(limiter, rateLimitClient(ss.Context()), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// rateLimit counts a call to method against the limits of client. Health
+// checks and reflection are never limited.
+func rateLimit(limiter application.RateLimiter, client, method string) error {
+	if unauthenticated(method) {
+		return nil
+	}
+	if decision := limiter.Allow(client, method); !decision.Allowed {
+		return rateLimited(decision)
+	}
+	return nil
+}
+
+// rateLimited rejects a call over its rate or daily quota with
+// RESOURCE_EXHAUSTED and the time to wait as RetryInfo
+func rateLimited(decision domain.RateLimitDecision) error {
+	code, message := common.CodeRateLimited, "Rate limit exceeded"
+	if decision.QuotaExceeded {
+		code, message = common.CodeQuotaExceeded, "Daily quota exceeded"
+	}
+	return common.GRPCError(code, message,
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(decision.RetryAfter)})
+}
+
+// rateLimitClient identifies the caller of ctx for rate limiting by its
+// identity, or by its IP address when it is anonymous
+func rateLimitClient(ctx context.Context) string {
+	if identity := domain.IdentityFromContext(ctx); identity != nil && !identity.IsAnonymous() {
+		return identity.String()
+	}
+	return "ip:" + remoteIP(ctx)
+}
+
+// clientAttr identifies the caller of ctx in call logs
+func clientAttr(ctx context.Context) slog.Attr {
+	if identity := domain.IdentityFromContext(ctx); identity != nil {
+		return slog.String("client", identity.String())
+	}
+	return slog.String("client", string(domain.AuthMethodAnonymous))
+}
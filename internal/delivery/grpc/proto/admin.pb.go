@@ -0,0 +1,644 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: internal/delivery/grpc/proto/admin.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TriggerUpdateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerUpdateRequest) Reset() {
+	*x = TriggerUpdateRequest{}
+	mi := &file_internal_delivery_grpc_proto_admin_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerUpdateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerUpdateRequest) ProtoMessage() {}
+
+func (x *TriggerUpdateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_admin_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerUpdateRequest.ProtoReflect.Descriptor instead.
+func (*TriggerUpdateRequest) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_admin_proto_rawDescGZIP(), []int{0}
+}
+
+type TriggerUpdateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerUpdateResponse) Reset() {
+	*x = TriggerUpdateResponse{}
+	mi := &file_internal_delivery_grpc_proto_admin_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerUpdateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerUpdateResponse) ProtoMessage() {}
+
+func (x *TriggerUpdateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_admin_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerUpdateResponse.ProtoReflect.Descriptor instead.
+func (*TriggerUpdateResponse) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_admin_proto_rawDescGZIP(), []int{1}
+}
+
+type PauseUpdatesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PauseUpdatesRequest) Reset() {
+	*x = PauseUpdatesRequest{}
+	mi := &file_internal_delivery_grpc_proto_admin_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PauseUpdatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PauseUpdatesRequest) ProtoMessage() {}
+
+func (x *PauseUpdatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_admin_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PauseUpdatesRequest.ProtoReflect.Descriptor instead.
+func (*PauseUpdatesRequest) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_admin_proto_rawDescGZIP(), []int{2}
+}
+
+type ResumeUpdatesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeUpdatesRequest) Reset() {
+	*x = ResumeUpdatesRequest{}
+	mi := &file_internal_delivery_grpc_proto_admin_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeUpdatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeUpdatesRequest) ProtoMessage() {}
+
+func (x *ResumeUpdatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_admin_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeUpdatesRequest.ProtoReflect.Descriptor instead.
+func (*ResumeUpdatesRequest) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_admin_proto_rawDescGZIP(), []int{3}
+}
+
+type GetSchedulerStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSchedulerStatusRequest) Reset() {
+	*x = GetSchedulerStatusRequest{}
+	mi := &file_internal_delivery_grpc_proto_admin_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSchedulerStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSchedulerStatusRequest) ProtoMessage() {}
+
+func (x *GetSchedulerStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_admin_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSchedulerStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetSchedulerStatusRequest) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_admin_proto_rawDescGZIP(), []int{4}
+}
+
+type SchedulerStatus struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Running bool                   `protobuf:"varint,1,opt,name=running,proto3" json:"running,omitempty"`
+	Paused  bool                   `protobuf:"varint,2,opt,name=paused,proto3" json:"paused,omitempty"`
+	// last_update and next_update are RFC3339, empty when unknown
+	LastUpdate          string  `protobuf:"bytes,3,opt,name=last_update,json=lastUpdate,proto3" json:"last_update,omitempty"`
+	NextUpdate          string  `protobuf:"bytes,4,opt,name=next_update,json=nextUpdate,proto3" json:"next_update,omitempty"`
+	LastError           string  `protobuf:"bytes,5,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	ConsecutiveFailures int32   `protobuf:"varint,6,opt,name=consecutive_failures,json=consecutiveFailures,proto3" json:"consecutive_failures,omitempty"`
+	TotalUpdates        int32   `protobuf:"varint,7,opt,name=total_updates,json=totalUpdates,proto3" json:"total_updates,omitempty"`
+	SuccessfulUpdates   int32   `protobuf:"varint,8,opt,name=successful_updates,json=successfulUpdates,proto3" json:"successful_updates,omitempty"`
+	SuccessRate         float64 `protobuf:"fixed64,9,opt,name=success_rate,json=successRate,proto3" json:"success_rate,omitempty"`
+	RegistrySize        int64   `protobuf:"varint,10,opt,name=registry_size,json=registrySize,proto3" json:"registry_size,omitempty"`
+	Quarantined         bool    `protobuf:"varint,11,opt,name=quarantined,proto3" json:"quarantined,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *SchedulerStatus) Reset() {
+	*x = SchedulerStatus{}
+	mi := &file_internal_delivery_grpc_proto_admin_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SchedulerStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SchedulerStatus) ProtoMessage() {}
+
+func (x *SchedulerStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_admin_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SchedulerStatus.ProtoReflect.Descriptor instead.
+func (*SchedulerStatus) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_admin_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SchedulerStatus) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+func (x *SchedulerStatus) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *SchedulerStatus) GetLastUpdate() string {
+	if x != nil {
+		return x.LastUpdate
+	}
+	return ""
+}
+
+func (x *SchedulerStatus) GetNextUpdate() string {
+	if x != nil {
+		return x.NextUpdate
+	}
+	return ""
+}
+
+func (x *SchedulerStatus) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *SchedulerStatus) GetConsecutiveFailures() int32 {
+	if x != nil {
+		return x.ConsecutiveFailures
+	}
+	return 0
+}
+
+func (x *SchedulerStatus) GetTotalUpdates() int32 {
+	if x != nil {
+		return x.TotalUpdates
+	}
+	return 0
+}
+
+func (x *SchedulerStatus) GetSuccessfulUpdates() int32 {
+	if x != nil {
+		return x.SuccessfulUpdates
+	}
+	return 0
+}
+
+func (x *SchedulerStatus) GetSuccessRate() float64 {
+	if x != nil {
+		return x.SuccessRate
+	}
+	return 0
+}
+
+func (x *SchedulerStatus) GetRegistrySize() int64 {
+	if x != nil {
+		return x.RegistrySize
+	}
+	return 0
+}
+
+func (x *SchedulerStatus) GetQuarantined() bool {
+	if x != nil {
+		return x.Quarantined
+	}
+	return false
+}
+
+type ClearRegistryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClearRegistryRequest) Reset() {
+	*x = ClearRegistryRequest{}
+	mi := &file_internal_delivery_grpc_proto_admin_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClearRegistryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearRegistryRequest) ProtoMessage() {}
+
+func (x *ClearRegistryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_admin_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearRegistryRequest.ProtoReflect.Descriptor instead.
+func (*ClearRegistryRequest) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_admin_proto_rawDescGZIP(), []int{6}
+}
+
+type ClearRegistryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClearRegistryResponse) Reset() {
+	*x = ClearRegistryResponse{}
+	mi := &file_internal_delivery_grpc_proto_admin_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClearRegistryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearRegistryResponse) ProtoMessage() {}
+
+func (x *ClearRegistryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_admin_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearRegistryResponse.ProtoReflect.Descriptor instead.
+func (*ClearRegistryResponse) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_admin_proto_rawDescGZIP(), []int{7}
+}
+
+type GetSourceHealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSourceHealthRequest) Reset() {
+	*x = GetSourceHealthRequest{}
+	mi := &file_internal_delivery_grpc_proto_admin_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSourceHealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSourceHealthRequest) ProtoMessage() {}
+
+func (x *GetSourceHealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_admin_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSourceHealthRequest.ProtoReflect.Descriptor instead.
+func (*GetSourceHealthRequest) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_admin_proto_rawDescGZIP(), []int{8}
+}
+
+type GetSourceHealthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sources       []*SourceHealth        `protobuf:"bytes,1,rep,name=sources,proto3" json:"sources,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSourceHealthResponse) Reset() {
+	*x = GetSourceHealthResponse{}
+	mi := &file_internal_delivery_grpc_proto_admin_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSourceHealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSourceHealthResponse) ProtoMessage() {}
+
+func (x *GetSourceHealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_admin_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSourceHealthResponse.ProtoReflect.Descriptor instead.
+func (*GetSourceHealthResponse) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_admin_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetSourceHealthResponse) GetSources() []*SourceHealth {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+type SourceHealth struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Healthy       bool                   `protobuf:"varint,2,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SourceHealth) Reset() {
+	*x = SourceHealth{}
+	mi := &file_internal_delivery_grpc_proto_admin_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SourceHealth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SourceHealth) ProtoMessage() {}
+
+func (x *SourceHealth) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_admin_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SourceHealth.ProtoReflect.Descriptor instead.
+func (*SourceHealth) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_admin_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SourceHealth) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SourceHealth) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+var File_internal_delivery_grpc_proto_admin_proto protoreflect.FileDescriptor
+
+const file_internal_delivery_grpc_proto_admin_proto_rawDesc = "" +
+	"\n" +
+	"(internal/delivery/grpc/proto/admin.proto\x12\vblocking.v1\"\x16\n" +
+	"\x14TriggerUpdateRequest\"\x17\n" +
+	"\x15TriggerUpdateResponse\"\x15\n" +
+	"\x13PauseUpdatesRequest\"\x16\n" +
+	"\x14ResumeUpdatesRequest\"\x1b\n" +
+	"\x19GetSchedulerStatusRequest\"\x95\x03\n" +
+	"\x0fSchedulerStatus\x12\x18\n" +
+	"\arunning\x18\x01 \x01(\bR\arunning\x12\x16\n" +
+	"\x06paused\x18\x02 \x01(\bR\x06paused\x12\x1f\n" +
+	"\vlast_update\x18\x03 \x01(\tR\n" +
+	"lastUpdate\x12\x1f\n" +
+	"\vnext_update\x18\x04 \x01(\tR\n" +
+	"nextUpdate\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x05 \x01(\tR\tlastError\x121\n" +
+	"\x14consecutive_failures\x18\x06 \x01(\x05R\x13consecutiveFailures\x12#\n" +
+	"\rtotal_updates\x18\a \x01(\x05R\ftotalUpdates\x12-\n" +
+	"\x12successful_updates\x18\b \x01(\x05R\x11successfulUpdates\x12!\n" +
+	"\fsuccess_rate\x18\t \x01(\x01R\vsuccessRate\x12#\n" +
+	"\rregistry_size\x18\n" +
+	" \x01(\x03R\fregistrySize\x12 \n" +
+	"\vquarantined\x18\v \x01(\bR\vquarantined\"\x16\n" +
+	"\x14ClearRegistryRequest\"\x17\n" +
+	"\x15ClearRegistryResponse\"\x18\n" +
+	"\x16GetSourceHealthRequest\"N\n" +
+	"\x17GetSourceHealthResponse\x123\n" +
+	"\asources\x18\x01 \x03(\v2\x19.blocking.v1.SourceHealthR\asources\"<\n" +
+	"\fSourceHealth\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\ahealthy\x18\x02 \x01(\bR\ahealthy2\x9a\x04\n" +
+	"\fAdminService\x12V\n" +
+	"\rTriggerUpdate\x12!.blocking.v1.TriggerUpdateRequest\x1a\".blocking.v1.TriggerUpdateResponse\x12N\n" +
+	"\fPauseUpdates\x12 .blocking.v1.PauseUpdatesRequest\x1a\x1c.blocking.v1.SchedulerStatus\x12P\n" +
+	"\rResumeUpdates\x12!.blocking.v1.ResumeUpdatesRequest\x1a\x1c.blocking.v1.SchedulerStatus\x12Z\n" +
+	"\x12GetSchedulerStatus\x12&.blocking.v1.GetSchedulerStatusRequest\x1a\x1c.blocking.v1.SchedulerStatus\x12V\n" +
+	"\rClearRegistry\x12!.blocking.v1.ClearRegistryRequest\x1a\".blocking.v1.ClearRegistryResponse\x12\\\n" +
+	"\x0fGetSourceHealth\x12#.blocking.v1.GetSourceHealthRequest\x1a$.blocking.v1.GetSourceHealthResponseBBZ@github.com/kerim-dauren/rkn-checker/internal/delivery/grpc/protob\x06proto3"
+
+var (
+	file_internal_delivery_grpc_proto_admin_proto_rawDescOnce sync.Once
+	file_internal_delivery_grpc_proto_admin_proto_rawDescData []byte
+)
+
+func file_internal_delivery_grpc_proto_admin_proto_rawDescGZIP() []byte {
+	file_internal_delivery_grpc_proto_admin_proto_rawDescOnce.Do(func() {
+		file_internal_delivery_grpc_proto_admin_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_internal_delivery_grpc_proto_admin_proto_rawDesc), len(file_internal_delivery_grpc_proto_admin_proto_rawDesc)))
+	})
+	return file_internal_delivery_grpc_proto_admin_proto_rawDescData
+}
+
+var file_internal_delivery_grpc_proto_admin_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_internal_delivery_grpc_proto_admin_proto_goTypes = []any{
+	(*TriggerUpdateRequest)(nil),      // 0: blocking.v1.TriggerUpdateRequest
+	(*TriggerUpdateResponse)(nil),     // 1: blocking.v1.TriggerUpdateResponse
+	(*PauseUpdatesRequest)(nil),       // 2: blocking.v1.PauseUpdatesRequest
+	(*ResumeUpdatesRequest)(nil),      // 3: blocking.v1.ResumeUpdatesRequest
+	(*GetSchedulerStatusRequest)(nil), // 4: blocking.v1.GetSchedulerStatusRequest
+	(*SchedulerStatus)(nil),           // 5: blocking.v1.SchedulerStatus
+	(*ClearRegistryRequest)(nil),      // 6: blocking.v1.ClearRegistryRequest
+	(*ClearRegistryResponse)(nil),     // 7: blocking.v1.ClearRegistryResponse
+	(*GetSourceHealthRequest)(nil),    // 8: blocking.v1.GetSourceHealthRequest
+	(*GetSourceHealthResponse)(nil),   // 9: blocking.v1.GetSourceHealthResponse
+	(*SourceHealth)(nil),              // 10: blocking.v1.SourceHealth
+}
+var file_internal_delivery_grpc_proto_admin_proto_depIdxs = []int32{
+	10, // 0: blocking.v1.GetSourceHealthResponse.sources:type_name -> blocking.v1.SourceHealth
+	0,  // 1: blocking.v1.AdminService.TriggerUpdate:input_type -> blocking.v1.TriggerUpdateRequest
+	2,  // 2: blocking.v1.AdminService.PauseUpdates:input_type -> blocking.v1.PauseUpdatesRequest
+	3,  // 3: blocking.v1.AdminService.ResumeUpdates:input_type -> blocking.v1.ResumeUpdatesRequest
+	4,  // 4: blocking.v1.AdminService.GetSchedulerStatus:input_type -> blocking.v1.GetSchedulerStatusRequest
+	6,  // 5: blocking.v1.AdminService.ClearRegistry:input_type -> blocking.v1.ClearRegistryRequest
+	8,  // 6: blocking.v1.AdminService.GetSourceHealth:input_type -> blocking.v1.GetSourceHealthRequest
+	1,  // 7: blocking.v1.AdminService.TriggerUpdate:output_type -> blocking.v1.TriggerUpdateResponse
+	5,  // 8: blocking.v1.AdminService.PauseUpdates:output_type -> blocking.v1.SchedulerStatus
+	5,  // 9: blocking.v1.AdminService.ResumeUpdates:output_type -> blocking.v1.SchedulerStatus
+	5,  // 10: blocking.v1.AdminService.GetSchedulerStatus:output_type -> blocking.v1.SchedulerStatus
+	7,  // 11: blocking.v1.AdminService.ClearRegistry:output_type -> blocking.v1.ClearRegistryResponse
+	9,  // 12: blocking.v1.AdminService.GetSourceHealth:output_type -> blocking.v1.GetSourceHealthResponse
+	7,  // [7:13] is the sub-list for method output_type
+	1,  // [1:7] is the sub-list for method input_type
+	1,  // [1:1] is the sub-list for extension type_name
+	1,  // [1:1] is the sub-list for extension extendee
+	0,  // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_internal_delivery_grpc_proto_admin_proto_init() }
+func file_internal_delivery_grpc_proto_admin_proto_init() {
+	if File_internal_delivery_grpc_proto_admin_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_delivery_grpc_proto_admin_proto_rawDesc), len(file_internal_delivery_grpc_proto_admin_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_internal_delivery_grpc_proto_admin_proto_goTypes,
+		DependencyIndexes: file_internal_delivery_grpc_proto_admin_proto_depIdxs,
+		MessageInfos:      file_internal_delivery_grpc_proto_admin_proto_msgTypes,
+	}.Build()
+	File_internal_delivery_grpc_proto_admin_proto = out.File
+	file_internal_delivery_grpc_proto_admin_proto_goTypes = nil
+	file_internal_delivery_grpc_proto_admin_proto_depIdxs = nil
+}
@@ -0,0 +1,317 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: internal/delivery/grpc/proto/admin.proto
+
+package proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	AdminService_TriggerUpdate_FullMethodName      = "/blocking.v1.AdminService/TriggerUpdate"
+	AdminService_PauseUpdates_FullMethodName       = "/blocking.v1.AdminService/PauseUpdates"
+	AdminService_ResumeUpdates_FullMethodName      = "/blocking.v1.AdminService/ResumeUpdates"
+	AdminService_GetSchedulerStatus_FullMethodName = "/blocking.v1.AdminService/GetSchedulerStatus"
+	AdminService_ClearRegistry_FullMethodName      = "/blocking.v1.AdminService/ClearRegistry"
+	AdminService_GetSourceHealth_FullMethodName    = "/blocking.v1.AdminService/GetSourceHealth"
+)
+
+// AdminServiceClient is the client API for AdminService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// AdminService controls registry updates. Calls need the admin scope, from
+// the admin token or any credential granting it.
+type AdminServiceClient interface {
+	TriggerUpdate(ctx context.Context, in *TriggerUpdateRequest, opts ...grpc.CallOption) (*TriggerUpdateResponse, error)
+	PauseUpdates(ctx context.Context, in *PauseUpdatesRequest, opts ...grpc.CallOption) (*SchedulerStatus, error)
+	ResumeUpdates(ctx context.Context, in *ResumeUpdatesRequest, opts ...grpc.CallOption) (*SchedulerStatus, error)
+	GetSchedulerStatus(ctx context.Context, in *GetSchedulerStatusRequest, opts ...grpc.CallOption) (*SchedulerStatus, error)
+	ClearRegistry(ctx context.Context, in *ClearRegistryRequest, opts ...grpc.CallOption) (*ClearRegistryResponse, error)
+	GetSourceHealth(ctx context.Context, in *GetSourceHealthRequest, opts ...grpc.CallOption) (*GetSourceHealthResponse, error)
+}
+
+type adminServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminServiceClient(cc grpc.ClientConnInterface) AdminServiceClient {
+	return &adminServiceClient{cc}
+}
+
+func (c *adminServiceClient) TriggerUpdate(ctx context.Context, in *TriggerUpdateRequest, opts ...grpc.CallOption) (*TriggerUpdateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TriggerUpdateResponse)
+	err := c.cc.Invoke(ctx, AdminService_TriggerUpdate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) PauseUpdates(ctx context.Context, in *PauseUpdatesRequest, opts ...grpc.CallOption) (*SchedulerStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SchedulerStatus)
+	err := c.cc.Invoke(ctx, AdminService_PauseUpdates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) ResumeUpdates(ctx context.Context, in *ResumeUpdatesRequest, opts ...grpc.CallOption) (*SchedulerStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SchedulerStatus)
+	err := c.cc.Invoke(ctx, AdminService_ResumeUpdates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) GetSchedulerStatus(ctx context.Context, in *GetSchedulerStatusRequest, opts ...grpc.CallOption) (*SchedulerStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SchedulerStatus)
+	err := c.cc.Invoke(ctx, AdminService_GetSchedulerStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) ClearRegistry(ctx context.Context, in *ClearRegistryRequest, opts ...grpc.CallOption) (*ClearRegistryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ClearRegistryResponse)
+	err := c.cc.Invoke(ctx, AdminService_ClearRegistry_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) GetSourceHealth(ctx context.Context, in *GetSourceHealthRequest, opts ...grpc.CallOption) (*GetSourceHealthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSourceHealthResponse)
+	err := c.cc.Invoke(ctx, AdminService_GetSourceHealth_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServiceServer is the server API for AdminService service.
+// All implementations must embed UnimplementedAdminServiceServer
+// for forward compatibility.
+//
+// AdminService controls registry updates. Calls need the admin scope, from
+// the admin token or any credential granting it.
+type AdminServiceServer interface {
+	TriggerUpdate(context.Context, *TriggerUpdateRequest) (*TriggerUpdateResponse, error)
+	PauseUpdates(context.Context, *PauseUpdatesRequest) (*SchedulerStatus, error)
+	ResumeUpdates(context.Context, *ResumeUpdatesRequest) (*SchedulerStatus, error)
+	GetSchedulerStatus(context.Context, *GetSchedulerStatusRequest) (*SchedulerStatus, error)
+	ClearRegistry(context.Context, *ClearRegistryRequest) (*ClearRegistryResponse, error)
+	GetSourceHealth(context.Context, *GetSourceHealthRequest) (*GetSourceHealthResponse, error)
+	mustEmbedUnimplementedAdminServiceServer()
+}
+
+// UnimplementedAdminServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAdminServiceServer struct{}
+
+func (UnimplementedAdminServiceServer) TriggerUpdate(context.Context, *TriggerUpdateRequest) (*TriggerUpdateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TriggerUpdate not implemented")
+}
+func (UnimplementedAdminServiceServer) PauseUpdates(context.Context, *PauseUpdatesRequest) (*SchedulerStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseUpdates not implemented")
+}
+func (UnimplementedAdminServiceServer) ResumeUpdates(context.Context, *ResumeUpdatesRequest) (*SchedulerStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeUpdates not implemented")
+}
+func (UnimplementedAdminServiceServer) GetSchedulerStatus(context.Context, *GetSchedulerStatusRequest) (*SchedulerStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSchedulerStatus not implemented")
+}
+func (UnimplementedAdminServiceServer) ClearRegistry(context.Context, *ClearRegistryRequest) (*ClearRegistryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClearRegistry not implemented")
+}
+func (UnimplementedAdminServiceServer) GetSourceHealth(context.Context, *GetSourceHealthRequest) (*GetSourceHealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSourceHealth not implemented")
+}
+func (UnimplementedAdminServiceServer) mustEmbedUnimplementedAdminServiceServer() {}
+func (UnimplementedAdminServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeAdminServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdminServiceServer will
+// result in compilation errors.
+type UnsafeAdminServiceServer interface {
+	mustEmbedUnimplementedAdminServiceServer()
+}
+
+func RegisterAdminServiceServer(s grpc.ServiceRegistrar, srv AdminServiceServer) {
+	// If the following call pancis, it indicates UnimplementedAdminServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AdminService_ServiceDesc, srv)
+}
+
+func _AdminService_TriggerUpdate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TriggerUpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).TriggerUpdate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_TriggerUpdate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).TriggerUpdate(ctx, req.(*TriggerUpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_PauseUpdates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseUpdatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).PauseUpdates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_PauseUpdates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).PauseUpdates(ctx, req.(*PauseUpdatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_ResumeUpdates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeUpdatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ResumeUpdates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_ResumeUpdates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ResumeUpdates(ctx, req.(*ResumeUpdatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_GetSchedulerStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSchedulerStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).GetSchedulerStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_GetSchedulerStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).GetSchedulerStatus(ctx, req.(*GetSchedulerStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_ClearRegistry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClearRegistryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ClearRegistry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_ClearRegistry_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ClearRegistry(ctx, req.(*ClearRegistryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_GetSourceHealth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSourceHealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).GetSourceHealth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_GetSourceHealth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).GetSourceHealth(ctx, req.(*GetSourceHealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AdminService_ServiceDesc is the grpc.ServiceDesc for AdminService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AdminService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "blocking.v1.AdminService",
+	HandlerType: (*AdminServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "TriggerUpdate",
+			Handler:    _AdminService_TriggerUpdate_Handler,
+		},
+		{
+			MethodName: "PauseUpdates",
+			Handler:    _AdminService_PauseUpdates_Handler,
+		},
+		{
+			MethodName: "ResumeUpdates",
+			Handler:    _AdminService_ResumeUpdates_Handler,
+		},
+		{
+			MethodName: "GetSchedulerStatus",
+			Handler:    _AdminService_GetSchedulerStatus_Handler,
+		},
+		{
+			MethodName: "ClearRegistry",
+			Handler:    _AdminService_ClearRegistry_Handler,
+		},
+		{
+			MethodName: "GetSourceHealth",
+			Handler:    _AdminService_GetSourceHealth_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "internal/delivery/grpc/proto/admin.proto",
+}
@@ -21,6 +21,52 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type HealthCheckRequest_Probe int32
+
+const (
+	HealthCheckRequest_READINESS HealthCheckRequest_Probe = 0
+	HealthCheckRequest_LIVENESS  HealthCheckRequest_Probe = 1
+)
+
+// Enum value maps for HealthCheckRequest_Probe.
+var (
+	HealthCheckRequest_Probe_name = map[int32]string{
+		0: "READINESS",
+		1: "LIVENESS",
+	}
+	HealthCheckRequest_Probe_value = map[string]int32{
+		"READINESS": 0,
+		"LIVENESS":  1,
+	}
+)
+
+func (x HealthCheckRequest_Probe) Enum() *HealthCheckRequest_Probe {
+	p := new(HealthCheckRequest_Probe)
+	*p = x
+	return p
+}
+
+func (x HealthCheckRequest_Probe) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (HealthCheckRequest_Probe) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_delivery_grpc_proto_blocking_proto_enumTypes[0].Descriptor()
+}
+
+func (HealthCheckRequest_Probe) Type() protoreflect.EnumType {
+	return &file_internal_delivery_grpc_proto_blocking_proto_enumTypes[0]
+}
+
+func (x HealthCheckRequest_Probe) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use HealthCheckRequest_Probe.Descriptor instead.
+func (HealthCheckRequest_Probe) EnumDescriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{4, 0}
+}
+
 type HealthCheckResponse_Status int32
 
 const (
@@ -57,11 +103,11 @@ func (x HealthCheckResponse_Status) String() string {
 }
 
 func (HealthCheckResponse_Status) Descriptor() protoreflect.EnumDescriptor {
-	return file_internal_delivery_grpc_proto_blocking_proto_enumTypes[0].Descriptor()
+	return file_internal_delivery_grpc_proto_blocking_proto_enumTypes[1].Descriptor()
 }
 
 func (HealthCheckResponse_Status) Type() protoreflect.EnumType {
-	return &file_internal_delivery_grpc_proto_blocking_proto_enumTypes[0]
+	return &file_internal_delivery_grpc_proto_blocking_proto_enumTypes[1]
 }
 
 func (x HealthCheckResponse_Status) Number() protoreflect.EnumNumber {
@@ -73,9 +119,64 @@ func (HealthCheckResponse_Status) EnumDescriptor() ([]byte, []int) {
 	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{5, 0}
 }
 
+type EntryChange_Kind int32
+
+const (
+	EntryChange_UNKNOWN EntryChange_Kind = 0
+	EntryChange_ADDED   EntryChange_Kind = 1
+	EntryChange_REMOVED EntryChange_Kind = 2
+	EntryChange_CHANGED EntryChange_Kind = 3
+)
+
+// Enum value maps for EntryChange_Kind.
+var (
+	EntryChange_Kind_name = map[int32]string{
+		0: "UNKNOWN",
+		1: "ADDED",
+		2: "REMOVED",
+		3: "CHANGED",
+	}
+	EntryChange_Kind_value = map[string]int32{
+		"UNKNOWN": 0,
+		"ADDED":   1,
+		"REMOVED": 2,
+		"CHANGED": 3,
+	}
+)
+
+func (x EntryChange_Kind) Enum() *EntryChange_Kind {
+	p := new(EntryChange_Kind)
+	*p = x
+	return p
+}
+
+func (x EntryChange_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EntryChange_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_delivery_grpc_proto_blocking_proto_enumTypes[2].Descriptor()
+}
+
+func (EntryChange_Kind) Type() protoreflect.EnumType {
+	return &file_internal_delivery_grpc_proto_blocking_proto_enumTypes[2]
+}
+
+func (x EntryChange_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EntryChange_Kind.Descriptor instead.
+func (EntryChange_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{10, 0}
+}
+
 type CheckURLRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Url   string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	// at (RFC3339) or version answer the check from a retained snapshot
+	At            string `protobuf:"bytes,2,opt,name=at,proto3" json:"at,omitempty"`
+	Version       string `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -117,14 +218,29 @@ func (x *CheckURLRequest) GetUrl() string {
 	return ""
 }
 
+func (x *CheckURLRequest) GetAt() string {
+	if x != nil {
+		return x.At
+	}
+	return ""
+}
+
+func (x *CheckURLRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
 type CheckURLResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Blocked       bool                   `protobuf:"varint,1,opt,name=blocked,proto3" json:"blocked,omitempty"`
-	NormalizedUrl string                 `protobuf:"bytes,2,opt,name=normalized_url,json=normalizedUrl,proto3" json:"normalized_url,omitempty"`
-	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
-	Match         string                 `protobuf:"bytes,4,opt,name=match,proto3" json:"match,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Blocked         bool                   `protobuf:"varint,1,opt,name=blocked,proto3" json:"blocked,omitempty"`
+	NormalizedUrl   string                 `protobuf:"bytes,2,opt,name=normalized_url,json=normalizedUrl,proto3" json:"normalized_url,omitempty"`
+	Reason          string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	Match           string                 `protobuf:"bytes,4,opt,name=match,proto3" json:"match,omitempty"`
+	RegistryVersion string                 `protobuf:"bytes,5,opt,name=registry_version,json=registryVersion,proto3" json:"registry_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *CheckURLResponse) Reset() {
@@ -185,6 +301,13 @@ func (x *CheckURLResponse) GetMatch() string {
 	return ""
 }
 
+func (x *CheckURLResponse) GetRegistryVersion() string {
+	if x != nil {
+		return x.RegistryVersion
+	}
+	return ""
+}
+
 type GetStatsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -314,7 +437,8 @@ func (x *GetStatsResponse) GetVersion() string {
 }
 
 type HealthCheckRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Probe         HealthCheckRequest_Probe `protobuf:"varint,1,opt,name=probe,proto3,enum=blocking.v1.HealthCheckRequest_Probe" json:"probe,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -349,10 +473,19 @@ func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
 	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{4}
 }
 
+func (x *HealthCheckRequest) GetProbe() HealthCheckRequest_Probe {
+	if x != nil {
+		return x.Probe
+	}
+	return HealthCheckRequest_READINESS
+}
+
 type HealthCheckResponse struct {
-	state         protoimpl.MessageState     `protogen:"open.v1"`
-	Status        HealthCheckResponse_Status `protobuf:"varint,1,opt,name=status,proto3,enum=blocking.v1.HealthCheckResponse_Status" json:"status,omitempty"`
-	Message       string                     `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	state   protoimpl.MessageState     `protogen:"open.v1"`
+	Status  HealthCheckResponse_Status `protobuf:"varint,1,opt,name=status,proto3,enum=blocking.v1.HealthCheckResponse_Status" json:"status,omitempty"`
+	Message string                     `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// checks lists every readiness check; message names the failing ones
+	Checks        []*HealthCheckDetail `protobuf:"bytes,3,rep,name=checks,proto3" json:"checks,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -401,93 +534,1560 @@ func (x *HealthCheckResponse) GetMessage() string {
 	return ""
 }
 
-var File_internal_delivery_grpc_proto_blocking_proto protoreflect.FileDescriptor
+func (x *HealthCheckResponse) GetChecks() []*HealthCheckDetail {
+	if x != nil {
+		return x.Checks
+	}
+	return nil
+}
 
-const file_internal_delivery_grpc_proto_blocking_proto_rawDesc = "" +
-	"\n" +
-	"+internal/delivery/grpc/proto/blocking.proto\x12\vblocking.v1\"#\n" +
-	"\x0fCheckURLRequest\x12\x10\n" +
-	"\x03url\x18\x01 \x01(\tR\x03url\"\x81\x01\n" +
-	"\x10CheckURLResponse\x12\x18\n" +
-	"\ablocked\x18\x01 \x01(\bR\ablocked\x12%\n" +
-	"\x0enormalized_url\x18\x02 \x01(\tR\rnormalizedUrl\x12\x16\n" +
-	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x14\n" +
-	"\x05match\x18\x04 \x01(\tR\x05match\"\x11\n" +
-	"\x0fGetStatsRequest\"\x86\x02\n" +
-	"\x10GetStatsResponse\x12#\n" +
-	"\rtotal_entries\x18\x01 \x01(\x03R\ftotalEntries\x12%\n" +
-	"\x0edomain_entries\x18\x02 \x01(\x03R\rdomainEntries\x12)\n" +
-	"\x10wildcard_entries\x18\x03 \x01(\x03R\x0fwildcardEntries\x12\x1d\n" +
-	"\n" +
-	"ip_entries\x18\x04 \x01(\x03R\tipEntries\x12!\n" +
-	"\furl_patterns\x18\x05 \x01(\x03R\vurlPatterns\x12\x1f\n" +
-	"\vlast_update\x18\x06 \x01(\tR\n" +
-	"lastUpdate\x12\x18\n" +
-	"\aversion\x18\a \x01(\tR\aversion\"\x14\n" +
-	"\x12HealthCheckRequest\"\xba\x01\n" +
-	"\x13HealthCheckResponse\x12?\n" +
-	"\x06status\x18\x01 \x01(\x0e2'.blocking.v1.HealthCheckResponse.StatusR\x06status\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"H\n" +
-	"\x06Status\x12\v\n" +
-	"\aUNKNOWN\x10\x00\x12\v\n" +
-	"\aSERVING\x10\x01\x12\x0f\n" +
-	"\vNOT_SERVING\x10\x02\x12\x13\n" +
-	"\x0fSERVICE_UNKNOWN\x10\x032\xf5\x01\n" +
-	"\x0fBlockingService\x12G\n" +
-	"\bCheckURL\x12\x1c.blocking.v1.CheckURLRequest\x1a\x1d.blocking.v1.CheckURLResponse\x12G\n" +
-	"\bGetStats\x12\x1c.blocking.v1.GetStatsRequest\x1a\x1d.blocking.v1.GetStatsResponse\x12P\n" +
-	"\vHealthCheck\x12\x1f.blocking.v1.HealthCheckRequest\x1a .blocking.v1.HealthCheckResponseBBZ@github.com/kerim-dauren/rkn-checker/internal/delivery/grpc/protob\x06proto3"
+type HealthCheckDetail struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Healthy       bool                   `protobuf:"varint,2,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-var (
-	file_internal_delivery_grpc_proto_blocking_proto_rawDescOnce sync.Once
-	file_internal_delivery_grpc_proto_blocking_proto_rawDescData []byte
-)
+func (x *HealthCheckDetail) Reset() {
+	*x = HealthCheckDetail{}
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
 
-func file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP() []byte {
-	file_internal_delivery_grpc_proto_blocking_proto_rawDescOnce.Do(func() {
-		file_internal_delivery_grpc_proto_blocking_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_internal_delivery_grpc_proto_blocking_proto_rawDesc), len(file_internal_delivery_grpc_proto_blocking_proto_rawDesc)))
-	})
-	return file_internal_delivery_grpc_proto_blocking_proto_rawDescData
+func (x *HealthCheckDetail) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-var file_internal_delivery_grpc_proto_blocking_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_internal_delivery_grpc_proto_blocking_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
-var file_internal_delivery_grpc_proto_blocking_proto_goTypes = []any{
-	(HealthCheckResponse_Status)(0), // 0: blocking.v1.HealthCheckResponse.Status
-	(*CheckURLRequest)(nil),         // 1: blocking.v1.CheckURLRequest
-	(*CheckURLResponse)(nil),        // 2: blocking.v1.CheckURLResponse
-	(*GetStatsRequest)(nil),         // 3: blocking.v1.GetStatsRequest
-	(*GetStatsResponse)(nil),        // 4: blocking.v1.GetStatsResponse
-	(*HealthCheckRequest)(nil),      // 5: blocking.v1.HealthCheckRequest
-	(*HealthCheckResponse)(nil),     // 6: blocking.v1.HealthCheckResponse
+func (*HealthCheckDetail) ProtoMessage() {}
+
+func (x *HealthCheckDetail) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
-var file_internal_delivery_grpc_proto_blocking_proto_depIdxs = []int32{
-	0, // 0: blocking.v1.HealthCheckResponse.status:type_name -> blocking.v1.HealthCheckResponse.Status
-	1, // 1: blocking.v1.BlockingService.CheckURL:input_type -> blocking.v1.CheckURLRequest
-	3, // 2: blocking.v1.BlockingService.GetStats:input_type -> blocking.v1.GetStatsRequest
-	5, // 3: blocking.v1.BlockingService.HealthCheck:input_type -> blocking.v1.HealthCheckRequest
-	2, // 4: blocking.v1.BlockingService.CheckURL:output_type -> blocking.v1.CheckURLResponse
-	4, // 5: blocking.v1.BlockingService.GetStats:output_type -> blocking.v1.GetStatsResponse
-	6, // 6: blocking.v1.BlockingService.HealthCheck:output_type -> blocking.v1.HealthCheckResponse
-	4, // [4:7] is the sub-list for method output_type
-	1, // [1:4] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+
+// Deprecated: Use HealthCheckDetail.ProtoReflect.Descriptor instead.
+func (*HealthCheckDetail) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{6}
 }
 
-func init() { file_internal_delivery_grpc_proto_blocking_proto_init() }
-func file_internal_delivery_grpc_proto_blocking_proto_init() {
-	if File_internal_delivery_grpc_proto_blocking_proto != nil {
-		return
+func (x *HealthCheckDetail) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *HealthCheckDetail) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *HealthCheckDetail) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ListChangesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Since         string                 `protobuf:"bytes,1,opt,name=since,proto3" json:"since,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListChangesRequest) Reset() {
+	*x = ListChangesRequest{}
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListChangesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListChangesRequest) ProtoMessage() {}
+
+func (x *ListChangesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListChangesRequest.ProtoReflect.Descriptor instead.
+func (*ListChangesRequest) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListChangesRequest) GetSince() string {
+	if x != nil {
+		return x.Since
+	}
+	return ""
+}
+
+type ListChangesResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	CurrentVersion string                 `protobuf:"bytes,1,opt,name=current_version,json=currentVersion,proto3" json:"current_version,omitempty"`
+	Changelogs     []*Changelog           `protobuf:"bytes,2,rep,name=changelogs,proto3" json:"changelogs,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListChangesResponse) Reset() {
+	*x = ListChangesResponse{}
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListChangesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListChangesResponse) ProtoMessage() {}
+
+func (x *ListChangesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListChangesResponse.ProtoReflect.Descriptor instead.
+func (*ListChangesResponse) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListChangesResponse) GetCurrentVersion() string {
+	if x != nil {
+		return x.CurrentVersion
+	}
+	return ""
+}
+
+func (x *ListChangesResponse) GetChangelogs() []*Changelog {
+	if x != nil {
+		return x.Changelogs
+	}
+	return nil
+}
+
+type Changelog struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FromVersion   string                 `protobuf:"bytes,1,opt,name=from_version,json=fromVersion,proto3" json:"from_version,omitempty"`
+	ToVersion     string                 `protobuf:"bytes,2,opt,name=to_version,json=toVersion,proto3" json:"to_version,omitempty"`
+	AppliedAt     string                 `protobuf:"bytes,3,opt,name=applied_at,json=appliedAt,proto3" json:"applied_at,omitempty"`
+	Added         int32                  `protobuf:"varint,4,opt,name=added,proto3" json:"added,omitempty"`
+	Removed       int32                  `protobuf:"varint,5,opt,name=removed,proto3" json:"removed,omitempty"`
+	Changed       int32                  `protobuf:"varint,6,opt,name=changed,proto3" json:"changed,omitempty"`
+	Changes       []*EntryChange         `protobuf:"bytes,7,rep,name=changes,proto3" json:"changes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Changelog) Reset() {
+	*x = Changelog{}
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Changelog) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Changelog) ProtoMessage() {}
+
+func (x *Changelog) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Changelog.ProtoReflect.Descriptor instead.
+func (*Changelog) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Changelog) GetFromVersion() string {
+	if x != nil {
+		return x.FromVersion
+	}
+	return ""
+}
+
+func (x *Changelog) GetToVersion() string {
+	if x != nil {
+		return x.ToVersion
+	}
+	return ""
+}
+
+func (x *Changelog) GetAppliedAt() string {
+	if x != nil {
+		return x.AppliedAt
+	}
+	return ""
+}
+
+func (x *Changelog) GetAdded() int32 {
+	if x != nil {
+		return x.Added
+	}
+	return 0
+}
+
+func (x *Changelog) GetRemoved() int32 {
+	if x != nil {
+		return x.Removed
+	}
+	return 0
+}
+
+func (x *Changelog) GetChanged() int32 {
+	if x != nil {
+		return x.Changed
+	}
+	return 0
+}
+
+func (x *Changelog) GetChanges() []*EntryChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+type EntryChange struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Kind                EntryChange_Kind       `protobuf:"varint,1,opt,name=kind,proto3,enum=blocking.v1.EntryChange_Kind" json:"kind,omitempty"`
+	Value               string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Type                string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Decision            string                 `protobuf:"bytes,4,opt,name=decision,proto3" json:"decision,omitempty"`
+	DecisionOrg         string                 `protobuf:"bytes,5,opt,name=decision_org,json=decisionOrg,proto3" json:"decision_org,omitempty"`
+	PreviousType        string                 `protobuf:"bytes,6,opt,name=previous_type,json=previousType,proto3" json:"previous_type,omitempty"`
+	PreviousDecision    string                 `protobuf:"bytes,7,opt,name=previous_decision,json=previousDecision,proto3" json:"previous_decision,omitempty"`
+	PreviousDecisionOrg string                 `protobuf:"bytes,8,opt,name=previous_decision_org,json=previousDecisionOrg,proto3" json:"previous_decision_org,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *EntryChange) Reset() {
+	*x = EntryChange{}
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EntryChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntryChange) ProtoMessage() {}
+
+func (x *EntryChange) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntryChange.ProtoReflect.Descriptor instead.
+func (*EntryChange) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *EntryChange) GetKind() EntryChange_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return EntryChange_UNKNOWN
+}
+
+func (x *EntryChange) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *EntryChange) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *EntryChange) GetDecision() string {
+	if x != nil {
+		return x.Decision
+	}
+	return ""
+}
+
+func (x *EntryChange) GetDecisionOrg() string {
+	if x != nil {
+		return x.DecisionOrg
+	}
+	return ""
+}
+
+func (x *EntryChange) GetPreviousType() string {
+	if x != nil {
+		return x.PreviousType
+	}
+	return ""
+}
+
+func (x *EntryChange) GetPreviousDecision() string {
+	if x != nil {
+		return x.PreviousDecision
+	}
+	return ""
+}
+
+func (x *EntryChange) GetPreviousDecisionOrg() string {
+	if x != nil {
+		return x.PreviousDecisionOrg
+	}
+	return ""
+}
+
+type GetHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Domain        string                 `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHistoryRequest) Reset() {
+	*x = GetHistoryRequest{}
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoryRequest) ProtoMessage() {}
+
+func (x *GetHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetHistoryRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+type GetHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Blocked       bool                   `protobuf:"varint,1,opt,name=blocked,proto3" json:"blocked,omitempty"`
+	Patterns      []*PatternHistory      `protobuf:"bytes,2,rep,name=patterns,proto3" json:"patterns,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHistoryResponse) Reset() {
+	*x = GetHistoryResponse{}
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoryResponse) ProtoMessage() {}
+
+func (x *GetHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetHistoryResponse) GetBlocked() bool {
+	if x != nil {
+		return x.Blocked
+	}
+	return false
+}
+
+func (x *GetHistoryResponse) GetPatterns() []*PatternHistory {
+	if x != nil {
+		return x.Patterns
+	}
+	return nil
+}
+
+type PatternHistory struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pattern       string                 `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	Blocked       bool                   `protobuf:"varint,2,opt,name=blocked,proto3" json:"blocked,omitempty"`
+	Events        []*HistoryEvent        `protobuf:"bytes,3,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PatternHistory) Reset() {
+	*x = PatternHistory{}
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PatternHistory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PatternHistory) ProtoMessage() {}
+
+func (x *PatternHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PatternHistory.ProtoReflect.Descriptor instead.
+func (*PatternHistory) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *PatternHistory) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *PatternHistory) GetBlocked() bool {
+	if x != nil {
+		return x.Blocked
+	}
+	return false
+}
+
+func (x *PatternHistory) GetEvents() []*HistoryEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type HistoryEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          EntryChange_Kind       `protobuf:"varint,1,opt,name=kind,proto3,enum=blocking.v1.EntryChange_Kind" json:"kind,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Version       string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	At            string                 `protobuf:"bytes,4,opt,name=at,proto3" json:"at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistoryEvent) Reset() {
+	*x = HistoryEvent{}
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoryEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryEvent) ProtoMessage() {}
+
+func (x *HistoryEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryEvent.ProtoReflect.Descriptor instead.
+func (*HistoryEvent) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *HistoryEvent) GetKind() EntryChange_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return EntryChange_UNKNOWN
+}
+
+func (x *HistoryEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *HistoryEvent) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *HistoryEvent) GetAt() string {
+	if x != nil {
+		return x.At
+	}
+	return ""
+}
+
+type GetEntryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetEntryRequest) Reset() {
+	*x = GetEntryRequest{}
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetEntryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEntryRequest) ProtoMessage() {}
+
+func (x *GetEntryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEntryRequest.ProtoReflect.Descriptor instead.
+func (*GetEntryRequest) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetEntryRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListEntriesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Q             string                 `protobuf:"bytes,2,opt,name=q,proto3" json:"q,omitempty"`
+	Cursor        string                 `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEntriesRequest) Reset() {
+	*x = ListEntriesRequest{}
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEntriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEntriesRequest) ProtoMessage() {}
+
+func (x *ListEntriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEntriesRequest.ProtoReflect.Descriptor instead.
+func (*ListEntriesRequest) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListEntriesRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ListEntriesRequest) GetQ() string {
+	if x != nil {
+		return x.Q
+	}
+	return ""
+}
+
+func (x *ListEntriesRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListEntriesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListEntriesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Entries       []*RegistryEntry       `protobuf:"bytes,2,rep,name=entries,proto3" json:"entries,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,3,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEntriesResponse) Reset() {
+	*x = ListEntriesResponse{}
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEntriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEntriesResponse) ProtoMessage() {}
+
+func (x *ListEntriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEntriesResponse.ProtoReflect.Descriptor instead.
+func (*ListEntriesResponse) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListEntriesResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ListEntriesResponse) GetEntries() []*RegistryEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *ListEntriesResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type RegistryEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Pattern       string                 `protobuf:"bytes,3,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	Paths         []string               `protobuf:"bytes,4,rep,name=paths,proto3" json:"paths,omitempty"`
+	Decision      string                 `protobuf:"bytes,5,opt,name=decision,proto3" json:"decision,omitempty"`
+	DecisionOrg   string                 `protobuf:"bytes,6,opt,name=decision_org,json=decisionOrg,proto3" json:"decision_org,omitempty"`
+	AddedDate     string                 `protobuf:"bytes,7,opt,name=added_date,json=addedDate,proto3" json:"added_date,omitempty"`
+	BlockedDate   string                 `protobuf:"bytes,8,opt,name=blocked_date,json=blockedDate,proto3" json:"blocked_date,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegistryEntry) Reset() {
+	*x = RegistryEntry{}
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegistryEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegistryEntry) ProtoMessage() {}
+
+func (x *RegistryEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegistryEntry.ProtoReflect.Descriptor instead.
+func (*RegistryEntry) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *RegistryEntry) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RegistryEntry) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *RegistryEntry) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *RegistryEntry) GetPaths() []string {
+	if x != nil {
+		return x.Paths
+	}
+	return nil
+}
+
+func (x *RegistryEntry) GetDecision() string {
+	if x != nil {
+		return x.Decision
+	}
+	return ""
+}
+
+func (x *RegistryEntry) GetDecisionOrg() string {
+	if x != nil {
+		return x.DecisionOrg
+	}
+	return ""
+}
+
+func (x *RegistryEntry) GetAddedDate() string {
+	if x != nil {
+		return x.AddedDate
+	}
+	return ""
+}
+
+func (x *RegistryEntry) GetBlockedDate() string {
+	if x != nil {
+		return x.BlockedDate
+	}
+	return ""
+}
+
+type SearchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// suffix (default), substring or regex
+	Mode          string `protobuf:"bytes,1,opt,name=mode,proto3" json:"mode,omitempty"`
+	Q             string `protobuf:"bytes,2,opt,name=q,proto3" json:"q,omitempty"`
+	Limit         int32  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SearchRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetQ() string {
+	if x != nil {
+		return x.Q
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type SearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Entries       []*RegistryEntry       `protobuf:"bytes,2,rep,name=entries,proto3" json:"entries,omitempty"`
+	Truncated     bool                   `protobuf:"varint,3,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	TimedOut      bool                   `protobuf:"varint,4,opt,name=timed_out,json=timedOut,proto3" json:"timed_out,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SearchResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *SearchResponse) GetEntries() []*RegistryEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+func (x *SearchResponse) GetTimedOut() bool {
+	if x != nil {
+		return x.TimedOut
+	}
+	return false
+}
+
+type WatchRegistryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// since_version resumes after the event of that registry version
+	SinceVersion  string `protobuf:"bytes,1,opt,name=since_version,json=sinceVersion,proto3" json:"since_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRegistryRequest) Reset() {
+	*x = WatchRegistryRequest{}
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRegistryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRegistryRequest) ProtoMessage() {}
+
+func (x *WatchRegistryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRegistryRequest.ProtoReflect.Descriptor instead.
+func (*WatchRegistryRequest) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *WatchRegistryRequest) GetSinceVersion() string {
+	if x != nil {
+		return x.SinceVersion
+	}
+	return ""
+}
+
+type WatchRegistryResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Frame:
+	//
+	//	*WatchRegistryResponse_Event
+	//	*WatchRegistryResponse_Heartbeat
+	Frame         isWatchRegistryResponse_Frame `protobuf_oneof:"frame"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRegistryResponse) Reset() {
+	*x = WatchRegistryResponse{}
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRegistryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRegistryResponse) ProtoMessage() {}
+
+func (x *WatchRegistryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRegistryResponse.ProtoReflect.Descriptor instead.
+func (*WatchRegistryResponse) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *WatchRegistryResponse) GetFrame() isWatchRegistryResponse_Frame {
+	if x != nil {
+		return x.Frame
+	}
+	return nil
+}
+
+func (x *WatchRegistryResponse) GetEvent() *RegistryEvent {
+	if x != nil {
+		if x, ok := x.Frame.(*WatchRegistryResponse_Event); ok {
+			return x.Event
+		}
+	}
+	return nil
+}
+
+func (x *WatchRegistryResponse) GetHeartbeat() *Heartbeat {
+	if x != nil {
+		if x, ok := x.Frame.(*WatchRegistryResponse_Heartbeat); ok {
+			return x.Heartbeat
+		}
+	}
+	return nil
+}
+
+type isWatchRegistryResponse_Frame interface {
+	isWatchRegistryResponse_Frame()
+}
+
+type WatchRegistryResponse_Event struct {
+	Event *RegistryEvent `protobuf:"bytes,1,opt,name=event,proto3,oneof"`
+}
+
+type WatchRegistryResponse_Heartbeat struct {
+	Heartbeat *Heartbeat `protobuf:"bytes,2,opt,name=heartbeat,proto3,oneof"`
+}
+
+func (*WatchRegistryResponse_Event) isWatchRegistryResponse_Frame() {}
+
+func (*WatchRegistryResponse_Heartbeat) isWatchRegistryResponse_Frame() {}
+
+type RegistryEvent struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Version   string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Size      int32                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Counts    map[string]int32       `protobuf:"bytes,3,rep,name=counts,proto3" json:"counts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	AppliedAt string                 `protobuf:"bytes,4,opt,name=applied_at,json=appliedAt,proto3" json:"applied_at,omitempty"`
+	// diff is unset for the first registry applied after startup
+	Diff          *DiffSummary `protobuf:"bytes,5,opt,name=diff,proto3" json:"diff,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegistryEvent) Reset() {
+	*x = RegistryEvent{}
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegistryEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegistryEvent) ProtoMessage() {}
+
+func (x *RegistryEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegistryEvent.ProtoReflect.Descriptor instead.
+func (*RegistryEvent) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *RegistryEvent) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *RegistryEvent) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *RegistryEvent) GetCounts() map[string]int32 {
+	if x != nil {
+		return x.Counts
+	}
+	return nil
+}
+
+func (x *RegistryEvent) GetAppliedAt() string {
+	if x != nil {
+		return x.AppliedAt
+	}
+	return ""
+}
+
+func (x *RegistryEvent) GetDiff() *DiffSummary {
+	if x != nil {
+		return x.Diff
+	}
+	return nil
+}
+
+type DiffSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FromVersion   string                 `protobuf:"bytes,1,opt,name=from_version,json=fromVersion,proto3" json:"from_version,omitempty"`
+	Added         int32                  `protobuf:"varint,2,opt,name=added,proto3" json:"added,omitempty"`
+	Removed       int32                  `protobuf:"varint,3,opt,name=removed,proto3" json:"removed,omitempty"`
+	Changed       int32                  `protobuf:"varint,4,opt,name=changed,proto3" json:"changed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffSummary) Reset() {
+	*x = DiffSummary{}
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffSummary) ProtoMessage() {}
+
+func (x *DiffSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffSummary.ProtoReflect.Descriptor instead.
+func (*DiffSummary) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *DiffSummary) GetFromVersion() string {
+	if x != nil {
+		return x.FromVersion
+	}
+	return ""
+}
+
+func (x *DiffSummary) GetAdded() int32 {
+	if x != nil {
+		return x.Added
+	}
+	return 0
+}
+
+func (x *DiffSummary) GetRemoved() int32 {
+	if x != nil {
+		return x.Removed
+	}
+	return 0
+}
+
+func (x *DiffSummary) GetChanged() int32 {
+	if x != nil {
+		return x.Changed
+	}
+	return 0
+}
+
+type Heartbeat struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	At            string                 `protobuf:"bytes,1,opt,name=at,proto3" json:"at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Heartbeat) Reset() {
+	*x = Heartbeat{}
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Heartbeat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Heartbeat) ProtoMessage() {}
+
+func (x *Heartbeat) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_delivery_grpc_proto_blocking_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Heartbeat.ProtoReflect.Descriptor instead.
+func (*Heartbeat) Descriptor() ([]byte, []int) {
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *Heartbeat) GetAt() string {
+	if x != nil {
+		return x.At
+	}
+	return ""
+}
+
+var File_internal_delivery_grpc_proto_blocking_proto protoreflect.FileDescriptor
+
+const file_internal_delivery_grpc_proto_blocking_proto_rawDesc = "" +
+	"\n" +
+	"+internal/delivery/grpc/proto/blocking.proto\x12\vblocking.v1\"M\n" +
+	"\x0fCheckURLRequest\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x0e\n" +
+	"\x02at\x18\x02 \x01(\tR\x02at\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\"\xac\x01\n" +
+	"\x10CheckURLResponse\x12\x18\n" +
+	"\ablocked\x18\x01 \x01(\bR\ablocked\x12%\n" +
+	"\x0enormalized_url\x18\x02 \x01(\tR\rnormalizedUrl\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x14\n" +
+	"\x05match\x18\x04 \x01(\tR\x05match\x12)\n" +
+	"\x10registry_version\x18\x05 \x01(\tR\x0fregistryVersion\"\x11\n" +
+	"\x0fGetStatsRequest\"\x86\x02\n" +
+	"\x10GetStatsResponse\x12#\n" +
+	"\rtotal_entries\x18\x01 \x01(\x03R\ftotalEntries\x12%\n" +
+	"\x0edomain_entries\x18\x02 \x01(\x03R\rdomainEntries\x12)\n" +
+	"\x10wildcard_entries\x18\x03 \x01(\x03R\x0fwildcardEntries\x12\x1d\n" +
+	"\n" +
+	"ip_entries\x18\x04 \x01(\x03R\tipEntries\x12!\n" +
+	"\furl_patterns\x18\x05 \x01(\x03R\vurlPatterns\x12\x1f\n" +
+	"\vlast_update\x18\x06 \x01(\tR\n" +
+	"lastUpdate\x12\x18\n" +
+	"\aversion\x18\a \x01(\tR\aversion\"w\n" +
+	"\x12HealthCheckRequest\x12;\n" +
+	"\x05probe\x18\x01 \x01(\x0e2%.blocking.v1.HealthCheckRequest.ProbeR\x05probe\"$\n" +
+	"\x05Probe\x12\r\n" +
+	"\tREADINESS\x10\x00\x12\f\n" +
+	"\bLIVENESS\x10\x01\"\xf2\x01\n" +
+	"\x13HealthCheckResponse\x12?\n" +
+	"\x06status\x18\x01 \x01(\x0e2'.blocking.v1.HealthCheckResponse.StatusR\x06status\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x126\n" +
+	"\x06checks\x18\x03 \x03(\v2\x1e.blocking.v1.HealthCheckDetailR\x06checks\"H\n" +
+	"\x06Status\x12\v\n" +
+	"\aUNKNOWN\x10\x00\x12\v\n" +
+	"\aSERVING\x10\x01\x12\x0f\n" +
+	"\vNOT_SERVING\x10\x02\x12\x13\n" +
+	"\x0fSERVICE_UNKNOWN\x10\x03\"[\n" +
+	"\x11HealthCheckDetail\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\ahealthy\x18\x02 \x01(\bR\ahealthy\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"*\n" +
+	"\x12ListChangesRequest\x12\x14\n" +
+	"\x05since\x18\x01 \x01(\tR\x05since\"v\n" +
+	"\x13ListChangesResponse\x12'\n" +
+	"\x0fcurrent_version\x18\x01 \x01(\tR\x0ecurrentVersion\x126\n" +
+	"\n" +
+	"changelogs\x18\x02 \x03(\v2\x16.blocking.v1.ChangelogR\n" +
+	"changelogs\"\xea\x01\n" +
+	"\tChangelog\x12!\n" +
+	"\ffrom_version\x18\x01 \x01(\tR\vfromVersion\x12\x1d\n" +
+	"\n" +
+	"to_version\x18\x02 \x01(\tR\ttoVersion\x12\x1d\n" +
+	"\n" +
+	"applied_at\x18\x03 \x01(\tR\tappliedAt\x12\x14\n" +
+	"\x05added\x18\x04 \x01(\x05R\x05added\x12\x18\n" +
+	"\aremoved\x18\x05 \x01(\x05R\aremoved\x12\x18\n" +
+	"\achanged\x18\x06 \x01(\x05R\achanged\x122\n" +
+	"\achanges\x18\a \x03(\v2\x18.blocking.v1.EntryChangeR\achanges\"\xe9\x02\n" +
+	"\vEntryChange\x121\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x1d.blocking.v1.EntryChange.KindR\x04kind\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x1a\n" +
+	"\bdecision\x18\x04 \x01(\tR\bdecision\x12!\n" +
+	"\fdecision_org\x18\x05 \x01(\tR\vdecisionOrg\x12#\n" +
+	"\rprevious_type\x18\x06 \x01(\tR\fpreviousType\x12+\n" +
+	"\x11previous_decision\x18\a \x01(\tR\x10previousDecision\x122\n" +
+	"\x15previous_decision_org\x18\b \x01(\tR\x13previousDecisionOrg\"8\n" +
+	"\x04Kind\x12\v\n" +
+	"\aUNKNOWN\x10\x00\x12\t\n" +
+	"\x05ADDED\x10\x01\x12\v\n" +
+	"\aREMOVED\x10\x02\x12\v\n" +
+	"\aCHANGED\x10\x03\"+\n" +
+	"\x11GetHistoryRequest\x12\x16\n" +
+	"\x06domain\x18\x01 \x01(\tR\x06domain\"g\n" +
+	"\x12GetHistoryResponse\x12\x18\n" +
+	"\ablocked\x18\x01 \x01(\bR\ablocked\x127\n" +
+	"\bpatterns\x18\x02 \x03(\v2\x1b.blocking.v1.PatternHistoryR\bpatterns\"w\n" +
+	"\x0ePatternHistory\x12\x18\n" +
+	"\apattern\x18\x01 \x01(\tR\apattern\x12\x18\n" +
+	"\ablocked\x18\x02 \x01(\bR\ablocked\x121\n" +
+	"\x06events\x18\x03 \x03(\v2\x19.blocking.v1.HistoryEventR\x06events\"\x7f\n" +
+	"\fHistoryEvent\x121\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x1d.blocking.v1.EntryChange.KindR\x04kind\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\x12\x0e\n" +
+	"\x02at\x18\x04 \x01(\tR\x02at\"!\n" +
+	"\x0fGetEntryRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"d\n" +
+	"\x12ListEntriesRequest\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\f\n" +
+	"\x01q\x18\x02 \x01(\tR\x01q\x12\x16\n" +
+	"\x06cursor\x18\x03 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\"\x86\x01\n" +
+	"\x13ListEntriesResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x124\n" +
+	"\aentries\x18\x02 \x03(\v2\x1a.blocking.v1.RegistryEntryR\aentries\x12\x1f\n" +
+	"\vnext_cursor\x18\x03 \x01(\tR\n" +
+	"nextCursor\"\xe4\x01\n" +
+	"\rRegistryEntry\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x18\n" +
+	"\apattern\x18\x03 \x01(\tR\apattern\x12\x14\n" +
+	"\x05paths\x18\x04 \x03(\tR\x05paths\x12\x1a\n" +
+	"\bdecision\x18\x05 \x01(\tR\bdecision\x12!\n" +
+	"\fdecision_org\x18\x06 \x01(\tR\vdecisionOrg\x12\x1d\n" +
+	"\n" +
+	"added_date\x18\a \x01(\tR\taddedDate\x12!\n" +
+	"\fblocked_date\x18\b \x01(\tR\vblockedDate\"G\n" +
+	"\rSearchRequest\x12\x12\n" +
+	"\x04mode\x18\x01 \x01(\tR\x04mode\x12\f\n" +
+	"\x01q\x18\x02 \x01(\tR\x01q\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"\x9b\x01\n" +
+	"\x0eSearchResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x124\n" +
+	"\aentries\x18\x02 \x03(\v2\x1a.blocking.v1.RegistryEntryR\aentries\x12\x1c\n" +
+	"\ttruncated\x18\x03 \x01(\bR\ttruncated\x12\x1b\n" +
+	"\ttimed_out\x18\x04 \x01(\bR\btimedOut\";\n" +
+	"\x14WatchRegistryRequest\x12#\n" +
+	"\rsince_version\x18\x01 \x01(\tR\fsinceVersion\"\x8c\x01\n" +
+	"\x15WatchRegistryResponse\x122\n" +
+	"\x05event\x18\x01 \x01(\v2\x1a.blocking.v1.RegistryEventH\x00R\x05event\x126\n" +
+	"\theartbeat\x18\x02 \x01(\v2\x16.blocking.v1.HeartbeatH\x00R\theartbeatB\a\n" +
+	"\x05frame\"\x85\x02\n" +
+	"\rRegistryEvent\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x05R\x04size\x12>\n" +
+	"\x06counts\x18\x03 \x03(\v2&.blocking.v1.RegistryEvent.CountsEntryR\x06counts\x12\x1d\n" +
+	"\n" +
+	"applied_at\x18\x04 \x01(\tR\tappliedAt\x12,\n" +
+	"\x04diff\x18\x05 \x01(\v2\x18.blocking.v1.DiffSummaryR\x04diff\x1a9\n" +
+	"\vCountsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"z\n" +
+	"\vDiffSummary\x12!\n" +
+	"\ffrom_version\x18\x01 \x01(\tR\vfromVersion\x12\x14\n" +
+	"\x05added\x18\x02 \x01(\x05R\x05added\x12\x18\n" +
+	"\aremoved\x18\x03 \x01(\x05R\aremoved\x12\x18\n" +
+	"\achanged\x18\x04 \x01(\x05R\achanged\"\x1b\n" +
+	"\tHeartbeat\x12\x0e\n" +
+	"\x02at\x18\x01 \x01(\tR\x02at2\xcb\x05\n" +
+	"\x0fBlockingService\x12G\n" +
+	"\bCheckURL\x12\x1c.blocking.v1.CheckURLRequest\x1a\x1d.blocking.v1.CheckURLResponse\x12G\n" +
+	"\bGetStats\x12\x1c.blocking.v1.GetStatsRequest\x1a\x1d.blocking.v1.GetStatsResponse\x12P\n" +
+	"\vHealthCheck\x12\x1f.blocking.v1.HealthCheckRequest\x1a .blocking.v1.HealthCheckResponse\x12P\n" +
+	"\vListChanges\x12\x1f.blocking.v1.ListChangesRequest\x1a .blocking.v1.ListChangesResponse\x12M\n" +
+	"\n" +
+	"GetHistory\x12\x1e.blocking.v1.GetHistoryRequest\x1a\x1f.blocking.v1.GetHistoryResponse\x12D\n" +
+	"\bGetEntry\x12\x1c.blocking.v1.GetEntryRequest\x1a\x1a.blocking.v1.RegistryEntry\x12P\n" +
+	"\vListEntries\x12\x1f.blocking.v1.ListEntriesRequest\x1a .blocking.v1.ListEntriesResponse\x12A\n" +
+	"\x06Search\x12\x1a.blocking.v1.SearchRequest\x1a\x1b.blocking.v1.SearchResponse\x12X\n" +
+	"\rWatchRegistry\x12!.blocking.v1.WatchRegistryRequest\x1a\".blocking.v1.WatchRegistryResponse0\x01BBZ@github.com/kerim-dauren/rkn-checker/internal/delivery/grpc/protob\x06proto3"
+
+var (
+	file_internal_delivery_grpc_proto_blocking_proto_rawDescOnce sync.Once
+	file_internal_delivery_grpc_proto_blocking_proto_rawDescData []byte
+)
+
+func file_internal_delivery_grpc_proto_blocking_proto_rawDescGZIP() []byte {
+	file_internal_delivery_grpc_proto_blocking_proto_rawDescOnce.Do(func() {
+		file_internal_delivery_grpc_proto_blocking_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_internal_delivery_grpc_proto_blocking_proto_rawDesc), len(file_internal_delivery_grpc_proto_blocking_proto_rawDesc)))
+	})
+	return file_internal_delivery_grpc_proto_blocking_proto_rawDescData
+}
+
+var file_internal_delivery_grpc_proto_blocking_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_internal_delivery_grpc_proto_blocking_proto_msgTypes = make([]protoimpl.MessageInfo, 27)
+var file_internal_delivery_grpc_proto_blocking_proto_goTypes = []any{
+	(HealthCheckRequest_Probe)(0),   // 0: blocking.v1.HealthCheckRequest.Probe
+	(HealthCheckResponse_Status)(0), // 1: blocking.v1.HealthCheckResponse.Status
+	(EntryChange_Kind)(0),           // 2: blocking.v1.EntryChange.Kind
+	(*CheckURLRequest)(nil),         // 3: blocking.v1.CheckURLRequest
+	(*CheckURLResponse)(nil),        // 4: blocking.v1.CheckURLResponse
+	(*GetStatsRequest)(nil),         // 5: blocking.v1.GetStatsRequest
+	(*GetStatsResponse)(nil),        // 6: blocking.v1.GetStatsResponse
+	(*HealthCheckRequest)(nil),      // 7: blocking.v1.HealthCheckRequest
+	(*HealthCheckResponse)(nil),     // 8: blocking.v1.HealthCheckResponse
+	(*HealthCheckDetail)(nil),       // 9: blocking.v1.HealthCheckDetail
+	(*ListChangesRequest)(nil),      // 10: blocking.v1.ListChangesRequest
+	(*ListChangesResponse)(nil),     // 11: blocking.v1.ListChangesResponse
+	(*Changelog)(nil),               // 12: blocking.v1.Changelog
+	(*EntryChange)(nil),             // 13: blocking.v1.EntryChange
+	(*GetHistoryRequest)(nil),       // 14: blocking.v1.GetHistoryRequest
+	(*GetHistoryResponse)(nil),      // 15: blocking.v1.GetHistoryResponse
+	(*PatternHistory)(nil),          // 16: blocking.v1.PatternHistory
+	(*HistoryEvent)(nil),            // 17: blocking.v1.HistoryEvent
+	(*GetEntryRequest)(nil),         // 18: blocking.v1.GetEntryRequest
+	(*ListEntriesRequest)(nil),      // 19: blocking.v1.ListEntriesRequest
+	(*ListEntriesResponse)(nil),     // 20: blocking.v1.ListEntriesResponse
+	(*RegistryEntry)(nil),           // 21: blocking.v1.RegistryEntry
+	(*SearchRequest)(nil),           // 22: blocking.v1.SearchRequest
+	(*SearchResponse)(nil),          // 23: blocking.v1.SearchResponse
+	(*WatchRegistryRequest)(nil),    // 24: blocking.v1.WatchRegistryRequest
+	(*WatchRegistryResponse)(nil),   // 25: blocking.v1.WatchRegistryResponse
+	(*RegistryEvent)(nil),           // 26: blocking.v1.RegistryEvent
+	(*DiffSummary)(nil),             // 27: blocking.v1.DiffSummary
+	(*Heartbeat)(nil),               // 28: blocking.v1.Heartbeat
+	nil,                             // 29: blocking.v1.RegistryEvent.CountsEntry
+}
+var file_internal_delivery_grpc_proto_blocking_proto_depIdxs = []int32{
+	0,  // 0: blocking.v1.HealthCheckRequest.probe:type_name -> blocking.v1.HealthCheckRequest.Probe
+	1,  // 1: blocking.v1.HealthCheckResponse.status:type_name -> blocking.v1.HealthCheckResponse.Status
+	9,  // 2: blocking.v1.HealthCheckResponse.checks:type_name -> blocking.v1.HealthCheckDetail
+	12, // 3: blocking.v1.ListChangesResponse.changelogs:type_name -> blocking.v1.Changelog
+	13, // 4: blocking.v1.Changelog.changes:type_name -> blocking.v1.EntryChange
+	2,  // 5: blocking.v1.EntryChange.kind:type_name -> blocking.v1.EntryChange.Kind
+	16, // 6: blocking.v1.GetHistoryResponse.patterns:type_name -> blocking.v1.PatternHistory
+	17, // 7: blocking.v1.PatternHistory.events:type_name -> blocking.v1.HistoryEvent
+	2,  // 8: blocking.v1.HistoryEvent.kind:type_name -> blocking.v1.EntryChange.Kind
+	21, // 9: blocking.v1.ListEntriesResponse.entries:type_name -> blocking.v1.RegistryEntry
+	21, // 10: blocking.v1.SearchResponse.entries:type_name -> blocking.v1.RegistryEntry
+	26, // 11: blocking.v1.WatchRegistryResponse.event:type_name -> blocking.v1.RegistryEvent
+	28, // 12: blocking.v1.WatchRegistryResponse.heartbeat:type_name -> blocking.v1.Heartbeat
+	29, // 13: blocking.v1.RegistryEvent.counts:type_name -> blocking.v1.RegistryEvent.CountsEntry
+	27, // 14: blocking.v1.RegistryEvent.diff:type_name -> blocking.v1.DiffSummary
+	3,  // 15: blocking.v1.BlockingService.CheckURL:input_type -> blocking.v1.CheckURLRequest
+	5,  // 16: blocking.v1.BlockingService.GetStats:input_type -> blocking.v1.GetStatsRequest
+	7,  // 17: blocking.v1.BlockingService.HealthCheck:input_type -> blocking.v1.HealthCheckRequest
+	10, // 18: blocking.v1.BlockingService.ListChanges:input_type -> blocking.v1.ListChangesRequest
+	14, // 19: blocking.v1.BlockingService.GetHistory:input_type -> blocking.v1.GetHistoryRequest
+	18, // 20: blocking.v1.BlockingService.GetEntry:input_type -> blocking.v1.GetEntryRequest
+	19, // 21: blocking.v1.BlockingService.ListEntries:input_type -> blocking.v1.ListEntriesRequest
+	22, // 22: blocking.v1.BlockingService.Search:input_type -> blocking.v1.SearchRequest
+	24, // 23: blocking.v1.BlockingService.WatchRegistry:input_type -> blocking.v1.WatchRegistryRequest
+	4,  // 24: blocking.v1.BlockingService.CheckURL:output_type -> blocking.v1.CheckURLResponse
+	6,  // 25: blocking.v1.BlockingService.GetStats:output_type -> blocking.v1.GetStatsResponse
+	8,  // 26: blocking.v1.BlockingService.HealthCheck:output_type -> blocking.v1.HealthCheckResponse
+	11, // 27: blocking.v1.BlockingService.ListChanges:output_type -> blocking.v1.ListChangesResponse
+	15, // 28: blocking.v1.BlockingService.GetHistory:output_type -> blocking.v1.GetHistoryResponse
+	21, // 29: blocking.v1.BlockingService.GetEntry:output_type -> blocking.v1.RegistryEntry
+	20, // 30: blocking.v1.BlockingService.ListEntries:output_type -> blocking.v1.ListEntriesResponse
+	23, // 31: blocking.v1.BlockingService.Search:output_type -> blocking.v1.SearchResponse
+	25, // 32: blocking.v1.BlockingService.WatchRegistry:output_type -> blocking.v1.WatchRegistryResponse
+	24, // [24:33] is the sub-list for method output_type
+	15, // [15:24] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_internal_delivery_grpc_proto_blocking_proto_init() }
+func file_internal_delivery_grpc_proto_blocking_proto_init() {
+	if File_internal_delivery_grpc_proto_blocking_proto != nil {
+		return
+	}
+	file_internal_delivery_grpc_proto_blocking_proto_msgTypes[22].OneofWrappers = []any{
+		(*WatchRegistryResponse_Event)(nil),
+		(*WatchRegistryResponse_Heartbeat)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_delivery_grpc_proto_blocking_proto_rawDesc), len(file_internal_delivery_grpc_proto_blocking_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   6,
+			NumEnums:      3,
+			NumMessages:   27,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -2,25 +2,179 @@ package grpc
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log/slog"
 	"net"
+	"sync"
 	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
 
 	"github.com/kerim-dauren/rkn-checker/internal/application"
 	"github.com/kerim-dauren/rkn-checker/internal/delivery/grpc/proto"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/listener"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/proxy"
 )
 
 type Server struct {
 	server          *grpc.Server
 	blockingService application.BlockingChecker
+	changelog       application.ChangelogReader
+	history         application.HistoryReader
+	entries         application.EntryBrowser
+	searcher        application.RegistrySearcher
+	feed            application.RegistryFeed
+	heartbeat       time.Duration
+	scheduler       application.SchedulerController
+	authenticator   application.Authenticator
+	rateLimiter     application.RateLimiter
+	proxies         *proxy.Resolver
+	proxyProtocol   bool
+	tlsConfig       *tls.Config
+	host            string
+	socket          string
+	readiness       application.ReadinessChecker
 	port            int
+
+	// health serves grpc.health.v1, following readiness every
+	// readinessInterval
+	health            *healthServer
+	readinessInterval time.Duration
+	// drainPeriod is how long NOT_SERVING is reported before stopping
+	drainPeriod time.Duration
+
+	// stop ends WatchRegistry and health Watch streams so a graceful stop
+	// does not wait on them
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// DefaultHeartbeatInterval is how often an idle WatchRegistry stream sends a
+// heartbeat
+const DefaultHeartbeatInterval = 15 * time.Second
+
+// shutdownTimeout bounds a graceful stop before remaining RPCs are cut off
+const shutdownTimeout = 30 * time.Second
+
+// Option configures optional Server dependencies
+type Option func(*Server)
+
+// WithChangelog serves registry changelogs through ListChanges
+func WithChangelog(changelog application.ChangelogReader) Option {
+	return func(s *Server) {
+		s.changelog = changelog
+	}
+}
+
+// WithHistory serves pattern timelines through GetHistory
+func WithHistory(history application.HistoryReader) Option {
+	return func(s *Server) {
+		s.history = history
+	}
+}
+
+// WithEntryBrowser serves registry entries through GetEntry and ListEntries
+func WithEntryBrowser(entries application.EntryBrowser) Option {
+	return func(s *Server) {
+		s.entries = entries
+	}
+}
+
+// WithSearcher serves registry searches through Search
+func WithSearcher(searcher application.RegistrySearcher) Option {
+	return func(s *Server) {
+		s.searcher = searcher
+	}
+}
+
+// WithRegistryFeed streams registry update events through WatchRegistry,
+// sending a heartbeat after every idle heartbeat interval
+func WithRegistryFeed(feed application.RegistryFeed, heartbeat time.Duration) Option {
+	return func(s *Server) {
+		s.feed = feed
+		s.heartbeat = heartbeat
+	}
+}
+
+// WithScheduler controls registry updates through the AdminService. The
+// service is only registered when an authenticator is set.
+func WithScheduler(scheduler application.SchedulerController) Option {
+	return func(s *Server) {
+		s.scheduler = scheduler
+	}
+}
+
+// WithAuthenticator requires the scope of every RPC except the health checks
+// and reflection from the callers resolved by authenticator
+func WithAuthenticator(authenticator application.Authenticator) Option {
+	return func(s *Server) {
+		s.authenticator = authenticator
+	}
+}
+
+// WithRateLimiter limits the calls of each client to every RPC except the
+// health checks and reflection
+func WithRateLimiter(limiter application.RateLimiter) Option {
+	return func(s *Server) {
+		s.rateLimiter = limiter
+	}
+}
+
+// WithReadiness answers HealthCheck readiness probes with the readiness checks
+// and reports NOT_SERVING on grpc.health.v1 while they fail
+func WithReadiness(readiness application.ReadinessChecker) Option {
+	return func(s *Server) {
+		s.readiness = readiness
+	}
+}
+
+// WithTrustedProxies resolves the addresses of clients calling through the
+// proxies of resolver from their forwarding metadata, and from PROXY protocol
+// headers when proxyProtocol is set
+func WithTrustedProxies(resolver *proxy.Resolver, proxyProtocol bool) Option {
+	return func(s *Server) {
+		s.proxies = resolver
+		s.proxyProtocol = proxyProtocol
+	}
 }
 
-func NewServer(blockingService application.BlockingChecker, port int) *Server {
+// WithTLS serves gRPC over TLS with tlsConfig instead of in plaintext
+func WithTLS(tlsConfig *tls.Config) Option {
+	return func(s *Server) {
+		s.tlsConfig = tlsConfig
+	}
+}
+
+// WithHost listens on host instead of every interface
+func WithHost(host string) Option {
+	return func(s *Server) {
+		s.host = host
+	}
+}
+
+// WithSocket listens on the Unix socket at path instead of the TCP port
+func WithSocket(path string) Option {
+	return func(s *Server) {
+		s.socket = path
+	}
+}
+
+// WithDrainPeriod keeps serving for period after health checks switch to
+// NOT_SERVING on shutdown, so load balancers can take the server out of
+// rotation before connections are closed
+func WithDrainPeriod(period time.Duration) Option {
+	return func(s *Server) {
+		s.drainPeriod = period
+	}
+}
+
+func NewServer(blockingService application.BlockingChecker, port int, options ...Option) *Server {
 	keepaliveParams := keepalive.ServerParameters{
 		MaxConnectionIdle:     15 * time.Second,
 		MaxConnectionAge:      30 * time.Second,
@@ -34,36 +188,113 @@ func NewServer(blockingService application.BlockingChecker, port int) *Server {
 		PermitWithoutStream: true,
 	}
 
+	s := &Server{
+		blockingService:   blockingService,
+		port:              port,
+		readinessInterval: DefaultReadinessInterval,
+		stop:              make(chan struct{}),
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	// Failed authentication attempts are limited around authentication.
+	// Authentication runs before logging so calls are logged with their
+	// caller, and calls are charged after it, to their caller, so calls they
+	// get rejected are logged too.
+	var unary []grpc.UnaryServerInterceptor
+	var stream []grpc.StreamServerInterceptor
+	if s.proxies != nil {
+		unary = append(unary, clientIPInterceptor(s.proxies))
+		stream = append(stream, streamClientIPInterceptor(s.proxies))
+	}
+	unary = append(unary, metricsInterceptor, recoveryInterceptor)
+	stream = append(stream, streamMetricsInterceptor, streamRecoveryInterceptor)
+	if s.authenticator != nil {
+		if s.rateLimiter != nil {
+			unary = append(unary, failedAuthenticationLimitInterceptor(s.rateLimiter))
+			stream = append(stream, streamFailedAuthenticationLimitInterceptor(s.rateLimiter))
+		}
+		unary = append(unary, authInterceptor(s.authenticator))
+		stream = append(stream, streamAuthInterceptor(s.authenticator))
+	}
+	unary = append(unary, loggingInterceptor)
+	stream = append(stream, streamLoggingInterceptor)
+	if s.rateLimiter != nil {
+		unary = append(unary, rateLimitInterceptor(s.rateLimiter))
+		stream = append(stream, streamRateLimitInterceptor(s.rateLimiter))
+	}
+
 	opts := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithFilter(traced))),
 		grpc.KeepaliveParams(keepaliveParams),
 		grpc.KeepaliveEnforcementPolicy(keepalivePolicy),
-		grpc.ChainUnaryInterceptor(recoveryInterceptor, loggingInterceptor),
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+	if s.tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(s.tlsConfig)))
 	}
 
-	server := grpc.NewServer(opts...)
+	s.server = grpc.NewServer(opts...)
 
-	return &Server{
-		server:          server,
-		blockingService: blockingService,
-		port:            port,
+	s.health = newHealthServer(s.stop)
+	healthpb.RegisterHealthServer(s.server, s.health)
+	if s.readiness != nil {
+		// Not serving until the readiness checks first pass
+		s.setServingStatus(healthpb.HealthCheckResponse_NOT_SERVING)
 	}
+	reflection.Register(s.server)
+
+	return s
 }
 
 func (s *Server) Start(ctx context.Context) error {
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	lis, err := listener.Listen(s.host, s.port, s.socket)
 	if err != nil {
-		return fmt.Errorf("failed to listen on port %d: %w", s.port, err)
+		return err
 	}
 
+	if s.proxies != nil && s.proxyProtocol {
+		lis = proxy.NewListener(lis, s.proxies)
+	}
+
+	slog.Info("Starting gRPC server", "address", listener.Address(s.host, s.port, s.socket),
+		"tls", s.tlsConfig != nil, "proxy_protocol", s.proxyProtocol)
+
+	return s.Serve(ctx, lis)
+}
+
+// Serve serves gRPC on lis until ctx is done or Stop is called
+func (s *Server) Serve(ctx context.Context, lis net.Listener) error {
 	handler := NewHandler(s.blockingService)
+	handler.changelog = s.changelog
+	handler.history = s.history
+	handler.entries = s.entries
+	handler.searcher = s.searcher
+	handler.feed = s.feed
+	handler.heartbeat = s.heartbeat
+	handler.stop = s.stop
+	handler.readiness = s.readiness
 	proto.RegisterBlockingServiceServer(s.server, handler)
 
-	slog.Info("Starting gRPC server", "port", s.port)
+	if s.scheduler != nil {
+		if s.authenticator != nil {
+			proto.RegisterAdminServiceServer(s.server, NewAdminHandler(s.scheduler))
+		} else {
+			slog.Warn("gRPC AdminService disabled, no authentication is configured")
+		}
+	}
+
+	go s.watchReadiness(ctx)
 
 	go func() {
-		<-ctx.Done()
-		slog.Info("Shutting down gRPC server...")
-		s.server.GracefulStop()
+		select {
+		case <-ctx.Done():
+			slog.Info("Shutting down gRPC server...")
+			s.Stop()
+		case <-s.stop:
+		}
 	}()
 
 	if err := s.server.Serve(lis); err != nil {
@@ -73,8 +304,27 @@ func (s *Server) Start(ctx context.Context) error {
 	return nil
 }
 
+// Stop reports NOT_SERVING to health checks, keeps serving for the drain
+// period, then stops gracefully. RPCs still running after shutdownTimeout
+// are cut off.
 func (s *Server) Stop() {
-	if s.server != nil {
+	s.health.Shutdown()
+	if s.drainPeriod > 0 {
+		slog.Info("Draining gRPC server", "period", s.drainPeriod)
+		time.Sleep(s.drainPeriod)
+	}
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	stopped := make(chan struct{})
+	go func() {
 		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		slog.Warn("gRPC graceful stop timed out, closing remaining connections")
+		s.server.Stop()
 	}
 }
@@ -0,0 +1,598 @@
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"net/netip"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/common"
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/grpc/proto"
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/proxy"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/ratelimit"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/tracing/tracingtest"
+)
+
+// toggleReadiness reports ready while ready is set
+type toggleReadiness struct {
+	ready atomic.Bool
+}
+
+func (r *toggleReadiness) Readiness(ctx context.Context) *domain.HealthReport {
+	return domain.NewHealthReport(domain.HealthCheck{Name: "registry_loaded", Healthy: r.ready.Load()})
+}
+
+// startBufconnServer serves s over an in-memory listener and returns a
+// client connection to it
+func startBufconnServer(t *testing.T, s *Server) *grpc.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(context.Background(), lis) }()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dialing bufconn server: %v", err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		s.Stop()
+		<-served
+	})
+	return conn
+}
+
+// nextStatus receives the next serving status from a Watch stream
+func nextStatus(t *testing.T, stream healthpb.Health_WatchClient) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("receiving health status: %v", err)
+	}
+	return resp.Status
+}
+
+func TestServer_HealthFollowsReadiness(t *testing.T) {
+	readiness := &toggleReadiness{}
+	s := NewServer(&mockBlockingService{}, 0, WithReadiness(readiness))
+	s.readinessInterval = 10 * time.Millisecond
+	client := healthpb.NewHealthClient(startBufconnServer(t, s))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("watching health: %v", err)
+	}
+	if got := nextStatus(t, stream); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("expected NOT_SERVING before the registry is ready, got %v", got)
+	}
+
+	readiness.ready.Store(true)
+	if got := nextStatus(t, stream); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("expected SERVING once ready, got %v", got)
+	}
+
+	resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: proto.BlockingService_ServiceDesc.ServiceName})
+	if err != nil || resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("expected BlockingService to be SERVING, got %v (%v)", resp, err)
+	}
+
+	readiness.ready.Store(false)
+	if got := nextStatus(t, stream); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("expected NOT_SERVING once readiness fails, got %v", got)
+	}
+}
+
+func TestServer_HealthNotServingOnStop(t *testing.T) {
+	s := NewServer(&mockBlockingService{}, 0)
+	s.readinessInterval = 10 * time.Millisecond
+	client := healthpb.NewHealthClient(startBufconnServer(t, s))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("watching health: %v", err)
+	}
+	if got := nextStatus(t, stream); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("expected SERVING without readiness checks, got %v", got)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(stopped)
+	}()
+
+	if got := nextStatus(t, stream); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("expected NOT_SERVING on shutdown, got %v", got)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("expected the watch to end with Unavailable, got %v", err)
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("graceful stop waited on the health watch")
+	}
+}
+
+func TestServer_DrainsBeforeStopping(t *testing.T) {
+	s := NewServer(&mockBlockingService{
+		checkURLFunc: func(ctx context.Context, rawURL string) (*domain.BlockingResult, error) {
+			return domain.NewBlockingResult(false, rawURL, nil), nil
+		},
+	}, 0, WithDrainPeriod(time.Second))
+	conn := startBufconnServer(t, s)
+	health := healthpb.NewHealthClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(stopped)
+	}()
+
+	waitFor := time.Now().Add(time.Second)
+	for {
+		resp, err := health.Check(ctx, &healthpb.HealthCheckRequest{})
+		if err == nil && resp.Status == healthpb.HealthCheckResponse_NOT_SERVING {
+			break
+		}
+		if time.Now().After(waitFor) {
+			t.Fatalf("expected NOT_SERVING while draining, got %v (%v)", resp, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := checkURL(proto.NewBlockingServiceClient(conn))(ctx); err != nil {
+		t.Errorf("expected RPCs to be served while draining, got %v", err)
+	}
+	select {
+	case <-stopped:
+		t.Error("expected the server to keep serving for the drain period")
+	default:
+	}
+	<-stopped
+}
+
+func TestServer_Reflection(t *testing.T) {
+	s := NewServer(&mockBlockingService{}, 0)
+	client := reflectionpb.NewServerReflectionClient(startBufconnServer(t, s))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.ServerReflectionInfo(ctx)
+	if err != nil {
+		t.Fatalf("opening reflection stream: %v", err)
+	}
+	if err := stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}); err != nil {
+		t.Fatalf("listing services: %v", err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("receiving services: %v", err)
+	}
+
+	services := make(map[string]bool)
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		services[service.Name] = true
+	}
+	for _, expected := range []string{proto.BlockingService_ServiceDesc.ServiceName, healthpb.Health_ServiceDesc.ServiceName} {
+		if !services[expected] {
+			t.Errorf("expected %s to be listed, got %v", expected, services)
+		}
+	}
+}
+
+func TestServer_ContinuesTraceContext(t *testing.T) {
+	exporter := tracingtest.Exporter(t)
+
+	var handled trace.SpanContext
+	s := NewServer(&mockBlockingService{
+		checkURLFunc: func(ctx context.Context, rawURL string) (*domain.BlockingResult, error) {
+			handled = trace.SpanContextFromContext(ctx)
+			return domain.NewBlockingResult(false, rawURL, nil), nil
+		},
+	}, 0)
+	conn := startBufconnServer(t, s)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		"traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	if _, err := proto.NewBlockingServiceClient(conn).CheckURL(ctx, &proto.CheckURLRequest{Url: "example.com"}); err != nil {
+		t.Fatalf("CheckURL() unexpected error: %v", err)
+	}
+	if _, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("health check: %v", err)
+	}
+
+	if handled.TraceID().String() != traceID {
+		t.Errorf("expected the handler to run in trace %s, got %s", traceID, handled.TraceID())
+	}
+
+	spans := exporter.GetSpans()
+	server := tracingtest.Find(spans, "blocking.v1.BlockingService/CheckURL")
+	if server == nil {
+		t.Fatalf("expected a server span for CheckURL, got %d spans", len(spans))
+	}
+	if server.Parent.SpanID().String() != "00f067aa0ba902b7" || !server.Parent.IsRemote() {
+		t.Errorf("expected the server span under the remote caller span, got parent %s", server.Parent.SpanID())
+	}
+	if tracingtest.Find(spans, "grpc.health.v1.Health/Check") != nil {
+		t.Error("expected health checks to be left out of traces")
+	}
+}
+
+// stubAuthenticator accepts the tokens it maps to identities
+type stubAuthenticator struct {
+	tokens map[string]*domain.Identity
+	// certificateScopes are granted to client certificates, refused when empty
+	certificateScopes []domain.Scope
+}
+
+func (a *stubAuthenticator) AuthenticateToken(token string) (*domain.Identity, error) {
+	if identity, ok := a.tokens[token]; ok {
+		return identity, nil
+	}
+	return nil, domain.ErrInvalidCredentials
+}
+
+func (a *stubAuthenticator) AuthenticateCertificate(cert *x509.Certificate) (*domain.Identity, error) {
+	if len(a.certificateScopes) == 0 {
+		return nil, domain.ErrInvalidCredentials
+	}
+	return &domain.Identity{Method: domain.AuthMethodCertificate, Subject: cert.Subject.CommonName, Scopes: a.certificateScopes}, nil
+}
+
+func (a *stubAuthenticator) Anonymous() *domain.Identity {
+	return &domain.Identity{Method: domain.AuthMethodAnonymous}
+}
+
+func TestServer_Authentication(t *testing.T) {
+	authenticator := &stubAuthenticator{tokens: map[string]*domain.Identity{
+		"checker-key":  {Method: domain.AuthMethodAPIKey, Subject: "checker", Scopes: []domain.Scope{domain.ScopeCheck}},
+		"registry-key": {Method: domain.AuthMethodAPIKey, Subject: "browser", Scopes: []domain.Scope{domain.ScopeRegistry}},
+		"admin-token":  {Method: domain.AuthMethodAdminToken, Subject: "admin", Scopes: []domain.Scope{domain.ScopeAdmin}},
+	}}
+
+	var caller *domain.Identity
+	s := NewServer(&mockBlockingService{
+		checkURLFunc: func(ctx context.Context, rawURL string) (*domain.BlockingResult, error) {
+			caller = domain.IdentityFromContext(ctx)
+			return domain.NewBlockingResult(false, rawURL, nil), nil
+		},
+	}, 0, WithAuthenticator(authenticator), WithScheduler(&mockSchedulerController{}))
+	conn := startBufconnServer(t, s)
+	blocking := proto.NewBlockingServiceClient(conn)
+	admin := proto.NewAdminServiceClient(conn)
+
+	withKey := func(key string) context.Context {
+		if key == "" {
+			return context.Background()
+		}
+		return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+	}
+
+	tests := []struct {
+		name         string
+		call         func(ctx context.Context) error
+		key          string
+		expectedCode codes.Code
+	}{
+		{"Check without credentials", checkURL(blocking), "", codes.Unauthenticated},
+		{"Check with an invalid key", checkURL(blocking), "unknown", codes.Unauthenticated},
+		{"Check with the check scope", checkURL(blocking), "checker-key", codes.OK},
+		{"Stats without the stats scope", func(ctx context.Context) error {
+			_, err := blocking.GetStats(ctx, &proto.GetStatsRequest{})
+			return err
+		}, "checker-key", codes.PermissionDenied},
+		{"Stream without the registry scope", watchRegistry(blocking), "checker-key", codes.PermissionDenied},
+		{"Stream with the registry scope", watchRegistry(blocking), "registry-key", codes.Unimplemented},
+		{"Admin without the admin scope", getSchedulerStatus(admin), "checker-key", codes.PermissionDenied},
+		{"Admin with the admin token", getSchedulerStatus(admin), "admin-token", codes.OK},
+		{"Health checks stay open", func(ctx context.Context) error {
+			_, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+			return err
+		}, "", codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := status.Code(tt.call(withKey(tt.key))); code != tt.expectedCode {
+				t.Errorf("expected code %v, got %v", tt.expectedCode, code)
+			}
+		})
+	}
+
+	if caller == nil || caller.String() != "api_key:checker" {
+		t.Errorf("expected the handler to see the caller identity, got %v", caller)
+	}
+}
+
+func checkURL(client proto.BlockingServiceClient) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := client.CheckURL(ctx, &proto.CheckURLRequest{Url: "example.com"})
+		return err
+	}
+}
+
+func watchRegistry(client proto.BlockingServiceClient) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		stream, err := client.WatchRegistry(ctx, &proto.WatchRegistryRequest{})
+		if err != nil {
+			return err
+		}
+		if _, err := stream.Recv(); err != io.EOF {
+			return err
+		}
+		return nil
+	}
+}
+
+func getSchedulerStatus(client proto.AdminServiceClient) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := client.GetSchedulerStatus(ctx, &proto.GetSchedulerStatusRequest{})
+		return err
+	}
+}
+
+// stubRateLimiter rejects the methods it maps to a decision
+type stubRateLimiter struct {
+	rejected map[string]domain.RateLimitDecision
+}
+
+func (l *stubRateLimiter) Allow(client, route string) domain.RateLimitDecision {
+	return l.Peek(client, route)
+}
+
+func (l *stubRateLimiter) Peek(client, route string) domain.RateLimitDecision {
+	if decision, ok := l.rejected[route]; ok {
+		return decision
+	}
+	return domain.RateLimitDecision{Allowed: true}
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	limiter := &stubRateLimiter{rejected: map[string]domain.RateLimitDecision{
+		proto.BlockingService_CheckURL_FullMethodName:      {RetryAfter: 3 * time.Second},
+		proto.BlockingService_WatchRegistry_FullMethodName: {RetryAfter: time.Hour, QuotaExceeded: true},
+		healthpb.Health_Check_FullMethodName:               {RetryAfter: time.Second},
+	}}
+	s := NewServer(&mockBlockingService{}, 0, WithRateLimiter(limiter))
+	conn := startBufconnServer(t, s)
+	blocking := proto.NewBlockingServiceClient(conn)
+
+	for name, call := range map[string]struct {
+		call   func(ctx context.Context) error
+		reason common.Code
+	}{
+		"unary":  {checkURL(blocking), common.CodeRateLimited},
+		"stream": {watchRegistry(blocking), common.CodeQuotaExceeded},
+	} {
+		err := call.call(context.Background())
+		st := status.Convert(err)
+		if st.Code() != codes.ResourceExhausted {
+			t.Fatalf("%s: expected ResourceExhausted, got %v", name, err)
+		}
+		if reason := errorReason(st); reason != string(call.reason) {
+			t.Errorf("%s: expected reason %s, got %q", name, call.reason, reason)
+		}
+
+		var retryInfo *errdetails.RetryInfo
+		for _, detail := range st.Details() {
+			if info, ok := detail.(*errdetails.RetryInfo); ok {
+				retryInfo = info
+			}
+		}
+		if retryInfo == nil || retryInfo.GetRetryDelay().AsDuration() <= 0 {
+			t.Errorf("%s: expected the retry delay in the error details, got %v", name, st.Details())
+		}
+	}
+
+	if _, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Errorf("expected health checks not to be rate limited, got %v", err)
+	}
+}
+
+func TestServer_RateLimitByCaller(t *testing.T) {
+	limiter, err := ratelimit.NewLimiter(ratelimit.Limits{Default: ratelimit.Rule{Rate: 0.001, Burst: 1}}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator := &stubAuthenticator{tokens: map[string]*domain.Identity{
+		"billing-key": {Method: domain.AuthMethodAPIKey, Subject: "billing", Scopes: []domain.Scope{domain.ScopeCheck}},
+		"support-key": {Method: domain.AuthMethodAPIKey, Subject: "support", Scopes: []domain.Scope{domain.ScopeCheck}},
+	}}
+	s := NewServer(&mockBlockingService{
+		checkURLFunc: func(ctx context.Context, rawURL string) (*domain.BlockingResult, error) {
+			return domain.NewBlockingResult(false, rawURL, nil), nil
+		},
+	}, 0, WithAuthenticator(authenticator), WithRateLimiter(limiter))
+	blocking := proto.NewBlockingServiceClient(startBufconnServer(t, s))
+
+	// Every call comes from the same address
+	calls := []struct {
+		apiKey   string
+		expected codes.Code
+	}{
+		{"billing-key", codes.OK},
+		{"support-key", codes.OK},
+		{"billing-key", codes.ResourceExhausted},
+		{"guessed-key", codes.Unauthenticated},
+		{"support-key", codes.ResourceExhausted},
+		{"guessed-key", codes.ResourceExhausted},
+	}
+	for i, call := range calls {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", call.apiKey)
+		if err := checkURL(blocking)(ctx); status.Code(err) != call.expected {
+			t.Errorf("call %d with %s: expected %v, got %v", i, call.apiKey, call.expected, err)
+		}
+	}
+}
+
+func TestResolveClientIP(t *testing.T) {
+	resolver, err := proxy.NewResolver([]string{"10.0.0.0/8"}, proxy.HeaderForwarded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		peer     string
+		metadata []string
+		expected string
+	}{
+		{"Trusted proxy", "10.0.0.2:41000", []string{"forwarded", "for=198.51.100.1"}, "198.51.100.1"},
+		{"Untrusted peer spoofing the metadata", "203.0.113.9:41000", []string{"forwarded", "for=198.51.100.1"}, "203.0.113.9"},
+		{"Other header ignored", "10.0.0.2:41000", []string{"x-forwarded-for", "198.51.100.1"}, "10.0.0.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: net.TCPAddrFromAddrPort(netip.MustParseAddrPort(tt.peer))})
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(tt.metadata...))
+
+			ctx = resolveClientIP(ctx, resolver)
+			if got := remoteIP(ctx); got != tt.expected {
+				t.Errorf("expected remote IP %s, got %s", tt.expected, got)
+			}
+			if got := rateLimitClient(ctx); got != "ip:"+tt.expected {
+				t.Errorf("expected rate limit client ip:%s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+// issueCertificate creates a certificate for name signed by parent, or a
+// self-signed CA when parent is nil
+func issueCertificate(t *testing.T, name string, parent *tls.Certificate) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  parent == nil,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := template, any(key)
+	if parent != nil {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, key.Public(), signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestServer_MutualTLS(t *testing.T) {
+	ca := issueCertificate(t, "rkn-checker CA", nil)
+	serverCert := issueCertificate(t, "rkn-checker.internal", &ca)
+	clientCert := issueCertificate(t, "billing", &ca)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+
+	var caller *domain.Identity
+	s := NewServer(&mockBlockingService{
+		checkURLFunc: func(ctx context.Context, rawURL string) (*domain.BlockingResult, error) {
+			caller = domain.IdentityFromContext(ctx)
+			return domain.NewBlockingResult(false, rawURL, nil), nil
+		},
+	}, 0,
+		WithAuthenticator(&stubAuthenticator{certificateScopes: []domain.Scope{domain.ScopeCheck}}),
+		WithTLS(&tls.Config{
+			Certificates: []tls.Certificate{serverCert},
+			ClientCAs:    pool,
+			ClientAuth:   tls.VerifyClientCertIfGiven,
+		}))
+
+	lis := bufconn.Listen(1 << 20)
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(context.Background(), lis) }()
+	t.Cleanup(func() {
+		s.Stop()
+		<-served
+	})
+
+	dial := func(certificates ...tls.Certificate) proto.BlockingServiceClient {
+		conn, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+				RootCAs:      pool,
+				ServerName:   "rkn-checker.internal",
+				Certificates: certificates,
+			})))
+		if err != nil {
+			t.Fatalf("dialing bufconn server: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return proto.NewBlockingServiceClient(conn)
+	}
+
+	if err := checkURL(dial(clientCert))(context.Background()); err != nil {
+		t.Fatalf("unexpected error over mutual TLS: %v", err)
+	}
+	if caller == nil || caller.String() != "client_cert:billing" {
+		t.Errorf("expected the client certificate to identify the caller, got %v", caller)
+	}
+
+	if code := status.Code(checkURL(dial())(context.Background())); code != codes.Unauthenticated {
+		t.Errorf("expected a caller without certificate to be unauthenticated, got %v", code)
+	}
+}
@@ -0,0 +1,117 @@
+package rest
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/application"
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/common"
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// AdminHandler serves operational endpoints under /admin/v1/
+type AdminHandler struct {
+	ingestReporter application.IngestReporter
+	quarantine     application.QuarantineManager
+}
+
+func NewAdminHandler(ingestReporter application.IngestReporter, quarantine application.QuarantineManager) *AdminHandler {
+	return &AdminHandler{
+		ingestReporter: ingestReporter,
+		quarantine:     quarantine,
+	}
+}
+
+func (h *AdminHandler) GetIngestReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	report := h.ingestReporter.LastIngestReport()
+	if report == nil {
+		WriteErrorResponse(w, common.CodeNotFound, "No registry update has been applied yet")
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, newIngestReportResponse(report))
+}
+
+func (h *AdminHandler) GetQuarantine(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	quarantined := h.quarantine.Quarantined()
+	if quarantined == nil {
+		WriteErrorResponse(w, common.CodeNotFound, "No registry update is quarantined")
+		return
+	}
+
+	response := QuarantineResponse{
+		Reason:        quarantined.Reason,
+		QuarantinedAt: quarantined.QuarantinedAt.Format(time.RFC3339),
+		CurrentSize:   quarantined.CurrentSize,
+		CandidateSize: quarantined.CandidateSize,
+	}
+	if quarantined.Report != nil {
+		report := newIngestReportResponse(quarantined.Report)
+		response.Report = &report
+	}
+
+	WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *AdminHandler) ApplyQuarantine(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if err := h.quarantine.ApplyQuarantined(); err != nil {
+		WriteDomainError(w, err, "Failed to apply quarantined registry")
+		return
+	}
+
+	slog.Warn("Quarantined registry applied by admin override", "remote_ip", getRemoteIP(r))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func newIngestReportResponse(report *domain.IngestReport) IngestReportResponse {
+	response := IngestReportResponse{
+		Format:         report.Format,
+		Encoding:       report.Encoding,
+		StartedAt:      report.StartedAt.Format(time.RFC3339),
+		DurationMs:     report.Duration.Milliseconds(),
+		RowsRead:       report.RowsRead,
+		Accepted:       report.Accepted,
+		CountsByType:   make(map[string]int, len(report.CountsByType)),
+		RejectedCount:  report.RejectedCount,
+		Rejected:       make([]RejectedEntryResponse, 0, len(report.Rejected)),
+		DuplicateCount: report.DuplicateCount,
+		Duplicates:     make([]DuplicateEntryResponse, 0, len(report.Duplicates)),
+	}
+
+	for blockingType, count := range report.CountsByType {
+		response.CountsByType[blockingType.String()] = count
+	}
+	for _, rejected := range report.Rejected {
+		response.Rejected = append(response.Rejected, RejectedEntryResponse{
+			Line:   rejected.Line,
+			Value:  rejected.Value,
+			Reason: rejected.Reason,
+		})
+	}
+	for _, duplicate := range report.Duplicates {
+		response.Duplicates = append(response.Duplicates, DuplicateEntryResponse{
+			Line:      duplicate.Line,
+			FirstLine: duplicate.FirstLine,
+			Value:     duplicate.Value,
+		})
+	}
+
+	return response
+}
@@ -0,0 +1,241 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+type mockIngestReporter struct {
+	report *domain.IngestReport
+}
+
+func (m *mockIngestReporter) LastIngestReport() *domain.IngestReport {
+	return m.report
+}
+
+type mockQuarantineManager struct {
+	quarantined *domain.QuarantinedUpdate
+	applyErr    error
+	applied     int
+}
+
+func (m *mockQuarantineManager) Quarantined() *domain.QuarantinedUpdate {
+	return m.quarantined
+}
+
+func (m *mockQuarantineManager) ApplyQuarantined() error {
+	if m.applyErr != nil {
+		return m.applyErr
+	}
+	if m.quarantined == nil {
+		return domain.ErrNoQuarantinedUpdate
+	}
+	m.applied++
+	m.quarantined = nil
+	return nil
+}
+
+func TestAdminHandler_GetIngestReport(t *testing.T) {
+	report := domain.NewIngestReport("zip")
+	report.Encoding = "windows-1251"
+	report.RowsRead = 3
+	report.AddAccepted(domain.BlockingTypeDomain)
+	report.AddAccepted(domain.BlockingTypeIP)
+	report.AddRejected(3, "invalid..domain", "unrecognized entry format")
+	report.AddDuplicate(4, 2, "example.com")
+
+	tests := []struct {
+		name           string
+		method         string
+		report         *domain.IngestReport
+		expectedStatus int
+	}{
+		{
+			name:           "GET returns last report",
+			method:         http.MethodGet,
+			report:         report,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "no update applied yet",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "POST method should return method not allowed",
+			method:         http.MethodPost,
+			report:         report,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAdminHandler(&mockIngestReporter{report: tt.report}, nil)
+
+			req := httptest.NewRequest(tt.method, "/admin/v1/ingest-report", nil)
+			w := httptest.NewRecorder()
+
+			handler.GetIngestReport(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, but got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resp IngestReportResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+
+			if resp.Format != "zip" || resp.Encoding != "windows-1251" {
+				t.Errorf("unexpected format/encoding: %s/%s", resp.Format, resp.Encoding)
+			}
+			if resp.CountsByType["domain"] != 1 || resp.CountsByType["ip"] != 1 {
+				t.Errorf("unexpected counts by type: %v", resp.CountsByType)
+			}
+			if resp.RejectedCount != 1 || resp.Rejected[0].Line != 3 || resp.Rejected[0].Value != "invalid..domain" {
+				t.Errorf("unexpected rejected entries: %+v", resp.Rejected)
+			}
+			if resp.DuplicateCount != 1 || resp.Duplicates[0].FirstLine != 2 {
+				t.Errorf("unexpected duplicates: %+v", resp.Duplicates)
+			}
+		})
+	}
+}
+
+func TestAdminHandler_Quarantine(t *testing.T) {
+	quarantined := &domain.QuarantinedUpdate{
+		Reason:        "registry shrank by 90.0% (100 -> 10 entries), limit is 30.0%",
+		CurrentSize:   100,
+		CandidateSize: 10,
+		Report:        domain.NewIngestReport("csv"),
+	}
+
+	t.Run("GET returns quarantined update", func(t *testing.T) {
+		handler := NewAdminHandler(nil, &mockQuarantineManager{quarantined: quarantined})
+
+		w := httptest.NewRecorder()
+		handler.GetQuarantine(w, httptest.NewRequest(http.MethodGet, "/admin/v1/quarantine", nil))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, but got %d", http.StatusOK, w.Code)
+		}
+
+		var resp QuarantineResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if resp.Reason != quarantined.Reason || resp.CandidateSize != 10 || resp.Report == nil {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+	})
+
+	t.Run("GET without quarantine", func(t *testing.T) {
+		handler := NewAdminHandler(nil, &mockQuarantineManager{})
+
+		w := httptest.NewRecorder()
+		handler.GetQuarantine(w, httptest.NewRequest(http.MethodGet, "/admin/v1/quarantine", nil))
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d, but got %d", http.StatusNotFound, w.Code)
+		}
+	})
+
+	t.Run("POST apply force-applies update", func(t *testing.T) {
+		manager := &mockQuarantineManager{quarantined: quarantined}
+		handler := NewAdminHandler(nil, manager)
+
+		w := httptest.NewRecorder()
+		handler.ApplyQuarantine(w, httptest.NewRequest(http.MethodPost, "/admin/v1/quarantine/apply", nil))
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Expected status %d, but got %d", http.StatusNoContent, w.Code)
+		}
+		if manager.applied != 1 {
+			t.Errorf("expected quarantined update to be applied once, got %d", manager.applied)
+		}
+
+		w = httptest.NewRecorder()
+		handler.ApplyQuarantine(w, httptest.NewRequest(http.MethodPost, "/admin/v1/quarantine/apply", nil))
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d, but got %d", http.StatusNotFound, w.Code)
+		}
+	})
+
+	t.Run("apply failure", func(t *testing.T) {
+		handler := NewAdminHandler(nil, &mockQuarantineManager{quarantined: quarantined, applyErr: errors.New("store failure")})
+
+		w := httptest.NewRecorder()
+		handler.ApplyQuarantine(w, httptest.NewRequest(http.MethodPost, "/admin/v1/quarantine/apply", nil))
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status %d, but got %d", http.StatusInternalServerError, w.Code)
+		}
+	})
+
+	t.Run("GET apply is not allowed", func(t *testing.T) {
+		handler := NewAdminHandler(nil, &mockQuarantineManager{quarantined: quarantined})
+
+		w := httptest.NewRecorder()
+		handler.ApplyQuarantine(w, httptest.NewRequest(http.MethodGet, "/admin/v1/quarantine/apply", nil))
+
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status %d, but got %d", http.StatusMethodNotAllowed, w.Code)
+		}
+	})
+}
+
+func TestServer_AdminRoutesRequireAuthenticator(t *testing.T) {
+	quarantine := &mockQuarantineManager{quarantined: &domain.QuarantinedUpdate{}}
+	authenticator := &stubAuthenticator{
+		tokens: map[string]*domain.Identity{
+			"admin-token": {Method: domain.AuthMethodAdminToken, Subject: "admin", Scopes: []domain.Scope{domain.ScopeAdmin}},
+		},
+	}
+
+	t.Run("No authenticator", func(t *testing.T) {
+		mux := http.NewServeMux()
+		NewServer(nil, 0, WithQuarantineManager(quarantine)).registerAdminRoutes(mux)
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/v1/quarantine/apply", nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected admin routes not to be served, got status %d", w.Code)
+		}
+		if quarantine.applied != 0 {
+			t.Error("expected the quarantined update not to be applied")
+		}
+	})
+
+	t.Run("With authenticator", func(t *testing.T) {
+		mux := http.NewServeMux()
+		NewServer(nil, 0, WithQuarantineManager(quarantine), WithAuthenticator(authenticator)).registerAdminRoutes(mux)
+		handler := AuthenticationMiddleware(authenticator, mux)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/v1/quarantine/apply", nil))
+		if w.Code != http.StatusUnauthorized && w.Code != http.StatusForbidden {
+			t.Errorf("expected anonymous callers to be refused, got status %d", w.Code)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/v1/quarantine/apply", nil)
+		req.Header.Set("Authorization", "Bearer admin-token")
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusNoContent {
+			t.Errorf("expected status 204 for the admin, got %d", w.Code)
+		}
+		if quarantine.applied != 1 {
+			t.Errorf("expected the quarantined update to be applied once, got %d", quarantine.applied)
+		}
+	})
+}
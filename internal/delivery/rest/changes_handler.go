@@ -0,0 +1,74 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/application"
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/common"
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// ChangesHandler serves registry changelogs
+type ChangesHandler struct {
+	changelog application.ChangelogReader
+}
+
+func NewChangesHandler(changelog application.ChangelogReader) *ChangesHandler {
+	return &ChangesHandler{
+		changelog: changelog,
+	}
+}
+
+func (h *ChangesHandler) ListChanges(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	changelogs, err := h.changelog.ChangesSince(r.URL.Query().Get("since"))
+	if err != nil {
+		WriteDomainError(w, err, "Failed to list changes")
+		return
+	}
+
+	response := ChangesResponse{
+		CurrentVersion: h.changelog.CurrentVersion(),
+		Changelogs:     make([]ChangelogResponse, 0, len(changelogs)),
+	}
+	for _, changelog := range changelogs {
+		response.Changelogs = append(response.Changelogs, newChangelogResponse(changelog))
+	}
+
+	WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func newChangelogResponse(changelog *domain.Changelog) ChangelogResponse {
+	response := ChangelogResponse{
+		FromVersion: changelog.FromVersion,
+		ToVersion:   changelog.ToVersion,
+		AppliedAt:   changelog.AppliedAt.Format(time.RFC3339),
+		Added:       changelog.Added,
+		Removed:     changelog.Removed,
+		Changed:     changelog.Changed,
+		Changes:     make([]EntryChangeResponse, 0, len(changelog.Changes)),
+	}
+
+	for _, change := range changelog.Changes {
+		entry := EntryChangeResponse{
+			Kind:        change.Kind.String(),
+			Value:       change.Value,
+			Type:        change.Type.String(),
+			Decision:    change.Decision,
+			DecisionOrg: change.DecisionOrg,
+		}
+		if change.Kind == domain.ChangeKindChanged {
+			entry.PreviousType = change.PreviousType.String()
+			entry.PreviousDecision = change.PreviousDecision
+			entry.PreviousDecisionOrg = change.PreviousDecisionOrg
+		}
+		response.Changes = append(response.Changes, entry)
+	}
+
+	return response
+}
@@ -0,0 +1,103 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+type mockChangelogReader struct {
+	changelogs []*domain.Changelog
+	current    string
+}
+
+func (m *mockChangelogReader) ChangesSince(version string) ([]*domain.Changelog, error) {
+	if version == "" {
+		return m.changelogs, nil
+	}
+	for i, changelog := range m.changelogs {
+		if changelog.FromVersion == version {
+			return m.changelogs[i:], nil
+		}
+	}
+	if version == m.current {
+		return nil, nil
+	}
+	return nil, domain.ErrUnknownRegistryVersion
+}
+
+func (m *mockChangelogReader) CurrentVersion() string {
+	return m.current
+}
+
+func TestChangesHandler_ListChanges(t *testing.T) {
+	reader := &mockChangelogReader{
+		current: "v3",
+		changelogs: []*domain.Changelog{
+			{
+				FromVersion: "v1", ToVersion: "v2", AppliedAt: time.Now(), Added: 1,
+				Changes: []domain.EntryChange{{Kind: domain.ChangeKindAdded, Value: "new.com", Type: domain.BlockingTypeDomain}},
+			},
+			{
+				FromVersion: "v2", ToVersion: "v3", AppliedAt: time.Now(), Changed: 1,
+				Changes: []domain.EntryChange{{
+					Kind: domain.ChangeKindChanged, Value: "10.0.0.1", Type: domain.BlockingTypeIP,
+					Decision: "new", PreviousType: domain.BlockingTypeIP, PreviousDecision: "old",
+				}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		query          string
+		expectedStatus int
+		expectedCount  int
+	}{
+		{name: "all changes", method: http.MethodGet, expectedStatus: http.StatusOK, expectedCount: 2},
+		{name: "since version", method: http.MethodGet, query: "?since=v2", expectedStatus: http.StatusOK, expectedCount: 1},
+		{name: "up to date", method: http.MethodGet, query: "?since=v3", expectedStatus: http.StatusOK, expectedCount: 0},
+		{name: "unknown version", method: http.MethodGet, query: "?since=v0", expectedStatus: http.StatusGone},
+		{name: "POST method should return method not allowed", method: http.MethodPost, expectedStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewChangesHandler(reader)
+
+			req := httptest.NewRequest(tt.method, "/api/v1/changes"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.ListChanges(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, but got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resp ChangesResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if resp.CurrentVersion != "v3" {
+				t.Errorf("expected current version v3, got %q", resp.CurrentVersion)
+			}
+			if len(resp.Changelogs) != tt.expectedCount {
+				t.Fatalf("expected %d changelogs, got %d", tt.expectedCount, len(resp.Changelogs))
+			}
+			if tt.expectedCount == 1 {
+				change := resp.Changelogs[0].Changes[0]
+				if change.Kind != "changed" || change.PreviousDecision != "old" || change.Decision != "new" {
+					t.Errorf("unexpected change: %+v", change)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package rest
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/kerim-dauren/rkn-checker/internal/application"
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/common"
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/collateral"
+)
+
+// maxHostedListSize bounds the size of an uploaded hosted domain list
+const maxHostedListSize = 16 << 20
+
+// CollateralHandler runs collateral-damage analyses of hosted domains as jobs
+type CollateralHandler struct {
+	jobs application.CollateralJobRunner
+}
+
+func NewCollateralHandler(jobs application.CollateralJobRunner) *CollateralHandler {
+	return &CollateralHandler{
+		jobs: jobs,
+	}
+}
+
+// SubmitJob accepts a CSV or JSON list of hosted domains and their IPs and
+// starts an analysis
+func (h *CollateralHandler) SubmitJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	hosted, err := collateral.ParseHosted(http.MaxBytesReader(w, r.Body, maxHostedListSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			WriteErrorResponse(w, common.CodePayloadTooLarge, "Hosted domain list is too large")
+			return
+		}
+		WriteErrorResponse(w, common.CodeInvalidArgument, err.Error())
+		return
+	}
+
+	job, err := h.jobs.Submit(hosted)
+	if err != nil {
+		WriteDomainError(w, err, "Failed to submit job")
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/collateral/jobs/"+job.ID)
+	WriteJSONResponse(w, http.StatusAccepted, newCollateralJobResponse(job))
+}
+
+// GetJob returns the state of a job, with its report once it is done. The
+// report is returned as CSV with format=csv.
+func (h *CollateralHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = collateral.FormatJSON
+	}
+	if format != collateral.FormatJSON && format != collateral.FormatCSV {
+		WriteErrorResponse(w, common.CodeInvalidArgument, "Invalid format")
+		return
+	}
+
+	job, err := h.jobs.Job(r.PathValue("id"))
+	if err != nil {
+		WriteDomainError(w, err, "Failed to get job")
+		return
+	}
+
+	if format == collateral.FormatCSV {
+		if job.Status != domain.JobStatusDone {
+			WriteErrorResponse(w, common.CodeConflict, "Job is "+job.Status.String())
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="collateral-`+job.ID+`.csv"`)
+		if err := collateral.WriteCSV(w, job.Report); err != nil {
+			slog.Error("Failed to write collateral report", "job", job.ID, "error", err)
+		}
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, newCollateralJobResponse(job))
+}
+
+func newCollateralJobResponse(job *domain.CollateralJob) CollateralJobResponse {
+	response := CollateralJobResponse{
+		ID:          job.ID,
+		Status:      job.Status.String(),
+		Domains:     job.Domains,
+		SubmittedAt: formatDate(job.SubmittedAt),
+		FinishedAt:  formatDate(job.FinishedAt),
+		Error:       job.Error,
+	}
+	if job.Report != nil {
+		response.Report = collateral.NewDocument(job.Report)
+	}
+	return response
+}
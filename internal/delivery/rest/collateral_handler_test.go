@@ -0,0 +1,146 @@
+package rest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+type mockCollateralJobs struct {
+	submitted []domain.HostedDomain
+	jobs      map[string]*domain.CollateralJob
+	err       error
+}
+
+func (m *mockCollateralJobs) Submit(hosted []domain.HostedDomain) (*domain.CollateralJob, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.submitted = hosted
+	return &domain.CollateralJob{ID: "job1", Status: domain.JobStatusPending, Domains: len(hosted), SubmittedAt: time.Now()}, nil
+}
+
+func (m *mockCollateralJobs) Job(id string) (*domain.CollateralJob, error) {
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrJobNotFound, id)
+	}
+	return job, nil
+}
+
+func TestCollateralHandler_SubmitJob(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		expectedStatus int
+		expectedCount  int
+		err            error
+	}{
+		{name: "CSV list", method: http.MethodPost, body: "domain,ip\nshop.example,203.0.113.7\n", expectedStatus: http.StatusAccepted, expectedCount: 1},
+		{name: "JSON list", method: http.MethodPost, body: `[{"domain":"a.example","ips":["203.0.113.7"]},{"domain":"b.example","ips":[]}]`, expectedStatus: http.StatusAccepted, expectedCount: 2},
+		{name: "invalid IP", method: http.MethodPost, body: "shop.example,nope\n", expectedStatus: http.StatusBadRequest},
+		{name: "GET method should return method not allowed", method: http.MethodGet, expectedStatus: http.StatusMethodNotAllowed},
+		{name: "full queue", method: http.MethodPost, body: "shop.example,203.0.113.7\n", expectedStatus: http.StatusTooManyRequests, err: domain.ErrTooManyJobs},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobs := &mockCollateralJobs{err: tt.err}
+			handler := NewCollateralHandler(jobs)
+
+			req := httptest.NewRequest(tt.method, "/api/v1/collateral/jobs", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.SubmitJob(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, but got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusAccepted {
+				return
+			}
+
+			if len(jobs.submitted) != tt.expectedCount {
+				t.Errorf("Expected %d submitted domains, got %d", tt.expectedCount, len(jobs.submitted))
+			}
+			if location := w.Header().Get("Location"); location != "/api/v1/collateral/jobs/job1" {
+				t.Errorf("Unexpected Location %q", location)
+			}
+		})
+	}
+}
+
+func TestCollateralHandler_GetJob(t *testing.T) {
+	report := &domain.CollateralReport{
+		Version: "v1",
+		Findings: []*domain.CollateralFinding{{
+			Domain: "shop.example",
+			IPs:    []string{"203.0.113.7"},
+			Status: domain.CollateralStatusCollateral,
+			Hits: []domain.CollateralHit{{
+				IP:     "203.0.113.7",
+				Record: &domain.IPRecord{RecordID: "42", Reason: domain.IPMatchDomain, Pattern: "203.0.113.7", Domains: []string{"casino.example"}},
+			}},
+		}},
+	}
+	jobs := &mockCollateralJobs{jobs: map[string]*domain.CollateralJob{
+		"done":    {ID: "done", Status: domain.JobStatusDone, Domains: 1, Report: report},
+		"running": {ID: "running", Status: domain.JobStatusRunning, Domains: 1},
+	}}
+
+	tests := []struct {
+		name           string
+		id             string
+		query          string
+		expectedStatus int
+	}{
+		{name: "finished job as JSON", id: "done", expectedStatus: http.StatusOK},
+		{name: "finished job as CSV", id: "done", query: "?format=csv", expectedStatus: http.StatusOK},
+		{name: "running job as CSV", id: "running", query: "?format=csv", expectedStatus: http.StatusConflict},
+		{name: "unknown job", id: "missing", expectedStatus: http.StatusNotFound},
+		{name: "invalid format", id: "done", query: "?format=xml", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewCollateralHandler(jobs)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/collateral/jobs/"+tt.id+tt.query, nil)
+			req.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+
+			handler.GetJob(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, but got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			if tt.query == "?format=csv" {
+				rows, err := csv.NewReader(w.Body).ReadAll()
+				if err != nil || len(rows) != 2 || rows[1][1] != "collateral" {
+					t.Errorf("Unexpected CSV report %v (%v)", rows, err)
+				}
+				return
+			}
+
+			var resp CollateralJobResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if resp.Status != "done" || resp.Report == nil || resp.Report.Summary["collateral"] != 1 {
+				t.Errorf("Unexpected job %+v", resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/application"
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/common"
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// EntriesHandler serves individual registry entries and pages of them
+type EntriesHandler struct {
+	entries application.EntryBrowser
+}
+
+func NewEntriesHandler(entries application.EntryBrowser) *EntriesHandler {
+	return &EntriesHandler{
+		entries: entries,
+	}
+}
+
+func (h *EntriesHandler) GetEntry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	entry, err := h.entries.GetEntry(r.PathValue("id"))
+	if err != nil {
+		WriteDomainError(w, err, "Failed to get registry entry")
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, newEntryResponse(entry))
+}
+
+func (h *EntriesHandler) ListEntries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	params := r.URL.Query()
+	query := domain.EntryQuery{
+		Query:  params.Get("q"),
+		Cursor: params.Get("cursor"),
+	}
+
+	if value := params.Get("type"); value != "" {
+		entryType, ok := domain.ParseBlockingType(value)
+		if !ok {
+			WriteErrorResponse(w, common.CodeInvalidArgument, "Invalid entry type")
+			return
+		}
+		query.Type = entryType
+	}
+
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			WriteErrorResponse(w, common.CodeInvalidArgument, "Invalid limit")
+			return
+		}
+		query.Limit = limit
+	}
+
+	page, err := h.entries.ListEntries(query)
+	if err != nil {
+		WriteDomainError(w, err, "Failed to list registry entries")
+		return
+	}
+
+	response := EntriesResponse{
+		Version:    page.Version,
+		Entries:    make([]EntryResponse, 0, len(page.Entries)),
+		NextCursor: page.NextCursor,
+	}
+	for _, entry := range page.Entries {
+		response.Entries = append(response.Entries, newEntryResponse(entry))
+	}
+
+	WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func newEntryResponse(entry *domain.RegistryEntry) EntryResponse {
+	return EntryResponse{
+		ID:          entry.ID,
+		Type:        entry.Type.String(),
+		Pattern:     entry.Value(),
+		Paths:       entry.Paths,
+		Decision:    entry.Decision,
+		DecisionOrg: entry.DecisionOrg,
+		AddedDate:   formatDate(entry.AddedDate),
+		BlockedDate: formatDate(entry.BlockedDate),
+	}
+}
+
+// formatDate formats a date as RFC3339, leaving unknown dates empty
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,140 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+type mockEntryBrowser struct {
+	entries   []*domain.RegistryEntry
+	lastQuery domain.EntryQuery
+}
+
+func (m *mockEntryBrowser) GetEntry(id string) (*domain.RegistryEntry, error) {
+	for _, entry := range m.entries {
+		if entry.ID == id {
+			return entry, nil
+		}
+	}
+	return nil, domain.ErrEntryNotFound
+}
+
+func (m *mockEntryBrowser) ListEntries(query domain.EntryQuery) (*domain.EntryPage, error) {
+	m.lastQuery = query
+	switch query.Cursor {
+	case "":
+		return &domain.EntryPage{Version: "v1", Entries: m.entries[:1], NextCursor: "next"}, nil
+	case "next":
+		return &domain.EntryPage{Version: "v1", Entries: m.entries[1:]}, nil
+	case "stale":
+		return nil, domain.ErrCursorExpired
+	default:
+		return nil, domain.ErrInvalidCursor
+	}
+}
+
+func newMockEntryBrowser() *mockEntryBrowser {
+	first, _ := domain.NewRegistryEntry(domain.BlockingTypeDomain, "example.com")
+	first.ID = "1-1"
+	first.Decision = "27-31-2020/Ид2071-20"
+	first.BlockedDate = time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	second, _ := domain.NewRegistryEntry(domain.BlockingTypeURLPath, "example.org/page")
+	second.ID = "2-1"
+	return &mockEntryBrowser{entries: []*domain.RegistryEntry{first, second}}
+}
+
+func TestEntriesHandler_GetEntry(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		id             string
+		expectedStatus int
+	}{
+		{name: "existing entry", method: http.MethodGet, id: "1-1", expectedStatus: http.StatusOK},
+		{name: "missing entry", method: http.MethodGet, id: "9-9", expectedStatus: http.StatusNotFound},
+		{name: "POST method should return method not allowed", method: http.MethodPost, id: "1-1", expectedStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewEntriesHandler(newMockEntryBrowser())
+
+			req := httptest.NewRequest(tt.method, "/api/v1/entries/"+tt.id, nil)
+			req.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+
+			handler.GetEntry(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, but got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resp EntryResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if resp.Pattern != "example.com" || resp.Type != "domain" || resp.BlockedDate != "2020-03-01T00:00:00Z" {
+				t.Errorf("unexpected entry: %+v", resp)
+			}
+		})
+	}
+}
+
+func TestEntriesHandler_ListEntries(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedCount  int
+		expectedNext   string
+	}{
+		{name: "first page", query: "?type=url_path&q=example&limit=1", expectedStatus: http.StatusOK, expectedCount: 1, expectedNext: "next"},
+		{name: "last page", query: "?cursor=next", expectedStatus: http.StatusOK, expectedCount: 1},
+		{name: "expired cursor", query: "?cursor=stale", expectedStatus: http.StatusGone},
+		{name: "invalid cursor", query: "?cursor=garbage", expectedStatus: http.StatusBadRequest},
+		{name: "invalid type", query: "?type=regex", expectedStatus: http.StatusBadRequest},
+		{name: "invalid limit", query: "?limit=-1", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			browser := newMockEntryBrowser()
+			handler := NewEntriesHandler(browser)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/entries"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.ListEntries(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, but got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resp EntriesResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(resp.Entries) != tt.expectedCount || resp.NextCursor != tt.expectedNext || resp.Version != "v1" {
+				t.Errorf("unexpected page: %+v", resp)
+			}
+		})
+	}
+
+	browser := newMockEntryBrowser()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/entries?type=url_path&q=example&limit=5", nil)
+	NewEntriesHandler(browser).ListEntries(httptest.NewRecorder(), req)
+	if browser.lastQuery.Type != domain.BlockingTypeURLPath || browser.lastQuery.Query != "example" || browser.lastQuery.Limit != 5 {
+		t.Errorf("unexpected query: %+v", browser.lastQuery)
+	}
+}
@@ -0,0 +1,141 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/application"
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/common"
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// DefaultHeartbeatInterval is how often an idle event stream sends a
+// heartbeat comment
+const DefaultHeartbeatInterval = 15 * time.Second
+
+// registryEventName is the SSE event name of registry update events
+const registryEventName = "registry.updated"
+
+// EventsHandler streams registry update events as server-sent events
+type EventsHandler struct {
+	feed      application.RegistryFeed
+	heartbeat time.Duration
+	stop      <-chan struct{}
+}
+
+// NewEventsHandler creates an events handler. Streams end when stop is
+// closed, so they do not hold up a graceful shutdown.
+func NewEventsHandler(feed application.RegistryFeed, heartbeat time.Duration, stop <-chan struct{}) *EventsHandler {
+	if heartbeat <= 0 {
+		heartbeat = DefaultHeartbeatInterval
+	}
+	return &EventsHandler{
+		feed:      feed,
+		heartbeat: heartbeat,
+		stop:      stop,
+	}
+}
+
+// StreamEvents sends an event each time a new registry is swapped into the
+// store. Clients resume after the version in Last-Event-ID, or in ?since=
+// on the first connection.
+func (h *EventsHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	since := r.Header.Get("Last-Event-ID")
+	if since == "" {
+		since = r.URL.Query().Get("since")
+	}
+
+	rc := http.NewResponseController(w)
+	// The stream outlives the server write timeout
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		slog.Warn("Failed to clear write deadline for event stream", "error", err)
+	}
+
+	missed, events, cancel := h.feed.Subscribe(since)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		slog.Error("Event stream cannot be flushed", "error", err)
+		return
+	}
+
+	for _, event := range missed {
+		if err := writeRegistryEvent(w, event); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.stop:
+			return
+		case event, ok := <-events:
+			if !ok {
+				// Fell too far behind; the client reconnects and resumes
+				return
+			}
+			if err := writeRegistryEvent(w, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeRegistryEvent writes one SSE frame whose ID is the registry version
+func writeRegistryEvent(w http.ResponseWriter, event *domain.RegistryEvent) error {
+	data, err := json.Marshal(newRegistryEventResponse(event))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.Version, registryEventName, data)
+	return err
+}
+
+func newRegistryEventResponse(event *domain.RegistryEvent) RegistryEventResponse {
+	response := RegistryEventResponse{
+		Version:   event.Version,
+		Size:      event.Size,
+		Counts:    make(map[string]int, len(event.Counts)),
+		AppliedAt: formatDate(event.AppliedAt),
+	}
+	for blockingType, count := range event.Counts {
+		response.Counts[blockingType.String()] = count
+	}
+	if event.Diff != nil {
+		response.Diff = &DiffSummaryResponse{
+			FromVersion: event.Diff.FromVersion,
+			Added:       event.Diff.Added,
+			Removed:     event.Diff.Removed,
+			Changed:     event.Diff.Changed,
+		}
+	}
+	return response
+}
@@ -0,0 +1,159 @@
+package rest
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/feed"
+)
+
+// sseFrame is one event read from a stream; comment frames have only a
+// comment
+type sseFrame struct {
+	id      string
+	event   string
+	data    string
+	comment string
+}
+
+// readFrame reads the next SSE frame
+func readFrame(t *testing.T, reader *bufio.Reader) sseFrame {
+	t.Helper()
+	var frame sseFrame
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading event stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			return frame
+		case strings.HasPrefix(line, ":"):
+			frame.comment = strings.TrimSpace(line[1:])
+		case strings.HasPrefix(line, "id: "):
+			frame.id = line[len("id: "):]
+		case strings.HasPrefix(line, "event: "):
+			frame.event = line[len("event: "):]
+		case strings.HasPrefix(line, "data: "):
+			frame.data = line[len("data: "):]
+		}
+	}
+}
+
+func openEventStream(t *testing.T, server *httptest.Server, query, lastEventID string) *bufio.Reader {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/v1/events"+query, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("opening event stream: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	return bufio.NewReader(resp.Body)
+}
+
+func TestEventsHandler_StreamEvents(t *testing.T) {
+	registryFeed := feed.NewFeed(0)
+	registryFeed.Publish(&domain.RegistryEvent{Version: "v1", Size: 1})
+	registryFeed.Publish(&domain.RegistryEvent{
+		Version:   "v2",
+		Size:      2,
+		Counts:    map[domain.BlockingType]int{domain.BlockingTypeDomain: 2},
+		AppliedAt: time.Now(),
+		Diff:      &domain.DiffSummary{FromVersion: "v1", Added: 1},
+	})
+
+	stop := make(chan struct{})
+	defer close(stop)
+	handler := NewEventsHandler(registryFeed, time.Hour, stop)
+	server := httptest.NewServer(http.HandlerFunc(handler.StreamEvents))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		query       string
+		lastEventID string
+	}{
+		{"Last-Event-ID", "", "v1"},
+		{"Version cursor", "?since=v1", ""},
+		{"Last-Event-ID wins over cursor", "?since=v9", "v1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := openEventStream(t, server, tt.query, tt.lastEventID)
+
+			frame := readFrame(t, reader)
+			if frame.id != "v2" || frame.event != "registry.updated" {
+				t.Fatalf("expected the missed v2 event, got %+v", frame)
+			}
+
+			var event RegistryEventResponse
+			if err := json.Unmarshal([]byte(frame.data), &event); err != nil {
+				t.Fatalf("invalid event data: %v", err)
+			}
+			if event.Size != 2 || event.Counts["domain"] != 2 || event.Diff == nil || event.Diff.Added != 1 {
+				t.Errorf("unexpected event %s", frame.data)
+			}
+		})
+	}
+}
+
+func TestEventsHandler_LiveEventsAndHeartbeat(t *testing.T) {
+	registryFeed := feed.NewFeed(0)
+	stop := make(chan struct{})
+	handler := NewEventsHandler(registryFeed, 20*time.Millisecond, stop)
+	server := httptest.NewServer(http.HandlerFunc(handler.StreamEvents))
+	defer server.Close()
+
+	reader := openEventStream(t, server, "", "")
+
+	if frame := readFrame(t, reader); frame.comment != "heartbeat" {
+		t.Fatalf("expected a heartbeat on an idle stream, got %+v", frame)
+	}
+
+	registryFeed.Publish(&domain.RegistryEvent{Version: "v3"})
+	for {
+		frame := readFrame(t, reader)
+		if frame.comment == "heartbeat" {
+			continue
+		}
+		if frame.id != "v3" {
+			t.Fatalf("expected the live v3 event, got %+v", frame)
+		}
+		break
+	}
+
+	// Closing stop ends the stream
+	close(stop)
+	for {
+		if _, err := reader.ReadString('\n'); err != nil {
+			break
+		}
+	}
+}
+
+func TestEventsHandler_MethodNotAllowed(t *testing.T) {
+	handler := NewEventsHandler(feed.NewFeed(0), 0, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/events", nil)
+	w := httptest.NewRecorder()
+	handler.StreamEvents(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/kerim-dauren/rkn-checker/internal/application"
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/common"
 	"github.com/kerim-dauren/rkn-checker/internal/domain"
 )
 
@@ -22,40 +24,46 @@ func NewHandler(blockingService application.BlockingChecker) *Handler {
 
 func (h *Handler) CheckURL(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
 	var req CheckURLRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		WriteErrorResponse(w, http.StatusBadRequest, "Invalid JSON body")
+		WriteErrorResponse(w, common.CodeInvalidArgument, "Invalid JSON body")
 		return
 	}
 
 	if strings.TrimSpace(req.URL) == "" {
-		WriteErrorResponse(w, http.StatusBadRequest, "URL is required")
+		WriteErrorResponse(w, common.CodeURLEmpty, "URL is required")
 		return
 	}
 
-	result, err := h.blockingService.CheckURL(r.Context(), req.URL)
-	if err != nil {
-		switch err {
-		case domain.ErrEmptyURL:
-			WriteErrorResponse(w, http.StatusBadRequest, "URL is empty")
-			return
-		case domain.ErrInvalidURL:
-			WriteErrorResponse(w, http.StatusBadRequest, "Invalid URL format")
-			return
-		default:
-			slog.Error("Failed to check URL", "error", err)
-			WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+	if req.At != "" && req.Version != "" {
+		WriteErrorResponse(w, common.CodeInvalidArgument, "Only one of at and version may be set")
+		return
+	}
+
+	point := domain.RegistryPoint{Version: req.Version}
+	if req.At != "" {
+		at, err := time.Parse(time.RFC3339, req.At)
+		if err != nil {
+			WriteErrorResponse(w, common.CodeInvalidArgument, "Invalid at timestamp, expected RFC3339")
 			return
 		}
+		point.At = at
+	}
+
+	result, err := h.blockingService.CheckURLAt(r.Context(), req.URL, point)
+	if err != nil {
+		WriteDomainError(w, err, "Failed to check URL")
+		return
 	}
 
 	response := CheckURLResponse{
-		Blocked:       result.IsBlocked,
-		NormalizedURL: result.NormalizedURL,
+		Blocked:         result.IsBlocked,
+		NormalizedURL:   result.NormalizedURL,
+		RegistryVersion: result.RegistryVersion,
 	}
 
 	if result.IsBlocked {
@@ -70,14 +78,14 @@ func (h *Handler) CheckURL(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
 	stats, err := h.blockingService.GetStats(r.Context())
 	if err != nil {
 		slog.Error("Failed to get stats", "error", err)
-		WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get statistics")
+		WriteErrorResponse(w, common.CodeInternal, "Failed to get statistics")
 		return
 	}
 
@@ -96,7 +104,7 @@ func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		WriteErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
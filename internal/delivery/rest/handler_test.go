@@ -4,17 +4,22 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/kerim-dauren/rkn-checker/internal/application"
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/common"
 	"github.com/kerim-dauren/rkn-checker/internal/domain"
 )
 
 type mockBlockingService struct {
-	checkURLFunc func(ctx context.Context, rawURL string) (*domain.BlockingResult, error)
-	getStatsFunc func(ctx context.Context) (*application.BlockingStats, error)
+	checkURLFunc   func(ctx context.Context, rawURL string) (*domain.BlockingResult, error)
+	checkURLAtFunc func(ctx context.Context, rawURL string, point domain.RegistryPoint) (*domain.BlockingResult, error)
+	getStatsFunc   func(ctx context.Context) (*application.BlockingStats, error)
 }
 
 func (m *mockBlockingService) CheckURL(ctx context.Context, rawURL string) (*domain.BlockingResult, error) {
@@ -24,6 +29,13 @@ func (m *mockBlockingService) CheckURL(ctx context.Context, rawURL string) (*dom
 	return domain.NewBlockingResult(false, rawURL, nil), nil
 }
 
+func (m *mockBlockingService) CheckURLAt(ctx context.Context, rawURL string, point domain.RegistryPoint) (*domain.BlockingResult, error) {
+	if m.checkURLAtFunc != nil {
+		return m.checkURLAtFunc(ctx, rawURL, point)
+	}
+	return m.CheckURL(ctx, rawURL)
+}
+
 func (m *mockBlockingService) GetStats(ctx context.Context) (*application.BlockingStats, error) {
 	if m.getStatsFunc != nil {
 		return m.getStatsFunc(ctx)
@@ -86,6 +98,45 @@ func TestHandler_CheckURL(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:            "URL checked at a past time should use the snapshot",
+			method:          http.MethodPost,
+			body:            CheckURLRequest{URL: "https://blocked.com", At: "2024-01-01T00:00:00Z"},
+			expectedStatus:  http.StatusOK,
+			expectedBlocked: func() *bool { b := true; return &b }(),
+			setup: func(m *mockBlockingService) {
+				m.checkURLAtFunc = func(ctx context.Context, rawURL string, point domain.RegistryPoint) (*domain.BlockingResult, error) {
+					if !point.At.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+						return nil, errors.New("unexpected point")
+					}
+					rule, _ := domain.NewBlockingRule(domain.BlockingTypeDomain, "blocked.com")
+					return domain.NewBlockingResult(true, "blocked.com", rule), nil
+				}
+			},
+		},
+		{
+			name:           "URL checked outside retention should return gone",
+			method:         http.MethodPost,
+			body:           CheckURLRequest{URL: "https://example.com", Version: "v0"},
+			expectedStatus: http.StatusGone,
+			setup: func(m *mockBlockingService) {
+				m.checkURLAtFunc = func(ctx context.Context, rawURL string, point domain.RegistryPoint) (*domain.BlockingResult, error) {
+					return nil, fmt.Errorf("%w: version %s", domain.ErrSnapshotNotRetained, point.Version)
+				}
+			},
+		},
+		{
+			name:           "invalid at timestamp should return bad request",
+			method:         http.MethodPost,
+			body:           CheckURLRequest{URL: "https://example.com", At: "yesterday"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "both at and version should return bad request",
+			method:         http.MethodPost,
+			body:           CheckURLRequest{URL: "https://example.com", At: "2024-01-01T00:00:00Z", Version: "v1"},
+			expectedStatus: http.StatusBadRequest,
+		},
 		{
 			name:           "invalid JSON should return bad request",
 			method:         http.MethodPost,
@@ -142,6 +193,88 @@ func TestHandler_CheckURL(t *testing.T) {
 	}
 }
 
+func TestHandler_CheckURL_Problem(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		err    error
+		code   common.Code
+		status int
+	}{
+		{
+			name:   "empty URL",
+			url:    "",
+			code:   common.CodeURLEmpty,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "wrapped invalid URL",
+			url:    "http://",
+			err:    fmt.Errorf("normalize: %w", domain.ErrInvalidURL),
+			code:   common.CodeURLInvalid,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "unsupported protocol",
+			url:    "gopher://example.com",
+			err:    fmt.Errorf("%w: gopher", domain.ErrUnsupportedProtocol),
+			code:   common.CodeProtocolUnsupported,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "registry not loaded",
+			url:    "https://example.com",
+			err:    domain.ErrRegistryNotReady,
+			code:   common.CodeRegistryNotReady,
+			status: http.StatusServiceUnavailable,
+		},
+		{
+			name:   "unclassified error",
+			url:    "https://example.com",
+			err:    errors.New("store unavailable"),
+			code:   common.CodeInternal,
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &mockBlockingService{}
+			if tt.err != nil {
+				service.checkURLFunc = func(ctx context.Context, rawURL string) (*domain.BlockingResult, error) {
+					return nil, tt.err
+				}
+			}
+			handler := NewHandler(service)
+
+			var body bytes.Buffer
+			json.NewEncoder(&body).Encode(CheckURLRequest{URL: tt.url})
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/check", &body)
+			w := httptest.NewRecorder()
+
+			handler.CheckURL(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, but got %d", tt.status, w.Code)
+			}
+			if contentType := w.Header().Get("Content-Type"); contentType != common.ProblemContentType {
+				t.Errorf("Expected content type %s, but got %s", common.ProblemContentType, contentType)
+			}
+
+			var problem common.Problem
+			if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+				t.Fatalf("Failed to decode problem: %v", err)
+			}
+			if problem.Code != tt.code || problem.Status != tt.status {
+				t.Errorf("Expected code %s with status %d, but got %+v", tt.code, tt.status, problem)
+			}
+			if tt.code == common.CodeInternal && problem.Detail == tt.err.Error() {
+				t.Errorf("Expected internal errors not to be exposed, but got %q", problem.Detail)
+			}
+		})
+	}
+}
+
 func TestHandler_GetStats(t *testing.T) {
 	tests := []struct {
 		name           string
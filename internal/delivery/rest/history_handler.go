@@ -0,0 +1,58 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/application"
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/common"
+)
+
+// HistoryHandler serves the block history of domains
+type HistoryHandler struct {
+	history application.HistoryReader
+}
+
+func NewHistoryHandler(history application.HistoryReader) *HistoryHandler {
+	return &HistoryHandler{
+		history: history,
+	}
+}
+
+func (h *HistoryHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	target := r.PathValue("domain")
+	histories, err := h.history.History(target)
+	if err != nil {
+		WriteDomainError(w, err, "Failed to get history")
+		return
+	}
+
+	response := HistoryResponse{
+		Target:   target,
+		Patterns: make([]PatternHistoryResponse, 0, len(histories)),
+	}
+	for _, history := range histories {
+		pattern := PatternHistoryResponse{
+			Pattern: history.Pattern,
+			Blocked: history.Blocked(),
+			Events:  make([]HistoryEventResponse, 0, len(history.Events)),
+		}
+		for _, event := range history.Events {
+			pattern.Events = append(pattern.Events, HistoryEventResponse{
+				Kind:    event.Kind.String(),
+				Type:    event.Type.String(),
+				Version: event.Version,
+				At:      event.At.Format(time.RFC3339),
+			})
+		}
+		response.Blocked = response.Blocked || pattern.Blocked
+		response.Patterns = append(response.Patterns, pattern)
+	}
+
+	WriteJSONResponse(w, http.StatusOK, response)
+}
@@ -0,0 +1,88 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+type mockHistoryReader struct {
+	histories map[string][]*domain.PatternHistory
+}
+
+func (m *mockHistoryReader) History(target string) ([]*domain.PatternHistory, error) {
+	if target == "" {
+		return nil, domain.ErrInvalidDomain
+	}
+	return m.histories[target], nil
+}
+
+func TestHistoryHandler_GetHistory(t *testing.T) {
+	at := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	reader := &mockHistoryReader{
+		histories: map[string][]*domain.PatternHistory{
+			"sub.example.com": {
+				{
+					Pattern: "*.example.com",
+					Events: []domain.HistoryEvent{
+						{Kind: domain.ChangeKindAdded, Type: domain.BlockingTypeWildcard, Version: "v1", At: at},
+						{Kind: domain.ChangeKindRemoved, Type: domain.BlockingTypeWildcard, Version: "v2", At: at.Add(time.Hour)},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		method          string
+		domain          string
+		expectedStatus  int
+		expectedPattern int
+	}{
+		{name: "domain with history", method: http.MethodGet, domain: "sub.example.com", expectedStatus: http.StatusOK, expectedPattern: 1},
+		{name: "domain without history", method: http.MethodGet, domain: "other.com", expectedStatus: http.StatusOK},
+		{name: "empty domain", method: http.MethodGet, expectedStatus: http.StatusBadRequest},
+		{name: "POST method should return method not allowed", method: http.MethodPost, domain: "other.com", expectedStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHistoryHandler(reader)
+
+			req := httptest.NewRequest(tt.method, "/api/v1/history/"+tt.domain, nil)
+			req.SetPathValue("domain", tt.domain)
+			w := httptest.NewRecorder()
+
+			handler.GetHistory(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, but got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resp HistoryResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(resp.Patterns) != tt.expectedPattern {
+				t.Fatalf("expected %d patterns, got %d", tt.expectedPattern, len(resp.Patterns))
+			}
+			if resp.Blocked {
+				t.Error("expected domain not to be blocked")
+			}
+			if tt.expectedPattern == 1 {
+				events := resp.Patterns[0].Events
+				if len(events) != 2 || events[1].Kind != "removed" || events[1].At != "2025-01-01T01:00:00Z" {
+					t.Errorf("unexpected events: %+v", events)
+				}
+			}
+		})
+	}
+}
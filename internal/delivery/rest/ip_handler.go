@@ -0,0 +1,63 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/kerim-dauren/rkn-checker/internal/application"
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/common"
+)
+
+// IPHandler serves reverse lookups from an IP address to registry records
+type IPHandler struct {
+	ipLookup application.IPLookup
+}
+
+func NewIPHandler(ipLookup application.IPLookup) *IPHandler {
+	return &IPHandler{
+		ipLookup: ipLookup,
+	}
+}
+
+func (h *IPHandler) LookupIP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	addr := r.PathValue("addr")
+	result, err := h.ipLookup.LookupIP(addr)
+	if err != nil {
+		WriteDomainError(w, err, "Failed to look up IP")
+		return
+	}
+
+	response := IPLookupResponse{
+		IP:      result.IP,
+		Version: result.Version,
+		Listed:  result.Listed(),
+		Records: make([]IPRecordResponse, 0, len(result.Records)),
+	}
+	for _, record := range result.Records {
+		response.Records = append(response.Records, IPRecordResponse{
+			RecordID:    record.RecordID,
+			Reason:      record.Reason.String(),
+			Pattern:     record.Pattern,
+			IPs:         nonNil(record.IPs),
+			Domains:     nonNil(record.Domains),
+			URLs:        nonNil(record.URLs),
+			Decision:    record.Decision,
+			DecisionOrg: record.DecisionOrg,
+			BlockedDate: formatDate(record.BlockedDate),
+		})
+	}
+
+	WriteJSONResponse(w, http.StatusOK, response)
+}
+
+// nonNil keeps empty lists from being encoded as null
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,73 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+type mockIPLookup struct{}
+
+func (m *mockIPLookup) LookupIP(addr string) (*domain.IPLookupResult, error) {
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidIP, addr)
+	}
+	result := &domain.IPLookupResult{IP: ip.String(), Version: "v1"}
+	if addr == "1.1.1.1" {
+		result.Records = []*domain.IPRecord{
+			{RecordID: "7", Reason: domain.IPMatchDomain, Pattern: "1.1.1.1", IPs: []string{"1.1.1.1"}, Domains: []string{"example.com"}},
+		}
+	}
+	return result, nil
+}
+
+func TestIPHandler_LookupIP(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		addr           string
+		expectedStatus int
+		expectedListed bool
+	}{
+		{name: "listed IP", method: http.MethodGet, addr: "1.1.1.1", expectedStatus: http.StatusOK, expectedListed: true},
+		{name: "unlisted IP", method: http.MethodGet, addr: "8.8.8.8", expectedStatus: http.StatusOK},
+		{name: "invalid IP", method: http.MethodGet, addr: "example.com", expectedStatus: http.StatusBadRequest},
+		{name: "POST method should return method not allowed", method: http.MethodPost, addr: "1.1.1.1", expectedStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewIPHandler(&mockIPLookup{})
+
+			req := httptest.NewRequest(tt.method, "/api/v1/ip/"+tt.addr, nil)
+			req.SetPathValue("addr", tt.addr)
+			w := httptest.NewRecorder()
+
+			handler.LookupIP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, but got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resp IPLookupResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if resp.Listed != tt.expectedListed || len(resp.Records) > 0 != tt.expectedListed {
+				t.Errorf("Expected listed=%t, got %+v", tt.expectedListed, resp)
+			}
+			if tt.expectedListed && (resp.Records[0].Reason != "domain" || resp.Records[0].URLs == nil) {
+				t.Errorf("Unexpected record %+v", resp.Records[0])
+			}
+		})
+	}
+}
@@ -1,9 +1,26 @@
 package rest
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"math"
+	"net"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kerim-dauren/rkn-checker/internal/application"
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/common"
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/metrics"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/proxy"
 )
 
 func LoggingMiddleware(next http.Handler) http.Handler {
@@ -14,6 +31,7 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(wrapper, r)
 
 		duration := time.Since(start)
+		client := clientAttr(r)
 
 		if wrapper.statusCode >= 400 {
 			slog.Warn("HTTP request failed",
@@ -22,7 +40,8 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 				"status", wrapper.statusCode,
 				"duration", duration.String(),
 				"user_agent", r.UserAgent(),
-				"remote_ip", getRemoteIP(r))
+				"remote_ip", getRemoteIP(r),
+				client)
 		} else {
 			slog.Info("HTTP request completed",
 				"method", r.Method,
@@ -30,16 +49,137 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 				"status", wrapper.statusCode,
 				"duration", duration.String(),
 				"user_agent", r.UserAgent(),
-				"remote_ip", getRemoteIP(r))
+				"remote_ip", getRemoteIP(r),
+				client)
+		}
+	})
+}
+
+// routeKey is the context key of the route a request matched
+type routeKey struct{}
+
+// matchedRoute carries the pattern the mux matched back out to the
+// middleware around it. The mux records the pattern on the request it
+// receives, which is a copy of theirs once a middleware in between, such as
+// authentication, derived a new context.
+type matchedRoute struct {
+	pattern string
+}
+
+// withRoute returns r carrying a matchedRoute, reusing the one an outer
+// middleware added
+func withRoute(r *http.Request) (*http.Request, *matchedRoute) {
+	if route, ok := r.Context().Value(routeKey{}).(*matchedRoute); ok {
+		return r, route
+	}
+	route := &matchedRoute{}
+	return r.WithContext(context.WithValue(r.Context(), routeKey{}, route)), route
+}
+
+// routeOf returns the pattern r matched, as recorded by RouteMiddleware or
+// by the mux on r itself
+func routeOf(r *http.Request, route *matchedRoute) string {
+	if route.pattern != "" {
+		return route.pattern
+	}
+	return r.Pattern
+}
+
+// RouteMiddleware records the pattern mux matched for the metrics and
+// tracing middleware around it. It must wrap the mux directly.
+func RouteMiddleware(mux http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r)
+		if route, ok := r.Context().Value(routeKey{}).(*matchedRoute); ok {
+			route.pattern = r.Pattern
 		}
 	})
 }
 
-func CORSMiddleware(next http.Handler) http.Handler {
+// MetricsMiddleware counts requests and observes their latency by the route
+// pattern they matched, keeping path parameters out of the labels
+func MetricsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		start := time.Now()
+		r, matched := withRoute(r)
+
+		wrapper := &responseWriter{ResponseWriter: w, statusCode: 200}
+		next.ServeHTTP(wrapper, r)
+
+		route := routeOf(r, matched)
+		if route == "" {
+			route = "unmatched"
+		}
+
+		metrics.HTTPRequests.WithLabelValues(route, r.Method, strconv.Itoa(wrapper.statusCode)).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+	})
+}
+
+// untracedPaths are probe and scrape endpoints kept out of traces
+var untracedPaths = map[string]bool{
+	"/health":  true,
+	"/livez":   true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// TracingMiddleware continues the W3C trace context of incoming requests and
+// records each request as a server span named after the route it matched
+func TracingMiddleware(next http.Handler) http.Handler {
+	// The route is only known once the mux has matched the request. It is
+	// copied onto the request of the tracing handler, which renames the span
+	// after it.
+	routed := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inner, matched := withRoute(r)
+		next.ServeHTTP(w, inner)
+		if route := routeOf(inner, matched); route != "" {
+			r.Pattern = route
+			trace.SpanFromContext(r.Context()).SetAttributes(semconv.HTTPRoute(route))
+		}
+	})
+
+	return otelhttp.NewHandler(routed, "rest",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			if r.Pattern == "" {
+				return r.Method
+			}
+			return r.Method + " " + r.Pattern
+		}),
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			return !untracedPaths[r.URL.Path]
+		}))
+}
+
+// ClientIPMiddleware replaces the remote address of requests received from
+// the trusted proxies of resolver with the client address they forwarded
+func ClientIPMiddleware(resolver *proxy.Resolver, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if peer := proxy.ParseHost(r.RemoteAddr); peer.IsValid() {
+			client := resolver.ClientIP(peer, r.Header.Values(resolver.Header()))
+			if client != peer {
+				r.RemoteAddr = client.String()
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CORSMiddleware allows cross-origin requests from the allowed origins, or
+// from any origin when none are listed
+func CORSMiddleware(allowedOrigins []string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(allowedOrigins) == 0 {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Add("Vary", "Origin")
+			if origin := r.Header.Get("Origin"); slices.Contains(allowedOrigins, origin) {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+		}
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, Last-Event-ID")
 
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusOK)
@@ -50,6 +190,154 @@ func CORSMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// AuthenticationMiddleware resolves the caller of every request and carries
+// its identity in the request context. Requests presenting credentials that
+// are not accepted are rejected; callers without credentials are anonymous.
+func AuthenticationMiddleware(authenticator application.Authenticator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, err := authenticate(authenticator, r)
+		if err != nil {
+			slog.Warn("Rejected credentials",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"remote_ip", getRemoteIP(r),
+				"error", err)
+
+			writeUnauthorized(w, "Invalid credentials")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(domain.ContextWithIdentity(r.Context(), identity)))
+	})
+}
+
+// authenticate resolves the caller from a bearer token or API key header,
+// then from a verified client certificate
+func authenticate(authenticator application.Authenticator, r *http.Request) (*domain.Identity, error) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return authenticator.AuthenticateToken(key)
+	}
+	if authorization := r.Header.Get("Authorization"); authorization != "" {
+		token, ok := strings.CutPrefix(authorization, "Bearer ")
+		if !ok {
+			return nil, errors.New("unsupported authorization scheme")
+		}
+		return authenticator.AuthenticateToken(token)
+	}
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		return authenticator.AuthenticateCertificate(r.TLS.VerifiedChains[0][0])
+	}
+	return authenticator.Anonymous(), nil
+}
+
+// RequireScope rejects callers that were not granted scope: anonymous
+// callers as unauthenticated, authenticated ones as forbidden
+func RequireScope(scope domain.Scope, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity := domain.IdentityFromContext(r.Context())
+		switch {
+		case identity != nil && identity.HasScope(scope):
+			next.ServeHTTP(w, r)
+		case identity == nil || identity.IsAnonymous():
+			writeUnauthorized(w, "Valid credentials required")
+		default:
+			slog.Warn("Forbidden request",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"client", identity.String(),
+				"scope", scope)
+
+			WriteErrorResponse(w, common.CodePermissionDenied, "Missing scope "+string(scope))
+		}
+	})
+}
+
+// FailedAuthenticationLimitMiddleware charges the requests whose credentials
+// are rejected to the address of their client under
+// domain.FailedAuthenticationRoute, and turns away the requests presenting
+// credentials from an address over that rule before they are checked, so
+// credentials cannot be guessed faster than the rule allows
+func FailedAuthenticationLimitMiddleware(limiter application.RateLimiter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !presentsCredentials(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		client := rateLimitClient(r)
+		if decision := limiter.Peek(client, domain.FailedAuthenticationRoute); !decision.Allowed {
+			writeRateLimited(w, decision)
+			return
+		}
+
+		wrapper := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(wrapper, r)
+		if wrapper.statusCode == http.StatusUnauthorized {
+			limiter.Allow(client, domain.FailedAuthenticationRoute)
+		}
+	})
+}
+
+// presentsCredentials reports whether r carries credentials for
+// authenticate to check
+func presentsCredentials(r *http.Request) bool {
+	return r.Header.Get("X-API-Key") != "" || r.Header.Get("Authorization") != "" ||
+		(r.TLS != nil && len(r.TLS.VerifiedChains) > 0)
+}
+
+// RateLimitMiddleware charges the calls to route to the identity of their
+// caller, or to the address of anonymous callers, rejecting them with 429
+// and the time to wait in Retry-After once over its rate or daily quota.
+// Every key thus has buckets of its own, even when keys share an address.
+func RateLimitMiddleware(limiter application.RateLimiter, route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if decision := limiter.Allow(rateLimitClient(r), route); !decision.Allowed {
+			writeRateLimited(w, decision)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeRateLimited rejects a call over its rate or daily quota
+func writeRateLimited(w http.ResponseWriter, decision domain.RateLimitDecision) {
+	retryAfter := strconv.Itoa(retryAfterSeconds(decision.RetryAfter))
+	w.Header().Set("Retry-After", retryAfter)
+	code := common.CodeRateLimited
+	if decision.QuotaExceeded {
+		code = common.CodeQuotaExceeded
+	}
+	WriteErrorResponse(w, code, "Retry after "+retryAfter+" seconds")
+}
+
+// rateLimitClient identifies the client of r for rate limiting by its
+// identity, or by its IP address when it is anonymous
+func rateLimitClient(r *http.Request) string {
+	if identity := domain.IdentityFromContext(r.Context()); identity != nil && !identity.IsAnonymous() {
+		return identity.String()
+	}
+	return "ip:" + getRemoteIP(r)
+}
+
+// retryAfterSeconds rounds wait up to whole seconds, as Retry-After expects
+func retryAfterSeconds(wait time.Duration) int {
+	return max(1, int(math.Ceil(wait.Seconds())))
+}
+
+func writeUnauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="rkn-checker"`)
+	WriteErrorResponse(w, common.CodeUnauthenticated, message)
+}
+
+// clientAttr identifies the caller of r in request logs
+func clientAttr(r *http.Request) slog.Attr {
+	if identity := domain.IdentityFromContext(r.Context()); identity != nil {
+		return slog.String("client", identity.String())
+	}
+	return slog.String("client", string(domain.AuthMethodAnonymous))
+}
+
 func RecoveryMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
@@ -59,7 +347,7 @@ func RecoveryMiddleware(next http.Handler) http.Handler {
 					"path", r.URL.Path,
 					"panic", err)
 
-				WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+				WriteErrorResponse(w, common.CodeInternal, "Internal server error")
 			}
 		}()
 
@@ -77,16 +365,18 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
-func getRemoteIP(r *http.Request) string {
-	forwarded := r.Header.Get("X-Forwarded-For")
-	if forwarded != "" {
-		return forwarded
-	}
+// Unwrap lets http.ResponseController reach the underlying writer
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
 
-	realIP := r.Header.Get("X-Real-IP")
-	if realIP != "" {
-		return realIP
+// getRemoteIP is the client address of r, as resolved by ClientIPMiddleware
+// when it came through a trusted proxy. Forwarding headers are never read
+// here, as any client can send them.
+func getRemoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
 	}
-
-	return r.RemoteAddr
+	return host
 }
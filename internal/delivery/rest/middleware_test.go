@@ -0,0 +1,398 @@
+package rest
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/common"
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/metrics"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/proxy"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/ratelimit"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/tracing/tracingtest"
+)
+
+func TestMetricsMiddleware_LabelsByRoutePattern(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/history/{domain}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	handler := MetricsMiddleware(mux)
+
+	matched := metrics.HTTPRequests.WithLabelValues("/api/v1/history/{domain}", http.MethodGet, "404")
+	unmatched := metrics.HTTPRequests.WithLabelValues("unmatched", http.MethodGet, "404")
+	matchedBefore, unmatchedBefore := testutil.ToFloat64(matched), testutil.ToFloat64(unmatched)
+
+	for _, path := range []string{"/api/v1/history/example.com", "/api/v1/history/example.org", "/nope"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if got := testutil.ToFloat64(matched) - matchedBefore; got != 2 {
+		t.Errorf("expected both history requests under the route pattern, got %v", got)
+	}
+	if got := testutil.ToFloat64(unmatched) - unmatchedBefore; got != 1 {
+		t.Errorf("expected one unmatched request, got %v", got)
+	}
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	exporter := tracingtest.Exporter(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/history/{domain}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/livez", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := TracingMiddleware(MetricsMiddleware(mux))
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/history/example.com", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/livez", nil))
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected a single span with probes untraced, got %d", len(spans))
+	}
+
+	span := spans[0]
+	if span.Name != "GET /api/v1/history/{domain}" {
+		t.Errorf("expected the span named after the route, got %q", span.Name)
+	}
+	if span.SpanContext.TraceID().String() != traceID || !span.Parent.IsRemote() {
+		t.Error("expected the span to continue the incoming trace")
+	}
+	attrs := attribute.NewSet(span.Attributes...)
+	if v, _ := attrs.Value("http.route"); v.AsString() != "/api/v1/history/{domain}" {
+		t.Errorf("expected the http.route attribute, got %q", v.AsString())
+	}
+}
+
+func TestServer_RouteSeenThroughAuthentication(t *testing.T) {
+	exporter := tracingtest.Exporter(t)
+	authenticator := &stubAuthenticator{tokens: map[string]*domain.Identity{
+		"registry-key": {Method: domain.AuthMethodAPIKey, Subject: "browser", Scopes: []domain.Scope{domain.ScopeRegistry}},
+	}}
+	s := NewServer(&mockBlockingService{}, 0,
+		WithAuthenticator(authenticator),
+		WithRateLimiter(&stubRateLimiter{}),
+		WithHistory(&mockHistoryReader{}))
+	handler := s.handler(make(chan struct{}))
+
+	matched := metrics.HTTPRequests.WithLabelValues("/api/v1/history/{domain}", http.MethodGet, "200")
+	before := testutil.ToFloat64(matched)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/history/example.com", nil)
+	req.Header.Set("X-API-Key", "registry-key")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	if got := testutil.ToFloat64(matched) - before; got != 1 {
+		t.Errorf("expected the request counted under its route pattern, got %v", got)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected a single span, got %d", len(spans))
+	}
+	if spans[0].Name != "GET /api/v1/history/{domain}" {
+		t.Errorf("expected the span named after the route, got %q", spans[0].Name)
+	}
+	attrs := attribute.NewSet(spans[0].Attributes...)
+	if v, _ := attrs.Value("http.route"); v.AsString() != "/api/v1/history/{domain}" {
+		t.Errorf("expected the http.route attribute, got %q", v.AsString())
+	}
+}
+
+// stubAuthenticator accepts the tokens it maps to identities and grants
+// client certificates the check scope
+type stubAuthenticator struct {
+	tokens    map[string]*domain.Identity
+	anonymous []domain.Scope
+}
+
+func (a *stubAuthenticator) AuthenticateToken(token string) (*domain.Identity, error) {
+	if identity, ok := a.tokens[token]; ok {
+		return identity, nil
+	}
+	return nil, domain.ErrInvalidCredentials
+}
+
+func (a *stubAuthenticator) AuthenticateCertificate(cert *x509.Certificate) (*domain.Identity, error) {
+	return &domain.Identity{Method: domain.AuthMethodCertificate, Subject: cert.Subject.CommonName, Scopes: []domain.Scope{domain.ScopeCheck}}, nil
+}
+
+func (a *stubAuthenticator) Anonymous() *domain.Identity {
+	return &domain.Identity{Method: domain.AuthMethodAnonymous, Scopes: a.anonymous}
+}
+
+func TestAuthenticationMiddleware_RequireScope(t *testing.T) {
+	authenticator := &stubAuthenticator{
+		tokens: map[string]*domain.Identity{
+			"checker-key": {Method: domain.AuthMethodAPIKey, Subject: "checker", Scopes: []domain.Scope{domain.ScopeCheck}},
+			"admin-token": {Method: domain.AuthMethodAdminToken, Subject: "admin", Scopes: []domain.Scope{domain.ScopeAdmin}},
+		},
+		anonymous: []domain.Scope{domain.ScopeStats},
+	}
+
+	var client string
+	mux := http.NewServeMux()
+	record := func(w http.ResponseWriter, r *http.Request) {
+		client = domain.IdentityFromContext(r.Context()).String()
+		w.WriteHeader(http.StatusNoContent)
+	}
+	mux.Handle("/api/v1/check", RequireScope(domain.ScopeCheck, http.HandlerFunc(record)))
+	mux.Handle("/api/v1/stats", RequireScope(domain.ScopeStats, http.HandlerFunc(record)))
+	mux.Handle("/admin/v1/scheduler", RequireScope(domain.ScopeAdmin, http.HandlerFunc(record)))
+	handler := AuthenticationMiddleware(authenticator, mux)
+
+	verifiedCert := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{
+		{Subject: pkix.Name{CommonName: "billing"}},
+	}}}
+
+	tests := []struct {
+		name           string
+		path           string
+		header         string
+		value          string
+		tls            *tls.ConnectionState
+		expectedStatus int
+		expectedClient string
+	}{
+		{"Bearer API key", "/api/v1/check", "Authorization", "Bearer checker-key", nil, http.StatusNoContent, "api_key:checker"},
+		{"API key header", "/api/v1/check", "X-API-Key", "checker-key", nil, http.StatusNoContent, "api_key:checker"},
+		{"Missing scope", "/admin/v1/scheduler", "X-API-Key", "checker-key", nil, http.StatusForbidden, ""},
+		{"Admin scope grants every scope", "/api/v1/check", "Authorization", "Bearer admin-token", nil, http.StatusNoContent, "admin_token:admin"},
+		{"Invalid key", "/api/v1/stats", "X-API-Key", "unknown", nil, http.StatusUnauthorized, ""},
+		{"Unsupported scheme", "/api/v1/check", "Authorization", "Basic checker-key", nil, http.StatusUnauthorized, ""},
+		{"Anonymous scope", "/api/v1/stats", "", "", nil, http.StatusNoContent, "anonymous"},
+		{"Anonymous without scope", "/api/v1/check", "", "", nil, http.StatusUnauthorized, ""},
+		{"Client certificate", "/api/v1/check", "", "", verifiedCert, http.StatusNoContent, "client_cert:billing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client = ""
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			req.TLS = tt.tls
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected a WWW-Authenticate challenge")
+			}
+			if client != tt.expectedClient {
+				t.Errorf("expected client %q, got %q", tt.expectedClient, client)
+			}
+		})
+	}
+}
+
+func TestCORSMiddleware_AllowedOrigins(t *testing.T) {
+	handler := CORSMiddleware([]string{"https://app.example.com"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for origin, expected := range map[string]string{
+		"https://app.example.com":  "https://app.example.com",
+		"https://evil.example.com": "",
+	} {
+		req := httptest.NewRequest(http.MethodOptions, "/api/v1/check", nil)
+		req.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != expected {
+			t.Errorf("origin %s: expected allowed origin %q, got %q", origin, expected, got)
+		}
+	}
+}
+
+// stubRateLimiter rejects the clients and routes it maps to a decision and
+// records the clients and routes charged
+type stubRateLimiter struct {
+	rejected map[string]domain.RateLimitDecision
+	charged  []string
+}
+
+func (l *stubRateLimiter) Allow(client, route string) domain.RateLimitDecision {
+	decision := l.Peek(client, route)
+	if decision.Allowed {
+		l.charged = append(l.charged, client+" "+route)
+	}
+	return decision
+}
+
+func (l *stubRateLimiter) Peek(client, route string) domain.RateLimitDecision {
+	if decision, ok := l.rejected[client+" "+route]; ok {
+		return decision
+	}
+	return domain.RateLimitDecision{Allowed: true}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	authenticator := &stubAuthenticator{
+		tokens: map[string]*domain.Identity{
+			"ci-key": {Method: domain.AuthMethodAPIKey, Subject: "ci", Scopes: []domain.Scope{domain.ScopeCheck}},
+		},
+		anonymous: []domain.Scope{domain.ScopeCheck},
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+
+	tests := []struct {
+		name               string
+		route              string
+		remoteAddr         string
+		apiKey             string
+		expectedStatus     int
+		expectedRetryAfter string
+		expectedCode       common.Code
+		expectedCharged    []string
+	}{
+		{"Allowed", "/api/v1/check", "10.0.0.9:53211", "", http.StatusNoContent, "", "", []string{"ip:10.0.0.9 /api/v1/check"}},
+		{"Rate by IP", "/api/v1/check", "10.0.0.1:53211", "", http.StatusTooManyRequests, "2", common.CodeRateLimited, nil},
+		{"Rounded up to a second", "/api/v1/short-one", "10.0.0.1:53211", "", http.StatusTooManyRequests, "1", common.CodeRateLimited, nil},
+		{"Key not charged to its IP", "/api/v1/check", "10.0.0.1:53211", "ci-key", http.StatusNoContent, "", "",
+			[]string{"api_key:ci /api/v1/check"}},
+		{"Quota by identity", "/api/v1/short-one", "10.0.0.9:53211", "ci-key", http.StatusTooManyRequests, "7200", common.CodeQuotaExceeded, nil},
+		{"Failed authentication charged to IP", "/api/v1/check", "10.0.0.9:53211", "guessed-key", http.StatusUnauthorized, "", common.CodeUnauthenticated,
+			[]string{"ip:10.0.0.9 failed_authentication"}},
+		{"Too many failed authentications", "/api/v1/check", "10.0.0.2:53211", "ci-key", http.StatusTooManyRequests, "30", common.CodeRateLimited, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := &stubRateLimiter{rejected: map[string]domain.RateLimitDecision{
+				"ip:10.0.0.1 /api/v1/check":         {RetryAfter: 1500 * time.Millisecond},
+				"ip:10.0.0.1 /api/v1/short-one":     {RetryAfter: time.Millisecond},
+				"api_key:ci /api/v1/short-one":      {RetryAfter: 2 * time.Hour, QuotaExceeded: true},
+				"ip:10.0.0.2 failed_authentication": {RetryAfter: 30 * time.Second},
+			}}
+			handler := FailedAuthenticationLimitMiddleware(limiter,
+				AuthenticationMiddleware(authenticator, RateLimitMiddleware(limiter, tt.route, ok)))
+
+			req := httptest.NewRequest(http.MethodGet, tt.route, nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.expectedRetryAfter {
+				t.Errorf("expected Retry-After %q, got %q", tt.expectedRetryAfter, got)
+			}
+			if tt.expectedCode != "" {
+				var problem common.Problem
+				if err := json.NewDecoder(w.Body).Decode(&problem); err != nil || problem.Code != tt.expectedCode {
+					t.Errorf("expected code %s, got %s", tt.expectedCode, w.Body.String())
+				}
+			}
+			if !slices.Equal(limiter.charged, tt.expectedCharged) {
+				t.Errorf("expected %v charged, got %v", tt.expectedCharged, limiter.charged)
+			}
+		})
+	}
+}
+
+func TestServer_KeysBehindOneAddress(t *testing.T) {
+	limiter, err := ratelimit.NewLimiter(ratelimit.Limits{Default: ratelimit.Rule{Rate: 0.001, Burst: 1}}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator := &stubAuthenticator{tokens: map[string]*domain.Identity{
+		"billing-key": {Method: domain.AuthMethodAPIKey, Subject: "billing", Scopes: []domain.Scope{domain.ScopeStats}},
+		"support-key": {Method: domain.AuthMethodAPIKey, Subject: "support", Scopes: []domain.Scope{domain.ScopeStats}},
+	}}
+	handler := NewServer(&mockBlockingService{}, 0,
+		WithAuthenticator(authenticator),
+		WithRateLimiter(limiter)).handler(make(chan struct{}))
+
+	calls := []struct {
+		apiKey         string
+		expectedStatus int
+	}{
+		{"billing-key", http.StatusOK},
+		{"support-key", http.StatusOK},
+		{"billing-key", http.StatusTooManyRequests},
+		{"guessed-key", http.StatusUnauthorized},
+		{"support-key", http.StatusTooManyRequests},
+		{"guessed-key", http.StatusTooManyRequests},
+	}
+	for i, call := range calls {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/stats", nil)
+		req.RemoteAddr = "192.0.2.7:40000"
+		req.Header.Set("X-API-Key", call.apiKey)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != call.expectedStatus {
+			t.Errorf("call %d with %s: expected status %d, got %d", i, call.apiKey, call.expectedStatus, w.Code)
+		}
+	}
+}
+
+func TestClientIPMiddleware(t *testing.T) {
+	resolver, err := proxy.NewResolver([]string{"10.0.0.0/8"}, proxy.HeaderXForwardedFor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var remoteIP, client string
+	handler := ClientIPMiddleware(resolver, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remoteIP = getRemoteIP(r)
+		client = rateLimitClient(r)
+	}))
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		expected   string
+	}{
+		{"Trusted proxy", "10.0.0.2:41000", "1.1.1.1, 198.51.100.1", "198.51.100.1"},
+		{"Untrusted peer spoofing the header", "203.0.113.9:41000", "198.51.100.1", "203.0.113.9"},
+		{"Trusted proxy without header", "10.0.0.2:41000", "", "10.0.0.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/check", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if remoteIP != tt.expected {
+				t.Errorf("expected remote IP %s, got %s", tt.expected, remoteIP)
+			}
+			if client != "ip:"+tt.expected {
+				t.Errorf("expected rate limit client ip:%s, got %s", tt.expected, client)
+			}
+		})
+	}
+}
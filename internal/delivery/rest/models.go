@@ -2,18 +2,26 @@ package rest
 
 import (
 	"encoding/json"
+	"log/slog"
 	"net/http"
+
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/common"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/collateral"
 )
 
 type CheckURLRequest struct {
 	URL string `json:"url"`
+	// At (RFC3339) or Version answer the check from a retained snapshot
+	At      string `json:"at,omitempty"`
+	Version string `json:"version,omitempty"`
 }
 
 type CheckURLResponse struct {
-	Blocked       bool   `json:"blocked"`
-	NormalizedURL string `json:"normalized_url"`
-	Reason        string `json:"reason,omitempty"`
-	Match         string `json:"match,omitempty"`
+	Blocked         bool   `json:"blocked"`
+	NormalizedURL   string `json:"normalized_url"`
+	Reason          string `json:"reason,omitempty"`
+	Match           string `json:"match,omitempty"`
+	RegistryVersion string `json:"registry_version,omitempty"`
 }
 
 type StatsResponse struct {
@@ -31,12 +39,258 @@ type HealthResponse struct {
 	Message string `json:"message"`
 }
 
-type ErrorResponse struct {
-	Error   string `json:"error"`
-	Code    int    `json:"code"`
+type ProbeResponse struct {
+	Status string               `json:"status"`
+	Checks []ProbeCheckResponse `json:"checks,omitempty"`
+}
+
+type ProbeCheckResponse struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
 	Message string `json:"message"`
 }
 
+type ChangesResponse struct {
+	CurrentVersion string              `json:"current_version"`
+	Changelogs     []ChangelogResponse `json:"changelogs"`
+}
+
+type ChangelogResponse struct {
+	FromVersion string                `json:"from_version"`
+	ToVersion   string                `json:"to_version"`
+	AppliedAt   string                `json:"applied_at"`
+	Added       int                   `json:"added"`
+	Removed     int                   `json:"removed"`
+	Changed     int                   `json:"changed"`
+	Changes     []EntryChangeResponse `json:"changes"`
+}
+
+type EntryChangeResponse struct {
+	Kind                string `json:"kind"`
+	Value               string `json:"value"`
+	Type                string `json:"type"`
+	Decision            string `json:"decision,omitempty"`
+	DecisionOrg         string `json:"decision_org,omitempty"`
+	PreviousType        string `json:"previous_type,omitempty"`
+	PreviousDecision    string `json:"previous_decision,omitempty"`
+	PreviousDecisionOrg string `json:"previous_decision_org,omitempty"`
+}
+
+type HistoryResponse struct {
+	Target   string                   `json:"target"`
+	Blocked  bool                     `json:"blocked"`
+	Patterns []PatternHistoryResponse `json:"patterns"`
+}
+
+type PatternHistoryResponse struct {
+	Pattern string                 `json:"pattern"`
+	Blocked bool                   `json:"blocked"`
+	Events  []HistoryEventResponse `json:"events"`
+}
+
+type HistoryEventResponse struct {
+	Kind    string `json:"kind"`
+	Type    string `json:"type"`
+	Version string `json:"version"`
+	At      string `json:"at"`
+}
+
+type EntryResponse struct {
+	ID          string   `json:"id"`
+	Type        string   `json:"type"`
+	Pattern     string   `json:"pattern"`
+	Paths       []string `json:"paths,omitempty"`
+	Decision    string   `json:"decision,omitempty"`
+	DecisionOrg string   `json:"decision_org,omitempty"`
+	AddedDate   string   `json:"added_date,omitempty"`
+	BlockedDate string   `json:"blocked_date,omitempty"`
+}
+
+type EntriesResponse struct {
+	Version    string          `json:"version"`
+	Entries    []EntryResponse `json:"entries"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+type SearchResponse struct {
+	Version   string          `json:"version"`
+	Entries   []EntryResponse `json:"entries"`
+	Truncated bool            `json:"truncated"`
+	TimedOut  bool            `json:"timed_out"`
+}
+
+type IPLookupResponse struct {
+	IP      string             `json:"ip"`
+	Version string             `json:"version"`
+	Listed  bool               `json:"listed"`
+	Records []IPRecordResponse `json:"records"`
+}
+
+type IPRecordResponse struct {
+	RecordID    string   `json:"record_id"`
+	Reason      string   `json:"reason"`
+	Pattern     string   `json:"pattern"`
+	IPs         []string `json:"ips"`
+	Domains     []string `json:"domains"`
+	URLs        []string `json:"urls"`
+	Decision    string   `json:"decision,omitempty"`
+	DecisionOrg string   `json:"decision_org,omitempty"`
+	BlockedDate string   `json:"blocked_date,omitempty"`
+}
+
+type CollateralJobResponse struct {
+	ID          string               `json:"id"`
+	Status      string               `json:"status"`
+	Domains     int                  `json:"domains"`
+	SubmittedAt string               `json:"submitted_at"`
+	FinishedAt  string               `json:"finished_at,omitempty"`
+	Error       string               `json:"error,omitempty"`
+	Report      *collateral.Document `json:"report,omitempty"`
+}
+
+type WatchItemRequest struct {
+	Target      string `json:"target"`
+	CallbackURL string `json:"callback_url,omitempty"`
+}
+
+type WatchItemResponse struct {
+	ID          string `json:"id"`
+	Target      string `json:"target"`
+	Normalized  string `json:"normalized"`
+	CallbackURL string `json:"callback_url,omitempty"`
+	Blocked     bool   `json:"blocked"`
+	Rule        string `json:"rule,omitempty"`
+	RuleType    string `json:"rule_type,omitempty"`
+	Version     string `json:"version,omitempty"`
+	CreatedAt   string `json:"created_at"`
+	ChangedAt   string `json:"changed_at"`
+}
+
+type WatchlistResponse struct {
+	Items []WatchItemResponse `json:"items"`
+}
+
+type WatchEventResponse struct {
+	ID       int64  `json:"id"`
+	ItemID   string `json:"item_id"`
+	Target   string `json:"target"`
+	Kind     string `json:"kind"`
+	Rule     string `json:"rule,omitempty"`
+	RuleType string `json:"rule_type,omitempty"`
+	Version  string `json:"version"`
+	At       string `json:"at"`
+}
+
+type RegistryEventResponse struct {
+	Version   string               `json:"version"`
+	Size      int                  `json:"size"`
+	Counts    map[string]int       `json:"counts"`
+	AppliedAt string               `json:"applied_at"`
+	Diff      *DiffSummaryResponse `json:"diff,omitempty"`
+}
+
+type DiffSummaryResponse struct {
+	FromVersion string `json:"from_version"`
+	Added       int    `json:"added"`
+	Removed     int    `json:"removed"`
+	Changed     int    `json:"changed"`
+}
+
+type WebhookSubscriptionRequest struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events,omitempty"`
+}
+
+type WebhookSubscriptionResponse struct {
+	ID        string   `json:"id"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	CreatedAt string   `json:"created_at"`
+}
+
+type WebhookSubscriptionsResponse struct {
+	Subscriptions []WebhookSubscriptionResponse `json:"subscriptions"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             string `json:"id"`
+	SubscriptionID string `json:"subscription_id,omitempty"`
+	URL            string `json:"url"`
+	EventID        string `json:"event_id"`
+	EventType      string `json:"event_type"`
+	CreatedAt      string `json:"created_at"`
+	Attempts       int    `json:"attempts"`
+	LastError      string `json:"last_error,omitempty"`
+	NextAttemptAt  string `json:"next_attempt_at,omitempty"`
+	DeadAt         string `json:"dead_at,omitempty"`
+}
+
+type DeadLettersResponse struct {
+	DeadLetters []WebhookDeliveryResponse `json:"dead_letters"`
+}
+
+type WatchEventsResponse struct {
+	Events []WatchEventResponse `json:"events"`
+}
+
+type IngestReportResponse struct {
+	Format         string                   `json:"format"`
+	Encoding       string                   `json:"encoding"`
+	StartedAt      string                   `json:"started_at"`
+	DurationMs     int64                    `json:"duration_ms"`
+	RowsRead       int                      `json:"rows_read"`
+	Accepted       int                      `json:"accepted"`
+	CountsByType   map[string]int           `json:"counts_by_type"`
+	RejectedCount  int                      `json:"rejected_count"`
+	Rejected       []RejectedEntryResponse  `json:"rejected"`
+	DuplicateCount int                      `json:"duplicate_count"`
+	Duplicates     []DuplicateEntryResponse `json:"duplicates"`
+}
+
+type RejectedEntryResponse struct {
+	Line   int    `json:"line"`
+	Value  string `json:"value"`
+	Reason string `json:"reason"`
+}
+
+type DuplicateEntryResponse struct {
+	Line      int    `json:"line"`
+	FirstLine int    `json:"first_line"`
+	Value     string `json:"value"`
+}
+
+type QuarantineResponse struct {
+	Reason        string                `json:"reason"`
+	QuarantinedAt string                `json:"quarantined_at"`
+	CurrentSize   int                   `json:"current_size"`
+	CandidateSize int                   `json:"candidate_size"`
+	Report        *IngestReportResponse `json:"report,omitempty"`
+}
+
+type SchedulerStatusResponse struct {
+	Running             bool    `json:"running"`
+	Paused              bool    `json:"paused"`
+	LastUpdate          string  `json:"last_update,omitempty"`
+	NextUpdate          string  `json:"next_update,omitempty"`
+	LastError           string  `json:"last_error,omitempty"`
+	ConsecutiveFailures int     `json:"consecutive_failures"`
+	TotalUpdates        int     `json:"total_updates"`
+	SuccessfulUpdates   int     `json:"successful_updates"`
+	SuccessRate         float64 `json:"success_rate"`
+	RegistrySize        int     `json:"registry_size"`
+	Quarantined         bool    `json:"quarantined"`
+}
+
+type SourceHealthResponse struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+}
+
+type SourcesResponse struct {
+	Sources []SourceHealthResponse `json:"sources"`
+}
+
 func WriteJSONResponse(w http.ResponseWriter, statusCode int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -46,11 +300,18 @@ func WriteJSONResponse(w http.ResponseWriter, statusCode int, data interface{})
 	}
 }
 
-func WriteErrorResponse(w http.ResponseWriter, statusCode int, message string) {
-	response := ErrorResponse{
-		Error:   http.StatusText(statusCode),
-		Code:    statusCode,
-		Message: message,
+// WriteErrorResponse writes an occurrence of code as RFC 7807 problem
+// details
+func WriteErrorResponse(w http.ResponseWriter, code common.Code, detail string) {
+	common.WriteProblem(w, code, detail)
+}
+
+// WriteDomainError writes err as problem details classified by its error
+// code. Internal errors are logged and reported with fallback as detail.
+func WriteDomainError(w http.ResponseWriter, err error, fallback string) {
+	code := common.CodeOf(err)
+	if code == common.CodeInternal {
+		slog.Error(fallback, "error", err)
 	}
-	WriteJSONResponse(w, statusCode, response)
+	common.WriteProblem(w, code, common.Detail(err, fallback))
 }
@@ -0,0 +1,76 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/kerim-dauren/rkn-checker/internal/application"
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/common"
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// Probe and check statuses
+const (
+	probePass = "pass"
+	probeFail = "fail"
+)
+
+// ProbeHandler serves the liveness and readiness probes
+type ProbeHandler struct {
+	readiness application.ReadinessChecker
+}
+
+func NewProbeHandler(readiness application.ReadinessChecker) *ProbeHandler {
+	return &ProbeHandler{
+		readiness: readiness,
+	}
+}
+
+// Livez reports that the process is up and serving requests
+func (h *ProbeHandler) Livez(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, ProbeResponse{Status: probePass})
+}
+
+// Readyz reports whether the service can answer checks. It returns 503 with
+// every check and the reason of each failing one when it cannot.
+func (h *ProbeHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	report := h.readiness.Readiness(r.Context())
+
+	statusCode := http.StatusOK
+	if !report.Healthy {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	WriteJSONResponse(w, statusCode, newProbeResponse(report))
+}
+
+func newProbeResponse(report *domain.HealthReport) ProbeResponse {
+	response := ProbeResponse{
+		Status: probeStatus(report.Healthy),
+		Checks: make([]ProbeCheckResponse, 0, len(report.Checks)),
+	}
+	for _, check := range report.Checks {
+		response.Checks = append(response.Checks, ProbeCheckResponse{
+			Name:    check.Name,
+			Status:  probeStatus(check.Healthy),
+			Message: check.Message,
+		})
+	}
+	return response
+}
+
+func probeStatus(healthy bool) string {
+	if healthy {
+		return probePass
+	}
+	return probeFail
+}
@@ -0,0 +1,99 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+type mockReadinessChecker struct {
+	report *domain.HealthReport
+}
+
+func (m *mockReadinessChecker) Readiness(ctx context.Context) *domain.HealthReport {
+	return m.report
+}
+
+func TestProbeHandler_Livez(t *testing.T) {
+	handler := NewProbeHandler(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/livez", nil)
+	w := httptest.NewRecorder()
+	handler.Livez(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestProbeHandler_Readyz(t *testing.T) {
+	tests := []struct {
+		name           string
+		report         *domain.HealthReport
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Ready",
+			report: domain.NewHealthReport(
+				domain.HealthCheck{Name: "registry_loaded", Healthy: true, Message: "100 entries loaded"},
+			),
+			expectedStatus: http.StatusOK,
+			expectedBody:   probePass,
+		},
+		{
+			name: "Not ready",
+			report: domain.NewHealthReport(
+				domain.HealthCheck{Name: "registry_loaded", Message: "registry is empty"},
+				domain.HealthCheck{Name: "scheduler", Healthy: true, Message: "updates are succeeding"},
+			),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   probeFail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewProbeHandler(&mockReadinessChecker{report: tt.report})
+
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			w := httptest.NewRecorder()
+			handler.Readyz(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			var response ProbeResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if response.Status != tt.expectedBody || len(response.Checks) != len(tt.report.Checks) {
+				t.Fatalf("unexpected response %+v", response)
+			}
+			for i, check := range tt.report.Checks {
+				if response.Checks[i].Name != check.Name || response.Checks[i].Message != check.Message {
+					t.Errorf("check %d: expected %+v, got %+v", i, check, response.Checks[i])
+				}
+			}
+		})
+	}
+}
+
+func TestProbeHandler_MethodNotAllowed(t *testing.T) {
+	handler := NewProbeHandler(&mockReadinessChecker{report: domain.NewHealthReport()})
+
+	for _, handle := range []http.HandlerFunc{handler.Livez, handler.Readyz} {
+		req := httptest.NewRequest(http.MethodPost, "/readyz", nil)
+		w := httptest.NewRecorder()
+		handle(w, req)
+
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, w.Code)
+		}
+	}
+}
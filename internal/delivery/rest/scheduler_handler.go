@@ -0,0 +1,123 @@
+package rest
+
+import (
+	"log/slog"
+	"net/http"
+	"sort"
+
+	"github.com/kerim-dauren/rkn-checker/internal/application"
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/common"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/updater"
+)
+
+// SchedulerHandler controls registry updates under /admin/v1/
+type SchedulerHandler struct {
+	scheduler application.SchedulerController
+}
+
+func NewSchedulerHandler(scheduler application.SchedulerController) *SchedulerHandler {
+	return &SchedulerHandler{
+		scheduler: scheduler,
+	}
+}
+
+// GetStatus reports the state of the update scheduler
+func (h *SchedulerHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, newSchedulerStatusResponse(h.scheduler.GetStatus()))
+}
+
+// TriggerUpdate starts a registry update without waiting for it to finish
+func (h *SchedulerHandler) TriggerUpdate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	h.scheduler.TriggerUpdate()
+	slog.Info("Registry update triggered by admin", "remote_ip", getRemoteIP(r))
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Pause stops scheduled registry updates
+func (h *SchedulerHandler) Pause(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	h.scheduler.Pause()
+	slog.Warn("Registry updates paused by admin", "remote_ip", getRemoteIP(r))
+
+	WriteJSONResponse(w, http.StatusOK, newSchedulerStatusResponse(h.scheduler.GetStatus()))
+}
+
+// Resume restarts scheduled registry updates
+func (h *SchedulerHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	h.scheduler.Resume()
+	slog.Info("Registry updates resumed by admin", "remote_ip", getRemoteIP(r))
+
+	WriteJSONResponse(w, http.StatusOK, newSchedulerStatusResponse(h.scheduler.GetStatus()))
+}
+
+// ClearRegistry removes every entry from the registry store
+func (h *SchedulerHandler) ClearRegistry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	h.scheduler.ClearRegistry()
+	slog.Warn("Registry cleared by admin", "remote_ip", getRemoteIP(r))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetSources reports the health of each registry source
+func (h *SchedulerHandler) GetSources(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	health := h.scheduler.SourceHealth(r.Context())
+
+	response := SourcesResponse{Sources: make([]SourceHealthResponse, 0, len(health))}
+	for name, healthy := range health {
+		response.Sources = append(response.Sources, SourceHealthResponse{Name: name, Healthy: healthy})
+	}
+	sort.Slice(response.Sources, func(i, j int) bool {
+		return response.Sources[i].Name < response.Sources[j].Name
+	})
+
+	WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func newSchedulerStatusResponse(status updater.Status) SchedulerStatusResponse {
+	response := SchedulerStatusResponse{
+		Running:             status.Running,
+		Paused:              status.Paused,
+		LastUpdate:          formatDate(status.LastUpdate),
+		NextUpdate:          formatDate(status.NextUpdate),
+		ConsecutiveFailures: status.ConsecutiveFailures,
+		TotalUpdates:        status.TotalUpdates,
+		SuccessfulUpdates:   status.SuccessfulUpdates,
+		SuccessRate:         status.SuccessRate(),
+		RegistrySize:        status.RegistrySize,
+		Quarantined:         status.Quarantined != nil,
+	}
+	if status.LastError != nil {
+		response.LastError = status.LastError.Error()
+	}
+	return response
+}
@@ -0,0 +1,160 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/updater"
+)
+
+type mockSchedulerController struct {
+	status    updater.Status
+	health    map[string]bool
+	triggered int
+	cleared   int
+}
+
+func (m *mockSchedulerController) TriggerUpdate() {
+	m.triggered++
+}
+
+func (m *mockSchedulerController) Pause() {
+	m.status.Paused = true
+}
+
+func (m *mockSchedulerController) Resume() {
+	m.status.Paused = false
+}
+
+func (m *mockSchedulerController) GetStatus() updater.Status {
+	return m.status
+}
+
+func (m *mockSchedulerController) ClearRegistry() {
+	m.cleared++
+	m.status.RegistrySize = 0
+}
+
+func (m *mockSchedulerController) SourceHealth(ctx context.Context) map[string]bool {
+	return m.health
+}
+
+func TestSchedulerHandler_GetStatus(t *testing.T) {
+	scheduler := &mockSchedulerController{status: updater.Status{
+		Running:             true,
+		LastUpdate:          time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC),
+		NextUpdate:          time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC),
+		LastError:           errors.New("fetching registry: timeout"),
+		ConsecutiveFailures: 1,
+		TotalUpdates:        4,
+		SuccessfulUpdates:   3,
+		RegistrySize:        42,
+	}}
+	handler := NewSchedulerHandler(scheduler)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/v1/scheduler", nil)
+	w := httptest.NewRecorder()
+	handler.GetStatus(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response SchedulerStatusResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !response.Running || response.RegistrySize != 42 || response.SuccessRate != 75 {
+		t.Errorf("unexpected status %+v", response)
+	}
+	if response.LastError != "fetching registry: timeout" || response.ConsecutiveFailures != 1 {
+		t.Errorf("expected the last error and failure count, got %+v", response)
+	}
+	if response.NextUpdate != "2024-06-03T09:00:00Z" {
+		t.Errorf("expected next update 2024-06-03T09:00:00Z, got %q", response.NextUpdate)
+	}
+}
+
+func TestSchedulerHandler_Controls(t *testing.T) {
+	scheduler := &mockSchedulerController{status: updater.Status{RegistrySize: 42}}
+	handler := NewSchedulerHandler(scheduler)
+
+	tests := []struct {
+		name           string
+		handle         http.HandlerFunc
+		method         string
+		expectedStatus int
+	}{
+		{"Trigger update", handler.TriggerUpdate, http.MethodPost, http.StatusAccepted},
+		{"Pause", handler.Pause, http.MethodPost, http.StatusOK},
+		{"Resume", handler.Resume, http.MethodPost, http.StatusOK},
+		{"Clear registry", handler.ClearRegistry, http.MethodPost, http.StatusNoContent},
+		{"Trigger with GET", handler.TriggerUpdate, http.MethodGet, http.StatusMethodNotAllowed},
+		{"Clear with GET", handler.ClearRegistry, http.MethodGet, http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/admin/v1/scheduler", nil)
+			w := httptest.NewRecorder()
+			tt.handle(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+
+	if scheduler.triggered != 1 || scheduler.cleared != 1 {
+		t.Errorf("expected one trigger and one clear, got %d/%d", scheduler.triggered, scheduler.cleared)
+	}
+}
+
+func TestSchedulerHandler_PauseReportsStatus(t *testing.T) {
+	handler := NewSchedulerHandler(&mockSchedulerController{})
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/v1/scheduler/pause", nil)
+	w := httptest.NewRecorder()
+	handler.Pause(w, req)
+
+	var response SchedulerStatusResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !response.Paused {
+		t.Error("expected the paused status after pausing")
+	}
+}
+
+func TestSchedulerHandler_GetSources(t *testing.T) {
+	handler := NewSchedulerHandler(&mockSchedulerController{
+		health: map[string]bool{"official": false, "mirror": true},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/v1/sources", nil)
+	w := httptest.NewRecorder()
+	handler.GetSources(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response SourcesResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	expected := []SourceHealthResponse{{Name: "mirror", Healthy: true}, {Name: "official", Healthy: false}}
+	if len(response.Sources) != len(expected) {
+		t.Fatalf("expected %d sources, got %+v", len(expected), response.Sources)
+	}
+	for i, source := range expected {
+		if response.Sources[i] != source {
+			t.Errorf("source %d: expected %+v, got %+v", i, source, response.Sources[i])
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/kerim-dauren/rkn-checker/internal/application"
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/common"
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// SearchHandler serves registry searches
+type SearchHandler struct {
+	searcher application.RegistrySearcher
+}
+
+func NewSearchHandler(searcher application.RegistrySearcher) *SearchHandler {
+	return &SearchHandler{
+		searcher: searcher,
+	}
+}
+
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	params := r.URL.Query()
+	query := domain.SearchQuery{
+		Mode:    domain.SearchModeSuffix,
+		Pattern: params.Get("q"),
+	}
+
+	if value := params.Get("mode"); value != "" {
+		mode, ok := domain.ParseSearchMode(value)
+		if !ok {
+			WriteErrorResponse(w, common.CodeInvalidArgument, "Invalid search mode")
+			return
+		}
+		query.Mode = mode
+	}
+
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			WriteErrorResponse(w, common.CodeInvalidArgument, "Invalid limit")
+			return
+		}
+		query.Limit = limit
+	}
+
+	result, err := h.searcher.Search(r.Context(), query)
+	if err != nil {
+		WriteDomainError(w, err, "Failed to search registry")
+		return
+	}
+
+	response := SearchResponse{
+		Version:   result.Version,
+		Entries:   make([]EntryResponse, 0, len(result.Entries)),
+		Truncated: result.Truncated,
+		TimedOut:  result.TimedOut,
+	}
+	for _, entry := range result.Entries {
+		response.Entries = append(response.Entries, newEntryResponse(entry))
+	}
+
+	WriteJSONResponse(w, http.StatusOK, response)
+}
@@ -0,0 +1,73 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+type mockSearcher struct {
+	lastQuery domain.SearchQuery
+}
+
+func (m *mockSearcher) Search(ctx context.Context, query domain.SearchQuery) (*domain.SearchResult, error) {
+	m.lastQuery = query
+	if query.Pattern == "(" {
+		return nil, fmt.Errorf("%w: missing closing )", domain.ErrInvalidSearchQuery)
+	}
+	entry, _ := domain.NewRegistryEntry(domain.BlockingTypeDomain, "shop."+query.Pattern)
+	return &domain.SearchResult{Version: "v1", Entries: []*domain.RegistryEntry{entry}, Truncated: true}, nil
+}
+
+func TestSearchHandler_Search(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		query          string
+		expectedStatus int
+		expectedMode   domain.SearchMode
+	}{
+		{name: "suffix by default", method: http.MethodGet, query: "?q=example.com", expectedStatus: http.StatusOK, expectedMode: domain.SearchModeSuffix},
+		{name: "regex", method: http.MethodGet, query: "?mode=regex&q=casino&limit=10", expectedStatus: http.StatusOK, expectedMode: domain.SearchModeRegex},
+		{name: "invalid query", method: http.MethodGet, query: "?mode=regex&q=(", expectedStatus: http.StatusBadRequest},
+		{name: "invalid mode", method: http.MethodGet, query: "?mode=fuzzy&q=example", expectedStatus: http.StatusBadRequest},
+		{name: "invalid limit", method: http.MethodGet, query: "?q=example&limit=x", expectedStatus: http.StatusBadRequest},
+		{name: "POST method should return method not allowed", method: http.MethodPost, expectedStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searcher := &mockSearcher{}
+			handler := NewSearchHandler(searcher)
+
+			req := httptest.NewRequest(tt.method, "/api/v1/search"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.Search(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, but got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			if searcher.lastQuery.Mode != tt.expectedMode {
+				t.Errorf("expected mode %s, got %s", tt.expectedMode, searcher.lastQuery.Mode)
+			}
+
+			var resp SearchResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(resp.Entries) != 1 || !resp.Truncated || resp.Entries[0].Type != "domain" {
+				t.Errorf("unexpected response: %+v", resp)
+			}
+		})
+	}
+}
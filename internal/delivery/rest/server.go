@@ -2,48 +2,325 @@ package rest
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/kerim-dauren/rkn-checker/internal/application"
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/listener"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/proxy"
 )
 
 type Server struct {
 	server          *http.Server
 	blockingService application.BlockingChecker
+	ingestReporter  application.IngestReporter
+	quarantine      application.QuarantineManager
+	changelog       application.ChangelogReader
+	history         application.HistoryReader
+	entries         application.EntryBrowser
+	searcher        application.RegistrySearcher
+	ipLookup        application.IPLookup
+	collateral      application.CollateralJobRunner
+	watchlist       application.WatchlistManager
+	webhooks        application.WebhookManager
+	feed            application.RegistryFeed
+	heartbeat       time.Duration
+	scheduler       application.SchedulerController
+	authenticator   application.Authenticator
+	corsOrigins     []string
+	rateLimiter     application.RateLimiter
+	proxies         *proxy.Resolver
+	proxyProtocol   bool
+	tlsConfig       *tls.Config
+	host            string
+	socket          string
+	readiness       application.ReadinessChecker
+	metrics         http.Handler
 	port            int
 }
 
-func NewServer(blockingService application.BlockingChecker, port int) *Server {
-	return &Server{
+// Option configures optional Server dependencies
+type Option func(*Server)
+
+// WithIngestReporter exposes the last ingest report under /admin/v1/
+func WithIngestReporter(reporter application.IngestReporter) Option {
+	return func(s *Server) {
+		s.ingestReporter = reporter
+	}
+}
+
+// WithQuarantineManager exposes quarantined registry updates under /admin/v1/
+func WithQuarantineManager(quarantine application.QuarantineManager) Option {
+	return func(s *Server) {
+		s.quarantine = quarantine
+	}
+}
+
+// WithChangelog serves registry changelogs at /api/v1/changes
+func WithChangelog(changelog application.ChangelogReader) Option {
+	return func(s *Server) {
+		s.changelog = changelog
+	}
+}
+
+// WithHistory serves pattern timelines at /api/v1/history/{domain}
+func WithHistory(history application.HistoryReader) Option {
+	return func(s *Server) {
+		s.history = history
+	}
+}
+
+// WithEntryBrowser serves registry entries under /api/v1/entries
+func WithEntryBrowser(entries application.EntryBrowser) Option {
+	return func(s *Server) {
+		s.entries = entries
+	}
+}
+
+// WithSearcher serves registry searches at /api/v1/search
+func WithSearcher(searcher application.RegistrySearcher) Option {
+	return func(s *Server) {
+		s.searcher = searcher
+	}
+}
+
+// WithIPLookup serves reverse IP lookups at /api/v1/ip/{addr}
+func WithIPLookup(ipLookup application.IPLookup) Option {
+	return func(s *Server) {
+		s.ipLookup = ipLookup
+	}
+}
+
+// WithCollateral runs collateral-damage jobs under /api/v1/collateral/jobs
+func WithCollateral(collateral application.CollateralJobRunner) Option {
+	return func(s *Server) {
+		s.collateral = collateral
+	}
+}
+
+// WithWatchlist serves watch items and their events under /api/v1/watchlist
+func WithWatchlist(watchlist application.WatchlistManager) Option {
+	return func(s *Server) {
+		s.watchlist = watchlist
+	}
+}
+
+// WithWebhooks manages webhook subscriptions under /admin/v1/webhooks
+func WithWebhooks(webhooks application.WebhookManager) Option {
+	return func(s *Server) {
+		s.webhooks = webhooks
+	}
+}
+
+// WithRegistryFeed streams registry update events at /api/v1/events, sending
+// a heartbeat after every idle heartbeat interval
+func WithRegistryFeed(feed application.RegistryFeed, heartbeat time.Duration) Option {
+	return func(s *Server) {
+		s.feed = feed
+		s.heartbeat = heartbeat
+	}
+}
+
+// WithScheduler controls registry updates under /admin/v1/. The controls
+// are only served when an authenticator is set.
+func WithScheduler(scheduler application.SchedulerController) Option {
+	return func(s *Server) {
+		s.scheduler = scheduler
+	}
+}
+
+// WithAuthenticator requires the scope of every route except the probes
+// from the callers resolved by authenticator
+func WithAuthenticator(authenticator application.Authenticator) Option {
+	return func(s *Server) {
+		s.authenticator = authenticator
+	}
+}
+
+// WithCORSOrigins only allows cross-origin requests from origins instead of
+// from any origin
+func WithCORSOrigins(origins []string) Option {
+	return func(s *Server) {
+		s.corsOrigins = origins
+	}
+}
+
+// WithReadiness serves the readiness probe at /readyz
+func WithReadiness(readiness application.ReadinessChecker) Option {
+	return func(s *Server) {
+		s.readiness = readiness
+	}
+}
+
+// WithMetrics serves Prometheus metrics at /metrics
+func WithMetrics(handler http.Handler) Option {
+	return func(s *Server) {
+		s.metrics = handler
+	}
+}
+
+// WithRateLimiter limits the calls of each client to every route except the
+// probes
+func WithRateLimiter(limiter application.RateLimiter) Option {
+	return func(s *Server) {
+		s.rateLimiter = limiter
+	}
+}
+
+// WithTrustedProxies resolves the addresses of clients calling through the
+// proxies of resolver from their forwarding header, and from PROXY protocol
+// headers when proxyProtocol is set
+func WithTrustedProxies(resolver *proxy.Resolver, proxyProtocol bool) Option {
+	return func(s *Server) {
+		s.proxies = resolver
+		s.proxyProtocol = proxyProtocol
+	}
+}
+
+// WithTLS serves HTTPS with tlsConfig instead of plain HTTP
+func WithTLS(tlsConfig *tls.Config) Option {
+	return func(s *Server) {
+		s.tlsConfig = tlsConfig
+	}
+}
+
+// WithHost listens on host instead of every interface
+func WithHost(host string) Option {
+	return func(s *Server) {
+		s.host = host
+	}
+}
+
+// WithSocket listens on the Unix socket at path instead of the TCP port
+func WithSocket(path string) Option {
+	return func(s *Server) {
+		s.socket = path
+	}
+}
+
+func NewServer(blockingService application.BlockingChecker, port int, opts ...Option) *Server {
+	s := &Server{
 		blockingService: blockingService,
 		port:            port,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func (s *Server) Start(ctx context.Context) error {
+// handler builds the routes and middleware chain of the server. stop ends
+// long-lived streams when it is closed.
+func (s *Server) handler(stop chan struct{}) http.Handler {
 	handler := NewHandler(s.blockingService)
 
 	mux := http.NewServeMux()
+	handle := func(pattern string, scope domain.Scope, handler http.HandlerFunc) {
+		mux.Handle(pattern, s.rateLimit(pattern, s.requireScope(scope, handler)))
+	}
 
-	mux.HandleFunc("/api/v1/check", handler.CheckURL)
-	mux.HandleFunc("/api/v1/stats", handler.GetStats)
+	handle("/api/v1/check", domain.ScopeCheck, handler.CheckURL)
+	handle("/api/v1/stats", domain.ScopeStats, handler.GetStats)
 	mux.HandleFunc("/health", handler.HealthCheck)
 
+	probes := NewProbeHandler(s.readiness)
+	mux.HandleFunc("/livez", probes.Livez)
+	if s.readiness != nil {
+		mux.HandleFunc("/readyz", probes.Readyz)
+	}
+
+	if s.metrics != nil {
+		handle("/metrics", domain.ScopeStats, s.metrics.ServeHTTP)
+	}
+
+	if s.feed != nil {
+		events := NewEventsHandler(s.feed, s.heartbeat, stop)
+		handle("/api/v1/events", domain.ScopeRegistry, events.StreamEvents)
+	}
+
+	if s.changelog != nil {
+		changes := NewChangesHandler(s.changelog)
+		handle("/api/v1/changes", domain.ScopeRegistry, changes.ListChanges)
+	}
+
+	if s.history != nil {
+		history := NewHistoryHandler(s.history)
+		handle("/api/v1/history/{domain}", domain.ScopeRegistry, history.GetHistory)
+	}
+
+	if s.entries != nil {
+		entries := NewEntriesHandler(s.entries)
+		handle("/api/v1/entries", domain.ScopeRegistry, entries.ListEntries)
+		handle("/api/v1/entries/{id}", domain.ScopeRegistry, entries.GetEntry)
+	}
+
+	if s.searcher != nil {
+		search := NewSearchHandler(s.searcher)
+		handle("/api/v1/search", domain.ScopeRegistry, search.Search)
+	}
+
+	if s.ipLookup != nil {
+		ip := NewIPHandler(s.ipLookup)
+		handle("/api/v1/ip/{addr}", domain.ScopeRegistry, ip.LookupIP)
+	}
+
+	if s.collateral != nil {
+		collateral := NewCollateralHandler(s.collateral)
+		handle("/api/v1/collateral/jobs", domain.ScopeCheck, collateral.SubmitJob)
+		handle("/api/v1/collateral/jobs/{id}", domain.ScopeCheck, collateral.GetJob)
+	}
+
+	if s.watchlist != nil {
+		watchlist := NewWatchlistHandler(s.watchlist)
+		handle("/api/v1/watchlist", domain.ScopeWatchlist, watchlist.Watchlist)
+		handle("/api/v1/watchlist/events", domain.ScopeWatchlist, watchlist.WatchEvents)
+		handle("/api/v1/watchlist/{id}", domain.ScopeWatchlist, watchlist.WatchItem)
+	}
+
+	s.registerAdminRoutes(mux)
+
 	// Apply middleware chain
-	finalHandler := CORSMiddleware(LoggingMiddleware(RecoveryMiddleware(mux)))
+	var authenticated http.Handler = LoggingMiddleware(RecoveryMiddleware(RouteMiddleware(mux)))
+	if s.authenticator != nil {
+		authenticated = AuthenticationMiddleware(s.authenticator, authenticated)
+		if s.rateLimiter != nil {
+			authenticated = FailedAuthenticationLimitMiddleware(s.rateLimiter, authenticated)
+		}
+	}
+	finalHandler := TracingMiddleware(CORSMiddleware(s.corsOrigins, MetricsMiddleware(authenticated)))
+	if s.proxies != nil {
+		finalHandler = ClientIPMiddleware(s.proxies, finalHandler)
+	}
+	return finalHandler
+}
+
+func (s *Server) Start(ctx context.Context) error {
+	// stop ends long-lived streams when the server shuts down
+	stop := make(chan struct{})
 
 	s.server = &http.Server{
-		Addr:         fmt.Sprintf(":%d", s.port),
-		Handler:      finalHandler,
+		Handler:      s.handler(stop),
+		TLSConfig:    s.tlsConfig,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  15 * time.Second,
 	}
+	s.server.RegisterOnShutdown(func() { close(stop) })
 
-	slog.Info("Starting REST server", "port", s.port)
+	lis, err := listener.Listen(s.host, s.port, s.socket)
+	if err != nil {
+		return err
+	}
+	if s.proxies != nil && s.proxyProtocol {
+		lis = proxy.NewListener(lis, s.proxies)
+	}
+
+	slog.Info("Starting REST server", "address", listener.Address(s.host, s.port, s.socket),
+		"tls", s.tlsConfig != nil, "proxy_protocol", s.proxyProtocol)
 
 	go func() {
 		<-ctx.Done()
@@ -57,13 +334,77 @@ func (s *Server) Start(ctx context.Context) error {
 		}
 	}()
 
-	if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	if s.tlsConfig != nil {
+		err = s.server.ServeTLS(lis, "", "")
+	} else {
+		err = s.server.Serve(lis)
+	}
+	if err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("REST server failed: %w", err)
 	}
 
 	return nil
 }
 
+// registerAdminRoutes serves the /admin/v1/ routes behind the admin scope.
+// Without an authenticator no caller could hold that scope, so none of them
+// are served.
+func (s *Server) registerAdminRoutes(mux *http.ServeMux) {
+	if s.authenticator == nil {
+		if s.ingestReporter != nil || s.quarantine != nil || s.webhooks != nil || s.scheduler != nil {
+			slog.Warn("Admin routes disabled, no authentication is configured")
+		}
+		return
+	}
+
+	handleAdmin := func(pattern string, handler http.HandlerFunc) {
+		mux.Handle(pattern, s.rateLimit(pattern, RequireScope(domain.ScopeAdmin, handler)))
+	}
+
+	admin := NewAdminHandler(s.ingestReporter, s.quarantine)
+	if s.ingestReporter != nil {
+		handleAdmin("/admin/v1/ingest-report", admin.GetIngestReport)
+	}
+	if s.quarantine != nil {
+		handleAdmin("/admin/v1/quarantine", admin.GetQuarantine)
+		handleAdmin("/admin/v1/quarantine/apply", admin.ApplyQuarantine)
+	}
+
+	if s.webhooks != nil {
+		webhooks := NewWebhookHandler(s.webhooks)
+		handleAdmin("/admin/v1/webhooks", webhooks.Subscriptions)
+		handleAdmin("/admin/v1/webhooks/{id}", webhooks.Subscription)
+		handleAdmin("/admin/v1/webhooks/dead-letters", webhooks.DeadLetters)
+		handleAdmin("/admin/v1/webhooks/dead-letters/{id}/replay", webhooks.Replay)
+	}
+
+	if s.scheduler != nil {
+		scheduler := NewSchedulerHandler(s.scheduler)
+		handleAdmin("/admin/v1/scheduler", scheduler.GetStatus)
+		handleAdmin("/admin/v1/scheduler/trigger", scheduler.TriggerUpdate)
+		handleAdmin("/admin/v1/scheduler/pause", scheduler.Pause)
+		handleAdmin("/admin/v1/scheduler/resume", scheduler.Resume)
+		handleAdmin("/admin/v1/registry/clear", scheduler.ClearRegistry)
+		handleAdmin("/admin/v1/sources", scheduler.GetSources)
+	}
+}
+
+// requireScope guards handler with scope when an authenticator is set
+func (s *Server) requireScope(scope domain.Scope, handler http.Handler) http.Handler {
+	if s.authenticator == nil {
+		return handler
+	}
+	return RequireScope(scope, handler)
+}
+
+// rateLimit limits the calls to route when a rate limiter is set
+func (s *Server) rateLimit(route string, handler http.Handler) http.Handler {
+	if s.rateLimiter == nil {
+		return handler
+	}
+	return RateLimitMiddleware(s.rateLimiter, route, handler)
+}
+
 func (s *Server) Stop() {
 	if s.server != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -0,0 +1,164 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/application"
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/common"
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// WatchlistHandler manages watched targets and lists their change events
+type WatchlistHandler struct {
+	watchlist application.WatchlistManager
+}
+
+func NewWatchlistHandler(watchlist application.WatchlistManager) *WatchlistHandler {
+	return &WatchlistHandler{
+		watchlist: watchlist,
+	}
+}
+
+// Watchlist lists watch items on GET and adds one on POST
+func (h *WatchlistHandler) Watchlist(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		items := h.watchlist.ListWatches()
+		response := WatchlistResponse{Items: make([]WatchItemResponse, 0, len(items))}
+		for _, item := range items {
+			response.Items = append(response.Items, newWatchItemResponse(item))
+		}
+		WriteJSONResponse(w, http.StatusOK, response)
+
+	case http.MethodPost:
+		var req WatchItemRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			WriteErrorResponse(w, common.CodeInvalidArgument, "Invalid JSON format")
+			return
+		}
+
+		item, err := h.watchlist.AddWatch(r.Context(), req.Target, req.CallbackURL)
+		if err != nil {
+			h.writeError(w, err)
+			return
+		}
+		w.Header().Set("Location", "/api/v1/watchlist/"+item.ID)
+		WriteJSONResponse(w, http.StatusCreated, newWatchItemResponse(item))
+
+	default:
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// WatchItem returns, replaces or deletes a single watch item
+func (h *WatchlistHandler) WatchItem(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	switch r.Method {
+	case http.MethodGet:
+		item, err := h.watchlist.GetWatch(id)
+		if err != nil {
+			h.writeError(w, err)
+			return
+		}
+		WriteJSONResponse(w, http.StatusOK, newWatchItemResponse(item))
+
+	case http.MethodPut:
+		var req WatchItemRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			WriteErrorResponse(w, common.CodeInvalidArgument, "Invalid JSON format")
+			return
+		}
+
+		item, err := h.watchlist.UpdateWatch(r.Context(), id, req.Target, req.CallbackURL)
+		if err != nil {
+			h.writeError(w, err)
+			return
+		}
+		WriteJSONResponse(w, http.StatusOK, newWatchItemResponse(item))
+
+	case http.MethodDelete:
+		if err := h.watchlist.DeleteWatch(id); err != nil {
+			h.writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// WatchEvents lists the change events of watch items, oldest first
+func (h *WatchlistHandler) WatchEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	params := r.URL.Query()
+	query := domain.WatchEventQuery{ItemID: params.Get("item")}
+
+	if value := params.Get("after"); value != "" {
+		after, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || after < 0 {
+			WriteErrorResponse(w, common.CodeInvalidArgument, "Invalid after")
+			return
+		}
+		query.After = after
+	}
+
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			WriteErrorResponse(w, common.CodeInvalidArgument, "Invalid limit")
+			return
+		}
+		query.Limit = limit
+	}
+
+	events := h.watchlist.WatchEvents(query)
+	response := WatchEventsResponse{Events: make([]WatchEventResponse, 0, len(events))}
+	for _, event := range events {
+		e := WatchEventResponse{
+			ID:      event.ID,
+			ItemID:  event.ItemID,
+			Target:  event.Target,
+			Kind:    event.Kind.String(),
+			Rule:    event.Rule,
+			Version: event.Version,
+			At:      event.At.Format(time.RFC3339),
+		}
+		if event.Rule != "" {
+			e.RuleType = event.RuleType.String()
+		}
+		response.Events = append(response.Events, e)
+	}
+
+	WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *WatchlistHandler) writeError(w http.ResponseWriter, err error) {
+	WriteDomainError(w, err, "Failed to manage watchlist")
+}
+
+func newWatchItemResponse(item *domain.WatchItem) WatchItemResponse {
+	response := WatchItemResponse{
+		ID:          item.ID,
+		Target:      item.Target,
+		Normalized:  item.Normalized,
+		CallbackURL: item.CallbackURL,
+		Blocked:     item.Blocked,
+		Rule:        item.Rule,
+		Version:     item.Version,
+		CreatedAt:   formatDate(item.CreatedAt),
+		ChangedAt:   formatDate(item.ChangedAt),
+	}
+	if item.Rule != "" {
+		response.RuleType = item.RuleType.String()
+	}
+	return response
+}
@@ -0,0 +1,196 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+type mockWatchlist struct {
+	items     map[string]*domain.WatchItem
+	lastQuery domain.WatchEventQuery
+}
+
+func newMockWatchlist() *mockWatchlist {
+	return &mockWatchlist{items: map[string]*domain.WatchItem{
+		"w1": {ID: "w1", Target: "example.com", Normalized: "example.com", Blocked: true, Rule: "*.example.com", RuleType: domain.BlockingTypeWildcard},
+	}}
+}
+
+func (m *mockWatchlist) AddWatch(ctx context.Context, target, callbackURL string) (*domain.WatchItem, error) {
+	if target == "" {
+		return nil, fmt.Errorf("%w: target is required", domain.ErrInvalidWatchItem)
+	}
+	item := &domain.WatchItem{ID: "w2", Target: target, CallbackURL: callbackURL, CreatedAt: time.Now()}
+	m.items[item.ID] = item
+	return item, nil
+}
+
+func (m *mockWatchlist) UpdateWatch(ctx context.Context, id, target, callbackURL string) (*domain.WatchItem, error) {
+	item, err := m.GetWatch(id)
+	if err != nil {
+		return nil, err
+	}
+	item.Target, item.CallbackURL = target, callbackURL
+	return item, nil
+}
+
+func (m *mockWatchlist) GetWatch(id string) (*domain.WatchItem, error) {
+	item, ok := m.items[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrWatchItemNotFound, id)
+	}
+	return item, nil
+}
+
+func (m *mockWatchlist) ListWatches() []*domain.WatchItem {
+	items := make([]*domain.WatchItem, 0, len(m.items))
+	for _, item := range m.items {
+		items = append(items, item)
+	}
+	return items
+}
+
+func (m *mockWatchlist) DeleteWatch(id string) error {
+	if _, err := m.GetWatch(id); err != nil {
+		return err
+	}
+	delete(m.items, id)
+	return nil
+}
+
+func (m *mockWatchlist) WatchEvents(query domain.WatchEventQuery) []*domain.WatchEvent {
+	m.lastQuery = query
+	return []*domain.WatchEvent{
+		{ID: query.After + 1, ItemID: "w1", Target: "example.com", Kind: domain.WatchEventBecameBlocked, Rule: "*.example.com", RuleType: domain.BlockingTypeWildcard, Version: "v2", At: time.Now()},
+	}
+}
+
+func TestWatchlistHandler_Watchlist(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		expectedStatus int
+	}{
+		{name: "list", method: http.MethodGet, expectedStatus: http.StatusOK},
+		{name: "add", method: http.MethodPost, body: `{"target":"shop.example","callback_url":"https://hook.example/"}`, expectedStatus: http.StatusCreated},
+		{name: "add without target", method: http.MethodPost, body: `{}`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid JSON", method: http.MethodPost, body: `{`, expectedStatus: http.StatusBadRequest},
+		{name: "DELETE method should return method not allowed", method: http.MethodDelete, expectedStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewWatchlistHandler(newMockWatchlist())
+
+			req := httptest.NewRequest(tt.method, "/api/v1/watchlist", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.Watchlist(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, but got %d", tt.expectedStatus, w.Code)
+			}
+
+			switch w.Code {
+			case http.StatusOK:
+				var resp WatchlistResponse
+				if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if len(resp.Items) != 1 || resp.Items[0].RuleType != "wildcard" {
+					t.Errorf("Unexpected items %+v", resp.Items)
+				}
+			case http.StatusCreated:
+				if location := w.Header().Get("Location"); location != "/api/v1/watchlist/w2" {
+					t.Errorf("Unexpected Location %q", location)
+				}
+			}
+		})
+	}
+}
+
+func TestWatchlistHandler_WatchItem(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		id             string
+		body           string
+		expectedStatus int
+	}{
+		{name: "get", method: http.MethodGet, id: "w1", expectedStatus: http.StatusOK},
+		{name: "get unknown", method: http.MethodGet, id: "nope", expectedStatus: http.StatusNotFound},
+		{name: "update", method: http.MethodPut, id: "w1", body: `{"target":"other.example"}`, expectedStatus: http.StatusOK},
+		{name: "delete", method: http.MethodDelete, id: "w1", expectedStatus: http.StatusNoContent},
+		{name: "delete unknown", method: http.MethodDelete, id: "nope", expectedStatus: http.StatusNotFound},
+		{name: "POST method should return method not allowed", method: http.MethodPost, id: "w1", expectedStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewWatchlistHandler(newMockWatchlist())
+
+			req := httptest.NewRequest(tt.method, "/api/v1/watchlist/"+tt.id, strings.NewReader(tt.body))
+			req.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+
+			handler.WatchItem(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, but got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestWatchlistHandler_WatchEvents(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedQuery  domain.WatchEventQuery
+	}{
+		{name: "all", expectedStatus: http.StatusOK},
+		{name: "filtered", query: "?item=w1&after=4&limit=10", expectedStatus: http.StatusOK, expectedQuery: domain.WatchEventQuery{ItemID: "w1", After: 4, Limit: 10}},
+		{name: "invalid after", query: "?after=x", expectedStatus: http.StatusBadRequest},
+		{name: "invalid limit", query: "?limit=0", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			watchlist := newMockWatchlist()
+			handler := NewWatchlistHandler(watchlist)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/watchlist/events"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.WatchEvents(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, but got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			if watchlist.lastQuery != tt.expectedQuery {
+				t.Errorf("Expected query %+v, got %+v", tt.expectedQuery, watchlist.lastQuery)
+			}
+			var resp WatchEventsResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(resp.Events) != 1 || resp.Events[0].Kind != "became_blocked" {
+				t.Errorf("Unexpected events %+v", resp.Events)
+			}
+		})
+	}
+}
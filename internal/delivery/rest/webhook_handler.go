@@ -0,0 +1,147 @@
+package rest
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/kerim-dauren/rkn-checker/internal/application"
+	"github.com/kerim-dauren/rkn-checker/internal/delivery/common"
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// WebhookHandler manages webhook subscriptions and their dead letters under
+// /admin/v1/webhooks
+type WebhookHandler struct {
+	webhooks application.WebhookManager
+}
+
+func NewWebhookHandler(webhooks application.WebhookManager) *WebhookHandler {
+	return &WebhookHandler{
+		webhooks: webhooks,
+	}
+}
+
+// Subscriptions lists subscriptions on GET and adds one on POST
+func (h *WebhookHandler) Subscriptions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		subscriptions := h.webhooks.Subscriptions()
+		response := WebhookSubscriptionsResponse{Subscriptions: make([]WebhookSubscriptionResponse, 0, len(subscriptions))}
+		for _, subscription := range subscriptions {
+			response.Subscriptions = append(response.Subscriptions, newWebhookSubscriptionResponse(subscription))
+		}
+		WriteJSONResponse(w, http.StatusOK, response)
+
+	case http.MethodPost:
+		var req WebhookSubscriptionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			WriteErrorResponse(w, common.CodeInvalidArgument, "Invalid JSON format")
+			return
+		}
+
+		events := make([]domain.WebhookEventType, 0, len(req.Events))
+		for _, name := range req.Events {
+			event, ok := domain.ParseWebhookEventType(name)
+			if !ok {
+				WriteErrorResponse(w, common.CodeInvalidArgument, "Unknown event type: "+name)
+				return
+			}
+			events = append(events, event)
+		}
+
+		subscription, err := h.webhooks.AddSubscription(req.URL, req.Secret, events)
+		if err != nil {
+			h.writeError(w, err)
+			return
+		}
+		w.Header().Set("Location", "/admin/v1/webhooks/"+subscription.ID)
+		WriteJSONResponse(w, http.StatusCreated, newWebhookSubscriptionResponse(subscription))
+
+	default:
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// Subscription removes a subscription on DELETE
+func (h *WebhookHandler) Subscription(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if err := h.webhooks.DeleteSubscription(r.PathValue("id")); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeadLetters lists the deliveries that ran out of attempts
+func (h *WebhookHandler) DeadLetters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	dead := h.webhooks.DeadLetters()
+	response := DeadLettersResponse{DeadLetters: make([]WebhookDeliveryResponse, 0, len(dead))}
+	for _, delivery := range dead {
+		response.DeadLetters = append(response.DeadLetters, newWebhookDeliveryResponse(delivery))
+	}
+	WriteJSONResponse(w, http.StatusOK, response)
+}
+
+// Replay queues a dead-lettered delivery again
+func (h *WebhookHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		WriteErrorResponse(w, common.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	delivery, err := h.webhooks.Replay(r.PathValue("id"))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	slog.Info("Webhook delivery replayed by admin", "delivery", delivery.ID, "remote_ip", getRemoteIP(r))
+
+	WriteJSONResponse(w, http.StatusAccepted, newWebhookDeliveryResponse(delivery))
+}
+
+func (h *WebhookHandler) writeError(w http.ResponseWriter, err error) {
+	WriteDomainError(w, err, "Failed to manage webhooks")
+}
+
+// newWebhookSubscriptionResponse leaves out the secret, which is write-only
+func newWebhookSubscriptionResponse(subscription *domain.WebhookSubscription) WebhookSubscriptionResponse {
+	response := WebhookSubscriptionResponse{
+		ID:        subscription.ID,
+		URL:       subscription.URL,
+		Events:    make([]string, 0, len(subscription.Events)),
+		CreatedAt: formatDate(subscription.CreatedAt),
+	}
+	for _, event := range subscription.Events {
+		response.Events = append(response.Events, event.String())
+	}
+	return response
+}
+
+func newWebhookDeliveryResponse(delivery *domain.WebhookDelivery) WebhookDeliveryResponse {
+	response := WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		SubscriptionID: delivery.SubscriptionID,
+		URL:            delivery.URL,
+		EventID:        delivery.Event.ID,
+		EventType:      delivery.Event.Type.String(),
+		CreatedAt:      formatDate(delivery.CreatedAt),
+		Attempts:       delivery.Attempts,
+		LastError:      delivery.LastError,
+		DeadAt:         formatDate(delivery.DeadAt),
+	}
+	if delivery.DeadAt.IsZero() {
+		response.NextAttemptAt = formatDate(delivery.NextAttemptAt)
+	}
+	return response
+}
@@ -0,0 +1,129 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/webhook"
+)
+
+func newTestWebhookHandler(t *testing.T) (*WebhookHandler, *webhook.Dispatcher) {
+	store, err := webhook.NewStore("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dispatcher := webhook.NewDispatcher(store, webhook.DefaultConfig())
+	return NewWebhookHandler(dispatcher), dispatcher
+}
+
+func TestWebhookHandler_Subscriptions(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{"Valid subscription", `{"url": "https://hooks.example/rkn", "secret": "s3cret", "events": ["registry.updated"]}`, http.StatusCreated},
+		{"Unknown event", `{"url": "https://hooks.example/rkn", "secret": "s3cret", "events": ["registry.deleted"]}`, http.StatusBadRequest},
+		{"Missing secret", `{"url": "https://hooks.example/rkn"}`, http.StatusBadRequest},
+		{"Invalid JSON", `{`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := newTestWebhookHandler(t)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/v1/webhooks", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			handler.Subscriptions(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+
+			var response WebhookSubscriptionResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			if w.Header().Get("Location") != "/admin/v1/webhooks/"+response.ID {
+				t.Errorf("unexpected Location %q", w.Header().Get("Location"))
+			}
+			if strings.Contains(w.Body.String(), "s3cret") {
+				t.Error("expected the secret to be left out of the response")
+			}
+
+			req = httptest.NewRequest(http.MethodGet, "/admin/v1/webhooks", nil)
+			w = httptest.NewRecorder()
+			handler.Subscriptions(w, req)
+
+			var list WebhookSubscriptionsResponse
+			json.Unmarshal(w.Body.Bytes(), &list)
+			if len(list.Subscriptions) != 1 || list.Subscriptions[0].Events[0] != "registry.updated" {
+				t.Errorf("unexpected subscriptions %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestWebhookHandler_DeleteSubscription(t *testing.T) {
+	handler, dispatcher := newTestWebhookHandler(t)
+	subscription, _ := dispatcher.AddSubscription("https://hooks.example/rkn", "s3cret", nil)
+
+	for _, expected := range []int{http.StatusNoContent, http.StatusNotFound} {
+		req := httptest.NewRequest(http.MethodDelete, "/admin/v1/webhooks/"+subscription.ID, nil)
+		req.SetPathValue("id", subscription.ID)
+		w := httptest.NewRecorder()
+		handler.Subscription(w, req)
+
+		if w.Code != expected {
+			t.Errorf("expected status %d, got %d", expected, w.Code)
+		}
+	}
+}
+
+func TestWebhookHandler_DeadLettersAndReplay(t *testing.T) {
+	store, _ := webhook.NewStore("")
+	store.PutSubscription(&domain.WebhookSubscription{ID: "sub", URL: "https://hooks.example/rkn", Secret: "s3cret"})
+	delivery := &domain.WebhookDelivery{
+		ID:             "d1",
+		SubscriptionID: "sub",
+		URL:            "https://hooks.example/rkn",
+		Event:          domain.WebhookEvent{ID: "e1", Type: domain.WebhookEventSourceUnhealthy, Source: "official"},
+		Attempts:       8,
+		LastError:      "webhook returned status 503",
+	}
+	store.Enqueue(delivery)
+	store.Kill(delivery)
+	handler := NewWebhookHandler(webhook.NewDispatcher(store, webhook.DefaultConfig()))
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/v1/webhooks/dead-letters", nil)
+	w := httptest.NewRecorder()
+	handler.DeadLetters(w, req)
+
+	var dead DeadLettersResponse
+	json.Unmarshal(w.Body.Bytes(), &dead)
+	if w.Code != http.StatusOK || len(dead.DeadLetters) != 1 {
+		t.Fatalf("expected one dead letter, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := dead.DeadLetters[0]; got.EventType != "source.unhealthy" || got.Attempts != 8 || got.LastError == "" {
+		t.Errorf("unexpected dead letter %+v", got)
+	}
+
+	for _, expected := range []int{http.StatusAccepted, http.StatusNotFound} {
+		req := httptest.NewRequest(http.MethodPost, "/admin/v1/webhooks/dead-letters/d1/replay", nil)
+		req.SetPathValue("id", "d1")
+		w := httptest.NewRecorder()
+		handler.Replay(w, req)
+
+		if w.Code != expected {
+			t.Errorf("expected status %d, got %d", expected, w.Code)
+		}
+	}
+	if len(store.Pending()) != 1 {
+		t.Error("expected the replayed delivery to be pending")
+	}
+}
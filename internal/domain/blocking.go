@@ -14,6 +14,7 @@ const (
 	BlockingTypeIP
 	BlockingTypeURLPath
 	BlockingTypeSNI
+	BlockingTypeSubnet
 )
 
 func (bt BlockingType) String() string {
@@ -28,11 +29,23 @@ func (bt BlockingType) String() string {
 		return "url_path"
 	case BlockingTypeSNI:
 		return "sni"
+	case BlockingTypeSubnet:
+		return "subnet"
 	default:
 		return "unknown"
 	}
 }
 
+// ParseBlockingType returns the blocking type with the given String form
+func ParseBlockingType(s string) (BlockingType, bool) {
+	for bt := BlockingTypeDomain; bt <= BlockingTypeSubnet; bt++ {
+		if bt.String() == s {
+			return bt, true
+		}
+	}
+	return BlockingTypeUnknown, false
+}
+
 type BlockingRule struct {
 	Type     BlockingType
 	Pattern  string
@@ -81,6 +94,10 @@ func (br *BlockingRule) validate() error {
 		if !IsValidDomain(br.Pattern) {
 			return ErrInvalidDomain
 		}
+	case BlockingTypeSubnet:
+		if !IsValidSubnet(br.Pattern) {
+			return ErrInvalidIP
+		}
 	default:
 		return ErrBlockingRuleInvalid
 	}
@@ -107,6 +124,8 @@ func (br *BlockingRule) Matches(url *URL) bool {
 		return strings.HasPrefix(normalized, br.Pattern)
 	case BlockingTypeSNI:
 		return normalized == br.Pattern
+	case BlockingTypeSubnet:
+		return SubnetContains(br.Pattern, normalized)
 	default:
 		return false
 	}
@@ -118,6 +137,8 @@ type BlockingResult struct {
 	Rule          *BlockingRule
 	Reason        BlockingType
 	CheckedAt     time.Time
+	// RegistryVersion is set when the result comes from a historical snapshot
+	RegistryVersion string
 }
 
 func NewBlockingResult(isBlocked bool, normalizedURL string, rule *BlockingRule) *BlockingResult {
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+type ChangeKind int
+
+const (
+	ChangeKindUnknown ChangeKind = iota
+	ChangeKindAdded
+	ChangeKindRemoved
+	ChangeKindChanged
+)
+
+func (ck ChangeKind) String() string {
+	switch ck {
+	case ChangeKindAdded:
+		return "added"
+	case ChangeKindRemoved:
+		return "removed"
+	case ChangeKindChanged:
+		return "changed"
+	default:
+		return "unknown"
+	}
+}
+
+// EntryChange is a single difference between two registry versions. Entries
+// are matched by their blocked value; Previous* fields are set for changes.
+type EntryChange struct {
+	Kind                ChangeKind
+	Value               string
+	Type                BlockingType
+	Decision            string
+	PreviousType        BlockingType
+	PreviousDecision    string
+	DecisionOrg         string
+	PreviousDecisionOrg string
+}
+
+// Changelog lists what changed when one registry version replaced another
+type Changelog struct {
+	FromVersion string
+	ToVersion   string
+	AppliedAt   time.Time
+	Added       int
+	Removed     int
+	Changed     int
+	Changes     []EntryChange
+}
+
+// DiffRegistries computes the changes that turn previous into current
+func DiffRegistries(previous, current *Registry) *Changelog {
+	changelog := &Changelog{
+		FromVersion: previous.Version,
+		ToVersion:   current.Version,
+		AppliedAt:   time.Now(),
+	}
+
+	before := make(map[string]*RegistryEntry, len(previous.Entries))
+	for _, entry := range previous.Entries {
+		before[entry.Value()] = entry
+	}
+
+	for _, entry := range current.Entries {
+		value := entry.Value()
+		old, ok := before[value]
+		if !ok {
+			changelog.Changes = append(changelog.Changes, EntryChange{
+				Kind:        ChangeKindAdded,
+				Value:       value,
+				Type:        entry.Type,
+				Decision:    entry.Decision,
+				DecisionOrg: entry.DecisionOrg,
+			})
+			changelog.Added++
+			continue
+		}
+		delete(before, value)
+
+		if old.Type != entry.Type || old.Decision != entry.Decision || old.DecisionOrg != entry.DecisionOrg {
+			changelog.Changes = append(changelog.Changes, EntryChange{
+				Kind:                ChangeKindChanged,
+				Value:               value,
+				Type:                entry.Type,
+				Decision:            entry.Decision,
+				DecisionOrg:         entry.DecisionOrg,
+				PreviousType:        old.Type,
+				PreviousDecision:    old.Decision,
+				PreviousDecisionOrg: old.DecisionOrg,
+			})
+			changelog.Changed++
+		}
+	}
+
+	for value, old := range before {
+		changelog.Changes = append(changelog.Changes, EntryChange{
+			Kind:        ChangeKindRemoved,
+			Value:       value,
+			Type:        old.Type,
+			Decision:    old.Decision,
+			DecisionOrg: old.DecisionOrg,
+		})
+		changelog.Removed++
+	}
+
+	sort.Slice(changelog.Changes, func(i, j int) bool {
+		return changelog.Changes[i].Value < changelog.Changes[j].Value
+	})
+
+	return changelog
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"testing"
+)
+
+func newTestRegistry(version string, entries ...*RegistryEntry) *Registry {
+	registry := NewRegistry()
+	registry.Version = version
+	for _, entry := range entries {
+		registry.AddEntry(entry)
+	}
+	return registry
+}
+
+func newTestEntry(entryType BlockingType, value, decision string) *RegistryEntry {
+	entry, _ := NewRegistryEntry(entryType, value)
+	entry.Decision = decision
+	return entry
+}
+
+func TestDiffRegistries(t *testing.T) {
+	previous := newTestRegistry("v1",
+		newTestEntry(BlockingTypeDomain, "kept.com", "27-31-2020/Ид1"),
+		newTestEntry(BlockingTypeDomain, "removed.com", "27-31-2020/Ид2"),
+		newTestEntry(BlockingTypeIP, "10.0.0.1", "27-31-2020/Ид3"),
+	)
+	current := newTestRegistry("v2",
+		newTestEntry(BlockingTypeDomain, "kept.com", "27-31-2020/Ид1"),
+		newTestEntry(BlockingTypeIP, "10.0.0.1", "27-31-2021/Ид9"),
+		newTestEntry(BlockingTypeWildcard, "*.added.com", "27-31-2021/Ид10"),
+	)
+
+	changelog := DiffRegistries(previous, current)
+
+	if changelog.FromVersion != "v1" || changelog.ToVersion != "v2" {
+		t.Errorf("unexpected versions: %s -> %s", changelog.FromVersion, changelog.ToVersion)
+	}
+	if changelog.Added != 1 || changelog.Removed != 1 || changelog.Changed != 1 {
+		t.Errorf("unexpected counts: added=%d removed=%d changed=%d", changelog.Added, changelog.Removed, changelog.Changed)
+	}
+
+	want := []struct {
+		kind  ChangeKind
+		value string
+	}{
+		{ChangeKindAdded, "*.added.com"},
+		{ChangeKindChanged, "10.0.0.1"},
+		{ChangeKindRemoved, "removed.com"},
+	}
+	if len(changelog.Changes) != len(want) {
+		t.Fatalf("expected %d changes, got %+v", len(want), changelog.Changes)
+	}
+	for i, w := range want {
+		if changelog.Changes[i].Kind != w.kind || changelog.Changes[i].Value != w.value {
+			t.Errorf("change %d: expected %s %s, got %s %s", i, w.kind, w.value, changelog.Changes[i].Kind, changelog.Changes[i].Value)
+		}
+	}
+
+	changed := changelog.Changes[1]
+	if changed.PreviousDecision != "27-31-2020/Ид3" || changed.Decision != "27-31-2021/Ид9" {
+		t.Errorf("unexpected decision change: %+v", changed)
+	}
+}
+
+func TestDiffRegistries_Identical(t *testing.T) {
+	previous := newTestRegistry("v1", newTestEntry(BlockingTypeDomain, "example.com", ""))
+	current := newTestRegistry("v2", newTestEntry(BlockingTypeDomain, "example.com", ""))
+
+	changelog := DiffRegistries(previous, current)
+	if len(changelog.Changes) != 0 {
+		t.Errorf("expected no changes, got %+v", changelog.Changes)
+	}
+}
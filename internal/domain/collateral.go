@@ -0,0 +1,108 @@
+package domain
+
+import "time"
+
+// HostedDomain is a domain we host together with the addresses it resolves to
+type HostedDomain struct {
+	Domain string
+	IPs    []string
+}
+
+type CollateralStatus int
+
+const (
+	CollateralStatusUnknown CollateralStatus = iota
+	// CollateralStatusClear is a domain no rule affects
+	CollateralStatusClear
+	// CollateralStatusListed is a domain the registry names itself
+	CollateralStatusListed
+	// CollateralStatusCollateral is a domain affected only through IP or
+	// subnet rules whose records name other domains
+	CollateralStatusCollateral
+)
+
+func (cs CollateralStatus) String() string {
+	switch cs {
+	case CollateralStatusClear:
+		return "clear"
+	case CollateralStatusListed:
+		return "listed"
+	case CollateralStatusCollateral:
+		return "collateral"
+	default:
+		return "unknown"
+	}
+}
+
+// CollateralHit is a registry record that affects a hosted domain through one
+// of its IPs
+type CollateralHit struct {
+	IP     string
+	Record *IPRecord
+}
+
+// CollateralFinding is the analysis of one hosted domain. Rule is the rule
+// naming the domain when it is listed; Hits are the IP and subnet records
+// that name other domains.
+type CollateralFinding struct {
+	Domain string
+	IPs    []string
+	Status CollateralStatus
+	Rule   *BlockingRule
+	Hits   []CollateralHit
+}
+
+// CollateralReport is the analysis of a list of hosted domains against one
+// registry version
+type CollateralReport struct {
+	Version     string
+	GeneratedAt time.Time
+	Findings    []*CollateralFinding
+}
+
+// Count returns the number of domains with the given status
+func (r *CollateralReport) Count(status CollateralStatus) int {
+	count := 0
+	for _, finding := range r.Findings {
+		if finding.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+type JobStatus int
+
+const (
+	JobStatusUnknown JobStatus = iota
+	JobStatusPending
+	JobStatusRunning
+	JobStatusDone
+	JobStatusFailed
+)
+
+func (js JobStatus) String() string {
+	switch js {
+	case JobStatusPending:
+		return "pending"
+	case JobStatusRunning:
+		return "running"
+	case JobStatusDone:
+		return "done"
+	case JobStatusFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// CollateralJob is a collateral analysis run in the background
+type CollateralJob struct {
+	ID          string
+	Status      JobStatus
+	Domains     int
+	SubmittedAt time.Time
+	FinishedAt  time.Time
+	Report      *CollateralReport
+	Error       string
+}
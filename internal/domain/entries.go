@@ -0,0 +1,37 @@
+package domain
+
+const (
+	// DefaultEntryPageSize is the page size used when a query sets none
+	DefaultEntryPageSize = 100
+	// MaxEntryPageSize caps the page size of a query
+	MaxEntryPageSize = 1000
+)
+
+// EntryQuery selects a page of registry entries. The zero Type matches every
+// type and an empty Query matches every pattern.
+type EntryQuery struct {
+	Type   BlockingType
+	Query  string
+	Cursor string
+	Limit  int
+}
+
+// PageSize returns the query limit clamped to the allowed page sizes
+func (q EntryQuery) PageSize() int {
+	switch {
+	case q.Limit <= 0:
+		return DefaultEntryPageSize
+	case q.Limit > MaxEntryPageSize:
+		return MaxEntryPageSize
+	default:
+		return q.Limit
+	}
+}
+
+// EntryPage is one page of registry entries. NextCursor is empty on the last
+// page and only valid for the registry version the page was read from.
+type EntryPage struct {
+	Version    string
+	Entries    []*RegistryEntry
+	NextCursor string
+}
@@ -3,12 +3,31 @@ package domain
 import "errors"
 
 var (
-	ErrInvalidURL           = errors.New("invalid URL format")
-	ErrEmptyURL             = errors.New("URL cannot be empty")
-	ErrUnsupportedProtocol  = errors.New("unsupported protocol")
-	ErrInvalidDomain        = errors.New("invalid domain format")
-	ErrInvalidIP            = errors.New("invalid IP address format")
-	ErrNormalizationFailed  = errors.New("URL normalization failed")
-	ErrBlockingRuleInvalid  = errors.New("blocking rule is invalid")
-	ErrRegistryEntryInvalid = errors.New("registry entry is invalid")
+	ErrInvalidURL               = errors.New("invalid URL format")
+	ErrEmptyURL                 = errors.New("URL cannot be empty")
+	ErrUnsupportedProtocol      = errors.New("unsupported protocol")
+	ErrInvalidDomain            = errors.New("invalid domain format")
+	ErrInvalidIP                = errors.New("invalid IP address format")
+	ErrNormalizationFailed      = errors.New("URL normalization failed")
+	ErrBlockingRuleInvalid      = errors.New("blocking rule is invalid")
+	ErrRegistryEntryInvalid     = errors.New("registry entry is invalid")
+	ErrRegistrySignatureInvalid = errors.New("registry signature verification failed")
+	ErrRegistryQuarantined      = errors.New("registry update quarantined")
+	ErrRegistryNotReady         = errors.New("registry is not loaded yet")
+	ErrNoQuarantinedUpdate      = errors.New("no quarantined registry update")
+	ErrUnknownRegistryVersion   = errors.New("unknown registry version")
+	ErrSnapshotNotRetained      = errors.New("registry snapshot not retained")
+	ErrEntryNotFound            = errors.New("registry entry not found")
+	ErrInvalidCursor            = errors.New("invalid pagination cursor")
+	ErrCursorExpired            = errors.New("pagination cursor belongs to another registry version")
+	ErrInvalidSearchQuery       = errors.New("invalid search query")
+	ErrInvalidHostedList        = errors.New("invalid hosted domain list")
+	ErrJobNotFound              = errors.New("job not found")
+	ErrTooManyJobs              = errors.New("too many pending jobs")
+	ErrWatchItemNotFound        = errors.New("watch item not found")
+	ErrInvalidWatchItem         = errors.New("invalid watch item")
+	ErrWebhookNotFound          = errors.New("webhook subscription not found")
+	ErrInvalidWebhook           = errors.New("invalid webhook subscription")
+	ErrDeliveryNotFound         = errors.New("webhook delivery not found")
+	ErrInvalidCredentials       = errors.New("invalid credentials")
 )
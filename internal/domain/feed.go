@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// RegistryEvent announces a registry swapped into the store. Diff is nil for
+// the first registry applied after startup.
+type RegistryEvent struct {
+	Version   string
+	Size      int
+	Counts    map[BlockingType]int
+	AppliedAt time.Time
+	Diff      *DiffSummary
+}
+
+// DiffSummary counts the changes from the previously applied registry
+type DiffSummary struct {
+	FromVersion string
+	Added       int
+	Removed     int
+	Changed     int
+}
@@ -0,0 +1,27 @@
+package domain
+
+// HealthCheck is the outcome of one readiness check. Message explains the
+// outcome, in particular why the check failed.
+type HealthCheck struct {
+	Name    string
+	Healthy bool
+	Message string
+}
+
+// HealthReport is the outcome of a readiness probe; it is healthy only when
+// every check passed
+type HealthReport struct {
+	Healthy bool
+	Checks  []HealthCheck
+}
+
+// NewHealthReport builds a report from its checks
+func NewHealthReport(checks ...HealthCheck) *HealthReport {
+	report := &HealthReport{Healthy: true, Checks: checks}
+	for _, check := range checks {
+		if !check.Healthy {
+			report.Healthy = false
+		}
+	}
+	return report
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// HistoryEvent records a pattern entering or leaving the registry
+type HistoryEvent struct {
+	Kind    ChangeKind // ChangeKindAdded or ChangeKindRemoved
+	Type    BlockingType
+	Version string
+	At      time.Time
+}
+
+// PatternHistory is the timeline of a single registry pattern, oldest first
+type PatternHistory struct {
+	Pattern string
+	Events  []HistoryEvent
+}
+
+// Blocked reports whether the pattern is in the most recent registry version
+func (h *PatternHistory) Blocked() bool {
+	return len(h.Events) > 0 && h.Events[len(h.Events)-1].Kind == ChangeKindAdded
+}
+
+// CoveringPatterns returns the registry patterns that block the given domain:
+// the domain itself followed by every wildcard on it or one of its parents.
+// Wildcards, IPs and URL paths only cover themselves.
+func CoveringPatterns(domain string) []string {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	if domain == "" {
+		return nil
+	}
+
+	patterns := []string{domain}
+	if strings.HasPrefix(domain, "*.") || strings.Contains(domain, "/") || IsValidIP(domain) {
+		return patterns
+	}
+
+	for suffix := domain; ; {
+		patterns = append(patterns, "*."+suffix)
+		dot := strings.IndexByte(suffix, '.')
+		if dot < 0 {
+			break
+		}
+		suffix = suffix[dot+1:]
+	}
+
+	return patterns
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCoveringPatterns(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{input: "Sub.Example.com.", expected: []string{"sub.example.com", "*.sub.example.com", "*.example.com", "*.com"}},
+		{input: "*.example.com", expected: []string{"*.example.com"}},
+		{input: "10.0.0.1", expected: []string{"10.0.0.1"}},
+		{input: "example.com/path", expected: []string{"example.com/path"}},
+		{input: "  ", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := CoveringPatterns(tt.input); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"context"
+	"slices"
+)
+
+// Scope grants access to a group of REST routes and RPCs
+type Scope string
+
+const (
+	// ScopeCheck covers URL checks and collateral-damage jobs
+	ScopeCheck Scope = "check"
+	// ScopeStats covers registry statistics and metrics
+	ScopeStats Scope = "stats"
+	// ScopeRegistry covers browsing, searching and following the registry
+	ScopeRegistry Scope = "registry"
+	// ScopeWatchlist covers watch items and their events
+	ScopeWatchlist Scope = "watchlist"
+	// ScopeAdmin covers the admin API and grants every other scope
+	ScopeAdmin Scope = "admin"
+)
+
+// Scopes lists every scope a credential can carry
+var Scopes = []Scope{ScopeCheck, ScopeStats, ScopeRegistry, ScopeWatchlist, ScopeAdmin}
+
+// ParseScope returns the scope with the given name
+func ParseScope(s string) (Scope, bool) {
+	scope := Scope(s)
+	return scope, slices.Contains(Scopes, scope)
+}
+
+// AuthMethod is how a caller proved its identity
+type AuthMethod string
+
+const (
+	AuthMethodAnonymous   AuthMethod = "anonymous"
+	AuthMethodAPIKey      AuthMethod = "api_key"
+	AuthMethodAdminToken  AuthMethod = "admin_token"
+	AuthMethodJWT         AuthMethod = "jwt"
+	AuthMethodCertificate AuthMethod = "client_cert"
+)
+
+// Identity is the caller of a request and the scopes it was granted
+type Identity struct {
+	Method  AuthMethod
+	Subject string
+	Scopes  []Scope
+}
+
+// IsAnonymous reports whether the caller presented no credentials
+func (i *Identity) IsAnonymous() bool {
+	return i.Method == AuthMethodAnonymous
+}
+
+// HasScope reports whether the caller was granted scope, directly or
+// through the admin scope
+func (i *Identity) HasScope(scope Scope) bool {
+	return slices.Contains(i.Scopes, scope) || slices.Contains(i.Scopes, ScopeAdmin)
+}
+
+// String identifies the caller in logs
+func (i *Identity) String() string {
+	if i.IsAnonymous() {
+		return string(i.Method)
+	}
+	return string(i.Method) + ":" + i.Subject
+}
+
+type identityKey struct{}
+
+// ContextWithIdentity returns a copy of ctx carrying the caller identity
+func ContextWithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the caller identity carried by ctx, or nil
+func IdentityFromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityKey{}).(*Identity)
+	return identity
+}
@@ -0,0 +1,80 @@
+package domain
+
+import "time"
+
+// MaxReportedIngestIssues caps how many rejected and duplicate entries an
+// IngestReport keeps verbatim; the counters keep counting past it
+const MaxReportedIngestIssues = 1000
+
+// RejectedEntry is a registry value that could not be ingested
+type RejectedEntry struct {
+	Line   int
+	Value  string
+	Reason string
+}
+
+// DuplicateEntry is a registry value that was already ingested earlier in the
+// same dump
+type DuplicateEntry struct {
+	Line      int
+	FirstLine int
+	Value     string
+}
+
+// IngestReport describes how a single registry dump was ingested
+type IngestReport struct {
+	Format    string
+	Encoding  string
+	StartedAt time.Time
+	Duration  time.Duration
+
+	RowsRead       int
+	Accepted       int
+	CountsByType   map[BlockingType]int
+	RejectedCount  int
+	Rejected       []RejectedEntry
+	DuplicateCount int
+	Duplicates     []DuplicateEntry
+}
+
+func NewIngestReport(format string) *IngestReport {
+	return &IngestReport{
+		Format:       format,
+		StartedAt:    time.Now(),
+		CountsByType: make(map[BlockingType]int),
+	}
+}
+
+func (r *IngestReport) AddAccepted(blockingType BlockingType) {
+	r.Accepted++
+	r.CountsByType[blockingType]++
+}
+
+func (r *IngestReport) AddRejected(line int, value, reason string) {
+	r.RejectedCount++
+	if len(r.Rejected) < MaxReportedIngestIssues {
+		r.Rejected = append(r.Rejected, RejectedEntry{Line: line, Value: value, Reason: reason})
+	}
+}
+
+func (r *IngestReport) AddDuplicate(line, firstLine int, value string) {
+	r.DuplicateCount++
+	if len(r.Duplicates) < MaxReportedIngestIssues {
+		r.Duplicates = append(r.Duplicates, DuplicateEntry{Line: line, FirstLine: firstLine, Value: value})
+	}
+}
+
+// Finish records the total ingest duration
+func (r *IngestReport) Finish() {
+	r.Duration = time.Since(r.StartedAt)
+}
+
+// QuarantinedUpdate describes a fetched registry that tripped an update
+// guardrail and was held back instead of replacing the current one
+type QuarantinedUpdate struct {
+	Reason        string
+	QuarantinedAt time.Time
+	CurrentSize   int
+	CandidateSize int
+	Report        *IngestReport
+}
@@ -0,0 +1,58 @@
+package domain
+
+import "time"
+
+// IPMatchReason tells why a registry record references an IP address
+type IPMatchReason int
+
+const (
+	IPMatchUnknown IPMatchReason = iota
+	// IPMatchDirect is a record that lists the IP on its own, without any
+	// domain or URL
+	IPMatchDirect
+	// IPMatchDomain is a domain or URL record that carried the IP
+	IPMatchDomain
+	// IPMatchSubnet is a record listing a subnet that covers the IP
+	IPMatchSubnet
+)
+
+func (r IPMatchReason) String() string {
+	switch r {
+	case IPMatchDirect:
+		return "ip"
+	case IPMatchDomain:
+		return "domain"
+	case IPMatchSubnet:
+		return "subnet"
+	default:
+		return "unknown"
+	}
+}
+
+// IPRecord is a registry record referencing an IP address, with everything
+// else the record listed. Pattern is the IP or subnet of the record that
+// matched.
+type IPRecord struct {
+	RecordID    string
+	Reason      IPMatchReason
+	Pattern     string
+	IPs         []string
+	Domains     []string
+	URLs        []string
+	Decision    string
+	DecisionOrg string
+	BlockedDate time.Time
+}
+
+// IPLookupResult holds the records of one registry version that reference an
+// IP address
+type IPLookupResult struct {
+	IP      string
+	Version string
+	Records []*IPRecord
+}
+
+// Listed reports whether any record references the IP
+func (r *IPLookupResult) Listed() bool {
+	return len(r.Records) > 0
+}
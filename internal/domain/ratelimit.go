@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// FailedAuthenticationRoute is the route failed authentication attempts are
+// charged to, by the address they came from
+const FailedAuthenticationRoute = "failed_authentication"
+
+// RateLimitDecision is the outcome of rate limiting one call
+type RateLimitDecision struct {
+	Allowed bool
+	// RetryAfter is how long a rejected client should wait before retrying
+	RetryAfter time.Duration
+	// QuotaExceeded tells a spent daily quota apart from an exceeded rate
+	QuotaExceeded bool
+}
@@ -6,7 +6,9 @@ import (
 )
 
 type RegistryEntry struct {
-	ID          string
+	ID string
+	// RecordID identifies the registry record the entry was first listed in
+	RecordID    string
 	Type        BlockingType
 	Domain      string
 	IP          string
@@ -39,6 +41,11 @@ func NewRegistryEntry(entryType BlockingType, value string) (*RegistryEntry, err
 			return nil, ErrInvalidIP
 		}
 		entry.IP = value
+	case BlockingTypeSubnet:
+		if !IsValidSubnet(value) {
+			return nil, ErrInvalidIP
+		}
+		entry.IP = value
 	case BlockingTypeURLPath:
 		entry.URL = value
 	default:
@@ -48,17 +55,23 @@ func NewRegistryEntry(entryType BlockingType, value string) (*RegistryEntry, err
 	return entry, nil
 }
 
-func (re *RegistryEntry) ToBlockingRule() (*BlockingRule, error) {
-	var pattern string
-
+// Value returns the blocked value of the entry according to its type
+func (re *RegistryEntry) Value() string {
 	switch re.Type {
 	case BlockingTypeDomain, BlockingTypeWildcard, BlockingTypeSNI:
-		pattern = re.Domain
-	case BlockingTypeIP:
-		pattern = re.IP
+		return re.Domain
+	case BlockingTypeIP, BlockingTypeSubnet:
+		return re.IP
 	case BlockingTypeURLPath:
-		pattern = re.URL
+		return re.URL
 	default:
+		return ""
+	}
+}
+
+func (re *RegistryEntry) ToBlockingRule() (*BlockingRule, error) {
+	pattern := re.Value()
+	if pattern == "" {
 		return nil, ErrBlockingRuleInvalid
 	}
 
@@ -75,7 +88,7 @@ func (re *RegistryEntry) IsValid() bool {
 	switch re.Type {
 	case BlockingTypeDomain, BlockingTypeWildcard, BlockingTypeSNI:
 		return re.Domain != ""
-	case BlockingTypeIP:
+	case BlockingTypeIP, BlockingTypeSubnet:
 		return re.IP != ""
 	case BlockingTypeURLPath:
 		return re.URL != ""
@@ -84,8 +97,18 @@ func (re *RegistryEntry) IsValid() bool {
 	}
 }
 
+// RecordReference links an entry to a later registry record that listed the
+// same value again. Duplicates are not stored as entries, so this is the only
+// trace of the later record.
+type RecordReference struct {
+	Entry    int32
+	RecordID string
+}
+
 type Registry struct {
-	Entries     []*RegistryEntry
+	Entries []*RegistryEntry
+	// References holds the records that repeated an already listed entry
+	References  []RecordReference
 	LastUpdated time.Time
 	Version     string
 	Source      string
@@ -109,6 +132,11 @@ func (r *Registry) AddEntry(entry *RegistryEntry) error {
 	return nil
 }
 
+// AddReference records that a later record listed the entry at index again
+func (r *Registry) AddReference(index int32, recordID string) {
+	r.References = append(r.References, RecordReference{Entry: index, RecordID: recordID})
+}
+
 func (r *Registry) GetEntriesByType(blockingType BlockingType) []*RegistryEntry {
 	var entries []*RegistryEntry
 	for _, entry := range r.Entries {
@@ -122,3 +150,12 @@ func (r *Registry) GetEntriesByType(blockingType BlockingType) []*RegistryEntry
 func (r *Registry) Size() int {
 	return len(r.Entries)
 }
+
+// CountsByType returns the number of entries of each blocking type
+func (r *Registry) CountsByType() map[BlockingType]int {
+	counts := make(map[BlockingType]int)
+	for _, entry := range r.Entries {
+		counts[entry.Type]++
+	}
+	return counts
+}
@@ -0,0 +1,56 @@
+package domain
+
+type SearchMode int
+
+const (
+	SearchModeUnknown SearchMode = iota
+	SearchModeSuffix
+	SearchModeSubstring
+	SearchModeRegex
+)
+
+func (sm SearchMode) String() string {
+	switch sm {
+	case SearchModeSuffix:
+		return "suffix"
+	case SearchModeSubstring:
+		return "substring"
+	case SearchModeRegex:
+		return "regex"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseSearchMode returns the search mode with the given String form
+func ParseSearchMode(s string) (SearchMode, bool) {
+	for sm := SearchModeSuffix; sm <= SearchModeRegex; sm++ {
+		if sm.String() == s {
+			return sm, true
+		}
+	}
+	return SearchModeUnknown, false
+}
+
+// SearchQuery searches registry patterns. Suffix queries match every entry
+// at or below a domain, for example "example.com" or "*.example.com".
+type SearchQuery struct {
+	Mode    SearchMode
+	Pattern string
+	Limit   int
+}
+
+// PageSize returns the query limit clamped to the allowed page sizes
+func (q SearchQuery) PageSize() int {
+	return EntryQuery{Limit: q.Limit}.PageSize()
+}
+
+// SearchResult holds the entries matching a search. Truncated is set when
+// more entries matched than the limit allowed, and TimedOut when the search
+// ran out of time before covering the whole registry.
+type SearchResult struct {
+	Version   string
+	Entries   []*RegistryEntry
+	Truncated bool
+	TimedOut  bool
+}
@@ -16,6 +16,9 @@ var (
 	wwwRegex  = regexp.MustCompile(`^www\.`)
 )
 
+// supportedSchemes are the protocols of the resources the registry blocks
+var supportedSchemes = map[string]bool{"http": true, "https": true, "ftp": true}
+
 type URLNormalizer struct {
 	idnProfile *idna.Profile
 }
@@ -46,6 +49,10 @@ func (n *URLNormalizer) Normalize(rawURL string) (string, error) {
 		return "", domain.ErrInvalidURL
 	}
 
+	if !supportedSchemes[parsedURL.Scheme] {
+		return "", domain.ErrUnsupportedProtocol
+	}
+
 	if parsedURL.Host == "" {
 		return "", domain.ErrInvalidURL
 	}
@@ -6,6 +6,16 @@ import (
 	"github.com/kerim-dauren/rkn-checker/internal/domain"
 )
 
+func TestURLNormalizer_Normalize_UnsupportedProtocol(t *testing.T) {
+	normalizer := NewURLNormalizer()
+
+	for _, input := range []string{"gopher://example.com", "file:///etc/hosts", "ws://example.com"} {
+		if _, err := normalizer.Normalize(input); err != domain.ErrUnsupportedProtocol {
+			t.Errorf("Normalize(%q) error = %v, want %v", input, err, domain.ErrUnsupportedProtocol)
+		}
+	}
+}
+
 func TestURLNormalizer_Normalize(t *testing.T) {
 	normalizer := NewURLNormalizer()
 
@@ -53,6 +63,7 @@ func TestURLNormalizer_Normalize(t *testing.T) {
 		{"empty URL", "", "", true},
 		{"invalid URL", "not-a-url", "", true},
 		{"protocol only", "https://", "", true},
+		{"unsupported protocol", "gopher://example.com", "", true},
 	}
 
 	for _, tt := range tests {
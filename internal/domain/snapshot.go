@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// RegistryPoint selects the registry a check is answered from, either the one
+// active at a moment or a specific version. The zero value selects the
+// current registry.
+type RegistryPoint struct {
+	At      time.Time
+	Version string
+}
+
+// IsCurrent reports whether the point selects the current registry
+func (p RegistryPoint) IsCurrent() bool {
+	return p.At.IsZero() && p.Version == ""
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"net"
+	"net/netip"
 	"strings"
 )
 
@@ -70,3 +71,22 @@ func IsValidDomain(domain string) bool {
 func IsValidIP(ip string) bool {
 	return net.ParseIP(ip) != nil
 }
+
+// IsValidSubnet reports whether subnet is an address range in CIDR notation
+func IsValidSubnet(subnet string) bool {
+	_, err := netip.ParsePrefix(subnet)
+	return err == nil
+}
+
+// SubnetContains reports whether the CIDR subnet covers the IP address
+func SubnetContains(subnet, ip string) bool {
+	prefix, err := netip.ParsePrefix(subnet)
+	if err != nil {
+		return false
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	return prefix.Contains(addr.Unmap())
+}
@@ -0,0 +1,70 @@
+package domain
+
+import "time"
+
+// WatchItem is a domain, URL or IP watched for registry changes, with its
+// state as of the last evaluated registry version
+type WatchItem struct {
+	ID          string
+	Target      string
+	Normalized  string
+	CallbackURL string
+	CreatedAt   time.Time
+
+	Blocked   bool
+	Rule      string
+	RuleType  BlockingType
+	Version   string
+	ChangedAt time.Time
+}
+
+type WatchEventKind int
+
+const (
+	WatchEventUnknown WatchEventKind = iota
+	WatchEventBecameBlocked
+	WatchEventBecameUnblocked
+)
+
+func (k WatchEventKind) String() string {
+	switch k {
+	case WatchEventBecameBlocked:
+		return "became_blocked"
+	case WatchEventBecameUnblocked:
+		return "became_unblocked"
+	default:
+		return "unknown"
+	}
+}
+
+// WatchEvent records a watched target changing state. Rule is the rule that
+// blocks the target, or the one that blocked it before it was unblocked.
+type WatchEvent struct {
+	ID       int64
+	ItemID   string
+	Target   string
+	Kind     WatchEventKind
+	Rule     string
+	RuleType BlockingType
+	Version  string
+	At       time.Time
+}
+
+// WatchCallback is a watch event on its way to a callback URL
+type WatchCallback struct {
+	URL   string
+	Event *WatchEvent
+}
+
+// WatchEventQuery selects watch events newer than After, optionally for a
+// single item
+type WatchEventQuery struct {
+	ItemID string
+	After  int64
+	Limit  int
+}
+
+// PageSize returns the query limit clamped to the allowed page sizes
+func (q WatchEventQuery) PageSize() int {
+	return EntryQuery{Limit: q.Limit}.PageSize()
+}
@@ -0,0 +1,106 @@
+package domain
+
+import "time"
+
+type WebhookEventType int
+
+const (
+	WebhookEventUnknown WebhookEventType = iota
+	WebhookEventRegistryUpdated
+	WebhookEventRegistryUpdateFailed
+	WebhookEventSourceUnhealthy
+	// WebhookEventWatchChanged is sent only to the callback URLs of watch
+	// items, not to subscriptions
+	WebhookEventWatchChanged
+)
+
+func (t WebhookEventType) String() string {
+	switch t {
+	case WebhookEventRegistryUpdated:
+		return "registry.updated"
+	case WebhookEventRegistryUpdateFailed:
+		return "registry.update_failed"
+	case WebhookEventSourceUnhealthy:
+		return "source.unhealthy"
+	case WebhookEventWatchChanged:
+		return "watch.changed"
+	default:
+		return "unknown"
+	}
+}
+
+// WebhookEventTypes lists the event types a subscription can receive
+var WebhookEventTypes = []WebhookEventType{
+	WebhookEventRegistryUpdated,
+	WebhookEventRegistryUpdateFailed,
+	WebhookEventSourceUnhealthy,
+}
+
+// ParseWebhookEventType returns the event type with the given String form
+func ParseWebhookEventType(s string) (WebhookEventType, bool) {
+	for _, t := range WebhookEventTypes {
+		if t.String() == s {
+			return t, true
+		}
+	}
+	return WebhookEventUnknown, false
+}
+
+// WebhookEvent is a scheduler event sent to webhook subscriptions, or a
+// watch event sent to a callback URL. Version, Size and Counts describe an
+// updated registry, Error and ConsecutiveFailures a failed update, Source an
+// unhealthy source and Watch a watched target that changed state.
+type WebhookEvent struct {
+	ID         string
+	Type       WebhookEventType
+	OccurredAt time.Time
+
+	Version string
+	Size    int
+	Counts  map[BlockingType]int
+
+	Error               string
+	ConsecutiveFailures int
+
+	Source string
+
+	Watch *WatchEvent
+}
+
+// WebhookSubscription receives the events of the listed types, or of every
+// type when Events is empty, signed with its own secret
+type WebhookSubscription struct {
+	ID        string
+	URL       string
+	Secret    string
+	Events    []WebhookEventType
+	CreatedAt time.Time
+}
+
+// Wants reports whether the subscription receives events of the given type
+func (s *WebhookSubscription) Wants(t WebhookEventType) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, want := range s.Events {
+		if want == t {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event on its way to one subscription, or to a
+// watch callback URL when SubscriptionID is empty. A delivery that ran out
+// of attempts is dead-lettered with DeadAt set.
+type WebhookDelivery struct {
+	ID             string
+	SubscriptionID string
+	URL            string
+	Event          WebhookEvent
+	CreatedAt      time.Time
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      string
+	DeadAt         time.Time
+}
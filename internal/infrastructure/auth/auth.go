@@ -0,0 +1,161 @@
+// Package auth authenticates API callers by API key, admin token, JWT or TLS
+// client certificate and resolves the scopes they are granted
+package auth
+
+import (
+	"crypto/subtle"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// adminSubject identifies callers holding the admin token
+const adminSubject = "admin"
+
+// Config holds the credentials accepted from callers
+type Config struct {
+	// Keys lists static API keys as name:sha256:scope+scope, comma-separated
+	Keys string
+	// KeysFile points to a JSON array of API keys
+	KeysFile string
+	// AdminToken is accepted as a bearer token granting the admin scope
+	AdminToken string
+
+	// JWKSFile enables JWT bearer tokens signed by one of its keys
+	JWKSFile string
+	// Issuer and Audience are required on JWTs when set
+	Issuer   string
+	Audience string
+
+	// CertificateScopes are granted to verified TLS client certificates.
+	// Certificates are not accepted when empty.
+	CertificateScopes []domain.Scope
+	// AnonymousScopes are granted to callers presenting no credentials
+	AnonymousScopes []domain.Scope
+}
+
+// Authenticator resolves callers from the credentials configured in Config
+type Authenticator struct {
+	// keys maps key hashes to their client identity
+	keys              map[string]*domain.Identity
+	adminToken        string
+	jwt               *jwtVerifier
+	certificateScopes []domain.Scope
+	anonymous         *domain.Identity
+	now               func() time.Time
+}
+
+// New loads the credentials of cfg
+func New(cfg Config) (*Authenticator, error) {
+	keys, err := ParseKeys(cfg.Keys)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.KeysFile != "" {
+		fileKeys, err := LoadKeys(cfg.KeysFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, fileKeys...)
+	}
+
+	if err := ValidateScopes(cfg.CertificateScopes); err != nil {
+		return nil, fmt.Errorf("certificate scopes: %w", err)
+	}
+	if err := ValidateScopes(cfg.AnonymousScopes); err != nil {
+		return nil, fmt.Errorf("anonymous scopes: %w", err)
+	}
+
+	a := &Authenticator{
+		keys:              make(map[string]*domain.Identity, len(keys)),
+		adminToken:        cfg.AdminToken,
+		certificateScopes: cfg.CertificateScopes,
+		anonymous:         &domain.Identity{Method: domain.AuthMethodAnonymous, Scopes: cfg.AnonymousScopes},
+		now:               time.Now,
+	}
+
+	for _, key := range keys {
+		hash := strings.ToLower(key.SHA256)
+		if _, ok := a.keys[hash]; ok {
+			return nil, fmt.Errorf("API key %q reuses the key of another client", key.Name)
+		}
+		a.keys[hash] = &domain.Identity{Method: domain.AuthMethodAPIKey, Subject: key.Name, Scopes: key.Scopes}
+	}
+
+	if cfg.JWKSFile != "" {
+		a.jwt, err = newJWTVerifier(cfg.JWKSFile, cfg.Issuer, cfg.Audience)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return a, nil
+}
+
+// AuthenticateToken resolves a bearer token: the admin token, an API key or
+// a JWT
+func (a *Authenticator) AuthenticateToken(token string) (*domain.Identity, error) {
+	if token == "" {
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	if a.adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(a.adminToken)) == 1 {
+		return &domain.Identity{
+			Method:  domain.AuthMethodAdminToken,
+			Subject: adminSubject,
+			Scopes:  []domain.Scope{domain.ScopeAdmin},
+		}, nil
+	}
+
+	// Keys are looked up by hash, so lookups reveal nothing about the keys
+	if identity, ok := a.keys[HashKey(token)]; ok {
+		return identity, nil
+	}
+
+	if a.jwt != nil && strings.Count(token, ".") == 2 {
+		identity, err := a.jwt.verify(token, a.now())
+		if err != nil {
+			slog.Debug("Rejected JWT", "error", err)
+			return nil, domain.ErrInvalidCredentials
+		}
+		return identity, nil
+	}
+
+	return nil, domain.ErrInvalidCredentials
+}
+
+// AuthenticateCertificate resolves a client certificate verified during the
+// TLS handshake, identified by its common name or first SAN
+func (a *Authenticator) AuthenticateCertificate(cert *x509.Certificate) (*domain.Identity, error) {
+	if len(a.certificateScopes) == 0 {
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	subject := cert.Subject.CommonName
+	switch {
+	case subject != "":
+	case len(cert.DNSNames) > 0:
+		subject = cert.DNSNames[0]
+	case len(cert.URIs) > 0:
+		subject = cert.URIs[0].String()
+	case len(cert.EmailAddresses) > 0:
+		subject = cert.EmailAddresses[0]
+	default:
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	return &domain.Identity{
+		Method:  domain.AuthMethodCertificate,
+		Subject: subject,
+		Scopes:  a.certificateScopes,
+	}, nil
+}
+
+// Anonymous is the identity of callers presenting no credentials
+func (a *Authenticator) Anonymous() *domain.Identity {
+	return a.anonymous
+}
@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+func TestAuthenticator_AuthenticateToken(t *testing.T) {
+	a, err := New(Config{
+		Keys:       "ci:" + HashKey("ci-secret") + ":check+stats",
+		AdminToken: "0123456789abcdef",
+	})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	identity, err := a.AuthenticateToken("ci-secret")
+	if err != nil {
+		t.Fatalf("AuthenticateToken() unexpected error: %v", err)
+	}
+	if identity.String() != "api_key:ci" || !identity.HasScope(domain.ScopeStats) || identity.HasScope(domain.ScopeAdmin) {
+		t.Errorf("unexpected identity %+v", identity)
+	}
+
+	identity, err = a.AuthenticateToken("0123456789abcdef")
+	if err != nil {
+		t.Fatalf("AuthenticateToken() unexpected error: %v", err)
+	}
+	if identity.Method != domain.AuthMethodAdminToken || !identity.HasScope(domain.ScopeWatchlist) {
+		t.Errorf("expected the admin token to grant every scope, got %+v", identity)
+	}
+
+	for _, token := range []string{"", "unknown", HashKey("ci-secret")} {
+		if _, err := a.AuthenticateToken(token); !errors.Is(err, domain.ErrInvalidCredentials) {
+			t.Errorf("AuthenticateToken(%q) expected ErrInvalidCredentials, got %v", token, err)
+		}
+	}
+}
+
+func TestAuthenticator_AuthenticateToken_JWT(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: key.Public(), KeyID: "signing", Algorithm: string(jose.ES256), Use: "sig"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := New(Config{JWKSFile: path, Issuer: "https://idp.example.com", Audience: "rkn-checker"})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return now }
+
+	valid := jwt.Claims{
+		Issuer:   "https://idp.example.com",
+		Subject:  "reporting",
+		Audience: jwt.Audience{"rkn-checker"},
+		Expiry:   jwt.NewNumericDate(now.Add(time.Hour)),
+	}
+
+	identity, err := a.AuthenticateToken(signJWT(t, key, valid, map[string]any{"scope": "check registry openid"}))
+	if err != nil {
+		t.Fatalf("AuthenticateToken() unexpected error: %v", err)
+	}
+	if identity.String() != "jwt:reporting" ||
+		!slices.Equal(identity.Scopes, []domain.Scope{domain.ScopeCheck, domain.ScopeRegistry}) {
+		t.Errorf("unexpected identity %+v", identity)
+	}
+
+	identity, err = a.AuthenticateToken(signJWT(t, key, valid, map[string]any{"scp": []string{"stats"}}))
+	if err != nil {
+		t.Fatalf("AuthenticateToken() unexpected error: %v", err)
+	}
+	if !slices.Equal(identity.Scopes, []domain.Scope{domain.ScopeStats}) {
+		t.Errorf("expected the scp claim to carry the scopes, got %v", identity.Scopes)
+	}
+
+	expired := valid
+	expired.Expiry = jwt.NewNumericDate(now.Add(-time.Hour))
+	wrongAudience := valid
+	wrongAudience.Audience = jwt.Audience{"another-service"}
+	noExpiry := valid
+	noExpiry.Expiry = nil
+
+	rejected := map[string]string{
+		"expired":        signJWT(t, key, expired, nil),
+		"wrong audience": signJWT(t, key, wrongAudience, nil),
+		"no expiry":      signJWT(t, key, noExpiry, nil),
+		"unknown key":    signJWT(t, other, valid, nil),
+		"malformed":      "not.a.jwt",
+	}
+	for name, token := range rejected {
+		if _, err := a.AuthenticateToken(token); !errors.Is(err, domain.ErrInvalidCredentials) {
+			t.Errorf("%s: expected ErrInvalidCredentials, got %v", name, err)
+		}
+	}
+}
+
+// signJWT signs claims and extra private claims with key
+func signJWT(t *testing.T, key *ecdsa.PrivateKey, claims jwt.Claims, extra map[string]any) string {
+	t.Helper()
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "signing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := jwt.Signed(signer).Claims(claims).Claims(extra).Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestNew_RejectsPrivateJWKS(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: key, KeyID: "signing"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := New(Config{JWKSFile: path}); err == nil {
+		t.Error("expected an error for a JWKS holding a private key")
+	}
+}
+
+func TestAuthenticator_AuthenticateCertificate(t *testing.T) {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "billing.internal"}}
+
+	a, err := New(Config{})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	if _, err := a.AuthenticateCertificate(cert); !errors.Is(err, domain.ErrInvalidCredentials) {
+		t.Errorf("expected certificates to be refused without certificate scopes, got %v", err)
+	}
+
+	a, err = New(Config{CertificateScopes: []domain.Scope{domain.ScopeCheck}})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	identity, err := a.AuthenticateCertificate(cert)
+	if err != nil {
+		t.Fatalf("AuthenticateCertificate() unexpected error: %v", err)
+	}
+	if identity.String() != "client_cert:billing.internal" || !identity.HasScope(domain.ScopeCheck) {
+		t.Errorf("unexpected identity %+v", identity)
+	}
+
+	identity, err = a.AuthenticateCertificate(&x509.Certificate{DNSNames: []string{"reports.internal"}})
+	if err != nil || identity.Subject != "reports.internal" {
+		t.Errorf("expected the DNS SAN to identify the certificate, got %+v, %v", identity, err)
+	}
+}
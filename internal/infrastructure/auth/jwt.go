@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// signatureAlgorithms are the asymmetric algorithms accepted on JWTs. HMAC
+// is left out: the JWKS only ever holds public keys.
+var signatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+// jwtVerifier verifies JWTs against the keys of a local JWKS file
+type jwtVerifier struct {
+	keys     jose.JSONWebKeySet
+	expected jwt.Expected
+}
+
+// scopeClaims carries the scopes of a token, as the space-separated scope
+// claim of RFC 8693 or the scp claim some identity providers use instead
+type scopeClaims struct {
+	Scope string          `json:"scope"`
+	Scp   json.RawMessage `json:"scp"`
+}
+
+func newJWTVerifier(jwksPath, issuer, audience string) (*jwtVerifier, error) {
+	data, err := os.ReadFile(jwksPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading JWKS: %w", err)
+	}
+
+	var keys jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("parsing JWKS %s: %w", jwksPath, err)
+	}
+	if len(keys.Keys) == 0 {
+		return nil, fmt.Errorf("JWKS %s contains no keys", jwksPath)
+	}
+	for _, key := range keys.Keys {
+		if !key.IsPublic() {
+			return nil, fmt.Errorf("JWKS %s contains the private key %q", jwksPath, key.KeyID)
+		}
+	}
+
+	expected := jwt.Expected{Issuer: issuer}
+	if audience != "" {
+		expected.AnyAudience = jwt.Audience{audience}
+	}
+	return &jwtVerifier{keys: keys, expected: expected}, nil
+}
+
+// verify checks the signature and claims of token at now and returns the
+// identity of its subject
+func (v *jwtVerifier) verify(token string, now time.Time) (*domain.Identity, error) {
+	parsed, err := jwt.ParseSigned(token, signatureAlgorithms)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims jwt.Claims
+	var scopes scopeClaims
+	if err := parsed.Claims(v.keys, &claims, &scopes); err != nil {
+		return nil, err
+	}
+	if claims.Expiry == nil {
+		return nil, errors.New("token has no expiry")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+	if err := claims.ValidateWithLeeway(v.expected.WithTime(now), jwt.DefaultLeeway); err != nil {
+		return nil, err
+	}
+
+	return &domain.Identity{
+		Method:  domain.AuthMethodJWT,
+		Subject: claims.Subject,
+		Scopes:  scopes.granted(),
+	}, nil
+}
+
+// granted returns the known scopes of the token, ignoring scopes meant for
+// other services
+func (c scopeClaims) granted() []domain.Scope {
+	names := strings.Fields(c.Scope)
+
+	var scp []string
+	if json.Unmarshal(c.Scp, &scp) == nil {
+		names = append(names, scp...)
+	} else {
+		var s string
+		if json.Unmarshal(c.Scp, &s) == nil {
+			names = append(names, strings.Fields(s)...)
+		}
+	}
+
+	var scopes []domain.Scope
+	for _, name := range names {
+		if scope, ok := domain.ParseScope(name); ok {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
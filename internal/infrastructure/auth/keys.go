@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// APIKey is a client key known by its SHA-256 hash, so the key itself is
+// never stored
+type APIKey struct {
+	// Name identifies the client in logs
+	Name string `json:"name"`
+	// SHA256 is the hex-encoded SHA-256 hash of the key
+	SHA256 string         `json:"sha256"`
+	Scopes []domain.Scope `json:"scopes"`
+}
+
+// HashKey returns the hex-encoded SHA-256 hash stored for key
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseKeys parses comma-separated keys of the form name:sha256:scope+scope
+func ParseKeys(spec string) ([]APIKey, error) {
+	var keys []APIKey
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		parts := strings.Split(item, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("API key %q is not of the form name:sha256:scopes", item)
+		}
+
+		key := APIKey{Name: parts[0], SHA256: parts[1]}
+		for _, scope := range strings.Split(parts[2], "+") {
+			key.Scopes = append(key.Scopes, domain.Scope(scope))
+		}
+		keys = append(keys, key)
+	}
+
+	if err := validateKeys(keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// LoadKeys reads the JSON array of keys stored at path
+func LoadKeys(path string) ([]APIKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading API keys: %w", err)
+	}
+
+	var keys []APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("parsing API keys %s: %w", path, err)
+	}
+
+	if err := validateKeys(keys); err != nil {
+		return nil, fmt.Errorf("API keys %s: %w", path, err)
+	}
+	return keys, nil
+}
+
+func validateKeys(keys []APIKey) error {
+	names := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if key.Name == "" {
+			return fmt.Errorf("API key without a name")
+		}
+		if names[key.Name] {
+			return fmt.Errorf("duplicate API key name %q", key.Name)
+		}
+		names[key.Name] = true
+
+		if hash, err := hex.DecodeString(key.SHA256); err != nil || len(hash) != sha256.Size {
+			return fmt.Errorf("API key %q: sha256 must be a hex-encoded SHA-256 hash", key.Name)
+		}
+		if err := ValidateScopes(key.Scopes); err != nil {
+			return fmt.Errorf("API key %q: %w", key.Name, err)
+		}
+	}
+	return nil
+}
+
+// ValidateScopes rejects unknown scopes
+func ValidateScopes(scopes []domain.Scope) error {
+	for _, scope := range scopes {
+		if _, ok := domain.ParseScope(string(scope)); !ok {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+func TestParseKeys(t *testing.T) {
+	hash := HashKey("secret")
+
+	keys, err := ParseKeys(" ci:" + hash + ":check+stats , ops:" + hash + ":admin,")
+	if err != nil {
+		t.Fatalf("ParseKeys() unexpected error: %v", err)
+	}
+	if len(keys) != 2 || keys[0].Name != "ci" || keys[1].Name != "ops" {
+		t.Fatalf("unexpected keys %+v", keys)
+	}
+	if !slices.Equal(keys[0].Scopes, []domain.Scope{domain.ScopeCheck, domain.ScopeStats}) {
+		t.Errorf("unexpected scopes %v", keys[0].Scopes)
+	}
+
+	for _, spec := range []string{
+		"ci:" + hash,
+		"ci:plaintext-key:check",
+		"ci:" + hash + ":everything",
+		":" + hash + ":check",
+		"ci:" + hash + ":check,ci:" + hash + ":stats",
+	} {
+		if _, err := ParseKeys(spec); err == nil {
+			t.Errorf("ParseKeys(%q) expected an error", spec)
+		}
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	content := `[{"name": "billing", "sha256": "` + HashKey("secret") + `", "scopes": ["check"]}]`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := LoadKeys(path)
+	if err != nil {
+		t.Fatalf("LoadKeys() unexpected error: %v", err)
+	}
+	if len(keys) != 1 || keys[0].Name != "billing" || !slices.Equal(keys[0].Scopes, []domain.Scope{domain.ScopeCheck}) {
+		t.Errorf("unexpected keys %+v", keys)
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"name": "billing", "sha256": "abc", "scopes": []}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadKeys(path); err == nil {
+		t.Error("expected an error for a malformed hash")
+	}
+}
@@ -0,0 +1,234 @@
+package changelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// DefaultLimit is how many changelogs are kept when no limit is configured
+const DefaultLimit = 50
+
+// Store keeps the most recent registry changelogs in memory and, when a
+// snapshot directory is configured, on disk so they survive restarts
+type Store struct {
+	mu         sync.RWMutex
+	limit      int
+	dir        string
+	changelogs []*domain.Changelog // oldest first
+	version    string              // version of the registry currently applied
+}
+
+// NewStore creates a changelog store keeping up to limit changelogs. When
+// snapshotDir is not empty, changelogs are persisted below it and the ones
+// already there are loaded.
+func NewStore(limit int, snapshotDir string) (*Store, error) {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	s := &Store{limit: limit}
+	if snapshotDir == "" {
+		return s, nil
+	}
+
+	s.dir = filepath.Join(snapshotDir, "changelog")
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating changelog directory: %w", err)
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// OnRegistryUpdate records the diff between two consecutively applied
+// registries. The first registry applied after startup has nothing to diff
+// against and only becomes the new baseline.
+func (s *Store) OnRegistryUpdate(previous, current *domain.Registry, report *domain.IngestReport) {
+	if previous == nil {
+		s.mu.Lock()
+		s.version = current.Version
+		s.mu.Unlock()
+		return
+	}
+
+	changelog := domain.DiffRegistries(previous, current)
+	if err := s.Record(changelog); err != nil {
+		slog.Error("Failed to persist registry changelog", "to_version", changelog.ToVersion, "error", err)
+	}
+
+	slog.Info("Registry changelog recorded",
+		"from_version", changelog.FromVersion,
+		"to_version", changelog.ToVersion,
+		"added", changelog.Added,
+		"removed", changelog.Removed,
+		"changed", changelog.Changed)
+}
+
+// OnRegistryCleared forgets the applied version, so clients syncing from any
+// version have to resynchronise
+func (s *Store) OnRegistryCleared() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.version = ""
+}
+
+// Record appends a changelog, evicting the oldest ones beyond the limit. The
+// changelog is kept in memory even if persisting it fails.
+func (s *Store) Record(changelog *domain.Changelog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.changelogs = append(s.changelogs, changelog)
+	s.version = changelog.ToVersion
+
+	var evicted []*domain.Changelog
+	if excess := len(s.changelogs) - s.limit; excess > 0 {
+		evicted = s.changelogs[:excess]
+		s.changelogs = append([]*domain.Changelog(nil), s.changelogs[excess:]...)
+	}
+
+	if s.dir == "" {
+		return nil
+	}
+
+	for _, old := range evicted {
+		if err := os.Remove(s.path(old)); err != nil && !os.IsNotExist(err) {
+			slog.Warn("Failed to remove evicted changelog", "to_version", old.ToVersion, "error", err)
+		}
+	}
+
+	return s.persist(changelog)
+}
+
+// ChangesSince returns the changelogs that lead from the given registry
+// version to the current one, oldest first. An empty version returns every
+// retained changelog. domain.ErrUnknownRegistryVersion is returned when the
+// version is not retained, in which case the caller has to resynchronise.
+func (s *Store) ChangesSince(version string) ([]*domain.Changelog, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if version == "" {
+		return append([]*domain.Changelog(nil), s.changelogs...), nil
+	}
+	if version == s.version {
+		return []*domain.Changelog{}, nil
+	}
+
+	for i, changelog := range s.changelogs {
+		if changelog.FromVersion != version {
+			continue
+		}
+
+		chain := s.changelogs[i:]
+		// A restart without a diff breaks the chain to the current version
+		for j := 1; j < len(chain); j++ {
+			if chain[j].FromVersion != chain[j-1].ToVersion {
+				return nil, domain.ErrUnknownRegistryVersion
+			}
+		}
+		if chain[len(chain)-1].ToVersion != s.version {
+			return nil, domain.ErrUnknownRegistryVersion
+		}
+
+		return append([]*domain.Changelog(nil), chain...), nil
+	}
+
+	return nil, domain.ErrUnknownRegistryVersion
+}
+
+// Latest returns the changelog leading to the given registry version when it
+// is the most recently recorded one
+func (s *Store) Latest(toVersion string) (*domain.Changelog, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.changelogs) == 0 || s.changelogs[len(s.changelogs)-1].ToVersion != toVersion {
+		return nil, false
+	}
+	return s.changelogs[len(s.changelogs)-1], true
+}
+
+// CurrentVersion returns the version of the registry currently applied
+func (s *Store) CurrentVersion() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.version
+}
+
+// path returns the file a changelog is persisted to. Names sort by the time
+// the changelog was applied.
+func (s *Store) path(changelog *domain.Changelog) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d.json", changelog.AppliedAt.UnixNano()))
+}
+
+// persist writes a changelog atomically through a temporary file
+func (s *Store) persist(changelog *domain.Changelog) error {
+	data, err := json.Marshal(changelog)
+	if err != nil {
+		return fmt.Errorf("encoding changelog: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, "changelog-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating changelog file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing changelog: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing changelog: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), s.path(changelog))
+}
+
+// load reads persisted changelogs, keeping the newest up to the limit
+func (s *Store) load() error {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("listing changelogs: %w", err)
+	}
+	sort.Strings(paths)
+
+	if excess := len(paths) - s.limit; excess > 0 {
+		for _, path := range paths[:excess] {
+			os.Remove(path)
+		}
+		paths = paths[excess:]
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading changelog %s: %w", path, err)
+		}
+
+		var changelog domain.Changelog
+		if err := json.Unmarshal(data, &changelog); err != nil {
+			slog.Warn("Skipping unreadable changelog", "path", path, "error", err)
+			continue
+		}
+		s.changelogs = append(s.changelogs, &changelog)
+	}
+
+	if len(s.changelogs) > 0 {
+		s.version = s.changelogs[len(s.changelogs)-1].ToVersion
+	}
+
+	return nil
+}
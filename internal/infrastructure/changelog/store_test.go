@@ -0,0 +1,163 @@
+package changelog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// createRegistry creates a versioned registry of domain entries
+func createRegistry(version string, domains ...string) *domain.Registry {
+	registry := domain.NewRegistry()
+	registry.Version = version
+	for _, value := range domains {
+		entry, _ := domain.NewRegistryEntry(domain.BlockingTypeDomain, value)
+		registry.AddEntry(entry)
+	}
+	return registry
+}
+
+// applySequence feeds consecutive registries to the store like the scheduler does
+func applySequence(store *Store, registries ...*domain.Registry) {
+	var previous *domain.Registry
+	for _, registry := range registries {
+		store.OnRegistryUpdate(previous, registry, nil)
+		previous = registry
+	}
+}
+
+func TestStore_ChangesSince(t *testing.T) {
+	store, err := NewStore(10, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	applySequence(store,
+		createRegistry("v1", "a.com", "b.com"),
+		createRegistry("v2", "a.com", "c.com"),
+		createRegistry("v3", "c.com"),
+	)
+
+	tests := []struct {
+		name     string
+		since    string
+		expected []string
+		wantErr  error
+	}{
+		{name: "all retained", since: "", expected: []string{"v2", "v3"}},
+		{name: "from baseline", since: "v1", expected: []string{"v2", "v3"}},
+		{name: "from middle", since: "v2", expected: []string{"v3"}},
+		{name: "up to date", since: "v3", expected: []string{}},
+		{name: "unknown version", since: "v0", wantErr: domain.ErrUnknownRegistryVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changelogs, err := store.ChangesSince(tt.since)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(changelogs) != len(tt.expected) {
+				t.Fatalf("expected %d changelogs, got %d", len(tt.expected), len(changelogs))
+			}
+			for i, version := range tt.expected {
+				if changelogs[i].ToVersion != version {
+					t.Errorf("changelog %d: expected version %s, got %s", i, version, changelogs[i].ToVersion)
+				}
+			}
+		})
+	}
+
+	changelogs, _ := store.ChangesSince("v1")
+	if changelogs[0].Added != 1 || changelogs[0].Removed != 1 {
+		t.Errorf("unexpected diff v1 -> v2: %+v", changelogs[0])
+	}
+
+	if latest, ok := store.Latest("v3"); !ok || latest.FromVersion != "v2" {
+		t.Errorf("expected the latest changelog to lead from v2 to v3, got %+v", latest)
+	}
+	if _, ok := store.Latest("v2"); ok {
+		t.Error("expected only the most recent changelog to be returned")
+	}
+}
+
+func TestStore_Limit(t *testing.T) {
+	store, _ := NewStore(2, "")
+
+	applySequence(store,
+		createRegistry("v1", "a.com"),
+		createRegistry("v2", "b.com"),
+		createRegistry("v3", "c.com"),
+		createRegistry("v4", "d.com"),
+	)
+
+	changelogs, _ := store.ChangesSince("")
+	if len(changelogs) != 2 || changelogs[0].ToVersion != "v3" {
+		t.Fatalf("expected the 2 newest changelogs, got %d", len(changelogs))
+	}
+
+	if _, err := store.ChangesSince("v1"); !errors.Is(err, domain.ErrUnknownRegistryVersion) {
+		t.Errorf("expected evicted version to be unknown, got %v", err)
+	}
+}
+
+func TestStore_Persistence(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewStore(2, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	v1 := createRegistry("v1", "a.com")
+	v2 := createRegistry("v2", "a.com", "b.com")
+	v3 := createRegistry("v3", "b.com")
+	v4 := createRegistry("v4", "b.com", "c.com")
+	applySequence(store, v1, v2, v3, v4)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "changelog", "*.json"))
+	if len(files) != 2 {
+		t.Errorf("expected evicted changelog file to be removed, found %d files", len(files))
+	}
+
+	reloaded, err := NewStore(2, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reloaded.CurrentVersion() != "v4" {
+		t.Errorf("expected current version v4, got %q", reloaded.CurrentVersion())
+	}
+
+	changelogs, err := reloaded.ChangesSince("v2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changelogs) != 2 || changelogs[1].Changes[0].Value != "c.com" {
+		t.Errorf("unexpected reloaded changelogs: %+v", changelogs)
+	}
+
+	// A registry applied after restart without a diff breaks the chain
+	reloaded.OnRegistryUpdate(nil, createRegistry("v5", "c.com"), nil)
+	if _, err := reloaded.ChangesSince("v2"); !errors.Is(err, domain.ErrUnknownRegistryVersion) {
+		t.Errorf("expected broken chain to be unknown, got %v", err)
+	}
+}
+
+func TestNewStore_InvalidDirectory(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	os.WriteFile(file, nil, 0o600)
+
+	if _, err := NewStore(10, file); err == nil {
+		t.Error("expected error for snapshot dir that is a file")
+	}
+}
@@ -0,0 +1,286 @@
+package collateral
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// MaxHostedDomains bounds the number of domains in one hosted list
+const MaxHostedDomains = 100000
+
+// Output formats of a collateral report
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// hostedJSON is one element of a JSON hosted list
+type hostedJSON struct {
+	Domain string   `json:"domain"`
+	IPs    []string `json:"ips"`
+}
+
+// ParseHosted reads a list of hosted domains and their IPs. JSON input is an
+// array of {"domain": ..., "ips": [...]} objects. CSV input has a domain
+// followed by its IPs on each row, either in further columns or separated by
+// "|" or spaces, with commas or semicolons between columns; an optional
+// "domain" header row is skipped. Rows naming the same domain are merged.
+func ParseHosted(r io.Reader) ([]domain.HostedDomain, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(512)
+	head = bytes.TrimLeft(head, " \t\r\n\ufeff")
+
+	var hosted []domain.HostedDomain
+	var err error
+	if len(head) > 0 && head[0] == '[' {
+		hosted, err = parseHostedJSON(br)
+	} else {
+		// Semicolon-separated lists are common in spreadsheet exports
+		comma := ','
+		if line, _, _ := bytes.Cut(head, []byte("\n")); !bytes.ContainsRune(line, ',') && bytes.ContainsRune(line, ';') {
+			comma = ';'
+		}
+		hosted, err = parseHostedCSV(br, comma)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(hosted) == 0 {
+		return nil, fmt.Errorf("%w: no domains", domain.ErrInvalidHostedList)
+	}
+	if len(hosted) > MaxHostedDomains {
+		return nil, fmt.Errorf("%w: more than %d domains", domain.ErrInvalidHostedList, MaxHostedDomains)
+	}
+
+	return hosted, nil
+}
+
+func parseHostedJSON(r io.Reader) ([]domain.HostedDomain, error) {
+	var items []hostedJSON
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidHostedList, err)
+	}
+
+	list := newHostedList()
+	for i, item := range items {
+		if err := list.add(item.Domain, item.IPs); err != nil {
+			return nil, fmt.Errorf("%w: item %d: %v", domain.ErrInvalidHostedList, i+1, err)
+		}
+	}
+	return list.hosted, nil
+}
+
+func parseHostedCSV(r io.Reader, comma rune) ([]domain.HostedDomain, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	list := newHostedList()
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidHostedList, err)
+		}
+
+		name := strings.TrimSpace(strings.TrimPrefix(record[0], "\ufeff"))
+		if row == 1 && strings.EqualFold(name, "domain") {
+			continue
+		}
+
+		var ips []string
+		for _, field := range record[1:] {
+			ips = append(ips, strings.FieldsFunc(field, func(r rune) bool {
+				return r == '|' || r == ' '
+			})...)
+		}
+
+		if err := list.add(name, ips); err != nil {
+			return nil, fmt.Errorf("%w: row %d: %v", domain.ErrInvalidHostedList, row, err)
+		}
+	}
+	return list.hosted, nil
+}
+
+// hostedList merges the IPs of domains listed more than once
+type hostedList struct {
+	hosted []domain.HostedDomain
+	index  map[string]int
+}
+
+func newHostedList() *hostedList {
+	return &hostedList{index: make(map[string]int)}
+}
+
+func (l *hostedList) add(name string, ips []string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return fmt.Errorf("missing domain")
+	}
+
+	i, ok := l.index[name]
+	if !ok {
+		i = len(l.hosted)
+		l.index[name] = i
+		l.hosted = append(l.hosted, domain.HostedDomain{Domain: name})
+	}
+
+	for _, ip := range ips {
+		addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+		if err != nil {
+			return fmt.Errorf("invalid IP %q for %s", ip, name)
+		}
+		l.hosted[i].IPs = append(l.hosted[i].IPs, addr.String())
+	}
+	return nil
+}
+
+// Document is the JSON form of a collateral report
+type Document struct {
+	Version     string           `json:"version"`
+	GeneratedAt string           `json:"generated_at"`
+	Summary     map[string]int   `json:"summary"`
+	Domains     []DomainDocument `json:"domains"`
+}
+
+type DomainDocument struct {
+	Domain string        `json:"domain"`
+	IPs    []string      `json:"ips"`
+	Status string        `json:"status"`
+	Rule   string        `json:"rule,omitempty"`
+	Hits   []HitDocument `json:"hits,omitempty"`
+}
+
+type HitDocument struct {
+	IP          string   `json:"ip"`
+	Reason      string   `json:"reason"`
+	Pattern     string   `json:"pattern"`
+	RecordID    string   `json:"record_id"`
+	Domains     []string `json:"domains,omitempty"`
+	URLs        []string `json:"urls,omitempty"`
+	Decision    string   `json:"decision,omitempty"`
+	DecisionOrg string   `json:"decision_org,omitempty"`
+}
+
+// NewDocument converts a report to its JSON form
+func NewDocument(report *domain.CollateralReport) *Document {
+	doc := &Document{
+		Version:     report.Version,
+		GeneratedAt: report.GeneratedAt.Format(time.RFC3339),
+		Summary:     make(map[string]int),
+		Domains:     make([]DomainDocument, 0, len(report.Findings)),
+	}
+
+	for _, status := range []domain.CollateralStatus{
+		domain.CollateralStatusClear,
+		domain.CollateralStatusListed,
+		domain.CollateralStatusCollateral,
+	} {
+		doc.Summary[status.String()] = report.Count(status)
+	}
+
+	for _, finding := range report.Findings {
+		d := DomainDocument{
+			Domain: finding.Domain,
+			IPs:    finding.IPs,
+			Status: finding.Status.String(),
+		}
+		if d.IPs == nil {
+			d.IPs = []string{}
+		}
+		if finding.Rule != nil {
+			d.Rule = finding.Rule.Pattern
+		}
+		for _, hit := range finding.Hits {
+			d.Hits = append(d.Hits, HitDocument{
+				IP:          hit.IP,
+				Reason:      hit.Record.Reason.String(),
+				Pattern:     hit.Record.Pattern,
+				RecordID:    hit.Record.RecordID,
+				Domains:     hit.Record.Domains,
+				URLs:        hit.Record.URLs,
+				Decision:    hit.Record.Decision,
+				DecisionOrg: hit.Record.DecisionOrg,
+			})
+		}
+		doc.Domains = append(doc.Domains, d)
+	}
+
+	return doc
+}
+
+// WriteJSON writes a report as an indented JSON document
+func WriteJSON(w io.Writer, report *domain.CollateralReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(NewDocument(report))
+}
+
+// csvHeader lists the columns of a CSV report
+var csvHeader = []string{"domain", "status", "rule", "ip", "reason", "pattern", "record_id", "record_domains", "record_urls", "decision", "decision_org"}
+
+// WriteCSV writes a report with one row per hit, and one row for every
+// domain without hits
+func WriteCSV(w io.Writer, report *domain.CollateralReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, finding := range report.Findings {
+		rule := ""
+		if finding.Rule != nil {
+			rule = finding.Rule.Pattern
+		}
+
+		if len(finding.Hits) == 0 {
+			writer.Write([]string{finding.Domain, finding.Status.String(), rule, strings.Join(finding.IPs, "|"), "", "", "", "", "", "", ""})
+			continue
+		}
+		for _, hit := range finding.Hits {
+			record := hit.Record
+			writer.Write([]string{
+				finding.Domain,
+				finding.Status.String(),
+				rule,
+				hit.IP,
+				record.Reason.String(),
+				record.Pattern,
+				record.RecordID,
+				strings.Join(record.Domains, "|"),
+				strings.Join(record.URLs, "|"),
+				record.Decision,
+				record.DecisionOrg,
+			})
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Write writes a report in the given format
+func Write(w io.Writer, report *domain.CollateralReport, format string) error {
+	switch format {
+	case FormatJSON:
+		return WriteJSON(w, report)
+	case FormatCSV:
+		return WriteCSV(w, report)
+	default:
+		return fmt.Errorf("unsupported report format %q", format)
+	}
+}
@@ -0,0 +1,138 @@
+package collateral
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+func TestParseHosted(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []domain.HostedDomain
+		wantErr  bool
+	}{
+		{
+			name:  "CSV with header and IP columns",
+			input: "domain,ip\nshop.example,203.0.113.7,203.0.113.8\nblog.example,198.51.100.1\n",
+			expected: []domain.HostedDomain{
+				{Domain: "shop.example", IPs: []string{"203.0.113.7", "203.0.113.8"}},
+				{Domain: "blog.example", IPs: []string{"198.51.100.1"}},
+			},
+		},
+		{
+			name:  "semicolon CSV with merged rows and pipe lists",
+			input: "Shop.example;203.0.113.7|2001:db8::1\nshop.example;203.0.113.8\n",
+			expected: []domain.HostedDomain{
+				{Domain: "shop.example", IPs: []string{"203.0.113.7", "2001:db8::1", "203.0.113.8"}},
+			},
+		},
+		{
+			name:  "JSON",
+			input: ` [{"domain": "shop.example", "ips": ["203.0.113.7"]}]`,
+			expected: []domain.HostedDomain{
+				{Domain: "shop.example", IPs: []string{"203.0.113.7"}},
+			},
+		},
+		{name: "invalid IP", input: "shop.example,not-an-ip\n", wantErr: true},
+		{name: "missing domain", input: `[{"ips": ["203.0.113.7"]}]`, wantErr: true},
+		{name: "empty list", input: "domain,ip\n", wantErr: true},
+		{name: "malformed JSON", input: `[{"domain": }]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hosted, err := ParseHosted(strings.NewReader(tt.input))
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrInvalidHostedList) {
+					t.Errorf("expected ErrInvalidHostedList, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(hosted) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, hosted)
+			}
+			for i := range hosted {
+				if hosted[i].Domain != tt.expected[i].Domain || strings.Join(hosted[i].IPs, ",") != strings.Join(tt.expected[i].IPs, ",") {
+					t.Errorf("expected %v, got %v", tt.expected[i], hosted[i])
+				}
+			}
+		})
+	}
+}
+
+func createReport() *domain.CollateralReport {
+	rule, _ := domain.NewBlockingRule(domain.BlockingTypeDomain, "listed.example")
+	return &domain.CollateralReport{
+		Version:     "v1",
+		GeneratedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		Findings: []*domain.CollateralFinding{
+			{
+				Domain: "shop.example",
+				IPs:    []string{"203.0.113.7"},
+				Status: domain.CollateralStatusCollateral,
+				Hits: []domain.CollateralHit{
+					{IP: "203.0.113.7", Record: &domain.IPRecord{RecordID: "42", Reason: domain.IPMatchDomain, Pattern: "203.0.113.7", Domains: []string{"casino.example"}, Decision: "2-1"}},
+					{IP: "203.0.113.7", Record: &domain.IPRecord{RecordID: "43", Reason: domain.IPMatchSubnet, Pattern: "203.0.113.0/24"}},
+				},
+			},
+			{Domain: "listed.example", Status: domain.CollateralStatusListed, Rule: rule},
+		},
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, createReport()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc Document
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	if doc.Summary["collateral"] != 1 || doc.Summary["listed"] != 1 || doc.Summary["clear"] != 0 {
+		t.Errorf("unexpected summary %v", doc.Summary)
+	}
+	if len(doc.Domains) != 2 || len(doc.Domains[0].Hits) != 2 || doc.Domains[1].Rule != "listed.example" {
+		t.Errorf("unexpected domains %+v", doc.Domains)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, createReport(), FormatCSV); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+
+	// Header, one row per hit and one row for the domain without hits
+	if len(rows) != 4 {
+		t.Fatalf("expected 4 rows, got %d", len(rows))
+	}
+	if rows[1][0] != "shop.example" || rows[1][5] != "203.0.113.7" || rows[1][7] != "casino.example" {
+		t.Errorf("unexpected hit row %v", rows[1])
+	}
+	if rows[3][1] != "listed" || rows[3][2] != "listed.example" {
+		t.Errorf("unexpected listed row %v", rows[3])
+	}
+
+	if err := Write(&buf, createReport(), "xml"); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
@@ -2,119 +2,414 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/auth"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/proxy"
 	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/registry"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/tlsconfig"
 	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/updater"
 )
 
 // Config holds all application configuration
 type Config struct {
-	Server   ServerConfig   `json:"server"`
-	Registry RegistryConfig `json:"registry"`
-	Storage  StorageConfig  `json:"storage"`
-	Logging  LoggingConfig  `json:"logging"`
+	Server    ServerConfig    `json:"server"`
+	TLS       TLSConfig       `json:"tls"`
+	Registry  RegistryConfig  `json:"registry"`
+	Storage   StorageConfig   `json:"storage"`
+	Watchlist WatchlistConfig `json:"watchlist"`
+	Webhooks  WebhooksConfig  `json:"webhooks"`
+	Events    EventsConfig    `json:"events"`
+	Admin     AdminConfig     `json:"admin"`
+	Auth      AuthConfig      `json:"auth"`
+	RateLimit RateLimitConfig `json:"rate_limit"`
+	Health    HealthConfig    `json:"health"`
+	Tracing   TracingConfig   `json:"tracing"`
+	Logging   LoggingConfig   `json:"logging"`
 }
 
 // ServerConfig holds server-related configuration
 type ServerConfig struct {
-	GRPCPort int    `json:"grpc_port"`
-	RESTPort int    `json:"rest_port"`
-	Host     string `json:"host"`
-	Env      string `json:"env"`
+	GRPCPort int    `json:"grpc_port" yaml:"grpc_port"`
+	RESTPort int    `json:"rest_port" yaml:"rest_port"`
+	Host     string `json:"host" yaml:"host"`
+	Env      string `json:"env" yaml:"env"`
+
+	// RESTSocket and GRPCSocket are Unix socket paths served instead of
+	// Host and the ports, e.g. for sidecars
+	RESTSocket string `json:"rest_socket" yaml:"rest_socket"`
+	GRPCSocket string `json:"grpc_socket" yaml:"grpc_socket"`
+
+	// CORSAllowedOrigins are the origins allowed to call the REST API from a
+	// browser. Any origin is allowed when empty.
+	CORSAllowedOrigins []string `json:"cors_allowed_origins" yaml:"cors_allowed_origins"`
+
+	// TrustedProxies are the CIDRs of the reverse proxies whose forwarding
+	// headers are believed when resolving client addresses
+	TrustedProxies []string `json:"trusted_proxies" yaml:"trusted_proxies"`
+	// TrustedProxyHeader is the forwarding header the trusted proxies
+	// append to: X-Forwarded-For or Forwarded
+	TrustedProxyHeader string `json:"trusted_proxy_header" yaml:"trusted_proxy_header"`
+	// ProxyProtocol reads PROXY protocol headers sent by the trusted proxies
+	// on both listeners
+	ProxyProtocol bool `json:"proxy_protocol" yaml:"proxy_protocol"`
+
+	// GRPCDrainPeriod is how long the gRPC server reports NOT_SERVING to
+	// health checks before it stops, so load balancers stop routing to it
+	GRPCDrainPeriod time.Duration `json:"grpc_drain_period" yaml:"grpc_drain_period"`
 }
 
 // RegistryConfig holds registry-related configuration
 type RegistryConfig struct {
-	Sources       []registry.SourceConfig `json:"sources"`
-	UpdateConfig  updater.Config          `json:"update"`
-	MaxConcurrent int                     `json:"max_concurrent"`
-	Timeout       time.Duration           `json:"timeout"`
+	Sources       []registry.SourceConfig `json:"sources" yaml:"sources"`
+	UpdateConfig  updater.Config          `json:"update" yaml:"update"`
+	MaxConcurrent int                     `json:"max_concurrent" yaml:"max_concurrent"`
+	Timeout       time.Duration           `json:"timeout" yaml:"timeout"`
+
+	// TrustBundlePath points to PEM certificates trusted to sign dumps.
+	// Signature verification is disabled when empty.
+	TrustBundlePath string `json:"trust_bundle_path" yaml:"trust_bundle_path"`
 }
 
 // StorageConfig holds storage-related configuration
 type StorageConfig struct {
-	BloomFilterSize   int `json:"bloom_filter_size"`
-	BloomFilterHashes int `json:"bloom_filter_hashes"`
-	MaxRegistrySize   int `json:"max_registry_size"`
+	BloomFilterSize   int `json:"bloom_filter_size" yaml:"bloom_filter_size"`
+	BloomFilterHashes int `json:"bloom_filter_hashes" yaml:"bloom_filter_hashes"`
+	MaxRegistrySize   int `json:"max_registry_size" yaml:"max_registry_size"`
+
+	// SnapshotDir persists changelogs, history and registry snapshots across
+	// restarts when set
+	SnapshotDir    string `json:"snapshot_dir" yaml:"snapshot_dir"`
+	ChangelogLimit int    `json:"changelog_limit" yaml:"changelog_limit"`
+
+	// SnapshotRetention bounds the registry snapshots kept for point-in-time checks
+	SnapshotRetentionCount int           `json:"snapshot_retention_count" yaml:"snapshot_retention_count"`
+	SnapshotRetentionAge   time.Duration `json:"snapshot_retention_age" yaml:"snapshot_retention_age"`
+	// SnapshotCacheSize is the number of snapshots kept loaded for lookups
+	SnapshotCacheSize int `json:"snapshot_cache_size" yaml:"snapshot_cache_size"`
+}
+
+// WatchlistConfig holds watchlist notification configuration
+type WatchlistConfig struct {
+	// CallbackURL receives the events of watch items without their own
+	// callback URL. Such events are only listed when it is empty.
+	CallbackURL string `json:"callback_url" yaml:"callback_url"`
+}
+
+// WebhooksConfig holds the delivery settings of outbound webhooks
+type WebhooksConfig struct {
+	Timeout        time.Duration `json:"timeout" yaml:"timeout"`
+	MaxAttempts    int           `json:"max_attempts" yaml:"max_attempts"`
+	InitialBackoff time.Duration `json:"initial_backoff" yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `json:"max_backoff" yaml:"max_backoff"`
+	Workers        int           `json:"workers" yaml:"workers"`
+	// AllowPrivateNetworks permits callbacks to loopback, link-local and
+	// private addresses
+	AllowPrivateNetworks bool `json:"allow_private_networks" yaml:"allow_private_networks"`
+}
+
+// EventsConfig holds the settings of the registry update event streams
+type EventsConfig struct {
+	Backlog           int           `json:"backlog" yaml:"backlog"`
+	HeartbeatInterval time.Duration `json:"heartbeat_interval" yaml:"heartbeat_interval"`
+}
+
+// AdminConfig holds the settings of the admin API
+type AdminConfig struct {
+	// Token is the bearer token required on /admin/v1/ and the gRPC
+	// AdminService. The admin API is disabled when no credential is configured.
+	Token string `json:"-" yaml:"token"`
+}
+
+// minAdminTokenLength is the shortest admin token accepted
+const minAdminTokenLength = 16
+
+// AuthConfig holds the credentials accepted on the REST and gRPC APIs
+type AuthConfig struct {
+	// Enabled requires credentials with the matching scope on every route
+	// and RPC except the probes. Otherwise only the admin API requires
+	// credentials and everything else stays open.
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// APIKeys lists static keys as name:sha256:scope+scope, comma-separated
+	APIKeys     string `json:"-" yaml:"api_keys"`
+	APIKeysFile string `json:"api_keys_file" yaml:"api_keys_file"`
+
+	// JWKSFile enables JWT bearer tokens signed by one of its keys
+	JWKSFile    string `json:"jwks_file" yaml:"jwks_file"`
+	JWTIssuer   string `json:"jwt_issuer" yaml:"jwt_issuer"`
+	JWTAudience string `json:"jwt_audience" yaml:"jwt_audience"`
+
+	// ClientCertScopes are granted to verified TLS client certificates
+	ClientCertScopes []domain.Scope `json:"client_cert_scopes" yaml:"client_cert_scopes"`
+	// AnonymousScopes are granted to callers presenting no credentials
+	AnonymousScopes []domain.Scope `json:"anonymous_scopes" yaml:"anonymous_scopes"`
+}
+
+// TLSConfig holds the certificates of the REST and gRPC listeners, served in
+// plaintext when CertFile is empty
+type TLSConfig struct {
+	CertFile string `json:"cert_file" yaml:"cert_file"`
+	KeyFile  string `json:"key_file" yaml:"key_file"`
+	// ClientCAFile enables mutual TLS, verifying client certificates
+	// against its CAs
+	ClientCAFile      string `json:"client_ca_file" yaml:"client_ca_file"`
+	RequireClientCert bool   `json:"require_client_cert" yaml:"require_client_cert"`
+	MinVersion        string `json:"min_version" yaml:"min_version"`
+}
+
+// Enabled reports whether the listeners serve TLS
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != ""
+}
+
+// RateLimitConfig holds the per-client rate limits and daily quotas
+type RateLimitConfig struct {
+	// Rate, Burst and DailyQuota make up the default rule, applied to the
+	// routes the limits file has no rule for
+	Rate       float64 `json:"rate" yaml:"rate"`
+	Burst      int     `json:"burst" yaml:"burst"`
+	DailyQuota int64   `json:"daily_quota" yaml:"daily_quota"`
+	// File holds the per-route rules as JSON and is reloaded on SIGHUP
+	File string `json:"file" yaml:"file"`
+}
+
+// HealthConfig holds the settings of the readiness probe
+type HealthConfig struct {
+	// MaxRegistryAge is how old the loaded registry may get before the
+	// service reports not ready. Zero disables the check.
+	MaxRegistryAge time.Duration `json:"max_registry_age" yaml:"max_registry_age"`
+}
+
+// TracingConfig holds the OpenTelemetry trace export settings
+type TracingConfig struct {
+	// Enabled exports spans over OTLP. Incoming trace context is propagated
+	// either way.
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// Endpoint is the host:port of the OTLP gRPC collector
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+	Insecure bool   `json:"insecure" yaml:"insecure"`
+	// SampleRatio is the fraction of traces started here that are sampled
+	SampleRatio float64 `json:"sample_ratio" yaml:"sample_ratio"`
 }
 
 // LoggingConfig holds logging configuration
 type LoggingConfig struct {
-	Level  string `json:"level"`
-	Format string `json:"format"`
+	Level  string `json:"level" yaml:"level"`
+	Format string `json:"format" yaml:"format"`
 }
 
-// LoadConfig loads configuration from environment variables
+// LoadConfig loads configuration from the file named by CONFIG_FILE, if
+// any, and environment variables
 func LoadConfig() (*Config, error) {
-	config := &Config{
+	return Load(os.Getenv("CONFIG_FILE"))
+}
+
+// Load loads configuration from the defaults, then the YAML or JSON file at
+// path when it is set, then environment variables layered on top
+func Load(path string) (*Config, error) {
+	config := defaultConfig()
+
+	if path != "" {
+		if err := loadFile(path, config); err != nil {
+			return nil, err
+		}
+	}
+
+	applyEnv(config)
+
+	return config, nil
+}
+
+// defaultConfig returns the configuration used when neither the file nor
+// the environment sets a value
+func defaultConfig() *Config {
+	return &Config{
 		Server: ServerConfig{
-			GRPCPort: getEnvInt("GRPC_PORT", 9090),
-			RESTPort: getEnvInt("REST_PORT", 80),
-			Host:     getEnvString("HOST", "0.0.0.0"),
-			Env:      getEnvString("SERVER_ENV", "development"),
+			GRPCPort:           9090,
+			RESTPort:           80,
+			Host:               "0.0.0.0",
+			Env:                "development",
+			TrustedProxyHeader: proxy.HeaderXForwardedFor,
+			GRPCDrainPeriod:    5 * time.Second,
 		},
 		Registry: RegistryConfig{
-			Sources:       getDefaultSources(),
-			UpdateConfig:  getUpdateConfig(),
-			MaxConcurrent: getEnvInt("REGISTRY_MAX_CONCURRENT", 5),
-			Timeout:       getEnvDuration("REGISTRY_TIMEOUT", 30*time.Second),
+			Sources: registry.DefaultSourceConfigs(),
+			UpdateConfig: updater.Config{
+				Interval:      48 * time.Hour,
+				MaxRetries:    3,
+				RetryDelay:    5 * time.Minute,
+				UpdateTimeout: 10 * time.Minute,
+				Guard: updater.GuardConfig{
+					MaxShrinkRatio: 0.3,
+					MaxGrowthRatio: 1.0,
+				},
+			},
+			MaxConcurrent: 5,
+			Timeout:       30 * time.Second,
 		},
 		Storage: StorageConfig{
-			BloomFilterSize:   getEnvInt("BLOOM_FILTER_SIZE", 10000000),
-			BloomFilterHashes: getEnvInt("BLOOM_FILTER_HASHES", 7),
-			MaxRegistrySize:   getEnvInt("MAX_REGISTRY_SIZE", 5000000),
+			BloomFilterSize:   10000000,
+			BloomFilterHashes: 7,
+			MaxRegistrySize:   5000000,
+			ChangelogLimit:    50,
+
+			SnapshotRetentionCount: 7,
+			SnapshotRetentionAge:   30 * 24 * time.Hour,
+			SnapshotCacheSize:      2,
+		},
+		Watchlist: WatchlistConfig{},
+		Webhooks: WebhooksConfig{
+			Timeout:        10 * time.Second,
+			MaxAttempts:    8,
+			InitialBackoff: 30 * time.Second,
+			MaxBackoff:     time.Hour,
+			Workers:        4,
+		},
+		Events: EventsConfig{
+			Backlog:           100,
+			HeartbeatInterval: 15 * time.Second,
+		},
+		TLS: TLSConfig{
+			MinVersion: "1.2",
+		},
+		Health: HealthConfig{
+			MaxRegistryAge: 96 * time.Hour,
+		},
+		Tracing: TracingConfig{
+			Endpoint:    "localhost:4317",
+			SampleRatio: 1.0,
 		},
 		Logging: LoggingConfig{
-			Level:  getEnvString("LOG_LEVEL", "info"),
-			Format: getEnvString("LOG_FORMAT", "text"),
+			Level:  "info",
+			Format: "text",
 		},
 	}
+}
+
+// applyEnv overrides config with the environment variables that are set
+func applyEnv(c *Config) {
+	c.Server.GRPCPort = getEnvInt("GRPC_PORT", c.Server.GRPCPort)
+	c.Server.RESTPort = getEnvInt("REST_PORT", c.Server.RESTPort)
+	c.Server.Host = getEnvString("HOST", c.Server.Host)
+	c.Server.Env = getEnvString("SERVER_ENV", c.Server.Env)
+	c.Server.RESTSocket = getEnvString("REST_SOCKET", c.Server.RESTSocket)
+	c.Server.GRPCSocket = getEnvString("GRPC_SOCKET", c.Server.GRPCSocket)
+	c.Server.CORSAllowedOrigins = getEnvListOr("CORS_ALLOWED_ORIGINS", c.Server.CORSAllowedOrigins)
+	c.Server.TrustedProxies = getEnvListOr("TRUSTED_PROXIES", c.Server.TrustedProxies)
+	c.Server.TrustedProxyHeader = getEnvString("TRUSTED_PROXY_HEADER", c.Server.TrustedProxyHeader)
+	c.Server.ProxyProtocol = getEnvBool("PROXY_PROTOCOL", c.Server.ProxyProtocol)
+	c.Server.GRPCDrainPeriod = getEnvDuration("GRPC_DRAIN_PERIOD", c.Server.GRPCDrainPeriod)
+
+	applySourceEnv(c.Registry.Sources)
+	applyUpdateEnv(&c.Registry.UpdateConfig)
+	c.Registry.MaxConcurrent = getEnvInt("REGISTRY_MAX_CONCURRENT", c.Registry.MaxConcurrent)
+	c.Registry.Timeout = getEnvDuration("REGISTRY_TIMEOUT", c.Registry.Timeout)
+	c.Registry.TrustBundlePath = getEnvString("REGISTRY_TRUST_BUNDLE_PATH", c.Registry.TrustBundlePath)
+
+	c.Storage.BloomFilterSize = getEnvInt("BLOOM_FILTER_SIZE", c.Storage.BloomFilterSize)
+	c.Storage.BloomFilterHashes = getEnvInt("BLOOM_FILTER_HASHES", c.Storage.BloomFilterHashes)
+	c.Storage.MaxRegistrySize = getEnvInt("MAX_REGISTRY_SIZE", c.Storage.MaxRegistrySize)
+	c.Storage.SnapshotDir = getEnvString("SNAPSHOT_DIR", c.Storage.SnapshotDir)
+	c.Storage.ChangelogLimit = getEnvInt("CHANGELOG_LIMIT", c.Storage.ChangelogLimit)
+	c.Storage.SnapshotRetentionCount = getEnvInt("SNAPSHOT_RETENTION_COUNT", c.Storage.SnapshotRetentionCount)
+	c.Storage.SnapshotRetentionAge = getEnvDuration("SNAPSHOT_RETENTION_AGE", c.Storage.SnapshotRetentionAge)
+	c.Storage.SnapshotCacheSize = getEnvInt("SNAPSHOT_CACHE_SIZE", c.Storage.SnapshotCacheSize)
+
+	c.Watchlist.CallbackURL = getEnvString("WATCHLIST_CALLBACK_URL", c.Watchlist.CallbackURL)
 
-	// Override sources with custom URLs if provided
-	if officialURL := getEnvString("REGISTRY_OFFICIAL_URL", ""); officialURL != "" {
-		config.Registry.Sources[0].URL = officialURL
+	c.Webhooks.Timeout = getEnvDuration("WEBHOOK_TIMEOUT", c.Webhooks.Timeout)
+	c.Webhooks.MaxAttempts = getEnvInt("WEBHOOK_MAX_ATTEMPTS", c.Webhooks.MaxAttempts)
+	c.Webhooks.InitialBackoff = getEnvDuration("WEBHOOK_INITIAL_BACKOFF", c.Webhooks.InitialBackoff)
+	c.Webhooks.MaxBackoff = getEnvDuration("WEBHOOK_MAX_BACKOFF", c.Webhooks.MaxBackoff)
+	c.Webhooks.Workers = getEnvInt("WEBHOOK_WORKERS", c.Webhooks.Workers)
+	c.Webhooks.AllowPrivateNetworks = getEnvBool("WEBHOOK_ALLOW_PRIVATE_NETWORKS", c.Webhooks.AllowPrivateNetworks)
+
+	c.Events.Backlog = getEnvInt("EVENTS_BACKLOG", c.Events.Backlog)
+	c.Events.HeartbeatInterval = getEnvDuration("EVENTS_HEARTBEAT_INTERVAL", c.Events.HeartbeatInterval)
+
+	c.Admin.Token = getEnvString("ADMIN_TOKEN", c.Admin.Token)
+
+	c.Auth.Enabled = getEnvBool("AUTH_ENABLED", c.Auth.Enabled)
+	c.Auth.APIKeys = getEnvString("AUTH_API_KEYS", c.Auth.APIKeys)
+	c.Auth.APIKeysFile = getEnvString("AUTH_API_KEYS_FILE", c.Auth.APIKeysFile)
+	c.Auth.JWKSFile = getEnvString("AUTH_JWKS_FILE", c.Auth.JWKSFile)
+	c.Auth.JWTIssuer = getEnvString("AUTH_JWT_ISSUER", c.Auth.JWTIssuer)
+	c.Auth.JWTAudience = getEnvString("AUTH_JWT_AUDIENCE", c.Auth.JWTAudience)
+	if scopes := getEnvScopes("AUTH_CLIENT_CERT_SCOPES"); scopes != nil {
+		c.Auth.ClientCertScopes = scopes
+	}
+	if scopes := getEnvScopes("AUTH_ANONYMOUS_SCOPES"); scopes != nil {
+		c.Auth.AnonymousScopes = scopes
 	}
 
-	return config, nil
+	c.TLS.CertFile = getEnvString("TLS_CERT_FILE", c.TLS.CertFile)
+	c.TLS.KeyFile = getEnvString("TLS_KEY_FILE", c.TLS.KeyFile)
+	c.TLS.ClientCAFile = getEnvString("TLS_CLIENT_CA_FILE", c.TLS.ClientCAFile)
+	c.TLS.RequireClientCert = getEnvBool("TLS_REQUIRE_CLIENT_CERT", c.TLS.RequireClientCert)
+	c.TLS.MinVersion = getEnvString("TLS_MIN_VERSION", c.TLS.MinVersion)
+
+	c.RateLimit.Rate = getEnvFloat("RATE_LIMIT_RPS", c.RateLimit.Rate)
+	c.RateLimit.Burst = getEnvInt("RATE_LIMIT_BURST", c.RateLimit.Burst)
+	c.RateLimit.DailyQuota = int64(getEnvInt("RATE_LIMIT_DAILY_QUOTA", int(c.RateLimit.DailyQuota)))
+	c.RateLimit.File = getEnvString("RATE_LIMIT_FILE", c.RateLimit.File)
+
+	c.Health.MaxRegistryAge = getEnvDuration("HEALTH_MAX_REGISTRY_AGE", c.Health.MaxRegistryAge)
+
+	c.Tracing.Enabled = getEnvBool("TRACING_ENABLED", c.Tracing.Enabled)
+	c.Tracing.Endpoint = getEnvString("TRACING_ENDPOINT", c.Tracing.Endpoint)
+	c.Tracing.Insecure = getEnvBool("TRACING_INSECURE", c.Tracing.Insecure)
+	c.Tracing.SampleRatio = getEnvFloat("TRACING_SAMPLE_RATIO", c.Tracing.SampleRatio)
+
+	c.Logging.Level = getEnvString("LOG_LEVEL", c.Logging.Level)
+	c.Logging.Format = getEnvString("LOG_FORMAT", c.Logging.Format)
 }
 
-// getDefaultSources returns default registry source configurations
-func getDefaultSources() []registry.SourceConfig {
-	return []registry.SourceConfig{
-		{
-			Type:       registry.SourceTypeOfficial,
-			URL:        "https://vigruzki.rkn.gov.ru/services/OperatorRequest/",
-			Timeout:    60 * time.Second,
-			MaxRetries: 2,
-			UserAgent:  "RKN-Checker/1.0",
-			RKN: registry.RKNConfig{
-				DumpFormatVersion:  getEnvString("RKN_DUMP_FORMAT_VERSION", "2.4"),
-				PollInterval:       getEnvDuration("RKN_POLL_INTERVAL", 30*time.Second),
-				MaxPollAttempts:    getEnvInt("RKN_MAX_POLL_ATTEMPTS", 20),
-				RequestFilePath:    getEnvString("RKN_REQUEST_FILE_PATH", ""),
-				SignatureFilePath:  getEnvString("RKN_SIGNATURE_FILE_PATH", ""),
-				EMCHDFilePath:      getEnvString("RKN_EMCHD_FILE_PATH", ""),
-				EMCHDSignaturePath: getEnvString("RKN_EMCHD_SIGNATURE_PATH", ""),
-				CertFilePath:       getEnvString("RKN_CERT_FILE_PATH", ""),
-				KeyFilePath:        getEnvString("RKN_KEY_FILE_PATH", ""),
-				CAFilePath:         getEnvString("RKN_CA_FILE_PATH", ""),
-				InsecureSkipVerify: getEnvBool("RKN_INSECURE_SKIP_VERIFY", false),
-			},
-		},
+// applySourceEnv overrides the first official source with the RKN_*
+// environment variables and REGISTRY_OFFICIAL_URL
+func applySourceEnv(sources []registry.SourceConfig) {
+	i := slices.IndexFunc(sources, func(source registry.SourceConfig) bool {
+		return source.Type == registry.SourceTypeOfficial
+	})
+	if i < 0 {
+		return
 	}
+
+	source := &sources[i]
+	source.URL = getEnvString("REGISTRY_OFFICIAL_URL", source.URL)
+
+	rkn := &source.RKN
+	rkn.DumpFormatVersion = getEnvString("RKN_DUMP_FORMAT_VERSION", rkn.DumpFormatVersion)
+	rkn.PollInterval = getEnvDuration("RKN_POLL_INTERVAL", rkn.PollInterval)
+	rkn.MaxPollAttempts = getEnvInt("RKN_MAX_POLL_ATTEMPTS", rkn.MaxPollAttempts)
+	rkn.RequestFilePath = getEnvString("RKN_REQUEST_FILE_PATH", rkn.RequestFilePath)
+	rkn.SignatureFilePath = getEnvString("RKN_SIGNATURE_FILE_PATH", rkn.SignatureFilePath)
+	rkn.EMCHDFilePath = getEnvString("RKN_EMCHD_FILE_PATH", rkn.EMCHDFilePath)
+	rkn.EMCHDSignaturePath = getEnvString("RKN_EMCHD_SIGNATURE_PATH", rkn.EMCHDSignaturePath)
+	rkn.CertFilePath = getEnvString("RKN_CERT_FILE_PATH", rkn.CertFilePath)
+	rkn.KeyFilePath = getEnvString("RKN_KEY_FILE_PATH", rkn.KeyFilePath)
+	rkn.CAFilePath = getEnvString("RKN_CA_FILE_PATH", rkn.CAFilePath)
+	rkn.InsecureSkipVerify = getEnvBool("RKN_INSECURE_SKIP_VERIFY", rkn.InsecureSkipVerify)
 }
 
-// getUpdateConfig returns update scheduler configuration
-func getUpdateConfig() updater.Config {
-	return updater.Config{
-		Interval:      getEnvDuration("UPDATE_INTERVAL", 48*time.Hour),
-		MaxRetries:    getEnvInt("UPDATE_MAX_RETRIES", 3),
-		RetryDelay:    getEnvDuration("UPDATE_RETRY_DELAY", 5*time.Minute),
-		UpdateTimeout: getEnvDuration("UPDATE_TIMEOUT", 10*time.Minute),
-	}
+// applyUpdateEnv overrides the update scheduler configuration with the
+// UPDATE_* environment variables
+func applyUpdateEnv(update *updater.Config) {
+	update.Interval = getEnvDuration("UPDATE_INTERVAL", update.Interval)
+	update.MaxRetries = getEnvInt("UPDATE_MAX_RETRIES", update.MaxRetries)
+	update.RetryDelay = getEnvDuration("UPDATE_RETRY_DELAY", update.RetryDelay)
+	update.UpdateTimeout = getEnvDuration("UPDATE_TIMEOUT", update.UpdateTimeout)
+
+	guard := &update.Guard
+	guard.MaxShrinkRatio = getEnvFloat("UPDATE_GUARD_MAX_SHRINK", guard.MaxShrinkRatio)
+	guard.MaxGrowthRatio = getEnvFloat("UPDATE_GUARD_MAX_GROWTH", guard.MaxGrowthRatio)
+	guard.MinEntries = getEnvInt("UPDATE_GUARD_MIN_ENTRIES", guard.MinEntries)
+	guard.Canaries = getEnvListOr("UPDATE_GUARD_CANARIES", guard.Canaries)
 }
 
 // Validate validates the configuration
@@ -128,11 +423,24 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid REST port: %d", c.Server.RESTPort)
 	}
 
+	if c.Server.GRPCDrainPeriod < 0 {
+		return fmt.Errorf("gRPC drain period must not be negative")
+	}
+
+	if len(c.Server.TrustedProxies) > 0 {
+		if _, err := proxy.NewResolver(c.Server.TrustedProxies, c.Server.TrustedProxyHeader); err != nil {
+			return fmt.Errorf("invalid trusted proxies: %w", err)
+		}
+	} else if c.Server.ProxyProtocol {
+		return fmt.Errorf("PROXY protocol requires trusted proxies")
+	}
+
 	// Validate registry configuration
 	if len(c.Registry.Sources) == 0 {
 		return fmt.Errorf("at least one registry source must be configured")
 	}
 
+	names := make(map[string]bool)
 	for i, source := range c.Registry.Sources {
 		if source.URL == "" {
 			return fmt.Errorf("registry source %d has empty URL", i)
@@ -140,6 +448,23 @@ func (c *Config) Validate() error {
 		if source.Timeout <= 0 {
 			return fmt.Errorf("registry source %d has invalid timeout", i)
 		}
+		if names[source.SourceName()] {
+			return fmt.Errorf("registry source %d has duplicate name %q", i, source.SourceName())
+		}
+		names[source.SourceName()] = true
+	}
+
+	guard := c.Registry.UpdateConfig.Guard
+	if guard.MaxShrinkRatio < 0 || guard.MaxShrinkRatio >= 1 {
+		return fmt.Errorf("update guard max shrink must be in [0, 1): %v", guard.MaxShrinkRatio)
+	}
+
+	if guard.MaxGrowthRatio < 0 {
+		return fmt.Errorf("update guard max growth must not be negative: %v", guard.MaxGrowthRatio)
+	}
+
+	if guard.MinEntries < 0 {
+		return fmt.Errorf("update guard min entries must not be negative: %d", guard.MinEntries)
 	}
 
 	// Validate storage configuration
@@ -151,6 +476,117 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("bloom filter hash count must be positive")
 	}
 
+	if c.Storage.ChangelogLimit < 0 {
+		return fmt.Errorf("changelog limit must not be negative")
+	}
+
+	if c.Storage.SnapshotRetentionCount < 0 {
+		return fmt.Errorf("snapshot retention count must not be negative")
+	}
+
+	if c.Storage.SnapshotRetentionAge < 0 {
+		return fmt.Errorf("snapshot retention age must not be negative")
+	}
+
+	if c.Storage.SnapshotCacheSize < 0 {
+		return fmt.Errorf("snapshot cache size must not be negative")
+	}
+
+	// Validate watchlist configuration
+	if callback := c.Watchlist.CallbackURL; callback != "" {
+		parsed, err := url.Parse(callback)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("watchlist callback URL must be an absolute http or https URL: %s", callback)
+		}
+	}
+
+	// Validate webhook configuration
+	if c.Webhooks.Timeout < 0 || c.Webhooks.InitialBackoff < 0 || c.Webhooks.MaxBackoff < 0 {
+		return fmt.Errorf("webhook timeout and backoff must not be negative")
+	}
+
+	if c.Webhooks.MaxAttempts < 0 {
+		return fmt.Errorf("webhook max attempts must not be negative")
+	}
+
+	if c.Webhooks.Workers < 0 {
+		return fmt.Errorf("webhook workers must not be negative")
+	}
+
+	// Validate event stream configuration
+	if c.Events.Backlog < 0 {
+		return fmt.Errorf("events backlog must not be negative")
+	}
+
+	if c.Events.HeartbeatInterval < 0 {
+		return fmt.Errorf("events heartbeat interval must not be negative")
+	}
+
+	// Validate admin configuration
+	if c.Admin.Token != "" && len(c.Admin.Token) < minAdminTokenLength {
+		return fmt.Errorf("admin token must be at least %d characters", minAdminTokenLength)
+	}
+
+	// Validate auth configuration
+	if _, err := auth.ParseKeys(c.Auth.APIKeys); err != nil {
+		return fmt.Errorf("invalid auth API keys: %w", err)
+	}
+
+	if err := auth.ValidateScopes(c.Auth.ClientCertScopes); err != nil {
+		return fmt.Errorf("invalid auth client certificate scopes: %w", err)
+	}
+
+	if err := auth.ValidateScopes(c.Auth.AnonymousScopes); err != nil {
+		return fmt.Errorf("invalid auth anonymous scopes: %w", err)
+	}
+
+	if slices.Contains(c.Auth.AnonymousScopes, domain.ScopeAdmin) {
+		return fmt.Errorf("the admin scope cannot be granted to anonymous callers")
+	}
+
+	if (c.Auth.JWTIssuer != "" || c.Auth.JWTAudience != "") && c.Auth.JWKSFile == "" {
+		return fmt.Errorf("auth JWT issuer and audience require a JWKS file")
+	}
+
+	if c.Auth.Enabled && !c.HasCredentials() {
+		return fmt.Errorf("auth is enabled but no API keys, JWKS, client certificate scopes or admin token are configured")
+	}
+
+	// Validate TLS configuration
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return fmt.Errorf("TLS requires both a certificate and a key file")
+	}
+	if c.TLS.ClientCAFile != "" && !c.TLS.Enabled() {
+		return fmt.Errorf("a TLS client CA requires a TLS certificate")
+	}
+	if c.TLS.RequireClientCert && c.TLS.ClientCAFile == "" {
+		return fmt.Errorf("requiring TLS client certificates requires a client CA file")
+	}
+	if c.TLS.Enabled() {
+		if _, err := tlsconfig.ParseVersion(c.TLS.MinVersion); err != nil {
+			return fmt.Errorf("invalid TLS minimum version: %w", err)
+		}
+	}
+
+	// Validate rate limit configuration
+	if c.RateLimit.Rate < 0 || c.RateLimit.Burst < 0 || c.RateLimit.DailyQuota < 0 {
+		return fmt.Errorf("rate limit rate, burst and daily quota must not be negative")
+	}
+
+	// Validate health configuration
+	if c.Health.MaxRegistryAge < 0 {
+		return fmt.Errorf("health max registry age must not be negative")
+	}
+
+	// Validate tracing configuration
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("tracing sample ratio must be in [0, 1]: %v", c.Tracing.SampleRatio)
+	}
+
+	if c.Tracing.Enabled && c.Tracing.Endpoint == "" {
+		return fmt.Errorf("tracing endpoint must be set when tracing is enabled")
+	}
+
 	// Validate logging configuration
 	validLevels := map[string]bool{
 		"debug": true, "info": true, "warn": true, "error": true,
@@ -169,6 +605,13 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// HasCredentials reports whether any API key, JWKS, client certificate
+// scope or admin token is configured
+func (c *Config) HasCredentials() bool {
+	return c.Auth.APIKeys != "" || c.Auth.APIKeysFile != "" || c.Auth.JWKSFile != "" ||
+		len(c.Auth.ClientCertScopes) > 0 || c.Admin.Token != ""
+}
+
 // IsDevelopment returns true if running in development mode
 func (c *Config) IsDevelopment() bool {
 	return c.Server.Env == "development"
@@ -206,6 +649,44 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvScopes reads a comma-separated list of scopes
+func getEnvScopes(key string) []domain.Scope {
+	var scopes []domain.Scope
+	for _, item := range getEnvList(key) {
+		scopes = append(scopes, domain.Scope(item))
+	}
+	return scopes
+}
+
+// getEnvList reads a comma-separated list, dropping empty items
+func getEnvList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getEnvListOr reads a comma-separated list, returning defaultValue when
+// the variable is unset or holds no item
+func getEnvListOr(key string, defaultValue []string) []string {
+	if items := getEnvList(key); items != nil {
+		return items
+	}
+	return defaultValue
+}
+
 func getEnvBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
@@ -2,9 +2,13 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/auth"
 	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/registry"
 )
 
@@ -34,6 +38,10 @@ func TestLoadConfig_DefaultValues(t *testing.T) {
 		t.Errorf("expected env 'development', got %q", config.Server.Env)
 	}
 
+	if config.Server.GRPCDrainPeriod != 5*time.Second {
+		t.Errorf("expected gRPC drain period 5s, got %v", config.Server.GRPCDrainPeriod)
+	}
+
 	// Test default registry config
 	if len(config.Registry.Sources) != 1 {
 		t.Errorf("expected 1 registry source, got %d", len(config.Registry.Sources))
@@ -52,6 +60,45 @@ func TestLoadConfig_DefaultValues(t *testing.T) {
 		t.Errorf("expected bloom filter size 10000000, got %d", config.Storage.BloomFilterSize)
 	}
 
+	if config.Storage.SnapshotDir != "" || config.Storage.ChangelogLimit != 50 {
+		t.Errorf("expected no snapshot dir and changelog limit 50, got %q/%d",
+			config.Storage.SnapshotDir, config.Storage.ChangelogLimit)
+	}
+
+	if config.Storage.SnapshotRetentionCount != 7 || config.Storage.SnapshotRetentionAge != 30*24*time.Hour {
+		t.Errorf("expected snapshot retention of 7 snapshots over 720h, got %d/%v",
+			config.Storage.SnapshotRetentionCount, config.Storage.SnapshotRetentionAge)
+	}
+
+	if config.Storage.SnapshotCacheSize != 2 {
+		t.Errorf("expected 2 snapshots kept loaded, got %d", config.Storage.SnapshotCacheSize)
+	}
+
+	if config.Watchlist.CallbackURL != "" {
+		t.Errorf("expected no watchlist callback, got %q", config.Watchlist.CallbackURL)
+	}
+
+	if config.Webhooks.MaxAttempts != 8 || config.Webhooks.InitialBackoff != 30*time.Second || config.Webhooks.MaxBackoff != time.Hour {
+		t.Errorf("expected 8 webhook attempts backing off from 30s to 1h, got %d/%v/%v",
+			config.Webhooks.MaxAttempts, config.Webhooks.InitialBackoff, config.Webhooks.MaxBackoff)
+	}
+	if config.Webhooks.Workers != 4 {
+		t.Errorf("expected 4 webhook workers, got %d", config.Webhooks.Workers)
+	}
+
+	if config.Events.Backlog != 100 || config.Events.HeartbeatInterval != 15*time.Second {
+		t.Errorf("expected an event backlog of 100 and 15s heartbeats, got %d/%v",
+			config.Events.Backlog, config.Events.HeartbeatInterval)
+	}
+
+	if config.Health.MaxRegistryAge != 96*time.Hour {
+		t.Errorf("expected a max registry age of 96h, got %v", config.Health.MaxRegistryAge)
+	}
+
+	if config.Admin.Token != "" {
+		t.Errorf("expected no admin token by default, got %q", config.Admin.Token)
+	}
+
 	// Test default logging config
 	if config.Logging.Level != "info" {
 		t.Errorf("expected log level 'info', got %q", config.Logging.Level)
@@ -132,6 +179,308 @@ func TestLoadConfig_CustomRegistryURLs(t *testing.T) {
 	}
 }
 
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing config file: %v", err)
+	}
+	return path
+}
+
+func TestLoad_YAMLFile(t *testing.T) {
+	clearEnv()
+
+	path := writeConfigFile(t, "config.yaml", `
+server:
+  grpc_port: 9191
+  trusted_proxies: [10.0.0.0/8]
+registry:
+  sources:
+    - name: primary
+      url: https://primary.example.com/api
+      rkn:
+        poll_interval: 10s
+    - name: mirror
+      url: https://mirror.example.com/api
+      timeout: 2m
+  update:
+    interval: 12h
+storage:
+  changelog_limit: 10
+logging:
+  level: debug
+`)
+
+	os.Setenv("GRPC_PORT", "9292")
+	os.Setenv("REGISTRY_OFFICIAL_URL", "https://override.example.com/api")
+	defer clearEnv()
+
+	config, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("expected valid config, got error: %v", err)
+	}
+
+	// Environment variables take precedence over the file
+	if config.Server.GRPCPort != 9292 {
+		t.Errorf("expected GRPC port 9292 from the environment, got %d", config.Server.GRPCPort)
+	}
+
+	// Keys left out of the file keep their defaults
+	if config.Server.RESTPort != 80 {
+		t.Errorf("expected default REST port 80, got %d", config.Server.RESTPort)
+	}
+	if config.Storage.BloomFilterHashes != 7 {
+		t.Errorf("expected default bloom filter hashes 7, got %d", config.Storage.BloomFilterHashes)
+	}
+	if config.Registry.UpdateConfig.MaxRetries != 3 {
+		t.Errorf("expected default update max retries 3, got %d", config.Registry.UpdateConfig.MaxRetries)
+	}
+
+	if !slices.Equal(config.Server.TrustedProxies, []string{"10.0.0.0/8"}) {
+		t.Errorf("expected trusted proxies from the file, got %q", config.Server.TrustedProxies)
+	}
+	if config.Registry.UpdateConfig.Interval != 12*time.Hour {
+		t.Errorf("expected update interval 12h, got %v", config.Registry.UpdateConfig.Interval)
+	}
+	if config.Storage.ChangelogLimit != 10 {
+		t.Errorf("expected changelog limit 10, got %d", config.Storage.ChangelogLimit)
+	}
+	if config.Logging.Level != "debug" {
+		t.Errorf("expected log level debug, got %q", config.Logging.Level)
+	}
+
+	sources := config.Registry.Sources
+	if len(sources) != 2 {
+		t.Fatalf("expected 2 sources, got %d", len(sources))
+	}
+
+	primary := sources[0]
+	if primary.Type != registry.SourceTypeOfficial || primary.URL != "https://override.example.com/api" {
+		t.Errorf("expected official primary source with the overridden URL, got %+v", primary)
+	}
+	if primary.RKN.PollInterval != 10*time.Second {
+		t.Errorf("expected poll interval 10s, got %v", primary.RKN.PollInterval)
+	}
+	if primary.Timeout != 60*time.Second || primary.MaxRetries != 2 || primary.RKN.DumpFormatVersion != "2.4" {
+		t.Errorf("expected source defaults to be filled in, got %+v", primary)
+	}
+
+	mirror := sources[1]
+	if mirror.URL != "https://mirror.example.com/api" || mirror.Timeout != 2*time.Minute {
+		t.Errorf("expected mirror source from the file, got %+v", mirror)
+	}
+}
+
+func TestLoad_JSONFile(t *testing.T) {
+	clearEnv()
+
+	path := writeConfigFile(t, "config.json", `{
+		"server": {"rest_port": 8080},
+		"registry": {"update": {"interval": "6h"}},
+		"logging": {"format": "json"}
+	}`)
+
+	config, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if config.Server.RESTPort != 8080 {
+		t.Errorf("expected REST port 8080, got %d", config.Server.RESTPort)
+	}
+	if config.Registry.UpdateConfig.Interval != 6*time.Hour {
+		t.Errorf("expected update interval 6h, got %v", config.Registry.UpdateConfig.Interval)
+	}
+	if config.Logging.Format != "json" {
+		t.Errorf("expected log format json, got %q", config.Logging.Format)
+	}
+	if len(config.Registry.Sources) != 1 || config.Registry.Sources[0].URL == "" {
+		t.Errorf("expected the default source, got %+v", config.Registry.Sources)
+	}
+}
+
+func TestLoad_FileSections(t *testing.T) {
+	clearEnv()
+
+	path := writeConfigFile(t, "config.yaml", `
+tls:
+  cert_file: /etc/rkn-checker/tls.crt
+  key_file: /etc/rkn-checker/tls.key
+watchlist:
+  callback_url: https://hooks.example.com/watch
+webhooks:
+  workers: 8
+  max_backoff: 30m
+events:
+  backlog: 50
+admin:
+  token: file-admin-token-secret
+auth:
+  enabled: true
+  anonymous_scopes: [check]
+rate_limit:
+  rate: 5
+  daily_quota: 1000
+health:
+  max_registry_age: 48h
+tracing:
+  enabled: true
+  endpoint: collector:4317
+`)
+
+	config, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if config.TLS.CertFile != "/etc/rkn-checker/tls.crt" || config.TLS.KeyFile != "/etc/rkn-checker/tls.key" {
+		t.Errorf("expected TLS files from the file, got %+v", config.TLS)
+	}
+	if config.Watchlist.CallbackURL != "https://hooks.example.com/watch" {
+		t.Errorf("expected watchlist callback from the file, got %q", config.Watchlist.CallbackURL)
+	}
+	if config.Webhooks.Workers != 8 || config.Webhooks.MaxBackoff != 30*time.Minute || config.Webhooks.MaxAttempts == 0 {
+		t.Errorf("expected webhook settings from the file over the defaults, got %+v", config.Webhooks)
+	}
+	if config.Events.Backlog != 50 {
+		t.Errorf("expected events backlog 50, got %d", config.Events.Backlog)
+	}
+	if config.Admin.Token != "file-admin-token-secret" {
+		t.Error("expected the admin token from the file")
+	}
+	if !config.Auth.Enabled || !slices.Equal(config.Auth.AnonymousScopes, []domain.Scope{domain.ScopeCheck}) {
+		t.Errorf("expected auth settings from the file, got %+v", config.Auth)
+	}
+	if config.RateLimit.Rate != 5 || config.RateLimit.DailyQuota != 1000 {
+		t.Errorf("expected rate limits from the file, got %+v", config.RateLimit)
+	}
+	if config.Health.MaxRegistryAge != 48*time.Hour {
+		t.Errorf("expected max registry age 48h, got %v", config.Health.MaxRegistryAge)
+	}
+	if !config.Tracing.Enabled || config.Tracing.Endpoint != "collector:4317" {
+		t.Errorf("expected tracing settings from the file, got %+v", config.Tracing)
+	}
+	if err := config.Validate(); err != nil {
+		t.Errorf("expected valid config, got error: %v", err)
+	}
+}
+
+func TestLoad_FileSourceZeroValues(t *testing.T) {
+	clearEnv()
+
+	path := writeConfigFile(t, "config.yaml", `
+registry:
+  sources:
+    - name: primary
+      max_retries: 0
+      rkn:
+        max_poll_attempts: 5
+`)
+
+	config, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	source := config.Registry.Sources[0]
+	if source.MaxRetries != 0 {
+		t.Errorf("expected max retries 0 from the file, got %d", source.MaxRetries)
+	}
+	if source.RKN.MaxPollAttempts != 5 {
+		t.Errorf("expected max poll attempts 5, got %d", source.RKN.MaxPollAttempts)
+	}
+	if source.Timeout != 60*time.Second || source.RKN.PollInterval != 30*time.Second || source.URL == "" {
+		t.Errorf("expected the settings left out to take the defaults, got %+v", source)
+	}
+}
+
+func TestLoad_InvalidFile(t *testing.T) {
+	clearEnv()
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"unknown key", "server:\n  grpc_prot: 9090\n"},
+		{"unknown section", "metrics:\n  enabled: true\n"},
+		{"invalid duration", "registry:\n  update:\n    interval: soon\n"},
+		{"malformed", "server: [\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfigFile(t, "config.yaml", tt.content)
+			if _, err := Load(path); err == nil {
+				t.Error("expected error for invalid config file")
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected error for missing config file")
+	}
+}
+
+func TestConfig_Validate_DuplicateSourceNames(t *testing.T) {
+	clearEnv()
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	config.Registry.Sources = append(config.Registry.Sources, config.Registry.Sources[0])
+	if err := config.Validate(); err == nil {
+		t.Error("expected error for sources sharing a name")
+	}
+
+	config.Registry.Sources[1].Name = "mirror"
+	if err := config.Validate(); err != nil {
+		t.Errorf("expected valid config, got error: %v", err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	clearEnv()
+
+	current, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	next, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if changes := Diff(current, next); !changes.Empty() {
+		t.Errorf("expected no changes, got %+v", changes)
+	}
+
+	next.Registry.Sources[0].URL = "https://mirror.example.com/api"
+	next.Registry.UpdateConfig.Interval = time.Hour
+	next.Registry.UpdateConfig.Guard.MinEntries = 10
+	next.Logging.Level = "debug"
+	next.Server.GRPCPort = 9191
+	next.Admin.Token = "another-admin-token"
+
+	changes := Diff(current, next)
+
+	wantReloadable := []string{SourcesSetting, UpdateIntervalSetting, LogLevelSetting}
+	if !slices.Equal(changes.Reloadable, wantReloadable) {
+		t.Errorf("expected reloadable changes %q, got %q", wantReloadable, changes.Reloadable)
+	}
+
+	wantRestart := []string{"server.grpc_port", "registry.update.guard.min_entries", "admin.token"}
+	if !slices.Equal(changes.RestartRequired, wantRestart) {
+		t.Errorf("expected restart-required changes %q, got %q", wantRestart, changes.RestartRequired)
+	}
+}
+
 func TestConfig_Validate_ValidConfig(t *testing.T) {
 	config := &Config{
 		Server: ServerConfig{
@@ -321,6 +670,286 @@ func TestConfig_Validate_InvalidLogging(t *testing.T) {
 	}
 }
 
+func TestConfig_Validate_InvalidWatchlist(t *testing.T) {
+	tests := []struct {
+		name     string
+		callback string
+	}{
+		{"Relative callback URL", "/hooks/rkn"},
+		{"Non-HTTP callback URL", "ftp://example.com/hooks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				Server: ServerConfig{
+					GRPCPort: 9090,
+					RESTPort: 80,
+				},
+				Registry: RegistryConfig{
+					Sources: []registry.SourceConfig{
+						{URL: "https://example.com", Timeout: 30 * time.Second},
+					},
+				},
+				Storage: StorageConfig{
+					BloomFilterSize:   1000000,
+					BloomFilterHashes: 7,
+				},
+				Watchlist: WatchlistConfig{
+					CallbackURL: tt.callback,
+				},
+				Logging: LoggingConfig{
+					Level:  "info",
+					Format: "json",
+				},
+			}
+
+			err := config.Validate()
+			if err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_AdminToken(t *testing.T) {
+	config := &Config{
+		Server: ServerConfig{
+			GRPCPort: 9090,
+			RESTPort: 80,
+		},
+		Registry: RegistryConfig{
+			Sources: []registry.SourceConfig{
+				{URL: "https://example.com", Timeout: 30 * time.Second},
+			},
+		},
+		Storage: StorageConfig{
+			BloomFilterSize:   1000000,
+			BloomFilterHashes: 7,
+		},
+		Admin: AdminConfig{Token: "short"},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "json",
+		},
+	}
+
+	if err := config.Validate(); err == nil {
+		t.Error("expected validation error for a short admin token")
+	}
+
+	config.Admin.Token = "0123456789abcdef"
+	if err := config.Validate(); err != nil {
+		t.Errorf("unexpected validation error: %v", err)
+	}
+}
+
+func TestConfig_Validate_Tracing(t *testing.T) {
+	config := &Config{
+		Server: ServerConfig{
+			GRPCPort: 9090,
+			RESTPort: 80,
+		},
+		Registry: RegistryConfig{
+			Sources: []registry.SourceConfig{
+				{URL: "https://example.com", Timeout: 30 * time.Second},
+			},
+		},
+		Storage: StorageConfig{
+			BloomFilterSize:   1000000,
+			BloomFilterHashes: 7,
+		},
+		Tracing: TracingConfig{Enabled: true, SampleRatio: 1.5},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "json",
+		},
+	}
+
+	if err := config.Validate(); err == nil {
+		t.Error("expected validation error for a sample ratio above 1")
+	}
+
+	config.Tracing.SampleRatio = 0.1
+	if err := config.Validate(); err == nil {
+		t.Error("expected validation error for tracing without an endpoint")
+	}
+
+	config.Tracing.Endpoint = "otel-collector:4317"
+	if err := config.Validate(); err != nil {
+		t.Errorf("unexpected validation error: %v", err)
+	}
+}
+
+func TestConfig_Validate_Auth(t *testing.T) {
+	config := &Config{
+		Server: ServerConfig{
+			GRPCPort: 9090,
+			RESTPort: 80,
+		},
+		Registry: RegistryConfig{
+			Sources: []registry.SourceConfig{
+				{URL: "https://example.com", Timeout: 30 * time.Second},
+			},
+		},
+		Storage: StorageConfig{
+			BloomFilterSize:   1000000,
+			BloomFilterHashes: 7,
+		},
+		Auth: AuthConfig{Enabled: true},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "json",
+		},
+	}
+
+	if err := config.Validate(); err == nil {
+		t.Error("expected validation error for auth without credentials")
+	}
+
+	config.Auth.APIKeys = "ci:not-a-hash:check"
+	if err := config.Validate(); err == nil {
+		t.Error("expected validation error for an unhashed API key")
+	}
+
+	config.Auth.APIKeys = "ci:" + auth.HashKey("secret") + ":check"
+	config.Auth.AnonymousScopes = []domain.Scope{domain.ScopeAdmin}
+	if err := config.Validate(); err == nil {
+		t.Error("expected validation error for anonymous admin access")
+	}
+
+	config.Auth.AnonymousScopes = []domain.Scope{domain.ScopeStats}
+	config.Auth.JWTAudience = "rkn-checker"
+	if err := config.Validate(); err == nil {
+		t.Error("expected validation error for a JWT audience without a JWKS file")
+	}
+
+	config.Auth.JWKSFile = "/etc/rkn-checker/jwks.json"
+	if err := config.Validate(); err != nil {
+		t.Errorf("unexpected validation error: %v", err)
+	}
+}
+
+func TestConfig_Validate_RateLimit(t *testing.T) {
+	config := &Config{
+		Server: ServerConfig{
+			GRPCPort: 9090,
+			RESTPort: 80,
+		},
+		Registry: RegistryConfig{
+			Sources: []registry.SourceConfig{
+				{URL: "https://example.com", Timeout: 30 * time.Second},
+			},
+		},
+		Storage: StorageConfig{
+			BloomFilterSize:   1000000,
+			BloomFilterHashes: 7,
+		},
+		RateLimit: RateLimitConfig{Rate: 10, Burst: 20, DailyQuota: 10000},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "json",
+		},
+	}
+
+	if err := config.Validate(); err != nil {
+		t.Errorf("unexpected validation error: %v", err)
+	}
+
+	config.RateLimit.DailyQuota = -1
+	if err := config.Validate(); err == nil {
+		t.Error("expected validation error for a negative daily quota")
+	}
+}
+
+func TestConfig_Validate_TrustedProxies(t *testing.T) {
+	config := &Config{
+		Server: ServerConfig{
+			GRPCPort:      9090,
+			RESTPort:      80,
+			ProxyProtocol: true,
+		},
+		Registry: RegistryConfig{
+			Sources: []registry.SourceConfig{
+				{URL: "https://example.com", Timeout: 30 * time.Second},
+			},
+		},
+		Storage: StorageConfig{
+			BloomFilterSize:   1000000,
+			BloomFilterHashes: 7,
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "json",
+		},
+	}
+
+	if err := config.Validate(); err == nil {
+		t.Error("expected validation error for PROXY protocol without trusted proxies")
+	}
+
+	config.Server.TrustedProxies = []string{"10.0.0.0/8", "not-a-cidr"}
+	config.Server.TrustedProxyHeader = "X-Forwarded-For"
+	if err := config.Validate(); err == nil {
+		t.Error("expected validation error for an invalid trusted proxy")
+	}
+
+	config.Server.TrustedProxies = []string{"10.0.0.0/8", "192.168.1.10"}
+	config.Server.TrustedProxyHeader = "X-Real-IP"
+	if err := config.Validate(); err == nil {
+		t.Error("expected validation error for an unsupported forwarding header")
+	}
+
+	config.Server.TrustedProxyHeader = "forwarded"
+	if err := config.Validate(); err != nil {
+		t.Errorf("unexpected validation error: %v", err)
+	}
+}
+
+func TestConfig_Validate_TLS(t *testing.T) {
+	config := &Config{
+		Server: ServerConfig{
+			GRPCPort: 9090,
+			RESTPort: 80,
+		},
+		Registry: RegistryConfig{
+			Sources: []registry.SourceConfig{
+				{URL: "https://example.com", Timeout: 30 * time.Second},
+			},
+		},
+		Storage: StorageConfig{
+			BloomFilterSize:   1000000,
+			BloomFilterHashes: 7,
+		},
+		TLS: TLSConfig{CertFile: "/etc/rkn-checker/tls.crt", MinVersion: "1.2"},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "json",
+		},
+	}
+
+	if err := config.Validate(); err == nil {
+		t.Error("expected validation error for a certificate without a key")
+	}
+
+	config.TLS.KeyFile = "/etc/rkn-checker/tls.key"
+	config.TLS.RequireClientCert = true
+	if err := config.Validate(); err == nil {
+		t.Error("expected validation error for required client certificates without a client CA")
+	}
+
+	config.TLS.ClientCAFile = "/etc/rkn-checker/ca.crt"
+	config.TLS.MinVersion = "1.0"
+	if err := config.Validate(); err == nil {
+		t.Error("expected validation error for TLS 1.0")
+	}
+
+	config.TLS.MinVersion = "1.3"
+	if err := config.Validate(); err != nil {
+		t.Errorf("unexpected validation error: %v", err)
+	}
+}
+
 func TestConfig_IsDevelopment(t *testing.T) {
 	config := &Config{
 		Server: ServerConfig{Env: "development"},
@@ -397,6 +1026,64 @@ func TestUtilityFunctions(t *testing.T) {
 	if getEnvBool("NON_EXISTENT", false) {
 		t.Error("getEnvBool should return default for non-existent key")
 	}
+
+	// Test getEnvFloat
+	os.Setenv("TEST_FLOAT", "0.25")
+	defer os.Unsetenv("TEST_FLOAT")
+
+	if getEnvFloat("TEST_FLOAT", 1) != 0.25 {
+		t.Error("getEnvFloat should return environment value")
+	}
+
+	if getEnvFloat("NON_EXISTENT", 1) != 1 {
+		t.Error("getEnvFloat should return default for non-existent key")
+	}
+
+	// Test getEnvList
+	os.Setenv("TEST_LIST", " a.com, ,b.com ")
+	defer os.Unsetenv("TEST_LIST")
+
+	if list := getEnvList("TEST_LIST"); len(list) != 2 || list[0] != "a.com" || list[1] != "b.com" {
+		t.Errorf("getEnvList should split and trim items, got %q", list)
+	}
+
+	if getEnvList("NON_EXISTENT") != nil {
+		t.Error("getEnvList should return nil for non-existent key")
+	}
+}
+
+func TestLoadConfig_UpdateGuard(t *testing.T) {
+	clearEnv()
+
+	os.Setenv("UPDATE_GUARD_MAX_SHRINK", "0.1")
+	os.Setenv("UPDATE_GUARD_MIN_ENTRIES", "1000")
+	os.Setenv("UPDATE_GUARD_CANARIES", "rutracker.org,linkedin.com")
+
+	defer clearEnv()
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	guard := config.Registry.UpdateConfig.Guard
+	if guard.MaxShrinkRatio != 0.1 {
+		t.Errorf("expected max shrink 0.1, got %v", guard.MaxShrinkRatio)
+	}
+	if guard.MaxGrowthRatio != 1.0 {
+		t.Errorf("expected default max growth 1.0, got %v", guard.MaxGrowthRatio)
+	}
+	if guard.MinEntries != 1000 {
+		t.Errorf("expected min entries 1000, got %d", guard.MinEntries)
+	}
+	if len(guard.Canaries) != 2 {
+		t.Errorf("expected 2 canaries, got %q", guard.Canaries)
+	}
+
+	config.Registry.UpdateConfig.Guard.MaxShrinkRatio = 1.5
+	if err := config.Validate(); err == nil {
+		t.Error("expected validation error for max shrink >= 1")
+	}
 }
 
 // clearEnv removes all test-related environment variables
@@ -405,8 +1092,12 @@ func clearEnv() {
 		"GRPC_PORT", "REST_PORT", "HOST", "SERVER_ENV",
 		"LOG_LEVEL", "LOG_FORMAT", "UPDATE_INTERVAL",
 		"BLOOM_FILTER_SIZE", "BLOOM_FILTER_HASHES",
-		"REGISTRY_OFFICIAL_URL",
+		"REGISTRY_OFFICIAL_URL", "REGISTRY_TRUST_BUNDLE_PATH",
+		"UPDATE_GUARD_MAX_SHRINK", "UPDATE_GUARD_MAX_GROWTH",
+		"UPDATE_GUARD_MIN_ENTRIES", "UPDATE_GUARD_CANARIES",
 		"TEST_STRING", "TEST_INT", "TEST_DURATION", "TEST_BOOL",
+		"TEST_FLOAT", "TEST_LIST", "SNAPSHOT_DIR", "CHANGELOG_LIMIT",
+		"CONFIG_FILE",
 	}
 
 	for _, v := range vars {
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Settings applied to a running service when the configuration is reloaded,
+// named by their path in the configuration file
+const (
+	SourcesSetting        = "registry.sources"
+	UpdateIntervalSetting = "registry.update.interval"
+	LogLevelSetting       = "logging.level"
+)
+
+// reloadable lists the settings that take effect without a restart
+var reloadable = []string{SourcesSetting, UpdateIntervalSetting, LogLevelSetting}
+
+// Changes lists the settings that differ between two configurations
+type Changes struct {
+	// Reloadable settings can be applied to the running service
+	Reloadable []string
+	// RestartRequired settings only take effect after a restart
+	RestartRequired []string
+}
+
+// Empty reports whether no setting changed
+func (c Changes) Empty() bool {
+	return len(c.Reloadable) == 0 && len(c.RestartRequired) == 0
+}
+
+// Diff lists the settings of next that differ from current, by their dotted
+// path, e.g. "server.grpc_port". Lists, such as the registry sources, are
+// compared as a whole.
+func Diff(current, next *Config) Changes {
+	var changes Changes
+	diffValues("", reflect.ValueOf(*current), reflect.ValueOf(*next), func(path string) {
+		if slices.Contains(reloadable, path) {
+			changes.Reloadable = append(changes.Reloadable, path)
+		} else {
+			changes.RestartRequired = append(changes.RestartRequired, path)
+		}
+	})
+	return changes
+}
+
+// diffValues reports the path of every field that differs between a and b,
+// descending into structs
+func diffValues(path string, a, b reflect.Value, report func(string)) {
+	if a.Kind() != reflect.Struct {
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			report(path)
+		}
+		return
+	}
+
+	for i := range a.NumField() {
+		name := fieldName(a.Type().Field(i))
+		if path != "" {
+			name = path + "." + name
+		}
+		diffValues(name, a.Field(i), b.Field(i), report)
+	}
+}
+
+// fieldName names a field after its YAML or JSON key, falling back to its
+// lowercased Go name for fields without one
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"yaml", "json"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return strings.ToLower(field.Name)
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/registry"
+)
+
+// fileConfig lists the sections a configuration file may set. Sections and
+// keys the file leaves out keep their defaults.
+type fileConfig struct {
+	Server    *ServerConfig    `yaml:"server"`
+	TLS       *TLSConfig       `yaml:"tls"`
+	Registry  *RegistryConfig  `yaml:"registry"`
+	Storage   *StorageConfig   `yaml:"storage"`
+	Watchlist *WatchlistConfig `yaml:"watchlist"`
+	Webhooks  *WebhooksConfig  `yaml:"webhooks"`
+	Events    *EventsConfig    `yaml:"events"`
+	Admin     *AdminConfig     `yaml:"admin"`
+	Auth      *AuthConfig      `yaml:"auth"`
+	RateLimit *RateLimitConfig `yaml:"rate_limit"`
+	Health    *HealthConfig    `yaml:"health"`
+	Tracing   *TracingConfig   `yaml:"tracing"`
+	Logging   *LoggingConfig   `yaml:"logging"`
+}
+
+// sourcesFile holds the registry sources of a configuration file as they
+// were written
+type sourcesFile struct {
+	Registry struct {
+		Sources []yaml.Node `yaml:"sources"`
+	} `yaml:"registry"`
+}
+
+// loadFile overrides config with the YAML or JSON file at path. JSON is
+// read as YAML, which it is a subset of.
+func loadFile(path string, config *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	file := fileConfig{
+		Server:    &config.Server,
+		TLS:       &config.TLS,
+		Registry:  &config.Registry,
+		Storage:   &config.Storage,
+		Watchlist: &config.Watchlist,
+		Webhooks:  &config.Webhooks,
+		Events:    &config.Events,
+		Admin:     &config.Admin,
+		Auth:      &config.Auth,
+		RateLimit: &config.RateLimit,
+		Health:    &config.Health,
+		Tracing:   &config.Tracing,
+		Logging:   &config.Logging,
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	if err := decodeSources(data, config.Registry.Sources); err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// decodeSources decodes the registry sources of a configuration file again,
+// each onto the defaults of its type, so the settings a source leaves out
+// take the defaults while the ones it sets are kept, zero values included
+func decodeSources(data []byte, sources []registry.SourceConfig) error {
+	var file sourcesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return err
+	}
+
+	for i := range min(len(sources), len(file.Registry.Sources)) {
+		node := &file.Registry.Sources[i]
+
+		var source registry.SourceConfig
+		if err := node.Decode(&source); err != nil {
+			return err
+		}
+		if source.Type == "" || source.Type == registry.SourceTypeOfficial {
+			source = registry.DefaultSourceConfigs()[0]
+			if err := node.Decode(&source); err != nil {
+				return err
+			}
+		}
+		sources[i] = source
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Package egress keeps requests to caller-supplied URLs, such as webhook
+// and watch callbacks, from reaching loopback, link-local and private
+// addresses. Destinations are checked when a URL is accepted and again when
+// a connection is dialled, which also covers redirects and names that
+// resolve differently later.
+package egress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// resolveTimeout bounds the lookup of a host name when a URL is checked
+const resolveTimeout = 2 * time.Second
+
+// ErrForbiddenAddress is returned for destinations that are not publicly
+// routable
+var ErrForbiddenAddress = errors.New("destination address is not public")
+
+// sharedAddressSpace is the carrier-grade NAT range, which is not routed on
+// the internet either
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// Public reports whether addr is a publicly routable unicast address
+func Public(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() &&
+		!addr.IsPrivate() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+// CheckURL rejects a URL whose host is, or resolves to, an address that is
+// not public. Host names that do not resolve yet are accepted; the
+// connection is checked again when it is dialled.
+func CheckURL(ctx context.Context, target *url.URL) error {
+	host := target.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !Public(addr) {
+			return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if !Public(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrForbiddenAddress, host, addr)
+		}
+	}
+	return nil
+}
+
+// NewClient returns an HTTP client that refuses to connect to addresses
+// that are not public unless allowPrivate is set. Proxies from the
+// environment are not used, since the proxy rather than the destination
+// would be dialled.
+func NewClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = checkDial
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// checkDial refuses connections to addresses that are not public once the
+// destination is resolved
+func checkDial(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !Public(addr) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestPublic(t *testing.T) {
+	tests := []struct {
+		addr   string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+		{"224.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		if got := Public(netip.MustParseAddr(tt.addr)); got != tt.public {
+			t.Errorf("%s: expected public=%v, got %v", tt.addr, tt.public, got)
+		}
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	for _, raw := range []string{"http://127.0.0.1:8080/hook", "http://[::1]/hook", "http://169.254.169.254/", "https://localhost/hook"} {
+		target, _ := url.Parse(raw)
+		if err := CheckURL(context.Background(), target); !errors.Is(err, ErrForbiddenAddress) {
+			t.Errorf("%s: expected ErrForbiddenAddress, got %v", raw, err)
+		}
+	}
+
+	target, _ := url.Parse("https://93.184.216.34/hook")
+	if err := CheckURL(context.Background(), target); err != nil {
+		t.Errorf("expected a public address to be accepted, got %v", err)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	if _, err := NewClient(time.Second, false).Get(server.URL); !errors.Is(err, ErrForbiddenAddress) {
+		t.Errorf("expected the loopback connection to be refused, got %v", err)
+	}
+
+	resp, err := NewClient(time.Second, true).Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected private addresses to be allowed, got %v", err)
+	}
+	resp.Body.Close()
+}
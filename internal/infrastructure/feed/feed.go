@@ -0,0 +1,164 @@
+package feed
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+const (
+	// DefaultBacklog is how many recent events are kept for resuming clients
+	DefaultBacklog = 100
+
+	// subscriberBuffer is how many events a subscriber may fall behind
+	// before it is dropped and has to resume
+	subscriberBuffer = 16
+)
+
+// Feed fans registry update events out to streaming clients and keeps a
+// backlog so clients can resume after the last version they saw
+type Feed struct {
+	mu          sync.Mutex
+	limit       int
+	changelogs  ChangelogSource
+	backlog     []*domain.RegistryEvent
+	subscribers map[chan *domain.RegistryEvent]struct{}
+}
+
+// ChangelogSource provides the changelog recorded for a registry update
+type ChangelogSource interface {
+	Latest(toVersion string) (*domain.Changelog, bool)
+}
+
+// Option configures optional Feed dependencies
+type Option func(*Feed)
+
+// WithChangelogs takes the diff of each update from the changelogs recorded
+// for it instead of computing it again. The source must be notified of
+// updates before the feed.
+func WithChangelogs(changelogs ChangelogSource) Option {
+	return func(f *Feed) {
+		f.changelogs = changelogs
+	}
+}
+
+// NewFeed creates a feed keeping up to limit events for resuming clients.
+// A non-positive limit selects DefaultBacklog.
+func NewFeed(limit int, opts ...Option) *Feed {
+	if limit <= 0 {
+		limit = DefaultBacklog
+	}
+	f := &Feed{
+		limit:       limit,
+		subscribers: make(map[chan *domain.RegistryEvent]struct{}),
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// OnRegistryUpdate publishes an event for a registry swapped into the store
+func (f *Feed) OnRegistryUpdate(previous, current *domain.Registry, report *domain.IngestReport) {
+	event := &domain.RegistryEvent{
+		Version:   current.Version,
+		Size:      current.Size(),
+		Counts:    current.CountsByType(),
+		AppliedAt: time.Now(),
+	}
+	if previous != nil {
+		if changelog := f.changelog(previous, current); changelog != nil {
+			event.Diff = &domain.DiffSummary{
+				FromVersion: previous.Version,
+				Added:       changelog.Added,
+				Removed:     changelog.Removed,
+				Changed:     changelog.Changed,
+			}
+		}
+	}
+
+	f.Publish(event)
+}
+
+// changelog returns the diff between two registries, from the changelog
+// source when there is one
+func (f *Feed) changelog(previous, current *domain.Registry) *domain.Changelog {
+	if f.changelogs == nil {
+		return domain.DiffRegistries(previous, current)
+	}
+
+	changelog, ok := f.changelogs.Latest(current.Version)
+	if !ok || changelog.FromVersion != previous.Version {
+		slog.Warn("No changelog recorded for registry update", "from_version", previous.Version, "to_version", current.Version)
+		return nil
+	}
+	return changelog
+}
+
+// OnRegistryCleared publishes an event without a version for the cleared
+// registry, so clients stop relying on the last one
+func (f *Feed) OnRegistryCleared() {
+	f.Publish(&domain.RegistryEvent{
+		Counts:    make(map[domain.BlockingType]int),
+		AppliedAt: time.Now(),
+	})
+}
+
+// Publish appends an event to the backlog and sends it to every subscriber.
+// Subscribers that fell too far behind are dropped; their channel is closed.
+func (f *Feed) Publish(event *domain.RegistryEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.backlog = append(f.backlog, event)
+	if excess := len(f.backlog) - f.limit; excess > 0 {
+		f.backlog = append([]*domain.RegistryEvent(nil), f.backlog[excess:]...)
+	}
+
+	for ch := range f.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(f.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe returns the events published after the given version, oldest
+// first, and a channel receiving later events until cancel is called. An
+// empty version replays nothing. A version no longer in the backlog replays
+// the latest event, so the client learns the current registry. The channel
+// is closed when the subscriber falls too far behind.
+func (f *Feed) Subscribe(since string) ([]*domain.RegistryEvent, <-chan *domain.RegistryEvent, func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var missed []*domain.RegistryEvent
+	if since != "" && len(f.backlog) > 0 {
+		missed = f.backlog[len(f.backlog)-1:]
+		for i, event := range f.backlog {
+			if event.Version == since {
+				missed = f.backlog[i+1:]
+				break
+			}
+		}
+		missed = append([]*domain.RegistryEvent(nil), missed...)
+	}
+
+	ch := make(chan *domain.RegistryEvent, subscriberBuffer)
+	f.subscribers[ch] = struct{}{}
+
+	cancel := func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if _, ok := f.subscribers[ch]; ok {
+			delete(f.subscribers, ch)
+			close(ch)
+		}
+	}
+
+	return missed, ch, cancel
+}
@@ -0,0 +1,124 @@
+package feed
+
+import (
+	"testing"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+func createRegistry(version string, domains ...string) *domain.Registry {
+	registry := domain.NewRegistry()
+	registry.Version = version
+	for _, value := range domains {
+		entry, _ := domain.NewRegistryEntry(domain.BlockingTypeDomain, value)
+		registry.AddEntry(entry)
+	}
+	return registry
+}
+
+func TestFeed_OnRegistryUpdate(t *testing.T) {
+	feed := NewFeed(0)
+	_, events, cancel := feed.Subscribe("")
+	defer cancel()
+
+	first := createRegistry("v1", "a.com", "b.com")
+	second := createRegistry("v2", "b.com", "c.com", "d.com")
+	feed.OnRegistryUpdate(nil, first, nil)
+	feed.OnRegistryUpdate(first, second, nil)
+
+	event := <-events
+	if event.Version != "v1" || event.Size != 2 || event.Diff != nil {
+		t.Errorf("expected the first event without a diff, got %+v", event)
+	}
+
+	event = <-events
+	if event.Version != "v2" || event.Counts[domain.BlockingTypeDomain] != 3 {
+		t.Errorf("unexpected second event %+v", event)
+	}
+	if diff := event.Diff; diff == nil || diff.FromVersion != "v1" || diff.Added != 2 || diff.Removed != 1 {
+		t.Errorf("expected a diff of +2/-1 from v1, got %+v", diff)
+	}
+
+	feed.OnRegistryCleared()
+	if event = <-events; event.Version != "" || event.Size != 0 || event.Diff != nil {
+		t.Errorf("expected an event without a version for the clear, got %+v", event)
+	}
+}
+
+// recordedChangelogs returns the changelogs it was given
+type recordedChangelogs map[string]*domain.Changelog
+
+func (r recordedChangelogs) Latest(toVersion string) (*domain.Changelog, bool) {
+	changelog, ok := r[toVersion]
+	return changelog, ok
+}
+
+func TestFeed_UsesRecordedChangelogs(t *testing.T) {
+	changelogs := recordedChangelogs{
+		"v2": {FromVersion: "v1", ToVersion: "v2", Added: 7, Removed: 3},
+	}
+	feed := NewFeed(0, WithChangelogs(changelogs))
+	_, events, cancel := feed.Subscribe("")
+	defer cancel()
+
+	first := createRegistry("v1", "a.com")
+	second := createRegistry("v2", "b.com")
+	feed.OnRegistryUpdate(first, second, nil)
+
+	if diff := (<-events).Diff; diff == nil || diff.Added != 7 || diff.Removed != 3 {
+		t.Errorf("expected the recorded diff of +7/-3, got %+v", diff)
+	}
+}
+
+func TestFeed_SubscribeResumes(t *testing.T) {
+	feed := NewFeed(2)
+	for _, version := range []string{"v1", "v2", "v3"} {
+		feed.Publish(&domain.RegistryEvent{Version: version})
+	}
+
+	tests := []struct {
+		name     string
+		since    string
+		expected []string
+	}{
+		{"No cursor", "", nil},
+		{"Retained version", "v2", []string{"v3"}},
+		{"Current version", "v3", nil},
+		{"Evicted version", "v1", []string{"v3"}},
+		{"Unknown version", "v9", []string{"v3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			missed, _, cancel := feed.Subscribe(tt.since)
+			defer cancel()
+
+			if len(missed) != len(tt.expected) {
+				t.Fatalf("expected %v, got %d events", tt.expected, len(missed))
+			}
+			for i, version := range tt.expected {
+				if missed[i].Version != version {
+					t.Errorf("event %d: expected %s, got %s", i, version, missed[i].Version)
+				}
+			}
+		})
+	}
+}
+
+func TestFeed_DropsSlowSubscribers(t *testing.T) {
+	feed := NewFeed(0)
+	_, events, cancel := feed.Subscribe("")
+	defer cancel()
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		feed.Publish(&domain.RegistryEvent{Version: "v"})
+	}
+
+	received := 0
+	for range events {
+		received++
+	}
+	if received != subscriberBuffer {
+		t.Errorf("expected %d buffered events before the channel closed, got %d", subscriberBuffer, received)
+	}
+}
@@ -0,0 +1,37 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// lockFileName is the file locked inside the snapshot directory while the
+// history is in use
+const lockFileName = "history.lock"
+
+// ErrLocked is returned by Lock when another process holds a conflicting lock
+var ErrLocked = errors.New("history is in use by another process")
+
+// Lock locks the history in snapshotDir until the returned release is
+// called or the process exits. The service holds a shared lock while it
+// records history; offline tools that rewrite it take an exclusive one, so
+// neither overwrites the other's changes. Locking is advisory and only
+// enforced on Unix systems.
+func Lock(snapshotDir string, exclusive bool) (release func() error, err error) {
+	if err := os.MkdirAll(snapshotDir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating snapshot directory: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(snapshotDir, lockFileName), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening history lock: %w", err)
+	}
+	if err := flock(file, exclusive); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return file.Close, nil
+}
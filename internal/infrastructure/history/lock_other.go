@@ -0,0 +1,10 @@
+//go:build !unix
+
+package history
+
+import "os"
+
+// flock does nothing where flock(2) is not available
+func flock(file *os.File, exclusive bool) error {
+	return nil
+}
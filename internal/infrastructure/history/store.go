@@ -0,0 +1,357 @@
+package history
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// FileName is the file the history is persisted to inside the snapshot directory
+const FileName = "history.gob"
+
+// version is a registry version the history has seen. Events refer to it by
+// index so the version string is stored once.
+type version struct {
+	Version string
+	At      time.Time
+}
+
+// event is a pattern entering or leaving the registry
+type event struct {
+	Version int32
+	Removed bool
+	Type    domain.BlockingType
+}
+
+// snapshot is the persisted form of the history
+type snapshot struct {
+	Versions []version
+	Patterns map[string][]event
+}
+
+// Store records when each registry pattern was added and removed across
+// registry versions. Patterns are compared with the last recorded version
+// rather than the previously applied registry, so nothing is lost across
+// restarts.
+type Store struct {
+	mu       sync.RWMutex
+	path     string
+	versions []version
+	patterns map[string][]event
+}
+
+// NewStore creates a history store. When snapshotDir is not empty the history
+// is persisted below it and the one already there is loaded.
+func NewStore(snapshotDir string) (*Store, error) {
+	s := &Store{patterns: make(map[string][]event)}
+	if snapshotDir == "" {
+		return s, nil
+	}
+
+	if err := os.MkdirAll(snapshotDir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating snapshot directory: %w", err)
+	}
+	s.path = filepath.Join(snapshotDir, FileName)
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// OnRegistryUpdate records the events of a newly applied registry
+func (s *Store) OnRegistryUpdate(previous, current *domain.Registry, report *domain.IngestReport) {
+	added, removed := s.Record(current, time.Now())
+	if added == 0 && removed == 0 {
+		return
+	}
+
+	if err := s.Save(); err != nil {
+		slog.Error("Failed to persist registry history", "version", current.Version, "error", err)
+	}
+
+	slog.Info("Registry history recorded",
+		"version", current.Version,
+		"added", added,
+		"removed", removed)
+}
+
+// Record adds an event for every pattern that appeared in or disappeared from
+// the registry since the last recorded version, and returns how many of each
+// there were. A registry whose version was already recorded last is ignored.
+func (s *Store) Record(registry *domain.Registry, at time.Time) (added, removed int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.record(registry, at)
+}
+
+// record appends registry as the latest version; s.mu must be held
+func (s *Store) record(registry *domain.Registry, at time.Time) (added, removed int) {
+	if n := len(s.versions); n > 0 && s.versions[n-1].Version == registry.Version {
+		return 0, 0
+	}
+
+	index := int32(len(s.versions))
+	s.versions = append(s.versions, version{Version: registry.Version, At: at.UTC()})
+
+	seen := make(map[string]struct{}, len(registry.Entries))
+	for _, entry := range registry.Entries {
+		value := entry.Value()
+		seen[value] = struct{}{}
+
+		events := s.patterns[value]
+		if len(events) > 0 && !events[len(events)-1].Removed {
+			continue
+		}
+		s.patterns[value] = append(events, event{Version: index, Type: entry.Type})
+		added++
+	}
+
+	for pattern, events := range s.patterns {
+		last := events[len(events)-1]
+		if last.Removed {
+			continue
+		}
+		if _, ok := seen[pattern]; ok {
+			continue
+		}
+		s.patterns[pattern] = append(events, event{Version: index, Removed: true, Type: last.Type})
+		removed++
+	}
+
+	return added, removed
+}
+
+// Insert records registry as the version of at, in chronological order
+// among the recorded versions, and returns how many patterns were added and
+// removed by it. The events of the versions recorded after at are adjusted
+// to be relative to the inserted one. A registry whose version was already
+// recorded is ignored.
+func (s *Store) Insert(registry *domain.Registry, at time.Time) (added, removed int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, v := range s.versions {
+		if v.Version == registry.Version {
+			return 0, 0
+		}
+	}
+	position := sort.Search(len(s.versions), func(i int) bool {
+		return s.versions[i].At.After(at)
+	})
+	if position == len(s.versions) {
+		return s.record(registry, at)
+	}
+
+	types := make(map[string]domain.BlockingType, len(registry.Entries))
+	for _, entry := range registry.Entries {
+		types[entry.Value()] = entry.Type
+	}
+
+	index := int32(position)
+	for pattern, events := range s.patterns {
+		entryType, present := types[pattern]
+		var a, r int
+		s.patterns[pattern], a, r = insertEvents(events, index, present, entryType)
+		added += a
+		removed += r
+		delete(types, pattern)
+	}
+	// Patterns never seen before were not in the version that follows
+	for pattern, entryType := range types {
+		s.patterns[pattern] = []event{
+			{Version: index, Type: entryType},
+			{Version: index + 1, Removed: true, Type: entryType},
+		}
+		added++
+	}
+
+	s.versions = slices.Insert(s.versions, position, version{Version: registry.Version, At: at.UTC()})
+	return added, removed
+}
+
+// insertEvents returns the events of a pattern with a version inserted at
+// index, in which the pattern is present or not, and how many times it was
+// added and removed by that version. A version must already follow index:
+// events of later versions are shifted and the transition into the first of
+// them is recomputed.
+func insertEvents(events []event, index int32, present bool, entryType domain.BlockingType) ([]event, int, int) {
+	before, beforeType := stateAt(events, index-1)
+	after, afterType := stateAt(events, index)
+
+	updated := make([]event, 0, len(events)+2)
+	var added, removed int
+	for _, e := range events {
+		if e.Version < index {
+			updated = append(updated, e)
+		}
+	}
+
+	if present != before {
+		if present {
+			updated = append(updated, event{Version: index, Type: entryType})
+			added++
+		} else {
+			updated = append(updated, event{Version: index, Removed: true, Type: beforeType})
+			removed++
+		}
+	}
+
+	if after != present {
+		if after {
+			updated = append(updated, event{Version: index + 1, Type: afterType})
+		} else {
+			updated = append(updated, event{Version: index + 1, Removed: true, Type: entryType})
+		}
+	}
+
+	for _, e := range events {
+		if e.Version > index {
+			e.Version++
+			updated = append(updated, e)
+		}
+	}
+
+	return updated, added, removed
+}
+
+// stateAt reports whether a pattern was in the registry at version index,
+// according to its events, and its type when it last was
+func stateAt(events []event, index int32) (bool, domain.BlockingType) {
+	present, entryType := false, domain.BlockingType(0)
+	for _, e := range events {
+		if e.Version > index {
+			break
+		}
+		present, entryType = !e.Removed, e.Type
+	}
+	return present, entryType
+}
+
+// History returns the timelines of the patterns that cover target: the
+// pattern itself and any wildcard on it or its parent domains. Patterns that
+// never appeared in the registry are left out.
+func (s *Store) History(target string) ([]*domain.PatternHistory, error) {
+	patterns := domain.CoveringPatterns(target)
+	if len(patterns) == 0 {
+		return nil, domain.ErrInvalidDomain
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	histories := make([]*domain.PatternHistory, 0)
+	for _, pattern := range patterns {
+		events, ok := s.patterns[pattern]
+		if !ok {
+			continue
+		}
+
+		history := &domain.PatternHistory{
+			Pattern: pattern,
+			Events:  make([]domain.HistoryEvent, 0, len(events)),
+		}
+		for _, e := range events {
+			kind := domain.ChangeKindAdded
+			if e.Removed {
+				kind = domain.ChangeKindRemoved
+			}
+			v := s.versions[e.Version]
+			history.Events = append(history.Events, domain.HistoryEvent{
+				Kind:    kind,
+				Type:    e.Type,
+				Version: v.Version,
+				At:      v.At,
+			})
+		}
+		histories = append(histories, history)
+	}
+
+	return histories, nil
+}
+
+// Recorded reports whether the registry version was recorded
+func (s *Store) Recorded(registryVersion string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, v := range s.versions {
+		if v.Version == registryVersion {
+			return true
+		}
+	}
+	return false
+}
+
+// LastVersion returns the most recently recorded registry version
+func (s *Store) LastVersion() (string, time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.versions) == 0 {
+		return "", time.Time{}
+	}
+	last := s.versions[len(s.versions)-1]
+	return last.Version, last.At
+}
+
+// Save writes the history atomically through a temporary file. It does
+// nothing when no snapshot directory is configured.
+func (s *Store) Save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), "history-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating history file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	s.mu.RLock()
+	err = gob.NewEncoder(tmp).Encode(snapshot{Versions: s.versions, Patterns: s.patterns})
+	s.mu.RUnlock()
+	if err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing history: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// load reads the persisted history, if there is one
+func (s *Store) load() error {
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("opening history: %w", err)
+	}
+	defer file.Close()
+
+	var snap snapshot
+	if err := gob.NewDecoder(file).Decode(&snap); err != nil {
+		return fmt.Errorf("reading history: %w", err)
+	}
+
+	s.versions = snap.Versions
+	if snap.Patterns != nil {
+		s.patterns = snap.Patterns
+	}
+
+	return nil
+}
@@ -0,0 +1,217 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// createRegistry creates a versioned registry of the given entries
+func createRegistry(version string, entries ...*domain.RegistryEntry) *domain.Registry {
+	registry := domain.NewRegistry()
+	registry.Version = version
+	for _, entry := range entries {
+		registry.AddEntry(entry)
+	}
+	return registry
+}
+
+func createEntry(entryType domain.BlockingType, value string) *domain.RegistryEntry {
+	entry, _ := domain.NewRegistryEntry(entryType, value)
+	return entry
+}
+
+func TestStore_History(t *testing.T) {
+	store, err := NewStore("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.Record(createRegistry("v1",
+		createEntry(domain.BlockingTypeDomain, "blocked.com"),
+		createEntry(domain.BlockingTypeWildcard, "*.example.com"),
+	), start)
+	store.Record(createRegistry("v2",
+		createEntry(domain.BlockingTypeWildcard, "*.example.com"),
+	), start.Add(time.Hour))
+	store.Record(createRegistry("v3",
+		createEntry(domain.BlockingTypeDomain, "blocked.com"),
+		createEntry(domain.BlockingTypeWildcard, "*.example.com"),
+		createEntry(domain.BlockingTypeDomain, "sub.example.com"),
+	), start.Add(2*time.Hour))
+
+	histories, err := store.History("blocked.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(histories) != 1 {
+		t.Fatalf("expected 1 history, got %d", len(histories))
+	}
+
+	want := []struct {
+		kind    domain.ChangeKind
+		version string
+	}{
+		{domain.ChangeKindAdded, "v1"},
+		{domain.ChangeKindRemoved, "v2"},
+		{domain.ChangeKindAdded, "v3"},
+	}
+	events := histories[0].Events
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %+v", len(want), events)
+	}
+	for i, w := range want {
+		if events[i].Kind != w.kind || events[i].Version != w.version {
+			t.Errorf("event %d: expected %s in %s, got %s in %s", i, w.kind, w.version, events[i].Kind, events[i].Version)
+		}
+	}
+	if !events[1].At.Equal(start.Add(time.Hour)) {
+		t.Errorf("unexpected event time: %v", events[1].At)
+	}
+	if !histories[0].Blocked() {
+		t.Error("expected pattern to be blocked")
+	}
+
+	histories, _ = store.History("sub.example.com")
+	if len(histories) != 2 || histories[0].Pattern != "sub.example.com" || histories[1].Pattern != "*.example.com" {
+		t.Errorf("expected exact and wildcard histories, got %+v", histories)
+	}
+
+	histories, _ = store.History("unknown.org")
+	if len(histories) != 0 {
+		t.Errorf("expected no history, got %+v", histories)
+	}
+
+	if _, err := store.History(" "); !errors.Is(err, domain.ErrInvalidDomain) {
+		t.Errorf("expected ErrInvalidDomain, got %v", err)
+	}
+}
+
+func TestStore_Insert(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	registries := []*domain.Registry{
+		createRegistry("v1",
+			createEntry(domain.BlockingTypeDomain, "a.com"),
+			createEntry(domain.BlockingTypeDomain, "b.com")),
+		createRegistry("v2",
+			createEntry(domain.BlockingTypeDomain, "b.com"),
+			createEntry(domain.BlockingTypeWildcard, "*.c.com")),
+		createRegistry("v3",
+			createEntry(domain.BlockingTypeDomain, "a.com"),
+			createEntry(domain.BlockingTypeWildcard, "*.c.com"),
+			createEntry(domain.BlockingTypeDomain, "d.com")),
+		createRegistry("v4",
+			createEntry(domain.BlockingTypeDomain, "a.com")),
+	}
+
+	inOrder, _ := NewStore("")
+	for i, registry := range registries {
+		inOrder.Record(registry, start.Add(time.Duration(i)*time.Hour))
+	}
+
+	// Later versions first, then the older ones merged in between them
+	merged, _ := NewStore("")
+	for _, i := range []int{1, 3, 0, 2} {
+		merged.Insert(registries[i], start.Add(time.Duration(i)*time.Hour))
+	}
+
+	if added, removed := merged.Insert(registries[2], start.Add(2*time.Hour)); added != 0 || removed != 0 {
+		t.Errorf("expected a recorded version to be ignored, got %d added and %d removed", added, removed)
+	}
+
+	for _, target := range []string{"a.com", "b.com", "x.c.com", "d.com"} {
+		want, _ := inOrder.History(target)
+		got, _ := merged.History(target)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %+v, got %+v", target, want, got)
+		}
+	}
+
+	if version, _ := merged.LastVersion(); version != "v4" {
+		t.Errorf("expected v4 to stay the last version, got %s", version)
+	}
+}
+
+func TestLock(t *testing.T) {
+	dir := t.TempDir()
+
+	releaseService, err := Lock(dir, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := Lock(dir, true); !errors.Is(err, ErrLocked) {
+		t.Errorf("expected an exclusive lock to be refused, got %v", err)
+	}
+	releaseOther, err := Lock(dir, false)
+	if err != nil {
+		t.Errorf("expected shared locks to coexist, got %v", err)
+	} else {
+		releaseOther()
+	}
+
+	releaseService()
+	releaseBackfill, err := Lock(dir, true)
+	if err != nil {
+		t.Fatalf("expected the lock to be free once released, got %v", err)
+	}
+	releaseBackfill()
+}
+
+func TestStore_RecordSameVersion(t *testing.T) {
+	store, _ := NewStore("")
+
+	registry := createRegistry("v1", createEntry(domain.BlockingTypeDomain, "a.com"))
+	if added, _ := store.Record(registry, time.Now()); added != 1 {
+		t.Fatalf("expected 1 added pattern, got %d", added)
+	}
+	if added, removed := store.Record(registry, time.Now()); added != 0 || removed != 0 {
+		t.Errorf("expected repeated version to be ignored, got added=%d removed=%d", added, removed)
+	}
+}
+
+func TestStore_Persistence(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	store.OnRegistryUpdate(nil, createRegistry("v1", createEntry(domain.BlockingTypeIP, "10.0.0.1")), nil)
+	store.OnRegistryUpdate(nil, createRegistry("v2"), nil)
+
+	if _, err := os.Stat(filepath.Join(dir, FileName)); err != nil {
+		t.Fatalf("expected history file: %v", err)
+	}
+
+	reloaded, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if version, _ := reloaded.LastVersion(); version != "v2" {
+		t.Errorf("expected last version v2, got %q", version)
+	}
+
+	histories, _ := reloaded.History("10.0.0.1")
+	if len(histories) != 1 || len(histories[0].Events) != 2 || histories[0].Blocked() {
+		t.Errorf("unexpected reloaded history: %+v", histories)
+	}
+	if histories[0].Events[1].Type != domain.BlockingTypeIP {
+		t.Errorf("expected removal to keep the entry type, got %s", histories[0].Events[1].Type)
+	}
+}
+
+func TestNewStore_CorruptFile(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, FileName), []byte("not gob"), 0o600)
+
+	if _, err := NewStore(dir); err == nil {
+		t.Error("expected error for corrupt history file")
+	}
+}
@@ -0,0 +1,221 @@
+package iplookup
+
+import (
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// record groups the entries of one registry record that lists an IP or a
+// subnet
+type record struct {
+	id      string
+	entries []int32
+}
+
+// subnet is a subnet entry and the record position it belongs to
+type subnet struct {
+	entry  int32
+	record int32
+}
+
+// reference is an IP entry and the record position it belongs to
+type reference struct {
+	entry  int32
+	record int32
+}
+
+// table is the reverse index of one registry version
+type table struct {
+	version string
+	entries []*domain.RegistryEntry
+	records []record
+	ips     map[netip.Addr][]reference
+	subnets []subnet
+	// trie finds the positions in subnets of the subnets containing an
+	// address
+	trie subnetTrie
+}
+
+// Index maps IP addresses back to the registry records that list them,
+// either directly, alongside domains and URLs, or through a covering subnet.
+// Only records containing an IP or a subnet are indexed.
+type Index struct {
+	table atomic.Pointer[table]
+}
+
+// NewIndex creates an empty IP index
+func NewIndex() *Index {
+	idx := &Index{}
+	idx.table.Store(&table{ips: make(map[netip.Addr][]reference)})
+	return idx
+}
+
+// OnRegistryUpdate rebuilds the index for a newly applied registry
+func (idx *Index) OnRegistryUpdate(previous, current *domain.Registry, report *domain.IngestReport) {
+	start := time.Now()
+	idx.Build(current)
+	slog.Info("IP index built", "version", current.Version, "duration", time.Since(start))
+}
+
+// OnRegistryCleared empties the index
+func (idx *Index) OnRegistryCleared() {
+	idx.table.Store(&table{ips: make(map[netip.Addr][]reference)})
+}
+
+// Build replaces the indexed registry
+func (idx *Index) Build(registry *domain.Registry) {
+	t := &table{
+		version: registry.Version,
+		entries: registry.Entries,
+		ips:     make(map[netip.Addr][]reference),
+	}
+
+	positions := make(map[string]int32)
+	position := func(id string) int32 {
+		if pos, ok := positions[id]; ok {
+			return pos
+		}
+		pos := int32(len(t.records))
+		positions[id] = pos
+		t.records = append(t.records, record{id: id})
+		return pos
+	}
+
+	// Index every IP and subnet under the record that listed it, including
+	// later records that repeated an already listed value
+	add := func(index int32, recordID string) {
+		entry := registry.Entries[index]
+		switch entry.Type {
+		case domain.BlockingTypeIP:
+			if addr, err := netip.ParseAddr(entry.IP); err == nil {
+				addr = addr.Unmap()
+				t.ips[addr] = append(t.ips[addr], reference{entry: index, record: position(recordID)})
+			}
+		case domain.BlockingTypeSubnet:
+			if prefix, err := netip.ParsePrefix(entry.IP); err == nil {
+				t.trie.insert(prefix.Masked(), int32(len(t.subnets)))
+				t.subnets = append(t.subnets, subnet{entry: index, record: position(recordID)})
+			}
+		}
+	}
+	for i, entry := range registry.Entries {
+		add(int32(i), recordKey(entry, i))
+	}
+	for _, ref := range registry.References {
+		if int(ref.Entry) < len(registry.Entries) {
+			add(ref.Entry, ref.RecordID)
+		}
+	}
+
+	// Collect the other entries of the indexed records
+	for i, entry := range registry.Entries {
+		if pos, ok := positions[recordKey(entry, i)]; ok {
+			t.records[pos].entries = append(t.records[pos].entries, int32(i))
+		}
+	}
+	for _, ref := range registry.References {
+		if pos, ok := positions[ref.RecordID]; ok && int(ref.Entry) < len(registry.Entries) {
+			t.records[pos].entries = append(t.records[pos].entries, ref.Entry)
+		}
+	}
+
+	idx.table.Store(t)
+}
+
+// LookupIP returns every record referencing the IP address or a subnet
+// covering it. Records listing the IP itself come before subnet matches.
+func (idx *Index) LookupIP(addr string) (*domain.IPLookupResult, error) {
+	ip, err := netip.ParseAddr(strings.TrimSpace(addr))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidIP, addr)
+	}
+	ip = ip.Unmap()
+
+	t := idx.table.Load()
+	result := &domain.IPLookupResult{
+		IP:      ip.String(),
+		Version: t.version,
+		Records: make([]*domain.IPRecord, 0),
+	}
+
+	seen := make(map[int32]bool)
+	for _, ref := range t.ips[ip] {
+		if seen[ref.record] {
+			continue
+		}
+		seen[ref.record] = true
+		result.Records = append(result.Records, t.describe(ref.record, ref.entry, false))
+	}
+	for _, pos := range t.trie.match(ip) {
+		s := t.subnets[pos]
+		if seen[s.record] {
+			continue
+		}
+		seen[s.record] = true
+		result.Records = append(result.Records, t.describe(s.record, s.entry, true))
+	}
+
+	return result, nil
+}
+
+// describe builds the view of an indexed record matched through one of its
+// entries
+func (t *table) describe(pos, matched int32, viaSubnet bool) *domain.IPRecord {
+	rec := t.records[pos]
+	out := &domain.IPRecord{
+		RecordID: rec.id,
+		Pattern:  t.entries[matched].Value(),
+	}
+
+	members := append([]int32(nil), rec.entries...)
+	sort.Slice(members, func(i, j int) bool { return members[i] < members[j] })
+
+	for _, index := range members {
+		entry := t.entries[index]
+		switch entry.Type {
+		case domain.BlockingTypeIP, domain.BlockingTypeSubnet:
+			out.IPs = append(out.IPs, entry.Value())
+		case domain.BlockingTypeURLPath:
+			out.URLs = append(out.URLs, entry.Value())
+		default:
+			out.Domains = append(out.Domains, entry.Value())
+		}
+
+		// Decision details are kept on the entries the record listed first
+		if out.Decision == "" && recordKey(entry, int(index)) == rec.id {
+			out.Decision = entry.Decision
+			out.DecisionOrg = entry.DecisionOrg
+			out.BlockedDate = entry.BlockedDate
+		}
+	}
+
+	switch {
+	case viaSubnet:
+		out.Reason = domain.IPMatchSubnet
+	case len(out.Domains) > 0 || len(out.URLs) > 0:
+		out.Reason = domain.IPMatchDomain
+	default:
+		out.Reason = domain.IPMatchDirect
+	}
+
+	return out
+}
+
+// recordKey returns the record an entry belongs to. Entries without a record
+// stand on their own.
+func recordKey(entry *domain.RegistryEntry, index int) string {
+	if entry.RecordID != "" {
+		return entry.RecordID
+	}
+	if entry.ID != "" {
+		return entry.ID
+	}
+	return fmt.Sprintf("#%d", index)
+}
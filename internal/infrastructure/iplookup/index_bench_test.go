@@ -0,0 +1,50 @@
+package iplookup
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+func BenchmarkIndex_LookupIP_SubnetMatch(b *testing.B) {
+	idx := NewIndex()
+	idx.Build(createBenchmarkRegistry(100000))
+
+	testIP := "10.1.134.7"
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		idx.LookupIP(testIP)
+	}
+}
+
+func BenchmarkIndex_LookupIP_NoMatch(b *testing.B) {
+	idx := NewIndex()
+	idx.Build(createBenchmarkRegistry(100000))
+
+	testIP := "203.0.113.7"
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		idx.LookupIP(testIP)
+	}
+}
+
+// createBenchmarkRegistry creates a registry of size records, alternating
+// between a /24 subnet and an IP listed with a domain
+func createBenchmarkRegistry(size int) *domain.Registry {
+	registry := domain.NewRegistry()
+
+	for i := 0; i < size; i++ {
+		recordID := fmt.Sprintf("%d", i)
+		if i%2 == 0 {
+			addEntry(registry, recordID, domain.BlockingTypeSubnet, fmt.Sprintf("10.%d.%d.0/24", (i/256)%256, i%256))
+			continue
+		}
+		addEntry(registry, recordID, domain.BlockingTypeIP, fmt.Sprintf("172.%d.%d.%d", (i/65536)%256, (i/256)%256, i%256))
+		addEntry(registry, recordID, domain.BlockingTypeDomain, fmt.Sprintf("blocked%d.com", i))
+	}
+
+	return registry
+}
@@ -0,0 +1,114 @@
+package iplookup
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// addEntry adds an entry listed by a record to the registry
+func addEntry(registry *domain.Registry, recordID string, entryType domain.BlockingType, value string) {
+	entry, _ := domain.NewRegistryEntry(entryType, value)
+	entry.RecordID = recordID
+	entry.Decision = "decision-" + recordID
+	registry.AddEntry(entry)
+}
+
+func createRegistry() *domain.Registry {
+	registry := domain.NewRegistry()
+	registry.Version = "v1"
+
+	// Record 1 carries an IP for a domain and a URL
+	addEntry(registry, "1", domain.BlockingTypeIP, "1.1.1.1")
+	addEntry(registry, "1", domain.BlockingTypeDomain, "example.com")
+	addEntry(registry, "1", domain.BlockingTypeURLPath, "example.com/page")
+	// Record 2 lists IPs only
+	addEntry(registry, "2", domain.BlockingTypeIP, "2.2.2.2")
+	// Record 3 lists a subnet
+	addEntry(registry, "3", domain.BlockingTypeSubnet, "1.1.0.0/16")
+	// Record 4 repeats 1.1.1.1 together with its own domain
+	addEntry(registry, "4", domain.BlockingTypeDomain, "other.com")
+	registry.AddReference(0, "4")
+
+	return registry
+}
+
+func TestIndex_LookupIP(t *testing.T) {
+	idx := NewIndex()
+	idx.Build(createRegistry())
+
+	type expectation struct {
+		record  string
+		reason  domain.IPMatchReason
+		pattern string
+		domains int
+		urls    int
+	}
+
+	tests := []struct {
+		name     string
+		addr     string
+		expected []expectation
+	}{
+		{
+			name: "IP carried by domain records and covered by a subnet",
+			addr: "1.1.1.1",
+			expected: []expectation{
+				{record: "1", reason: domain.IPMatchDomain, pattern: "1.1.1.1", domains: 1, urls: 1},
+				{record: "4", reason: domain.IPMatchDomain, pattern: "1.1.1.1", domains: 1},
+				{record: "3", reason: domain.IPMatchSubnet, pattern: "1.1.0.0/16"},
+			},
+		},
+		{
+			name:     "IP listed directly",
+			addr:     "2.2.2.2",
+			expected: []expectation{{record: "2", reason: domain.IPMatchDirect, pattern: "2.2.2.2"}},
+		},
+		{
+			name:     "IPv4-mapped IPv6 address",
+			addr:     "::ffff:1.1.9.9",
+			expected: []expectation{{record: "3", reason: domain.IPMatchSubnet, pattern: "1.1.0.0/16"}},
+		},
+		{
+			name: "unlisted IP",
+			addr: "9.9.9.9",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := idx.LookupIP(tt.addr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Version != "v1" {
+				t.Errorf("expected version v1, got %s", result.Version)
+			}
+			if len(result.Records) != len(tt.expected) {
+				t.Fatalf("expected %d records, got %d", len(tt.expected), len(result.Records))
+			}
+
+			for i, want := range tt.expected {
+				got := result.Records[i]
+				if got.RecordID != want.record || got.Reason != want.reason || got.Pattern != want.pattern {
+					t.Errorf("record %d: expected %s/%s/%s, got %s/%s/%s", i, want.record, want.reason, want.pattern, got.RecordID, got.Reason, got.Pattern)
+				}
+				if len(got.Domains) != want.domains || len(got.URLs) != want.urls {
+					t.Errorf("record %d: expected %d domains and %d URLs, got %v and %v", i, want.domains, want.urls, got.Domains, got.URLs)
+				}
+				if got.Decision != "decision-"+want.record {
+					t.Errorf("record %d: expected the decision of record %s, got %q", i, want.record, got.Decision)
+				}
+			}
+		})
+	}
+}
+
+func TestIndex_LookupIP_Invalid(t *testing.T) {
+	idx := NewIndex()
+
+	if _, err := idx.LookupIP("example.com"); !errors.Is(err, domain.ErrInvalidIP) {
+		t.Errorf("expected ErrInvalidIP, got %v", err)
+	}
+}
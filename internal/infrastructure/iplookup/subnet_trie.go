@@ -0,0 +1,71 @@
+package iplookup
+
+import (
+	"net/netip"
+	"slices"
+)
+
+// subnetTrie is a binary trie over address bits holding the positions of
+// indexed subnets, so the subnets containing an address are found in at most
+// as many steps as it has bits, however many subnets there are. IPv4 and
+// IPv6 subnets are kept apart.
+type subnetTrie struct {
+	v4 *subnetNode
+	v6 *subnetNode
+}
+
+type subnetNode struct {
+	children  [2]*subnetNode
+	positions []int32
+}
+
+// insert adds the subnet at position to the trie
+func (t *subnetTrie) insert(prefix netip.Prefix, position int32) {
+	addr := prefix.Addr()
+
+	root := &t.v6
+	if addr.Is4() {
+		root = &t.v4
+	}
+	if *root == nil {
+		*root = &subnetNode{}
+	}
+
+	node := *root
+	bytes := addr.AsSlice()
+	for i := range prefix.Bits() {
+		bit := bitAt(bytes, i)
+		if node.children[bit] == nil {
+			node.children[bit] = &subnetNode{}
+		}
+		node = node.children[bit]
+	}
+	node.positions = append(node.positions, position)
+}
+
+// match returns the positions of every subnet containing addr, in the order
+// they were inserted
+func (t *subnetTrie) match(addr netip.Addr) []int32 {
+	node := t.v6
+	if addr.Is4() {
+		node = t.v4
+	}
+
+	var positions []int32
+	bytes := addr.AsSlice()
+	for i := 0; node != nil; i++ {
+		positions = append(positions, node.positions...)
+		if i == len(bytes)*8 {
+			break
+		}
+		node = node.children[bitAt(bytes, i)]
+	}
+
+	slices.Sort(positions)
+	return positions
+}
+
+// bitAt returns bit i of an address, counting from the most significant
+func bitAt(bytes []byte, i int) int {
+	return int(bytes[i/8]>>(7-i%8)) & 1
+}
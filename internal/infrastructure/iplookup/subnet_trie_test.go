@@ -0,0 +1,34 @@
+package iplookup
+
+import (
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+func TestSubnetTrie_Match(t *testing.T) {
+	var trie subnetTrie
+	for i, subnet := range []string{"10.20.0.0/16", "10.0.0.0/8", "192.168.1.0/24", "2001:db8::/32", "0.0.0.0/0", "10.20.0.0/16"} {
+		trie.insert(netip.MustParsePrefix(subnet), int32(i))
+	}
+
+	tests := []struct {
+		addr string
+		want []int32
+	}{
+		{"10.20.3.4", []int32{0, 1, 4, 5}},
+		{"10.21.0.1", []int32{1, 4}},
+		{"192.168.1.255", []int32{2, 4}},
+		{"8.8.8.8", []int32{4}},
+		{"2001:db8::1", []int32{3}},
+		{"2001:db9::1", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := trie.match(netip.MustParseAddr(tt.addr)); !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
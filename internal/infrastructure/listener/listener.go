@@ -0,0 +1,48 @@
+// Package listener opens the TCP and Unix socket listeners of the servers.
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+)
+
+// Listen listens on the Unix socket at socket when set, and on host:port
+// otherwise. A socket left behind by a previous run is replaced.
+func Listen(host string, port int, socket string) (net.Listener, error) {
+	if socket == "" {
+		lis, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", Address(host, port, socket), err)
+		}
+		return lis, nil
+	}
+
+	if info, err := os.Lstat(socket); err == nil {
+		if info.Mode().Type() != fs.ModeSocket {
+			return nil, fmt.Errorf("failed to listen on %s: not a socket", socket)
+		}
+		if err := os.Remove(socket); err != nil {
+			return nil, fmt.Errorf("removing stale socket %s: %w", socket, err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to listen on %s: %w", socket, err)
+	}
+
+	lis, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", socket, err)
+	}
+	return lis, nil
+}
+
+// Address describes where Listen listens, for logs
+func Address(host string, port int, socket string) string {
+	if socket != "" {
+		return "unix:" + socket
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
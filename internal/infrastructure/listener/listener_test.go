@@ -0,0 +1,50 @@
+package listener
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListen_Socket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "rkn-checker.sock")
+
+	lis, err := Listen("", 0, socket)
+	if err != nil {
+		t.Fatalf("Listen() unexpected error: %v", err)
+	}
+	if lis.Addr().Network() != "unix" {
+		t.Errorf("expected a Unix socket listener, got %s", lis.Addr().Network())
+	}
+
+	// Left behind, as after a crash
+	lis.(*net.UnixListener).SetUnlinkOnClose(false)
+	lis.Close()
+
+	lis, err = Listen("", 0, socket)
+	if err != nil {
+		t.Fatalf("expected the stale socket to be replaced, got %v", err)
+	}
+	lis.Close()
+
+	file := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Listen("", 0, file); err == nil {
+		t.Error("expected an error for a path that is not a socket")
+	}
+}
+
+func TestListen_Host(t *testing.T) {
+	lis, err := Listen("127.0.0.1", 0, "")
+	if err != nil {
+		t.Fatalf("Listen() unexpected error: %v", err)
+	}
+	defer lis.Close()
+
+	if host, _, _ := net.SplitHostPort(lis.Addr().String()); host != "127.0.0.1" {
+		t.Errorf("expected to listen on 127.0.0.1, got %s", lis.Addr())
+	}
+}
@@ -0,0 +1,207 @@
+// Package metrics defines the Prometheus metrics of the service. Metric names
+// and labels are what alerts are built on; rename or relabel them only as a
+// breaking change.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+const namespace = "rkn_checker"
+
+// Result label values
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Registry holds every metric of the service along with the Go runtime and
+// process collectors
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests counts REST requests by route pattern, method and status
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "REST requests by route pattern, method and status code.",
+	}, []string{"route", "method", "code"})
+
+	// HTTPRequestDuration observes REST request latency by route pattern and
+	// method
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "REST request latency by route pattern and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	// GRPCRequests counts gRPC calls by full method and status code
+	GRPCRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_requests_total",
+		Help:      "gRPC calls by full method name and status code.",
+	}, []string{"method", "code"})
+
+	// GRPCRequestDuration observes gRPC call latency by full method
+	GRPCRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "gRPC call latency by full method name.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	// Checks counts URL checks by outcome and the blocking type that matched
+	Checks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "checks_total",
+		Help:      "URL checks by outcome (blocked, allowed) and matching blocking type (none when allowed).",
+	}, []string{"outcome", "type"})
+
+	// NormalizationFailures counts URLs that could not be normalized
+	NormalizationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "normalization_failures_total",
+		Help:      "URLs rejected during normalization by error.",
+	}, []string{"error"})
+
+	// BloomLookups counts registry store lookups by bloom filter result
+	BloomLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bloom_lookups_total",
+		Help:      "Store lookups by bloom filter result: pass (may be blocked, checked exactly) or reject.",
+	}, []string{"result"})
+
+	// BloomFalsePositives counts lookups that passed the bloom filter but
+	// matched no rule
+	BloomFalsePositives = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bloom_false_positives_total",
+		Help:      "Store lookups that passed the bloom filter but matched no rule.",
+	})
+
+	// UpdateAttempts counts registry update attempts, retries included
+	UpdateAttempts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "registry_update_attempts_total",
+		Help:      "Registry update attempts, retries included.",
+	})
+
+	// UpdateFailures counts registry updates that failed after every retry
+	UpdateFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "registry_update_failures_total",
+		Help:      "Registry updates that failed after every retry.",
+	})
+
+	// UpdateDuration observes registry update duration, retries included
+	UpdateDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "registry_update_duration_seconds",
+		Help:      "Registry update duration including retries, by result.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200},
+	}, []string{"result"})
+
+	// SourceFetchBytes counts the bytes downloaded from each registry source
+	SourceFetchBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "source_fetch_bytes_total",
+		Help:      "Bytes downloaded from each registry source.",
+	}, []string{"source"})
+
+	// SourceFetchDuration observes the fetch latency of each registry source
+	SourceFetchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "source_fetch_duration_seconds",
+		Help:      "Time to fetch, verify and parse a dump from each registry source, by result.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600},
+	}, []string{"source", "result"})
+
+	// RateLimited counts calls rejected by rate limiting, by route and reason
+	RateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_total",
+		Help:      "Calls rejected by rate limiting, by REST route pattern or gRPC method and reason (rate, quota).",
+	}, []string{"route", "reason"})
+
+	// ParserRejectedEntries counts registry rows rejected by the parser
+	ParserRejectedEntries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "parser_rejected_entries_total",
+		Help:      "Registry rows rejected by the parser, by dump format.",
+	}, []string{"format"})
+)
+
+// BloomPass and BloomReject are resolved once for the store lookup hot path
+var (
+	BloomPass   = BloomLookups.WithLabelValues("pass")
+	BloomReject = BloomLookups.WithLabelValues("reject")
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		GRPCRequests,
+		GRPCRequestDuration,
+		Checks,
+		NormalizationFailures,
+		BloomLookups,
+		BloomFalsePositives,
+		UpdateAttempts,
+		UpdateFailures,
+		UpdateDuration,
+		SourceFetchBytes,
+		SourceFetchDuration,
+		ParserRejectedEntries,
+		RateLimited,
+	)
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveCheck records the outcome of a URL check
+func ObserveCheck(result *domain.BlockingResult) {
+	if result.IsBlocked {
+		Checks.WithLabelValues("blocked", result.Reason.String()).Inc()
+		return
+	}
+	Checks.WithLabelValues("allowed", "none").Inc()
+}
+
+// normalizationErrors maps normalization errors to their label values
+var normalizationErrors = []struct {
+	err   error
+	label string
+}{
+	{domain.ErrEmptyURL, "empty_url"},
+	{domain.ErrUnsupportedProtocol, "unsupported_protocol"},
+	{domain.ErrInvalidDomain, "invalid_domain"},
+	{domain.ErrInvalidIP, "invalid_ip"},
+	{domain.ErrNormalizationFailed, "normalization_failed"},
+	{domain.ErrInvalidURL, "invalid_url"},
+}
+
+// ObserveNormalizationFailure records a URL rejected during normalization
+func ObserveNormalizationFailure(err error) {
+	label := "other"
+	for _, known := range normalizationErrors {
+		if errors.Is(err, known.err) {
+			label = known.label
+			break
+		}
+	}
+	NormalizationFailures.WithLabelValues(label).Inc()
+}
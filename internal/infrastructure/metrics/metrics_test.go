@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+func TestObserveNormalizationFailure(t *testing.T) {
+	tests := []struct {
+		err   error
+		label string
+	}{
+		{domain.ErrEmptyURL, "empty_url"},
+		{fmt.Errorf("parsing: %w", domain.ErrInvalidDomain), "invalid_domain"},
+		{domain.ErrUnsupportedProtocol, "unsupported_protocol"},
+		{errors.New("unexpected"), "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			counter := NormalizationFailures.WithLabelValues(tt.label)
+			before := testutil.ToFloat64(counter)
+
+			ObserveNormalizationFailure(tt.err)
+
+			if got := testutil.ToFloat64(counter) - before; got != 1 {
+				t.Errorf("expected %s to be counted once, got %v", tt.label, got)
+			}
+		})
+	}
+}
+
+func TestObserveCheck(t *testing.T) {
+	blocked := Checks.WithLabelValues("blocked", domain.BlockingTypeWildcard.String())
+	allowed := Checks.WithLabelValues("allowed", "none")
+	blockedBefore, allowedBefore := testutil.ToFloat64(blocked), testutil.ToFloat64(allowed)
+
+	ObserveCheck(domain.NewBlockingResult(true, "sub.example.com", &domain.BlockingRule{Type: domain.BlockingTypeWildcard}))
+	ObserveCheck(domain.NewBlockingResult(false, "example.org", nil))
+
+	if got := testutil.ToFloat64(blocked) - blockedBefore; got != 1 {
+		t.Errorf("expected one blocked wildcard check, got %v", got)
+	}
+	if got := testutil.ToFloat64(allowed) - allowedBefore; got != 1 {
+		t.Errorf("expected one allowed check, got %v", got)
+	}
+}
+
+func TestStoreCollector(t *testing.T) {
+	collector := newStoreCollector(func() StoreStats {
+		return StoreStats{
+			CountsByType: map[domain.BlockingType]int64{
+				domain.BlockingTypeDomain: 3,
+				domain.BlockingTypeIP:     2,
+			},
+			LastUpdate:             time.Now().Add(-time.Hour),
+			BloomFalsePositiveRate: 0.01,
+		}
+	})
+
+	expected := `
+# HELP rkn_checker_store_entries Registry entries in the store by blocking type.
+# TYPE rkn_checker_store_entries gauge
+rkn_checker_store_entries{type="domain"} 3
+rkn_checker_store_entries{type="ip"} 2
+rkn_checker_store_entries{type="sni"} 0
+rkn_checker_store_entries{type="subnet"} 0
+rkn_checker_store_entries{type="url_path"} 0
+rkn_checker_store_entries{type="wildcard"} 0
+# HELP rkn_checker_bloom_estimated_false_positive_rate Bloom filter false positive rate estimated from its size and item count.
+# TYPE rkn_checker_bloom_estimated_false_positive_rate gauge
+rkn_checker_bloom_estimated_false_positive_rate 0.01
+`
+	err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"rkn_checker_store_entries", "rkn_checker_bloom_estimated_false_positive_rate")
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count := testutil.CollectAndCount(collector, "rkn_checker_registry_age_seconds"); count != 1 {
+		t.Errorf("expected the registry age once a registry is loaded, got %d series", count)
+	}
+}
+
+func TestStoreCollector_EmptyStore(t *testing.T) {
+	collector := newStoreCollector(func() StoreStats { return StoreStats{} })
+
+	if count := testutil.CollectAndCount(collector, "rkn_checker_registry_age_seconds"); count != 0 {
+		t.Errorf("expected no registry age while the store is empty, got %d series", count)
+	}
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// StoreStats is the registry store state exported on every scrape
+type StoreStats struct {
+	CountsByType           map[domain.BlockingType]int64
+	LastUpdate             time.Time
+	BloomFalsePositiveRate float64
+}
+
+// storeCollector exports the registry store state read at scrape time
+type storeCollector struct {
+	stats func() StoreStats
+
+	entries           *prometheus.Desc
+	registryAge       *prometheus.Desc
+	bloomFalsePosRate *prometheus.Desc
+}
+
+// RegisterStore exports the registry store state returned by stats: entries
+// per blocking type, registry age and the estimated bloom filter false
+// positive rate
+func RegisterStore(stats func() StoreStats) {
+	Registry.MustRegister(newStoreCollector(stats))
+}
+
+func newStoreCollector(stats func() StoreStats) *storeCollector {
+	return &storeCollector{
+		stats: stats,
+		entries: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "store", "entries"),
+			"Registry entries in the store by blocking type.",
+			[]string{"type"}, nil),
+		registryAge: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "registry", "age_seconds"),
+			"Seconds since the registry in the store was loaded; absent while the store is empty.",
+			nil, nil),
+		bloomFalsePosRate: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "bloom", "estimated_false_positive_rate"),
+			"Bloom filter false positive rate estimated from its size and item count.",
+			nil, nil),
+	}
+}
+
+func (c *storeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.entries
+	ch <- c.registryAge
+	ch <- c.bloomFalsePosRate
+}
+
+func (c *storeCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.stats()
+
+	// Every type is exported so per-type alerts see zero rather than no data
+	var total int64
+	for blockingType := domain.BlockingTypeDomain; blockingType <= domain.BlockingTypeSubnet; blockingType++ {
+		count := stats.CountsByType[blockingType]
+		total += count
+		ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue,
+			float64(count), blockingType.String())
+	}
+
+	// The age is only known once a registry has been loaded
+	if total > 0 && !stats.LastUpdate.IsZero() {
+		ch <- prometheus.MustNewConstMetric(c.registryAge, prometheus.GaugeValue,
+			time.Since(stats.LastUpdate).Seconds())
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.bloomFalsePosRate, prometheus.GaugeValue,
+		stats.BloomFalsePositiveRate)
+}
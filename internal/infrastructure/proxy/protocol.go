@@ -0,0 +1,213 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// headerTimeout bounds the time a trusted proxy may take to send the PROXY
+// protocol header of a connection
+const headerTimeout = 10 * time.Second
+
+// maxV1Header is the longest PROXY protocol v1 header, CRLF included
+const maxV1Header = 107
+
+var (
+	v1Signature = []byte("PROXY ")
+	v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+)
+
+// Listener accepts connections whose client address may be given by a PROXY
+// protocol v1 or v2 header. Only connections from trusted proxies have their
+// header read, and the header is optional so that the proxies' own health
+// checks may connect directly.
+type Listener struct {
+	net.Listener
+	resolver *Resolver
+}
+
+// NewListener wraps inner to read PROXY protocol headers from the trusted
+// proxies of resolver
+func NewListener(inner net.Listener, resolver *Resolver) *Listener {
+	return &Listener{Listener: inner, resolver: resolver}
+}
+
+// Accept waits for the next connection. Its header is read on first use, in
+// the goroutine serving it, so a slow proxy does not hold up others.
+func (l *Listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	peer := ParseHost(c.RemoteAddr().String())
+	if !l.resolver.Trusted(peer) {
+		return c, nil
+	}
+	return &conn{Conn: c, reader: bufio.NewReader(c)}, nil
+}
+
+// conn is a connection from a trusted proxy, reporting the client address
+// of its PROXY protocol header as its remote address
+type conn struct {
+	net.Conn
+	reader *bufio.Reader
+
+	once   sync.Once
+	remote net.Addr
+	err    error
+}
+
+// readHeader reads the PROXY protocol header, if any, before the first use
+// of the connection
+func (c *conn) readHeader() {
+	c.once.Do(func() {
+		c.remote = c.Conn.RemoteAddr()
+
+		c.Conn.SetReadDeadline(time.Now().Add(headerTimeout))
+		addr, err := readHeader(c.reader)
+		c.Conn.SetReadDeadline(time.Time{})
+
+		if err != nil {
+			c.err = fmt.Errorf("PROXY protocol header from %s: %w", c.remote, err)
+			return
+		}
+		if addr != nil {
+			c.remote = addr
+		}
+	})
+}
+
+func (c *conn) Read(b []byte) (int, error) {
+	c.readHeader()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.reader.Read(b)
+}
+
+func (c *conn) RemoteAddr() net.Addr {
+	c.readHeader()
+	return c.remote
+}
+
+// SetDeadline reads the header first so that the deadline set by the server
+// outlives it
+func (c *conn) SetDeadline(t time.Time) error {
+	c.readHeader()
+	return c.Conn.SetDeadline(t)
+}
+
+// SetReadDeadline reads the header first so that the deadline set by the
+// server outlives it
+func (c *conn) SetReadDeadline(t time.Time) error {
+	c.readHeader()
+	return c.Conn.SetReadDeadline(t)
+}
+
+// readHeader reads a PROXY protocol header and returns the client address
+// it carries. It returns a nil address when the connection starts without a
+// header, or the header gives no client address (v1 UNKNOWN, v2 LOCAL or a
+// non-IP family).
+func readHeader(r *bufio.Reader) (net.Addr, error) {
+	// A short read leaves the data to the server, which sees the error
+	start, _ := r.Peek(len(v2Signature))
+	switch {
+	case bytes.HasPrefix(start, v1Signature):
+		return readV1(r)
+	case bytes.Equal(start, v2Signature):
+		return readV2(r)
+	default:
+		return nil, nil
+	}
+}
+
+// readV1 reads a text header, e.g. "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n"
+func readV1(r *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for !bytes.HasSuffix(line, []byte("\r\n")) {
+		if len(line) == maxV1Header {
+			return nil, errors.New("v1 header too long")
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("reading v1 header: %w", err)
+		}
+		line = append(line, b)
+	}
+
+	fields := strings.Fields(string(line))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("malformed v1 header %q", strings.TrimSpace(string(line)))
+	}
+
+	addr, err := netip.ParseAddr(fields[2])
+	if err != nil || addr.Is4() != (fields[1] == "TCP4") {
+		return nil, fmt.Errorf("invalid v1 source address %q", fields[2])
+	}
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid v1 source port %q", fields[4])
+	}
+	return net.TCPAddrFromAddrPort(netip.AddrPortFrom(addr, uint16(port))), nil
+}
+
+// readV2 reads a binary header: the signature, version and command,
+// address family, payload length and the payload holding the addresses
+// followed by TLVs, which are skipped
+func readV2(r *bufio.Reader) (net.Addr, error) {
+	var header [16]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, fmt.Errorf("reading v2 header: %w", err)
+	}
+	if version := header[12] >> 4; version != 2 {
+		return nil, fmt.Errorf("unsupported v2 header version %d", version)
+	}
+	command := header[12] & 0x0f
+	if command > 1 {
+		return nil, fmt.Errorf("unsupported v2 command %d", command)
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, fmt.Errorf("reading v2 addresses: %w", err)
+	}
+
+	// LOCAL connections are the proxy's own, e.g. health checks
+	if command == 0 {
+		return nil, nil
+	}
+
+	var addr netip.Addr
+	var port uint16
+	switch family := header[13] >> 4; family {
+	case 1:
+		if len(payload) < 12 {
+			return nil, errors.New("short v2 IPv4 addresses")
+		}
+		addr = netip.AddrFrom4([4]byte(payload[0:4]))
+		port = binary.BigEndian.Uint16(payload[8:10])
+	case 2:
+		if len(payload) < 36 {
+			return nil, errors.New("short v2 IPv6 addresses")
+		}
+		addr = netip.AddrFrom16([16]byte(payload[0:16])).Unmap()
+		port = binary.BigEndian.Uint16(payload[32:34])
+	default:
+		return nil, nil
+	}
+	return net.TCPAddrFromAddrPort(netip.AddrPortFrom(addr, port)), nil
+}
@@ -0,0 +1,94 @@
+package proxy
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"net/netip"
+	"testing"
+)
+
+func TestListener(t *testing.T) {
+	v2 := func(command, family byte, addresses []byte) string {
+		header := append([]byte{}, v2Signature...)
+		header = append(header, 0x20|command, family, 0, 0)
+		binary.BigEndian.PutUint16(header[14:], uint16(len(addresses)))
+		return string(append(header, addresses...))
+	}
+	ipv4 := []byte{198, 51, 100, 1, 10, 0, 0, 1, 0x11, 0x5c, 0x01, 0xbb}
+	ipv6 := make([]byte, 36)
+	copy(ipv6, netip.MustParseAddr("2001:db8::7").AsSlice())
+	binary.BigEndian.PutUint16(ipv6[32:], 4711)
+
+	tests := []struct {
+		name           string
+		trusted        string
+		header         string
+		expectedRemote string
+		expectedError  bool
+	}{
+		{"v1 TCP4", "127.0.0.1", "PROXY TCP4 198.51.100.1 10.0.0.1 4444 443\r\n", "198.51.100.1:4444", false},
+		{"v1 TCP6", "127.0.0.1", "PROXY TCP6 2001:db8::7 2001:db8::1 4711 443\r\n", "[2001:db8::7]:4711", false},
+		{"v1 UNKNOWN", "127.0.0.1", "PROXY UNKNOWN\r\n", "127.0.0.1", false},
+		{"v1 malformed", "127.0.0.1", "PROXY TCP4 198.51.100.1\r\n", "127.0.0.1", true},
+		{"v2 IPv4", "127.0.0.1", v2(1, 0x11, append(ipv4, 0x03, 0x00, 0x00)), "198.51.100.1:4444", false},
+		{"v2 IPv6", "127.0.0.1", v2(1, 0x21, ipv6), "[2001:db8::7]:4711", false},
+		{"v2 LOCAL", "127.0.0.1", v2(0, 0x00, nil), "127.0.0.1", false},
+		{"v2 short addresses", "127.0.0.1", v2(1, 0x11, ipv4[:8]), "127.0.0.1", true},
+		{"No header", "127.0.0.1", "", "127.0.0.1", false},
+		{"Untrusted peer", "10.0.0.0/8", "PROXY TCP4 198.51.100.1 10.0.0.1 4444 443\r\n", "127.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, err := NewResolver([]string{tt.trusted}, HeaderXForwardedFor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			inner, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			lis := NewListener(inner, resolver)
+			defer lis.Close()
+
+			go func() {
+				client, err := net.Dial("tcp", inner.Addr().String())
+				if err != nil {
+					return
+				}
+				defer client.Close()
+				io.WriteString(client, tt.header+"GET / HTTP/1.1\r\n\r\n")
+			}()
+
+			c, err := lis.Accept()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+
+			body, err := io.ReadAll(c)
+			if tt.expectedError {
+				if err == nil {
+					t.Errorf("expected an error for the header, read %q", body)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected read error: %v", err)
+			}
+
+			host := ParseHost(c.RemoteAddr().String())
+			if remote := c.RemoteAddr().String(); remote != tt.expectedRemote && host.String() != tt.expectedRemote {
+				t.Errorf("expected remote address %s, got %s", tt.expectedRemote, remote)
+			}
+			expectedBody := "GET / HTTP/1.1\r\n\r\n"
+			if tt.trusted != "127.0.0.1" {
+				expectedBody = tt.header + expectedBody
+			}
+			if string(body) != expectedBody {
+				t.Errorf("expected the data after the header, got %q", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+// Package proxy resolves the addresses of clients calling through trusted
+// reverse proxies, from forwarding headers and PROXY protocol headers.
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+const (
+	// HeaderXForwardedFor names the de facto X-Forwarded-For header
+	HeaderXForwardedFor = "X-Forwarded-For"
+
+	// HeaderForwarded names the RFC 7239 Forwarded header
+	HeaderForwarded = "Forwarded"
+)
+
+// Resolver resolves client addresses from the forwarding header appended by
+// trusted proxies. Hops are only believed while every hop to their right is
+// a trusted proxy, so clients cannot spoof their address by sending the
+// header themselves.
+type Resolver struct {
+	trusted []netip.Prefix
+	header  string
+}
+
+// NewResolver creates a resolver trusting the proxies in the given CIDRs or
+// addresses and reading header, HeaderXForwardedFor or HeaderForwarded
+func NewResolver(trusted []string, header string) (*Resolver, error) {
+	switch {
+	case strings.EqualFold(header, HeaderXForwardedFor):
+		header = HeaderXForwardedFor
+	case strings.EqualFold(header, HeaderForwarded):
+		header = HeaderForwarded
+	default:
+		return nil, fmt.Errorf("unsupported forwarding header %q", header)
+	}
+
+	r := &Resolver{header: header}
+	for _, spec := range trusted {
+		prefix, err := parsePrefix(strings.TrimSpace(spec))
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", spec, err)
+		}
+		r.trusted = append(r.trusted, prefix)
+	}
+	return r, nil
+}
+
+func parsePrefix(spec string) (netip.Prefix, error) {
+	if strings.Contains(spec, "/") {
+		prefix, err := netip.ParsePrefix(spec)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(spec)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// Header is the forwarding header the resolver reads
+func (r *Resolver) Header() string {
+	return r.header
+}
+
+// Trusted reports whether addr belongs to a trusted proxy
+func (r *Resolver) Trusted(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range r.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP resolves the client of a call received from peer, given the
+// values of the forwarding header. The header is walked right to left from
+// peer, stopping at the first hop that is not a trusted proxy. An unknown or
+// obfuscated hop stops the walk at the proxy that forwarded it.
+func (r *Resolver) ClientIP(peer netip.Addr, values []string) netip.Addr {
+	client := peer.Unmap()
+	if !r.Trusted(client) {
+		return client
+	}
+
+	var hops []netip.Addr
+	if r.header == HeaderForwarded {
+		hops = parseForwarded(values)
+	} else {
+		hops = parseXForwardedFor(values)
+	}
+
+	for i := len(hops) - 1; i >= 0 && r.Trusted(client); i-- {
+		if !hops[i].IsValid() {
+			break
+		}
+		client = hops[i]
+	}
+	return client
+}
+
+// parseXForwardedFor lists the hops of X-Forwarded-For values, the invalid
+// ones as the zero address
+func parseXForwardedFor(values []string) []netip.Addr {
+	var hops []netip.Addr
+	for _, value := range values {
+		for _, hop := range strings.Split(value, ",") {
+			hops = append(hops, ParseHost(strings.TrimSpace(hop)))
+		}
+	}
+	return hops
+}
+
+// parseForwarded lists the for= hops of RFC 7239 Forwarded values, the
+// missing, unknown and obfuscated ones as the zero address
+func parseForwarded(values []string) []netip.Addr {
+	var hops []netip.Addr
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			var hop netip.Addr
+			for _, pair := range strings.Split(element, ";") {
+				key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if ok && strings.EqualFold(key, "for") {
+					hop = ParseHost(strings.Trim(value, `"`))
+				}
+			}
+			hops = append(hops, hop)
+		}
+	}
+	return hops
+}
+
+// ParseHost parses an IP address optionally followed by a port, IPv6
+// addresses with a port being bracketed. It returns the zero address when
+// host is not an IP address.
+func ParseHost(host string) netip.Addr {
+	if addrPort, err := netip.ParseAddrPort(host); err == nil {
+		return addrPort.Addr().Unmap()
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	addr, err := netip.ParseAddr(strings.Trim(host, "[]"))
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Unmap()
+}
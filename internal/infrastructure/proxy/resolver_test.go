@@ -0,0 +1,61 @@
+package proxy
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestResolver_ClientIP(t *testing.T) {
+	xff, err := NewResolver([]string{"10.0.0.0/8", "2001:db8::1"}, "x-forwarded-for")
+	if err != nil {
+		t.Fatalf("NewResolver() unexpected error: %v", err)
+	}
+	forwarded, err := NewResolver([]string{"10.0.0.0/8"}, HeaderForwarded)
+	if err != nil {
+		t.Fatalf("NewResolver() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		resolver *Resolver
+		peer     string
+		values   []string
+		expected string
+	}{
+		{"Untrusted peer ignores the header", xff, "203.0.113.9", []string{"198.51.100.1"}, "203.0.113.9"},
+		{"Trusted peer without header", xff, "10.0.0.2", nil, "10.0.0.2"},
+		{"Single hop", xff, "10.0.0.2", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"Spoofed hops left of the client", xff, "10.0.0.2", []string{"1.1.1.1, 198.51.100.1, 10.0.0.3"}, "198.51.100.1"},
+		{"Hops across header lines", xff, "10.0.0.2", []string{"1.1.1.1", "198.51.100.1:4711"}, "198.51.100.1"},
+		{"Trusted IPv6 proxy", xff, "[2001:db8::1]:443", []string{"2001:db8::2"}, "2001:db8::2"},
+		{"IPv4-mapped peer", xff, "::ffff:10.0.0.2", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"Garbage stops at the proxy", xff, "10.0.0.2", []string{"198.51.100.1, not-an-ip, 10.0.0.3"}, "10.0.0.3"},
+		{"Every hop trusted", xff, "10.0.0.2", []string{"10.0.0.4, 10.0.0.3"}, "10.0.0.4"},
+		{"Forwarded", forwarded, "10.0.0.2", []string{`for=1.1.1.1, for=198.51.100.1;proto=https, for="10.0.0.3:80"`}, "198.51.100.1"},
+		{"Forwarded IPv6", forwarded, "10.0.0.2", []string{`For="[2001:db8::7]:4711"`}, "2001:db8::7"},
+		{"Forwarded unknown", forwarded, "10.0.0.2", []string{"for=198.51.100.1, for=unknown"}, "10.0.0.2"},
+		{"Forwarded obfuscated", forwarded, "10.0.0.2", []string{"for=_hidden"}, "10.0.0.2"},
+		{"Forwarded ignores X-Forwarded-For syntax", forwarded, "10.0.0.2", []string{"198.51.100.1"}, "10.0.0.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.resolver.ClientIP(ParseHost(tt.peer), tt.values)
+			if got != netip.MustParseAddr(tt.expected) {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNewResolver_Invalid(t *testing.T) {
+	if _, err := NewResolver([]string{"10.0.0.0/33"}, HeaderXForwardedFor); err == nil {
+		t.Error("expected an error for an invalid CIDR")
+	}
+	if _, err := NewResolver([]string{"proxy.internal"}, HeaderXForwardedFor); err == nil {
+		t.Error("expected an error for a host name")
+	}
+	if _, err := NewResolver(nil, "X-Real-IP"); err == nil {
+		t.Error("expected an error for an unsupported header")
+	}
+}
@@ -0,0 +1,357 @@
+// Package ratelimit limits the calls of each client to each route with token
+// buckets and daily quotas. Quota usage survives restarts when persisted.
+package ratelimit
+
+import (
+	"cmp"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/metrics"
+)
+
+const (
+	// FileName is the file quota usage is persisted to inside the snapshot
+	// directory
+	FileName = "quotas.gob"
+
+	// flushInterval is how often quota usage is written to disk and idle
+	// buckets are dropped
+	flushInterval = 30 * time.Second
+
+	// defaultRule names the buckets and quotas shared by the routes without
+	// a rule of their own
+	defaultRule = "default"
+
+	// maxTrackedClients caps the quota usage kept for the current day. Once
+	// it is reached the clients that used the least are forgotten first.
+	maxTrackedClients = 100_000
+
+	// ipv6PrefixBits is the prefix length IPv6 clients are counted by, the
+	// size of the network usually assigned to a single subscriber
+	ipv6PrefixBits = 64
+)
+
+// usageKey identifies the bucket and quota of a client under a rule
+type usageKey struct {
+	Client string
+	Rule   string
+}
+
+// bucket is a token bucket, refilled lazily when a call is admitted
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// snapshot is the persisted form of the quota usage
+type snapshot struct {
+	Day  string
+	Used map[usageKey]int64
+}
+
+// Limiter admits the calls of clients within the rate and daily quota of the
+// routes they call
+type Limiter struct {
+	mu      sync.Mutex
+	limits  Limits
+	buckets map[usageKey]*bucket
+
+	// day is the UTC day used counts the quota usage of
+	day   string
+	used  map[usageKey]int64
+	dirty bool
+
+	path string
+	now  func() time.Time
+}
+
+// NewLimiter creates a limiter enforcing limits. When snapshotDir is not
+// empty quota usage is persisted below it and the usage already there for
+// the current day is loaded.
+func NewLimiter(limits Limits, snapshotDir string) (*Limiter, error) {
+	if err := limits.Validate(); err != nil {
+		return nil, err
+	}
+
+	l := &Limiter{
+		limits:  limits,
+		buckets: make(map[usageKey]*bucket),
+		used:    make(map[usageKey]int64),
+		now:     time.Now,
+	}
+	l.day = dayOf(l.now())
+	if snapshotDir == "" {
+		return l, nil
+	}
+
+	if err := os.MkdirAll(snapshotDir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating snapshot directory: %w", err)
+	}
+	l.path = filepath.Join(snapshotDir, FileName)
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Reload replaces the limits. Buckets and quota usage carry over, so
+// reloading does not hand clients a fresh burst or quota.
+func (l *Limiter) Reload(limits Limits) error {
+	if err := limits.Validate(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.limits = limits
+	return nil
+}
+
+// Allow admits or rejects a call of client to route, counting admitted calls
+// against the client's bucket and quota
+func (l *Limiter) Allow(client, route string) domain.RateLimitDecision {
+	return l.decide(client, route, true)
+}
+
+// Peek reports whether Allow would admit a call of client to route without
+// counting it
+func (l *Limiter) Peek(client, route string) domain.RateLimitDecision {
+	return l.decide(client, route, false)
+}
+
+// decide admits or rejects a call of client to route, counting it when
+// charge is set
+func (l *Limiter) decide(client, route string, charge bool) domain.RateLimitDecision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	rule, name := l.rule(route)
+	if rule.Unlimited() {
+		return domain.RateLimitDecision{Allowed: true}
+	}
+
+	now := l.now()
+	key := usageKey{Client: clientKey(client), Rule: name}
+
+	if rule.DailyQuota > 0 {
+		l.rollDay(now)
+		if l.used[key] >= rule.DailyQuota {
+			metrics.RateLimited.WithLabelValues(route, "quota").Inc()
+			return domain.RateLimitDecision{RetryAfter: untilNextDay(now), QuotaExceeded: true}
+		}
+	}
+
+	if rule.Rate > 0 {
+		b := l.refill(key, rule, now)
+		if b.tokens < 1 {
+			metrics.RateLimited.WithLabelValues(route, "rate").Inc()
+			wait := time.Duration((1 - b.tokens) / rule.Rate * float64(time.Second))
+			return domain.RateLimitDecision{RetryAfter: wait}
+		}
+		if charge {
+			b.tokens--
+		}
+	}
+
+	if charge && rule.DailyQuota > 0 {
+		if _, ok := l.used[key]; !ok && len(l.used) >= maxTrackedClients {
+			l.trimUsage()
+		}
+		l.used[key]++
+		l.dirty = true
+	}
+	return domain.RateLimitDecision{Allowed: true}
+}
+
+// trimUsage forgets the tenth of the quota usage that used the least, so
+// rotating client addresses cannot grow it without bound; callers must hold
+// the lock
+func (l *Limiter) trimUsage() {
+	keys := make([]usageKey, 0, len(l.used))
+	for key := range l.used {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b usageKey) int {
+		return cmp.Compare(l.used[a], l.used[b])
+	})
+
+	for _, key := range keys[:len(keys)-maxTrackedClients*9/10] {
+		delete(l.used, key)
+	}
+}
+
+// clientKey counts IPv6 clients by their /64 network, which a single host
+// can rotate its addresses within
+func clientKey(client string) string {
+	ip, ok := strings.CutPrefix(client, "ip:")
+	if !ok {
+		return client
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil || !addr.Is6() || addr.Is4In6() {
+		return client
+	}
+	prefix, err := addr.Prefix(ipv6PrefixBits)
+	if err != nil {
+		return client
+	}
+	return "ip:" + prefix.String()
+}
+
+// rule returns the rule of route and the name its usage is kept under;
+// callers must hold the lock
+func (l *Limiter) rule(route string) (Rule, string) {
+	if rule, ok := l.limits.Routes[route]; ok {
+		return rule, route
+	}
+	return l.limits.Default, defaultRule
+}
+
+// refill returns the bucket of key topped up for the time elapsed since its
+// last call; callers must hold the lock
+func (l *Limiter) refill(key usageKey, rule Rule, now time.Time) *bucket {
+	burst := rule.burst()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[key] = b
+		return b
+	}
+
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * rule.Rate
+		b.last = now
+	}
+	b.tokens = math.Min(b.tokens, burst)
+	return b
+}
+
+// rollDay starts counting a new day's quota once the UTC day changed;
+// callers must hold the lock
+func (l *Limiter) rollDay(now time.Time) {
+	if day := dayOf(now); day != l.day {
+		l.day = day
+		l.used = make(map[usageKey]int64)
+		l.dirty = true
+	}
+}
+
+// Run persists quota usage and drops idle buckets every flushInterval until
+// ctx is done, then persists the usage a last time
+func (l *Limiter) Run(ctx context.Context) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := l.Flush(); err != nil {
+				slog.Error("Failed to persist quota usage", "error", err)
+			}
+			return
+		case <-ticker.C:
+			l.dropIdleBuckets()
+			if err := l.Flush(); err != nil {
+				slog.Error("Failed to persist quota usage", "error", err)
+			}
+		}
+	}
+}
+
+// dropIdleBuckets forgets the buckets that refilled completely, which a new
+// bucket would recreate as they are
+func (l *Limiter) dropIdleBuckets() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for key, b := range l.buckets {
+		rule, _ := l.rule(key.Rule)
+		if rule.Rate == 0 || b.tokens+now.Sub(b.last).Seconds()*rule.Rate >= rule.burst() {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Flush writes the quota usage to disk if it changed since the last flush
+func (l *Limiter) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.path == "" || !l.dirty {
+		return nil
+	}
+	if err := l.save(); err != nil {
+		return err
+	}
+	l.dirty = false
+	return nil
+}
+
+// save writes the quota usage to disk; callers must hold the lock
+func (l *Limiter) save() error {
+	tmp, err := os.CreateTemp(filepath.Dir(l.path), "quotas-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating quota file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := gob.NewEncoder(tmp).Encode(snapshot{Day: l.day, Used: l.used}); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing quota usage: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing quota usage: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), l.path)
+}
+
+// load reads the persisted quota usage, if there is one for the current day
+func (l *Limiter) load() error {
+	file, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("opening quota usage: %w", err)
+	}
+	defer file.Close()
+
+	var snap snapshot
+	if err := gob.NewDecoder(file).Decode(&snap); err != nil {
+		return fmt.Errorf("reading quota usage: %w", err)
+	}
+
+	if snap.Day == l.day && snap.Used != nil {
+		l.used = snap.Used
+	}
+	return nil
+}
+
+func dayOf(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
+
+// untilNextDay is the time left until the next UTC day starts
+func untilNextDay(now time.Time) time.Duration {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	return next.Sub(now)
+}
@@ -0,0 +1,181 @@
+package ratelimit
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLimiter_Allow_Rate(t *testing.T) {
+	limiter, err := NewLimiter(Limits{
+		Default: Rule{Rate: 1, Burst: 2},
+		Routes:  map[string]Rule{"/api/v1/stats": {}},
+	}, "")
+	if err != nil {
+		t.Fatalf("NewLimiter() unexpected error: %v", err)
+	}
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	for i := range 2 {
+		if !limiter.Allow("ip:10.0.0.1", "/api/v1/check").Allowed {
+			t.Fatalf("expected call %d within the burst to be allowed", i+1)
+		}
+	}
+
+	decision := limiter.Allow("ip:10.0.0.1", "/api/v1/check")
+	if decision.Allowed || decision.QuotaExceeded {
+		t.Fatalf("expected the rate to be exceeded, got %+v", decision)
+	}
+	if decision.RetryAfter != time.Second {
+		t.Errorf("expected to retry after a second, got %v", decision.RetryAfter)
+	}
+
+	if !limiter.Allow("ip:10.0.0.2", "/api/v1/check").Allowed {
+		t.Error("expected clients to have buckets of their own")
+	}
+	if !limiter.Allow("ip:10.0.0.1", "/api/v1/stats").Allowed {
+		t.Error("expected a route with an unlimited rule not to be limited")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if decision := limiter.Allow("ip:10.0.0.1", "/api/v1/check"); decision.RetryAfter != 500*time.Millisecond {
+		t.Errorf("expected to retry after the rest of the refill, got %+v", decision)
+	}
+	now = now.Add(500 * time.Millisecond)
+	if !limiter.Allow("ip:10.0.0.1", "/api/v1/check").Allowed {
+		t.Error("expected the bucket to refill over time")
+	}
+}
+
+func TestLimiter_Allow_DailyQuota(t *testing.T) {
+	dir := t.TempDir()
+	limits := Limits{Routes: map[string]Rule{"/api/v1/check": {DailyQuota: 2}}}
+
+	limiter, err := NewLimiter(limits, dir)
+	if err != nil {
+		t.Fatalf("NewLimiter() unexpected error: %v", err)
+	}
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+
+	limiter.Allow("api_key:ci", "/api/v1/check")
+	if err := limiter.Flush(); err != nil {
+		t.Fatalf("Flush() unexpected error: %v", err)
+	}
+
+	restarted, err := NewLimiter(limits, dir)
+	if err != nil {
+		t.Fatalf("NewLimiter() unexpected error: %v", err)
+	}
+	restarted.now = limiter.now
+
+	if !restarted.Allow("api_key:ci", "/api/v1/check").Allowed {
+		t.Fatal("expected the last call of the quota to be allowed")
+	}
+	decision := restarted.Allow("api_key:ci", "/api/v1/check")
+	if decision.Allowed || !decision.QuotaExceeded {
+		t.Fatalf("expected the quota persisted before the restart to be spent, got %+v", decision)
+	}
+	if decision.RetryAfter != untilNextDay(now) {
+		t.Errorf("expected to retry at midnight UTC, got %v", decision.RetryAfter)
+	}
+
+	now = now.Add(decision.RetryAfter)
+	if !restarted.Allow("api_key:ci", "/api/v1/check").Allowed {
+		t.Error("expected the quota to reset on the next day")
+	}
+}
+
+func TestLimiter_Peek(t *testing.T) {
+	limiter, err := NewLimiter(Limits{Default: Rule{Rate: 1, Burst: 1, DailyQuota: 1}}, "")
+	if err != nil {
+		t.Fatalf("NewLimiter() unexpected error: %v", err)
+	}
+
+	for range 3 {
+		if !limiter.Peek("ip:10.0.0.1", "failed_authentication").Allowed {
+			t.Fatal("expected peeking not to count calls")
+		}
+	}
+	if !limiter.Allow("ip:10.0.0.1", "failed_authentication").Allowed {
+		t.Fatal("expected the first call to be allowed")
+	}
+	if limiter.Peek("ip:10.0.0.1", "failed_authentication").Allowed {
+		t.Error("expected peeking to report the spent bucket")
+	}
+}
+
+func TestLimiter_Reload(t *testing.T) {
+	limiter, err := NewLimiter(Limits{Default: Rule{Rate: 1}}, "")
+	if err != nil {
+		t.Fatalf("NewLimiter() unexpected error: %v", err)
+	}
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	limiter.Allow("ip:10.0.0.1", "/api/v1/check")
+	if limiter.Allow("ip:10.0.0.1", "/api/v1/check").Allowed {
+		t.Fatal("expected the rate to be exceeded")
+	}
+
+	if err := limiter.Reload(Limits{Default: Rule{Rate: -1}}); err == nil {
+		t.Error("expected an error for a negative rate")
+	}
+	if err := limiter.Reload(Limits{Default: Rule{Rate: 1, Burst: 3}}); err != nil {
+		t.Fatalf("Reload() unexpected error: %v", err)
+	}
+
+	if limiter.Allow("ip:10.0.0.1", "/api/v1/check").Allowed {
+		t.Error("expected reloading not to refill the bucket")
+	}
+	now = now.Add(3 * time.Second)
+	for i := range 3 {
+		if !limiter.Allow("ip:10.0.0.1", "/api/v1/check").Allowed {
+			t.Errorf("expected call %d within the reloaded burst to be allowed", i+1)
+		}
+	}
+}
+
+func TestLimiter_CountsIPv6ClientsByNetwork(t *testing.T) {
+	limiter, err := NewLimiter(Limits{Default: Rule{DailyQuota: 2}}, "")
+	if err != nil {
+		t.Fatalf("NewLimiter() unexpected error: %v", err)
+	}
+
+	limiter.Allow("ip:2001:db8:1:2::1", "/api/v1/check")
+	limiter.Allow("ip:2001:db8:1:2:aaaa::2", "/api/v1/check")
+	if limiter.Allow("ip:2001:db8:1:2:ffff::3", "/api/v1/check").Allowed {
+		t.Error("expected addresses of one /64 network to share a quota")
+	}
+	if !limiter.Allow("ip:2001:db8:1:3::1", "/api/v1/check").Allowed {
+		t.Error("expected another /64 network to have a quota of its own")
+	}
+	if !limiter.Allow("ip:10.0.0.1", "/api/v1/check").Allowed {
+		t.Error("expected IPv4 clients to be counted by address")
+	}
+	if len(limiter.used) != 3 {
+		t.Errorf("expected usage of 3 clients, got %d", len(limiter.used))
+	}
+}
+
+func TestLimiter_BoundsQuotaUsage(t *testing.T) {
+	limiter, err := NewLimiter(Limits{Default: Rule{DailyQuota: 10}}, "")
+	if err != nil {
+		t.Fatalf("NewLimiter() unexpected error: %v", err)
+	}
+
+	for range 3 {
+		limiter.Allow("api_key:ci", "/api/v1/check")
+	}
+	for i := range maxTrackedClients + 1 {
+		limiter.Allow(fmt.Sprintf("ip:10.%d.%d.%d", i>>16&0xff, i>>8&0xff, i&0xff), "/api/v1/check")
+	}
+
+	if len(limiter.used) > maxTrackedClients {
+		t.Errorf("expected at most %d clients tracked, got %d", maxTrackedClients, len(limiter.used))
+	}
+	if limiter.used[usageKey{Client: "api_key:ci", Rule: defaultRule}] != 3 {
+		t.Error("expected the usage of the busiest client to be kept")
+	}
+}
@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+)
+
+// Rule limits the calls of each client to a route
+type Rule struct {
+	// Rate is the sustained calls per second; zero leaves the rate unlimited
+	Rate float64 `json:"rate"`
+	// Burst is the number of calls allowed at once. It defaults to the rate
+	// rounded up.
+	Burst int `json:"burst"`
+	// DailyQuota caps the calls per UTC day; zero leaves them unlimited
+	DailyQuota int64 `json:"daily_quota"`
+}
+
+// Unlimited reports whether the rule limits nothing
+func (r Rule) Unlimited() bool {
+	return r.Rate == 0 && r.DailyQuota == 0
+}
+
+// burst is the bucket size of the rule
+func (r Rule) burst() float64 {
+	if r.Burst > 0 {
+		return float64(r.Burst)
+	}
+	return math.Max(1, math.Ceil(r.Rate))
+}
+
+func (r Rule) validate() error {
+	if r.Rate < 0 || math.IsInf(r.Rate, 0) || math.IsNaN(r.Rate) {
+		return fmt.Errorf("rate must be a non-negative number: %v", r.Rate)
+	}
+	if r.Burst < 0 {
+		return fmt.Errorf("burst must not be negative: %d", r.Burst)
+	}
+	if r.DailyQuota < 0 {
+		return fmt.Errorf("daily quota must not be negative: %d", r.DailyQuota)
+	}
+	return nil
+}
+
+// Limits holds the rules of every route
+type Limits struct {
+	// Default applies to the routes without a rule of their own, which share
+	// its buckets and quota
+	Default Rule `json:"default"`
+	// Routes holds the rules of REST route patterns and gRPC full method
+	// names, e.g. "/api/v1/check" or "/blocking.v1.BlockingService/CheckURL"
+	Routes map[string]Rule `json:"routes"`
+}
+
+// Unlimited reports whether no rule limits anything
+func (l Limits) Unlimited() bool {
+	for _, rule := range l.Routes {
+		if !rule.Unlimited() {
+			return false
+		}
+	}
+	return l.Default.Unlimited()
+}
+
+// Validate rejects negative rates, bursts and quotas
+func (l Limits) Validate() error {
+	if err := l.Default.validate(); err != nil {
+		return fmt.Errorf("default rule: %w", err)
+	}
+	for route, rule := range l.Routes {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("rule of %s: %w", route, err)
+		}
+	}
+	return nil
+}
+
+// LoadLimits reads the limits stored as JSON at path. The default rule
+// falls back to fallback when the file sets none.
+func LoadLimits(path string, fallback Rule) (Limits, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Limits{}, fmt.Errorf("reading rate limits: %w", err)
+	}
+
+	var limits Limits
+	if err := json.Unmarshal(data, &limits); err != nil {
+		return Limits{}, fmt.Errorf("parsing rate limits %s: %w", path, err)
+	}
+	if limits.Default == (Rule{}) {
+		limits.Default = fallback
+	}
+
+	if err := limits.Validate(); err != nil {
+		return Limits{}, fmt.Errorf("rate limits %s: %w", path, err)
+	}
+	return limits, nil
+}
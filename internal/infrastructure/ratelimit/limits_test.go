@@ -0,0 +1,42 @@
+package ratelimit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limits.json")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fallback := Rule{Rate: 5}
+
+	write(`{"routes": {"/api/v1/check": {"rate": 2, "burst": 4, "daily_quota": 1000}}}`)
+	limits, err := LoadLimits(path, fallback)
+	if err != nil {
+		t.Fatalf("LoadLimits() unexpected error: %v", err)
+	}
+	if limits.Default != fallback {
+		t.Errorf("expected the default rule to fall back, got %+v", limits.Default)
+	}
+	if rule := limits.Routes["/api/v1/check"]; rule != (Rule{Rate: 2, Burst: 4, DailyQuota: 1000}) {
+		t.Errorf("unexpected route rule %+v", rule)
+	}
+
+	write(`{"default": {"daily_quota": 100}}`)
+	if limits, err := LoadLimits(path, fallback); err != nil || limits.Default != (Rule{DailyQuota: 100}) {
+		t.Errorf("expected the file to set the default rule, got %+v, %v", limits.Default, err)
+	}
+
+	for _, content := range []string{`{"routes": {"/api/v1/check": {"rate": -1}}}`, `not json`} {
+		write(content)
+		if _, err := LoadLimits(path, fallback); err == nil {
+			t.Errorf("expected an error for %s", content)
+		}
+	}
+}
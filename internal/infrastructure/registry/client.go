@@ -3,15 +3,33 @@ package registry
 import (
 	"context"
 	"fmt"
+	"io"
+	"log/slog"
+	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/metrics"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/tracing"
 )
 
+// tracer records registry fetches and parsing
+var tracer = otel.Tracer("github.com/kerim-dauren/rkn-checker/internal/infrastructure/registry")
+
+// VersionLayout formats the version assigned to registries whose source does
+// not provide one; versions sort chronologically as strings
+const VersionLayout = "20060102T150405.000Z"
+
 // Client manages multiple registry sources with fallback logic
 type Client struct {
-	sources []Source
-	parser  *Parser
+	// mu guards sources, which may be replaced at runtime
+	mu       sync.RWMutex
+	sources  []Source
+	parser   *Parser
+	verifier *SignatureVerifier
 
 	// Configuration
 	maxConcurrent int
@@ -28,6 +46,10 @@ type ClientConfig struct {
 	Sources       []SourceConfig
 	MaxConcurrent int
 	Timeout       time.Duration
+
+	// TrustBundlePath enables detached signature verification of every dump
+	// against the PEM certificates in this file
+	TrustBundlePath string
 }
 
 // NewClient creates a new registry client with configured sources
@@ -37,24 +59,60 @@ func NewClient(config ClientConfig) (*Client, error) {
 	}
 
 	client := &Client{
-		sources:       make([]Source, 0, len(config.Sources)),
 		parser:        NewParser(),
 		maxConcurrent: config.MaxConcurrent,
 		timeout:       config.Timeout,
 	}
 
-	// Initialize sources based on configuration
-	for _, srcConfig := range config.Sources {
-		source, err := client.createSource(srcConfig)
+	if config.TrustBundlePath != "" {
+		verifier, err := NewSignatureVerifier(config.TrustBundlePath)
 		if err != nil {
-			return nil, fmt.Errorf("creating source %s: %w", srcConfig.Type, err)
+			return nil, fmt.Errorf("loading signature trust bundle: %w", err)
 		}
-		client.sources = append(client.sources, source)
+		client.verifier = verifier
+	}
+
+	sources, err := client.createSources(config.Sources)
+	if err != nil {
+		return nil, err
 	}
+	client.sources = sources
 
 	return client, nil
 }
 
+// SetSources replaces the configured sources. Fetches already running
+// finish with the previous sources.
+func (c *Client) SetSources(configs []SourceConfig) error {
+	if len(configs) == 0 {
+		return fmt.Errorf("at least one source must be configured")
+	}
+
+	sources, err := c.createSources(configs)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.sources = sources
+	c.mu.Unlock()
+
+	return nil
+}
+
+// createSources creates the source instances of configs
+func (c *Client) createSources(configs []SourceConfig) ([]Source, error) {
+	sources := make([]Source, 0, len(configs))
+	for _, srcConfig := range configs {
+		source, err := c.createSource(srcConfig)
+		if err != nil {
+			return nil, fmt.Errorf("creating source %s: %w", srcConfig.SourceName(), err)
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
 // createSource creates a source instance based on configuration
 func (c *Client) createSource(config SourceConfig) (Source, error) {
 	switch config.Type {
@@ -65,8 +123,9 @@ func (c *Client) createSource(config SourceConfig) (Source, error) {
 	}
 }
 
-// FetchRegistry attempts to fetch registry data from all configured sources
-func (c *Client) FetchRegistry(ctx context.Context) (*domain.Registry, error) {
+// FetchRegistry attempts to fetch registry data from all configured sources.
+// It returns the ingest report of the dump that was used.
+func (c *Client) FetchRegistry(ctx context.Context) (*domain.Registry, *domain.IngestReport, error) {
 	ctx, cancel := context.WithTimeout(ctx, c.timeout)
 	defer cancel()
 
@@ -75,10 +134,10 @@ func (c *Client) FetchRegistry(ctx context.Context) (*domain.Registry, error) {
 
 	var lastErr error
 	for _, source := range sources {
-		registry, err := c.fetchFromSource(ctx, source)
+		registry, report, err := c.fetchFromSource(ctx, source)
 		if err == nil {
 			c.onFetchSuccess(source.Name())
-			return registry, nil
+			return registry, report, nil
 		}
 
 		lastErr = err
@@ -91,47 +150,113 @@ func (c *Client) FetchRegistry(ctx context.Context) (*domain.Registry, error) {
 	}
 
 	c.consecutiveFailures++
-	return nil, fmt.Errorf("%w: last error: %v", ErrAllSourcesFailed, lastErr)
+	return nil, nil, fmt.Errorf("%w: last error: %w", ErrAllSourcesFailed, lastErr)
 }
 
 // fetchFromSource attempts to fetch and parse data from a single source
-func (c *Client) fetchFromSource(ctx context.Context, source Source) (*domain.Registry, error) {
+func (c *Client) fetchFromSource(ctx context.Context, source Source) (*domain.Registry, *domain.IngestReport, error) {
 	// Check if source is healthy before attempting fetch
 	if !source.IsHealthy(ctx) {
-		return nil, NewSourceError(source.Name(), "health_check",
+		return nil, nil, NewSourceError(source.Name(), "health_check",
 			fmt.Errorf("source is not healthy"))
 	}
 
+	ctx, span := tracer.Start(ctx, "fetch", trace.WithAttributes(tracing.SourceKey.String(source.Name())))
+	defer span.End()
+
+	start := time.Now()
+	registry, report, err := c.ingest(ctx, source)
+
+	result := metrics.ResultSuccess
+	if err != nil {
+		result = metrics.ResultFailure
+		tracing.RecordError(span, err)
+	}
+	metrics.SourceFetchDuration.WithLabelValues(source.Name(), result).Observe(time.Since(start).Seconds())
+
+	return registry, report, err
+}
+
+// ingest downloads, verifies and parses a dump from source
+func (c *Client) ingest(ctx context.Context, source Source) (*domain.Registry, *domain.IngestReport, error) {
 	// Fetch raw data
-	data, err := source.Fetch(ctx)
+	body, err := source.Fetch(ctx)
 	if err != nil {
-		return nil, NewSourceError(source.Name(), "fetch", err)
+		return nil, nil, NewSourceError(source.Name(), "fetch", err)
+	}
+	defer body.Close()
+
+	counted := &countingReader{r: body}
+	defer func() {
+		metrics.SourceFetchBytes.WithLabelValues(source.Name()).Add(float64(counted.n))
+	}()
+
+	var dump io.Reader = counted
+
+	// Verify detached signatures before any of the data is trusted. The dump
+	// is spooled to disk so the verified bytes are exactly the parsed ones.
+	if c.verifier != nil {
+		spool, err := NewSpool(counted)
+		if err != nil {
+			return nil, nil, NewSourceError(source.Name(), "fetch", err)
+		}
+		defer spool.Close()
+
+		signatures, err := c.verifier.VerifyArchive(spool, spool.Size())
+		if err != nil {
+			return nil, nil, NewSourceError(source.Name(), "verify", err)
+		}
+		for _, sig := range signatures {
+			slog.Info("Registry dump signature verified",
+				"source", source.Name(),
+				"member", sig.Member,
+				"signer", sig.Signer,
+				"serial", sig.Serial,
+				"signed_at", sig.SignedAt)
+		}
+
+		dump = spool
 	}
 
 	// Parse data into registry
-	registry, err := c.parser.Parse(data)
+	registry, report, err := c.parser.ParseContext(ctx, dump)
 	if err != nil {
-		return nil, NewSourceError(source.Name(), "parse", err)
+		return nil, nil, NewSourceError(source.Name(), "parse", err)
 	}
+	metrics.ParserRejectedEntries.WithLabelValues(report.Format).Add(float64(report.RejectedCount))
 
 	// Set registry metadata
 	registry.Source = source.Name()
 	registry.LastUpdated = time.Now()
+	if registry.Version == "" {
+		registry.Version = registry.LastUpdated.UTC().Format(VersionLayout)
+	}
 
-	return registry, nil
+	slog.Info("Registry dump ingested",
+		"source", source.Name(),
+		"format", report.Format,
+		"encoding", report.Encoding,
+		"rows", report.RowsRead,
+		"accepted", report.Accepted,
+		"rejected", report.RejectedCount,
+		"duplicates", report.DuplicateCount,
+		"duration", report.Duration)
+
+	return registry, report, nil
 }
 
 // orderSources returns sources ordered by preference
 func (c *Client) orderSources() []Source {
+	sources := c.GetSources()
 	if c.lastSuccessfulSource == "" {
-		return c.sources
+		return sources
 	}
 
 	// Move last successful source to front
-	ordered := make([]Source, 0, len(c.sources))
+	ordered := make([]Source, 0, len(sources))
 	var lastSuccessful Source
 
-	for _, source := range c.sources {
+	for _, source := range sources {
 		if source.Name() == c.lastSuccessfulSource {
 			lastSuccessful = source
 		} else {
@@ -163,7 +288,7 @@ func (c *Client) onFetchFailure(sourceName string, err error) {
 func (c *Client) GetHealthStatus(ctx context.Context) map[string]bool {
 	status := make(map[string]bool)
 
-	for _, source := range c.sources {
+	for _, source := range c.GetSources() {
 		status[source.Name()] = source.IsHealthy(ctx)
 	}
 
@@ -185,7 +310,22 @@ func (c *Client) GetLastSuccessfulSource() string {
 	return c.lastSuccessfulSource
 }
 
-// GetSources returns the configured sources (for testing)
+// GetSources returns the configured sources
 func (c *Client) GetSources() []Source {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	return c.sources
 }
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
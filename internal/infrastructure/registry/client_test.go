@@ -1,12 +1,19 @@
 package registry
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"io"
 	"testing"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
 	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/tracing"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/tracing/tracingtest"
 )
 
 // mockSource is a test implementation of the Source interface
@@ -19,12 +26,12 @@ type mockSource struct {
 	healthCallCount int
 }
 
-func (m *mockSource) Fetch(ctx context.Context) ([]byte, error) {
+func (m *mockSource) Fetch(ctx context.Context) (io.ReadCloser, error) {
 	m.fetchCallCount++
 	if m.err != nil {
 		return nil, m.err
 	}
-	return m.data, nil
+	return io.NopCloser(bytes.NewReader(m.data)), nil
 }
 
 func (m *mockSource) Name() string {
@@ -72,6 +79,40 @@ func TestNewClient_NoSources(t *testing.T) {
 	}
 }
 
+func TestClient_SetSources(t *testing.T) {
+	client, err := NewClient(ClientConfig{
+		Sources: []SourceConfig{{Type: SourceTypeOfficial, URL: "https://example.com"}},
+		Timeout: 30 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = client.SetSources([]SourceConfig{
+		{Name: "primary", Type: SourceTypeOfficial, URL: "https://primary.example.com"},
+		{Name: "mirror", Type: SourceTypeOfficial, URL: "https://mirror.example.com"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sources := client.GetSources()
+	if len(sources) != 2 || sources[0].Name() != "primary" || sources[1].Name() != "mirror" {
+		t.Errorf("expected sources primary and mirror, got %v", sources)
+	}
+
+	if err := client.SetSources(nil); err == nil {
+		t.Error("expected error for empty sources")
+	}
+
+	if err := client.SetSources([]SourceConfig{{Type: "mirror", URL: "https://example.com"}}); err == nil {
+		t.Error("expected error for unsupported source type")
+	}
+	if len(client.GetSources()) != 2 {
+		t.Error("failed update should keep the current sources")
+	}
+}
+
 func TestClient_FetchRegistry_Success(t *testing.T) {
 	testData := []byte("id;url;date\n1;example.com;2023-01-01")
 
@@ -88,7 +129,7 @@ func TestClient_FetchRegistry_Success(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	registry, err := client.FetchRegistry(ctx)
+	registry, _, err := client.FetchRegistry(ctx)
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -105,6 +146,10 @@ func TestClient_FetchRegistry_Success(t *testing.T) {
 		t.Errorf("expected source 'test-source', got %q", registry.Source)
 	}
 
+	if _, err := time.Parse(VersionLayout, registry.Version); err != nil {
+		t.Errorf("expected generated version, got %q", registry.Version)
+	}
+
 	if mockSrc.fetchCallCount != 1 {
 		t.Errorf("expected 1 fetch call, got %d", mockSrc.fetchCallCount)
 	}
@@ -134,7 +179,7 @@ func TestClient_FetchRegistry_AllSourcesFail(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	_, err := client.FetchRegistry(ctx)
+	_, _, err := client.FetchRegistry(ctx)
 
 	if err == nil {
 		t.Error("expected error when all sources fail")
@@ -163,7 +208,7 @@ func TestClient_FetchRegistry_UnhealthySource(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	_, err := client.FetchRegistry(ctx)
+	_, _, err := client.FetchRegistry(ctx)
 
 	if err == nil {
 		t.Error("expected error for unhealthy source")
@@ -201,7 +246,7 @@ func TestClient_FetchRegistry_FallbackToSecondSource(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	registry, err := client.FetchRegistry(ctx)
+	registry, _, err := client.FetchRegistry(ctx)
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -226,6 +271,42 @@ func TestClient_FetchRegistry_FallbackToSecondSource(t *testing.T) {
 	}
 }
 
+func TestClient_FetchRegistry_Spans(t *testing.T) {
+	exporter := tracingtest.Exporter(t)
+
+	client := &Client{
+		sources: []Source{
+			&mockSource{name: "source-1", err: errors.New("first source error"), healthy: true},
+			&mockSource{name: "source-2", data: []byte("id;url;date\n1;example.com;2023-01-01"), healthy: true},
+		},
+		parser:  NewParser(),
+		timeout: 30 * time.Second,
+	}
+
+	if _, _, err := client.FetchRegistry(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// One fetch span per source tried, the failed one marked as such
+	var fetches []string
+	for _, span := range exporter.GetSpans() {
+		if span.Name != "fetch" {
+			continue
+		}
+		attrs := attribute.NewSet(span.Attributes...)
+		source, _ := attrs.Value(tracing.SourceKey)
+		fetches = append(fetches, source.AsString())
+
+		failed := span.Status.Code == codes.Error
+		if failed != (source.AsString() == "source-1") {
+			t.Errorf("unexpected status %v for %s", span.Status.Code, source.AsString())
+		}
+	}
+	if len(fetches) != 2 {
+		t.Errorf("expected a fetch span for both sources, got %v", fetches)
+	}
+}
+
 func TestClient_orderSources(t *testing.T) {
 	mockSrc1 := &mockSource{name: "source-1"}
 	mockSrc2 := &mockSource{name: "source-2"}
@@ -290,7 +371,7 @@ func TestClient_FetchRegistry_ContextTimeout(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	_, err := client.FetchRegistry(ctx)
+	_, _, err := client.FetchRegistry(ctx)
 
 	if err == nil {
 		t.Error("expected timeout error")
@@ -350,7 +431,7 @@ func BenchmarkClient_FetchRegistry(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := client.FetchRegistry(ctx)
+		_, _, err := client.FetchRegistry(ctx)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
 )
 
 var (
@@ -29,6 +31,13 @@ var (
 	ErrRKNRequestFailed          = errors.New("RKN API request failed")
 	ErrRKNInvalidRequest         = errors.New("RKN API request format is invalid")
 	ErrRKNServiceUnavailable     = errors.New("RKN API service is temporarily unavailable")
+
+	// Signature verification errors
+	ErrSignatureMissing              = errors.New("detached signature is missing")
+	ErrSignatureMalformed            = errors.New("detached signature is not a valid CMS SignedData structure")
+	ErrSignatureDigestMismatch       = errors.New("signed digest does not match dump contents")
+	ErrSignatureUntrustedSigner      = errors.New("signer certificate is not trusted")
+	ErrSignatureAlgorithmUnsupported = errors.New("signature algorithm is not supported")
 )
 
 // SourceError wraps errors from registry sources with additional context
@@ -131,3 +140,30 @@ func NewRKNAPIErrorWithRequestID(code, message, operation, requestID string) *RK
 		RequestID: requestID,
 	}
 }
+
+// SignatureError reports a dump member that failed detached signature
+// verification. It matches both domain.ErrRegistrySignatureInvalid and the
+// underlying cause via errors.Is.
+type SignatureError struct {
+	Member string
+	Cause  error
+}
+
+func (e *SignatureError) Error() string {
+	if e.Member != "" {
+		return fmt.Sprintf("signature verification of %q failed: %v", e.Member, e.Cause)
+	}
+	return fmt.Sprintf("signature verification failed: %v", e.Cause)
+}
+
+func (e *SignatureError) Unwrap() []error {
+	return []error{domain.ErrRegistrySignatureInvalid, e.Cause}
+}
+
+// NewSignatureError creates a new SignatureError for the given archive member
+func NewSignatureError(member string, cause error) *SignatureError {
+	return &SignatureError{
+		Member: member,
+		Cause:  cause,
+	}
+}
@@ -1,6 +1,7 @@
 package registry
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"crypto/tls"
@@ -82,15 +83,17 @@ func NewOfficialSource(config SourceConfig) *OfficialSource {
 
 // Name returns the source name
 func (o *OfficialSource) Name() string {
-	return "Official RKN API"
+	return o.config.SourceName()
 }
 
-// Fetch retrieves registry data from official RKN API
+// Fetch opens a stream of registry data from official RKN API
 // Note: This may be blocked when accessed from Germany
-func (o *OfficialSource) Fetch(ctx context.Context) ([]byte, error) {
+func (o *OfficialSource) Fetch(ctx context.Context) (io.ReadCloser, error) {
 	var lastErr error
 
-	for attempt := 0; attempt < o.config.MaxRetries; attempt++ {
+	// At least one attempt is made, so MaxRetries 0 disables retries
+	attempts := max(1, o.config.MaxRetries)
+	for attempt := 0; attempt < attempts; attempt++ {
 		if attempt > 0 {
 			// Exponential backoff with jitter
 			backoff := time.Duration(attempt*attempt) * time.Second
@@ -120,7 +123,7 @@ func (o *OfficialSource) Fetch(ctx context.Context) ([]byte, error) {
 }
 
 // fetchOnce performs a single fetch attempt using SOAP API or direct HTTP (test mode)
-func (o *OfficialSource) fetchOnce(ctx context.Context) ([]byte, error) {
+func (o *OfficialSource) fetchOnce(ctx context.Context) (io.ReadCloser, error) {
 	// If in test mode, use direct HTTP GET instead of SOAP
 	if o.testMode {
 		return o.fetchDirect(ctx)
@@ -143,7 +146,7 @@ func (o *OfficialSource) fetchOnce(ctx context.Context) ([]byte, error) {
 }
 
 // fetchDirect performs a direct HTTP GET (for testing with mock servers)
-func (o *OfficialSource) fetchDirect(ctx context.Context) ([]byte, error) {
+func (o *OfficialSource) fetchDirect(ctx context.Context) (io.ReadCloser, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", o.config.URL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
@@ -156,22 +159,29 @@ func (o *OfficialSource) fetchDirect(ctx context.Context) ([]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
 		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
 	}
 
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
+	// Peek so an empty body fails here, where it can still be retried
+	body := bufio.NewReader(resp.Body)
+	if _, err := body.Peek(1); err != nil {
+		resp.Body.Close()
+		if err == io.EOF {
+			return nil, ErrEmptyData
+		}
 		return nil, fmt.Errorf("reading response body: %w", err)
 	}
 
-	if len(data) == 0 {
-		return nil, ErrEmptyData
-	}
+	return &bufferedBody{Reader: body, Closer: resp.Body}, nil
+}
 
-	return data, nil
+// bufferedBody pairs a buffered reader with the body it reads from
+type bufferedBody struct {
+	*bufio.Reader
+	io.Closer
 }
 
 // sendSOAPRequest sends a SOAP request for registry data
@@ -219,7 +229,7 @@ func (o *OfficialSource) sendSOAPRequest(ctx context.Context) (string, error) {
 }
 
 // getSOAPResult retrieves the result for a given request ID
-func (o *OfficialSource) getSOAPResult(ctx context.Context, requestID string) ([]byte, error) {
+func (o *OfficialSource) getSOAPResult(ctx context.Context, requestID string) (io.ReadCloser, error) {
 	// This is a simplified implementation
 	// Real implementation would poll getResult method until data is ready
 	return nil, fmt.Errorf("RKN API integration requires proper authentication setup and request/response handling - this is a placeholder implementation")
@@ -2,6 +2,7 @@ package registry
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 )
@@ -59,6 +60,21 @@ func TestOfficialSource_FetchWithoutAuthentication(t *testing.T) {
 	}
 }
 
+func TestOfficialSource_FetchWithoutRetries(t *testing.T) {
+	config := SourceConfig{
+		Type:       SourceTypeOfficial,
+		URL:        "https://vigruzki.rkn.gov.ru/services/OperatorRequest/",
+		Timeout:    5 * time.Second,
+		MaxRetries: 0,
+	}
+
+	// The single attempt fails because no authentication is configured
+	_, err := NewOfficialSource(config).Fetch(context.Background())
+	if err == nil || errors.Unwrap(err) == nil {
+		t.Errorf("expected the error of a single attempt, got %v", err)
+	}
+}
+
 func TestOfficialSource_FetchWithMockAuthentication(t *testing.T) {
 	config := SourceConfig{
 		Type:       SourceTypeOfficial,
@@ -2,18 +2,37 @@ package registry
 
 import (
 	"archive/zip"
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/csv"
 	"fmt"
+	"hash/maphash"
 	"io"
 	"net"
+	"net/netip"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/tracing"
 	"golang.org/x/text/encoding/charmap"
 )
 
+const (
+	// encodingSampleSize is how much of a dump is inspected to detect its
+	// format and text encoding
+	encodingSampleSize = 64 * 1024
+
+	encodingUTF8        = "utf-8"
+	encodingWindows1251 = "windows-1251"
+)
+
 // Parser handles parsing of registry data in various formats
 type Parser struct {
 	// Regex patterns for validation
@@ -33,44 +52,76 @@ func NewParser() *Parser {
 	}
 }
 
-// Parse parses registry data and returns a Registry
-func (p *Parser) Parse(data []byte) (*domain.Registry, error) {
-	if len(data) == 0 {
-		return nil, ErrEmptyData
+// Parse parses a registry dump read from r and returns a Registry together
+// with a report of what was accepted and rejected. CSV input is decoded
+// record by record; ZIP input is spooled to a temporary file unless r
+// already is a *Spool.
+func (p *Parser) Parse(r io.Reader) (*domain.Registry, *domain.IngestReport, error) {
+	return p.ParseContext(context.Background(), r)
+}
+
+// ParseContext is Parse recording the unzip and parse stages as spans under
+// ctx
+func (p *Parser) ParseContext(ctx context.Context, r io.Reader) (*domain.Registry, *domain.IngestReport, error) {
+	spool, _ := r.(*Spool)
+	if spool != nil {
+		r = spool.Reader()
+	}
+
+	br := bufio.NewReaderSize(r, encodingSampleSize)
+	if _, err := br.Peek(1); err == io.EOF {
+		return nil, nil, ErrEmptyData
 	}
 
 	// Detect format by checking magic bytes
-	format, err := p.detectFormat(data)
+	format, err := p.detectFormat(br)
 	if err != nil {
-		return nil, fmt.Errorf("detecting format: %w", err)
+		return nil, nil, fmt.Errorf("detecting format: %w", err)
 	}
 
+	report := domain.NewIngestReport(format)
+	defer report.Finish()
+
+	var registry *domain.Registry
 	switch format {
 	case "csv":
-		return p.parseCSV(data)
+		registry, err = p.parseCSV(ctx, br, report)
 	case "zip":
-		return p.parseZIP(data)
+		if spool == nil {
+			spool, err = NewSpool(br)
+			if err != nil {
+				return nil, nil, NewParsingError("zip", err)
+			}
+			defer spool.Close()
+		}
+		registry, err = p.parseZIP(ctx, spool, spool.Size(), report)
 	default:
-		return nil, NewParsingError(format, ErrUnsupportedFormat)
+		return nil, nil, NewParsingError(format, ErrUnsupportedFormat)
 	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return registry, report, nil
 }
 
-// detectFormat detects the data format based on content
-func (p *Parser) detectFormat(data []byte) (string, error) {
-	if len(data) < 4 {
+// detectFormat detects the data format from the buffered head of the input
+func (p *Parser) detectFormat(br *bufio.Reader) (string, error) {
+	head, _ := br.Peek(4)
+	if len(head) < 4 {
 		return "", ErrInvalidFormat
 	}
 
 	// Check for ZIP magic bytes
-	if bytes.HasPrefix(data, []byte{0x50, 0x4B, 0x03, 0x04}) ||
-		bytes.HasPrefix(data, []byte{0x50, 0x4B, 0x05, 0x06}) ||
-		bytes.HasPrefix(data, []byte{0x50, 0x4B, 0x07, 0x08}) {
+	if bytes.HasPrefix(head, []byte{0x50, 0x4B, 0x03, 0x04}) ||
+		bytes.HasPrefix(head, []byte{0x50, 0x4B, 0x05, 0x06}) ||
+		bytes.HasPrefix(head, []byte{0x50, 0x4B, 0x07, 0x08}) {
 		return "zip", nil
 	}
 
 	// Check if it looks like CSV (contains semicolons or commas)
-	sample := string(data[:min(1024, len(data))])
-	if strings.Contains(sample, ";") || strings.Contains(sample, ",") {
+	sample, _ := br.Peek(1024)
+	if bytes.ContainsAny(sample, ";,") {
 		return "csv", nil
 	}
 
@@ -78,8 +129,16 @@ func (p *Parser) detectFormat(data []byte) (string, error) {
 }
 
 // parseZIP extracts and parses CSV files from ZIP archive
-func (p *Parser) parseZIP(data []byte) (*domain.Registry, error) {
-	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+func (p *Parser) parseZIP(ctx context.Context, ra io.ReaderAt, size int64, report *domain.IngestReport) (registry *domain.Registry, err error) {
+	ctx, span := tracer.Start(ctx, "unzip", trace.WithAttributes(tracing.DumpSizeKey.Int64(size)))
+	defer func() {
+		if err != nil {
+			tracing.RecordError(span, err)
+		}
+		span.End()
+	}()
+
+	reader, err := zip.NewReader(ra, size)
 	if err != nil {
 		return nil, NewParsingError("zip", fmt.Errorf("opening ZIP: %w", err))
 	}
@@ -92,15 +151,14 @@ func (p *Parser) parseZIP(data []byte) (*domain.Registry, error) {
 				continue
 			}
 
-			csvData, err := io.ReadAll(rc)
+			// Try to parse this CSV file, discarding the report of any
+			// member that turns out not to hold registry data
+			memberReport := domain.NewIngestReport(report.Format)
+			registry, err := p.parseCSV(ctx, rc, memberReport)
 			rc.Close()
-			if err != nil {
-				continue
-			}
-
-			// Try to parse this CSV file
-			registry, err := p.parseCSV(csvData)
 			if err == nil {
+				memberReport.StartedAt = report.StartedAt
+				*report = *memberReport
 				return registry, nil
 			}
 		}
@@ -109,114 +167,295 @@ func (p *Parser) parseZIP(data []byte) (*domain.Registry, error) {
 	return nil, NewParsingError("zip", fmt.Errorf("no valid CSV found in archive"))
 }
 
-// parseCSV parses CSV format registry data
-func (p *Parser) parseCSV(data []byte) (*domain.Registry, error) {
-	// Try different encodings
-	encodings := []func([]byte) (string, error){
-		func(d []byte) (string, error) { return string(d), nil },         // UTF-8
-		func(d []byte) (string, error) { return p.decodeWindows1251(d) }, // Windows-1251
+// parseCSV parses CSV format registry data, decoding it on the fly with the
+// encoding detected from a prefix sample
+func (p *Parser) parseCSV(ctx context.Context, r io.Reader, report *domain.IngestReport) (*domain.Registry, error) {
+	_, span := tracer.Start(ctx, "parse")
+	defer span.End()
+
+	br, ok := r.(*bufio.Reader)
+	if !ok || br.Size() < encodingSampleSize {
+		br = bufio.NewReaderSize(r, encodingSampleSize)
 	}
 
-	var lastErr error
-	for _, decode := range encodings {
-		text, err := decode(data)
-		if err != nil {
-			lastErr = err
-			continue
-		}
+	sample, _ := br.Peek(encodingSampleSize)
 
-		registry, err := p.parseCSVText(text)
-		if err == nil {
-			return registry, nil
-		}
-		lastErr = err
+	var text io.Reader = br
+	report.Encoding = detectEncoding(sample)
+	if report.Encoding == encodingWindows1251 {
+		text = charmap.Windows1251.NewDecoder().Reader(br)
+	}
+
+	registry, err := p.parseCSVStream(text, report)
+	span.SetAttributes(
+		tracing.FormatKey.String(report.Format),
+		tracing.EncodingKey.String(report.Encoding),
+		tracing.RowsKey.Int(report.RowsRead),
+		tracing.AcceptedKey.Int(report.Accepted),
+		tracing.RejectedKey.Int(report.RejectedCount))
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, NewParsingError("csv", err)
 	}
 
-	return nil, NewParsingError("csv", lastErr)
+	return registry, nil
 }
 
-// decodeWindows1251 decodes Windows-1251 encoded data
-func (p *Parser) decodeWindows1251(data []byte) (string, error) {
-	decoder := charmap.Windows1251.NewDecoder()
-	result, err := decoder.Bytes(data)
-	if err != nil {
-		return "", err
+// detectEncoding guesses the text encoding of a dump from a prefix sample.
+// RKN dumps are either UTF-8 or Windows-1251, and Cyrillic Windows-1251 text
+// is practically never valid UTF-8.
+func detectEncoding(sample []byte) string {
+	// Drop a multi-byte sequence cut off by the end of the sample
+	for i := len(sample) - 1; i >= 0 && i >= len(sample)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(sample[i]) {
+			if !utf8.FullRune(sample[i:]) {
+				sample = sample[:i]
+			}
+			break
+		}
 	}
-	return string(result), nil
+
+	if utf8.Valid(sample) {
+		return encodingUTF8
+	}
+	return encodingWindows1251
 }
 
-// parseCSVText parses CSV text content
-func (p *Parser) parseCSVText(text string) (*domain.Registry, error) {
-	reader := csv.NewReader(strings.NewReader(text))
+// parseCSVStream parses CSV text content record by record, recording every
+// rejected and duplicate entry in report
+func (p *Parser) parseCSVStream(text io.Reader, report *domain.IngestReport) (*domain.Registry, error) {
+	reader := csv.NewReader(text)
 	reader.Comma = ';' // RKN registry uses semicolon separator
 	reader.LazyQuotes = true
 	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
 
 	registry := domain.NewRegistry()
 	registry.Source = "RKN Registry"
 
-	lineNum := 0
+	state := &ingestState{
+		registry: registry,
+		report:   report,
+		seed:     maphash.MakeSeed(),
+		seen:     make(map[uint32]int32),
+		collided: make(map[string]int32),
+	}
+
+	recordNum := 0
 	for {
 		record, err := reader.Read()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return nil, NewParsingErrorWithPosition("csv", lineNum, 0, err)
+			return nil, NewParsingErrorWithPosition("csv", state.line, 0, err)
 		}
 
-		lineNum++
+		recordNum++
+		state.line, _ = reader.FieldPos(0)
 
-		// Skip header or empty lines
-		if lineNum == 1 || len(record) == 0 {
+		// The header selects the layout; skip it and empty lines
+		if recordNum == 1 {
+			state.dumpLayout = isDumpHeader(record)
 			continue
 		}
-
-		if err := p.parseCSVRecord(record, registry); err != nil {
-			// Log parse errors but continue processing
+		if len(record) == 0 {
 			continue
 		}
+
+		report.RowsRead++
+		p.parseCSVRecord(record, state)
 	}
 
 	if registry.Size() == 0 {
-		return nil, NewParsingError("csv", fmt.Errorf("no valid entries found"))
+		return nil, fmt.Errorf("no valid entries found")
 	}
 
 	return registry, nil
 }
 
-// parseCSVRecord parses a single CSV record
-func (p *Parser) parseCSVRecord(record []string, registry *domain.Registry) error {
+// ingestState tracks a single parse run
+type ingestState struct {
+	registry *domain.Registry
+	report   *domain.IngestReport
+	line     int
+
+	// dumpLayout is set for the full RKN dump layout,
+	// ip|ip;domain;url|url;org;decision;date
+	dumpLayout bool
+
+	// Accepted values are indexed by a 32-bit hash rather than by string to
+	// keep duplicate detection cheap on multi-million row dumps. Values
+	// whose hash is already taken by a different value go to collided.
+	seed     maphash.Seed
+	seen     map[uint32]int32 // value hash -> entry index
+	collided map[string]int32 // value -> entry index
+	lines    []int32          // entry index -> line it was read from
+}
+
+// firstSeen returns the line a value was first accepted on, if it was
+func (s *ingestState) firstSeen(value string) (int, bool) {
+	index, ok := s.entryIndex(value)
+	if !ok {
+		return 0, false
+	}
+	return int(s.lines[index]), true
+}
+
+// entryIndex returns the index of the entry a value was accepted as, if it was
+func (s *ingestState) entryIndex(value string) (int32, bool) {
+	index, ok := s.seen[s.hash(value)]
+	if ok && s.registry.Entries[index].Value() != value {
+		index, ok = s.collided[value]
+	}
+	return index, ok
+}
+
+// markSeen records the most recently added entry as the first occurrence of value
+func (s *ingestState) markSeen(value string) {
+	index := int32(len(s.registry.Entries) - 1)
+	if _, taken := s.seen[s.hash(value)]; taken {
+		s.collided[value] = index
+	} else {
+		s.seen[s.hash(value)] = index
+	}
+	s.lines = append(s.lines, int32(s.line))
+}
+
+func (s *ingestState) hash(value string) uint32 {
+	return uint32(maphash.String(s.seed, value))
+}
+
+// recordFields holds what a record says about every entry listed in it
+type recordFields struct {
+	id          string
+	blockedDate time.Time
+	decision    string
+	decisionOrg string
+}
+
+// isDumpHeader reports whether the first record of a CSV file is the header
+// of the full RKN dump, which starts with an "Updated:" timestamp line
+func isDumpHeader(record []string) bool {
+	if len(record) == 0 {
+		return false
+	}
+	first := strings.TrimSpace(record[0])
+	return strings.HasPrefix(first, "Updated") || strings.EqualFold(first, "ip")
+}
+
+// parseCSVRecord parses a single CSV record. Entries that cannot be ingested
+// are recorded in the report instead of failing the record.
+func (p *Parser) parseCSVRecord(record []string, state *ingestState) {
+	if state.dumpLayout {
+		p.parseDumpRecord(record, state)
+		return
+	}
+
 	if len(record) < 2 {
-		return fmt.Errorf("insufficient columns")
+		state.report.AddRejected(state.line, strings.Join(record, ";"), "insufficient columns")
+		return
 	}
 
 	// Common RKN CSV format: [id, url, date, ...]
 	// We're primarily interested in the URL field
 	urlField := strings.TrimSpace(record[1])
 	if urlField == "" {
-		return fmt.Errorf("empty URL field")
+		state.report.AddRejected(state.line, strings.Join(record, ";"), "empty URL field")
+		return
+	}
+
+	// Entries are identified by their record and position within it, so IDs
+	// stay stable for as long as the record is unchanged
+	fields := recordFields{id: strings.TrimSpace(record[0])}
+	if fields.id == "" {
+		fields.id = strconv.Itoa(state.line)
+	}
+	if len(record) > 2 {
+		fields.blockedDate, _ = time.Parse(time.DateOnly, strings.TrimSpace(record[2]))
+	}
+
+	p.addRecordEntries(strings.Split(urlField, "|"), fields, state)
+}
+
+// parseDumpRecord parses a record of the full RKN dump. The IPs, domain and
+// URLs of a record are kept together under one record ID so that an IP can
+// be traced back to the content it was listed for.
+func (p *Parser) parseDumpRecord(record []string, state *ingestState) {
+	if len(record) < 2 {
+		state.report.AddRejected(state.line, strings.Join(record, ";"), "insufficient columns")
+		return
+	}
+
+	// The dump has no ID column; records are identified by their line
+	fields := recordFields{id: strconv.Itoa(state.line)}
+	var values []string
+	for column, field := range record {
+		field = strings.TrimSpace(field)
+		switch column {
+		case 0, 2:
+			values = append(values, strings.Split(field, "|")...)
+		case 1:
+			values = append(values, field)
+		case 3:
+			fields.decisionOrg = field
+		case 4:
+			fields.decision = field
+		case 5:
+			fields.blockedDate, _ = time.Parse(time.DateOnly, field)
+		}
+	}
+
+	if strings.TrimSpace(strings.Join(values, "")) == "" {
+		state.report.AddRejected(state.line, strings.Join(record, ";"), "empty record")
+		return
 	}
 
-	// Parse different types of entries
-	entries := strings.Split(urlField, "|")
-	for _, entry := range entries {
+	p.addRecordEntries(values, fields, state)
+}
+
+// addRecordEntries categorizes and adds the values listed in one record
+func (p *Parser) addRecordEntries(values []string, fields recordFields, state *ingestState) {
+	for position, entry := range values {
 		entry = strings.TrimSpace(entry)
 		if entry == "" {
 			continue
 		}
 
-		if err := p.categorizeEntry(entry, registry); err != nil {
-			// Continue processing even if one entry fails
+		registryEntry, err := p.categorizeEntry(entry)
+		if err != nil {
+			state.report.AddRejected(state.line, entry, err.Error())
 			continue
 		}
-	}
 
-	return nil
+		value := registryEntry.Value()
+		if index, ok := state.entryIndex(value); ok {
+			state.report.AddDuplicate(state.line, int(state.lines[index]), value)
+			// Keep the link from the later record to the entry it repeated
+			if state.registry.Entries[index].RecordID != fields.id {
+				state.registry.AddReference(index, fields.id)
+			}
+			continue
+		}
+
+		registryEntry.ID = fmt.Sprintf("%s-%d", fields.id, position+1)
+		registryEntry.RecordID = fields.id
+		registryEntry.BlockedDate = fields.blockedDate
+		registryEntry.Decision = fields.decision
+		registryEntry.DecisionOrg = fields.decisionOrg
+
+		if err := state.registry.AddEntry(registryEntry); err != nil {
+			state.report.AddRejected(state.line, entry, err.Error())
+			continue
+		}
+		state.markSeen(value)
+		state.report.AddAccepted(registryEntry.Type)
+	}
 }
 
-// categorizeEntry categorizes a single entry into the appropriate registry section
-func (p *Parser) categorizeEntry(entry string, registry *domain.Registry) error {
+// categorizeEntry classifies a single entry and builds the registry entry for it
+func (p *Parser) categorizeEntry(entry string) (*domain.RegistryEntry, error) {
 	originalEntry := entry
 	entry = strings.ToLower(entry)
 
@@ -232,12 +471,16 @@ func (p *Parser) categorizeEntry(entry string, registry *domain.Registry) error
 			blockingType = domain.BlockingTypeWildcard
 			value = entry
 		} else {
-			return fmt.Errorf("invalid wildcard format: %s", entry)
+			return nil, fmt.Errorf("invalid wildcard format: %s", entry)
 		}
 	} else if p.isIPAddress(entry) {
 		// Check for IP addresses
 		blockingType = domain.BlockingTypeIP
 		value = entry
+	} else if prefix, err := netip.ParsePrefix(entry); err == nil {
+		// Check for subnets before URLs, as both contain a slash
+		blockingType = domain.BlockingTypeSubnet
+		value = prefix.Masked().String()
 	} else if strings.Contains(entry, "/") {
 		// Check for URLs with paths
 		blockingType = domain.BlockingTypeURLPath
@@ -247,21 +490,16 @@ func (p *Parser) categorizeEntry(entry string, registry *domain.Registry) error
 		blockingType = domain.BlockingTypeDomain
 		value = entry
 	} else {
-		return fmt.Errorf("unrecognized entry format: %s", entry)
+		return nil, fmt.Errorf("unrecognized entry format: %s", entry)
 	}
 
 	// Create registry entry
 	registryEntry, err := domain.NewRegistryEntry(blockingType, value)
 	if err != nil {
-		return fmt.Errorf("creating registry entry for %s: %w", originalEntry, err)
+		return nil, fmt.Errorf("creating registry entry for %s: %w", originalEntry, err)
 	}
 
-	// Add additional context if available from CSV
-	if len(registry.Entries) > 0 {
-		registryEntry.ID = fmt.Sprintf("rkn_%d", len(registry.Entries))
-	}
-
-	return registry.AddEntry(registryEntry)
+	return registryEntry, nil
 }
 
 // stripProtocol removes protocol prefix from URL
@@ -289,11 +527,3 @@ func (p *Parser) isIPAddress(entry string) bool {
 	ip := net.ParseIP(entry)
 	return ip != nil
 }
-
-// min returns the minimum of two integers
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
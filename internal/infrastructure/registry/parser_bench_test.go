@@ -0,0 +1,138 @@
+package registry
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+	"sync"
+	"testing"
+	"time"
+)
+
+const benchmarkDumpRows = 2_000_000
+
+// syntheticDump generates a CSV dump row by row without materialising it
+type syntheticDump struct {
+	rows    int
+	next    int
+	pending []byte
+	size    int64
+}
+
+func newSyntheticDump(rows int) *syntheticDump {
+	return &syntheticDump{rows: rows, pending: []byte("id;url;date\n")}
+}
+
+func (d *syntheticDump) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(d.pending) == 0 {
+			if d.next >= d.rows {
+				break
+			}
+			d.pending = d.row(d.next)
+			d.next++
+		}
+		copied := copy(p[n:], d.pending)
+		d.pending = d.pending[copied:]
+		n += copied
+	}
+	d.size += int64(n)
+	if n == 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+func (d *syntheticDump) row(i int) []byte {
+	switch i % 4 {
+	case 0:
+		return fmt.Appendf(nil, "%d;*.wildcard%d.example.com;2023-01-01\n", i, i)
+	case 1:
+		return fmt.Appendf(nil, "%d;10.%d.%d.%d;2023-01-01\n", i, i>>16&0xff, i>>8&0xff, i&0xff)
+	default:
+		return fmt.Appendf(nil, "%d;https://blocked%d.example.com/path;2023-01-01\n", i, i)
+	}
+}
+
+// heapSampler records the peak live heap while running
+type heapSampler struct {
+	stop chan struct{}
+	done sync.WaitGroup
+	peak uint64
+}
+
+func startHeapSampler() *heapSampler {
+	s := &heapSampler{stop: make(chan struct{})}
+	s.done.Add(1)
+	go func() {
+		defer s.done.Done()
+		sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			metrics.Read(sample)
+			s.peak = max(s.peak, sample[0].Value.Uint64())
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return s
+}
+
+func (s *heapSampler) Stop() uint64 {
+	close(s.stop)
+	s.done.Wait()
+	return s.peak
+}
+
+func liveHeap() uint64 {
+	runtime.GC()
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+	return sample[0].Value.Uint64()
+}
+
+// BenchmarkParser_Parse_LargeDump parses a synthetic 2M-row dump and checks
+// that the transient heap stays well below the size of the dump itself
+func BenchmarkParser_Parse_LargeDump(b *testing.B) {
+	parser := NewParser()
+
+	// Collect aggressively so the sampled heap tracks live data rather than
+	// garbage waiting for the next cycle
+	defer debug.SetGCPercent(debug.SetGCPercent(10))
+
+	for i := 0; i < b.N; i++ {
+		baseline := liveHeap()
+		dump := newSyntheticDump(benchmarkDumpRows)
+
+		sampler := startHeapSampler()
+		registry, _, err := parser.Parse(dump)
+		peak := sampler.Stop()
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		retained := liveHeap() - baseline
+		overhead := int64(peak) - int64(baseline) - int64(retained)
+
+		const mb = 1 << 20
+		b.ReportMetric(float64(dump.size)/mb, "input-MB")
+		b.ReportMetric(float64(peak-baseline)/mb, "peak-heap-MB")
+		b.ReportMetric(float64(retained)/mb, "retained-MB")
+
+		if registry.Size() != benchmarkDumpRows {
+			b.Fatalf("expected %d entries, got %d", benchmarkDumpRows, registry.Size())
+		}
+		if overhead > dump.size {
+			b.Fatalf("parsing overhead %d MB exceeds input size %d MB", overhead/mb, dump.size/mb)
+		}
+
+		runtime.KeepAlive(registry)
+	}
+}
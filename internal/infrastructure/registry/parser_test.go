@@ -1,10 +1,20 @@
 package registry
 
 import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"hash/maphash"
 	"strings"
 	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/tracing"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/tracing/tracingtest"
 )
 
 func TestParser_detectFormat(t *testing.T) {
@@ -44,7 +54,7 @@ func TestParser_detectFormat(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			format, err := parser.detectFormat(tt.data)
+			format, err := parser.detectFormat(bufio.NewReader(bytes.NewReader(tt.data)))
 
 			if tt.wantErr && err == nil {
 				t.Error("expected error but got none")
@@ -59,7 +69,7 @@ func TestParser_detectFormat(t *testing.T) {
 	}
 }
 
-func TestParser_parseCSVText(t *testing.T) {
+func TestParser_parseCSVStream(t *testing.T) {
 	parser := NewParser()
 
 	csvData := `id;url;date
@@ -69,7 +79,7 @@ func TestParser_parseCSVText(t *testing.T) {
 4;blocked.com/path;2023-01-04
 5;https://secure.com;2023-01-05`
 
-	registry, err := parser.parseCSVText(csvData)
+	registry, err := parser.parseCSVStream(strings.NewReader(csvData), domain.NewIngestReport("csv"))
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -102,6 +112,85 @@ func TestParser_parseCSVText(t *testing.T) {
 	}
 }
 
+func TestParser_EntryIDs(t *testing.T) {
+	parser := NewParser()
+
+	csvData := `id;url;date
+17;example.com|*.example.org;2023-01-01
+;other.com;not-a-date`
+
+	registry, err := parser.parseCSVStream(strings.NewReader(csvData), domain.NewIngestReport("csv"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"17-1", "17-2", "3-1"}
+	if registry.Size() != len(expected) {
+		t.Fatalf("expected %d entries, got %d", len(expected), registry.Size())
+	}
+	for i, id := range expected {
+		if registry.Entries[i].ID != id {
+			t.Errorf("entry %d: expected ID %s, got %s", i, id, registry.Entries[i].ID)
+		}
+	}
+
+	if want := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC); !registry.Entries[0].BlockedDate.Equal(want) {
+		t.Errorf("expected blocked date %v, got %v", want, registry.Entries[0].BlockedDate)
+	}
+	if !registry.Entries[2].BlockedDate.IsZero() {
+		t.Errorf("expected no blocked date for an unparseable date, got %v", registry.Entries[2].BlockedDate)
+	}
+}
+
+func TestParser_DumpLayout(t *testing.T) {
+	parser := NewParser()
+
+	csvData := `Updated: 2024-05-01 10:00:00 +0000
+1.1.1.1 | 2.2.2.0/24;example.com;http://example.com/page;Court;2-123/2024;2024-04-01
+1.1.1.1;other.com;;FNS;7-1;2024-04-02
+3.3.3.3;;;Agency;9;2024-04-03`
+
+	registry, err := parser.parseCSVStream(strings.NewReader(csvData), domain.NewIngestReport("csv"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	type expectation struct {
+		id       string
+		record   string
+		kind     domain.BlockingType
+		value    string
+		decision string
+	}
+	expected := []expectation{
+		{"2-1", "2", domain.BlockingTypeIP, "1.1.1.1", "2-123/2024"},
+		{"2-2", "2", domain.BlockingTypeSubnet, "2.2.2.0/24", "2-123/2024"},
+		{"2-3", "2", domain.BlockingTypeDomain, "example.com", "2-123/2024"},
+		{"2-4", "2", domain.BlockingTypeURLPath, "example.com/page", "2-123/2024"},
+		{"3-2", "3", domain.BlockingTypeDomain, "other.com", "7-1"},
+		{"4-1", "4", domain.BlockingTypeIP, "3.3.3.3", "9"},
+	}
+	if registry.Size() != len(expected) {
+		t.Fatalf("expected %d entries, got %d", len(expected), registry.Size())
+	}
+	for i, want := range expected {
+		entry := registry.Entries[i]
+		if entry.ID != want.id || entry.RecordID != want.record || entry.Type != want.kind ||
+			entry.Value() != want.value || entry.Decision != want.decision {
+			t.Errorf("entry %d: expected %+v, got %s %s %s %s %s", i, want, entry.ID, entry.RecordID, entry.Type, entry.Value(), entry.Decision)
+		}
+	}
+
+	if entry := registry.Entries[0]; entry.DecisionOrg != "Court" || entry.BlockedDate.IsZero() {
+		t.Errorf("expected decision org and date from the record, got %q %v", entry.DecisionOrg, entry.BlockedDate)
+	}
+
+	// The second record repeats 1.1.1.1, which must stay linked to it
+	if len(registry.References) != 1 || registry.References[0] != (domain.RecordReference{Entry: 0, RecordID: "3"}) {
+		t.Errorf("expected a reference from record 3 to entry 0, got %+v", registry.References)
+	}
+}
+
 func TestParser_categorizeEntry(t *testing.T) {
 	parser := NewParser()
 	registry := domain.NewRegistry()
@@ -136,6 +225,12 @@ func TestParser_categorizeEntry(t *testing.T) {
 			expectedType: domain.BlockingTypeIP,
 			expectError:  false,
 		},
+		{
+			name:         "Subnet entry",
+			entry:        "10.0.0.0/8",
+			expectedType: domain.BlockingTypeSubnet,
+			expectError:  false,
+		},
 		{
 			name:         "URL path entry",
 			entry:        "example.com/blocked/path",
@@ -159,7 +254,10 @@ func TestParser_categorizeEntry(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			initialSize := registry.Size()
-			err := parser.categorizeEntry(tt.entry, registry)
+			entry, err := parser.categorizeEntry(tt.entry)
+			if err == nil {
+				err = registry.AddEntry(entry)
+			}
 
 			if tt.expectError && err == nil {
 				t.Error("expected error but got none")
@@ -239,7 +337,7 @@ func TestParser_isIPAddress(t *testing.T) {
 	}
 }
 
-func TestParser_parseCSVText_EmptyData(t *testing.T) {
+func TestParser_parseCSVStream_EmptyData(t *testing.T) {
 	parser := NewParser()
 
 	tests := []struct {
@@ -266,7 +364,7 @@ func TestParser_parseCSVText_EmptyData(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := parser.parseCSVText(tt.csvData)
+			_, err := parser.parseCSVStream(strings.NewReader(tt.csvData), domain.NewIngestReport("csv"))
 			if tt.wantErr && err == nil {
 				t.Error("expected error but got none")
 			}
@@ -277,14 +375,14 @@ func TestParser_parseCSVText_EmptyData(t *testing.T) {
 	}
 }
 
-func TestParser_parseCSVText_MultipleEntries(t *testing.T) {
+func TestParser_parseCSVStream_MultipleEntries(t *testing.T) {
 	parser := NewParser()
 
 	csvData := `id;url;date
 1;example1.com|example2.com;2023-01-01
 2;*.wildcard.com|blocked.com;2023-01-02`
 
-	registry, err := parser.parseCSVText(csvData)
+	registry, err := parser.parseCSVStream(strings.NewReader(csvData), domain.NewIngestReport("csv"))
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -295,7 +393,7 @@ func TestParser_parseCSVText_MultipleEntries(t *testing.T) {
 	}
 }
 
-func BenchmarkParser_parseCSVText(b *testing.B) {
+func BenchmarkParser_parseCSVStream(b *testing.B) {
 	parser := NewParser()
 
 	// Generate test data
@@ -311,9 +409,146 @@ func BenchmarkParser_parseCSVText(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := parser.parseCSVText(data)
+		_, err := parser.parseCSVStream(strings.NewReader(data), domain.NewIngestReport("csv"))
 		if err != nil {
 			b.Fatal(err)
 		}
 	}
 }
+
+func TestParser_Parse_IngestReport(t *testing.T) {
+	parser := NewParser()
+
+	csvData := `id;url;date
+1;example.com|*.wild.com;2023-01-01
+2;192.168.1.1;2023-01-02
+3;invalid..domain;2023-01-03
+4;example.com;2023-01-04
+5;;2023-01-05
+6;blocked.com/path;2023-01-06`
+
+	registry, report, err := parser.Parse(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.Format != "csv" || report.Encoding != encodingUTF8 {
+		t.Errorf("unexpected format/encoding: %s/%s", report.Format, report.Encoding)
+	}
+	if report.RowsRead != 6 {
+		t.Errorf("expected 6 rows read, got %d", report.RowsRead)
+	}
+	if report.Accepted != 4 || registry.Size() != 4 {
+		t.Errorf("expected 4 accepted entries, got %d (registry %d)", report.Accepted, registry.Size())
+	}
+
+	expectedCounts := map[domain.BlockingType]int{
+		domain.BlockingTypeDomain:   1,
+		domain.BlockingTypeWildcard: 1,
+		domain.BlockingTypeIP:       1,
+		domain.BlockingTypeURLPath:  1,
+	}
+	for blockingType, count := range expectedCounts {
+		if report.CountsByType[blockingType] != count {
+			t.Errorf("expected %d %s entries, got %d", count, blockingType, report.CountsByType[blockingType])
+		}
+	}
+
+	if report.RejectedCount != 2 || len(report.Rejected) != 2 {
+		t.Fatalf("expected 2 rejected entries, got %+v", report.Rejected)
+	}
+	if rejected := report.Rejected[0]; rejected.Line != 4 || rejected.Value != "invalid..domain" || rejected.Reason == "" {
+		t.Errorf("unexpected rejected entry: %+v", rejected)
+	}
+	if rejected := report.Rejected[1]; rejected.Line != 6 || rejected.Reason != "empty URL field" {
+		t.Errorf("unexpected rejected entry: %+v", rejected)
+	}
+
+	if report.DuplicateCount != 1 {
+		t.Fatalf("expected 1 duplicate, got %d", report.DuplicateCount)
+	}
+	if duplicate := report.Duplicates[0]; duplicate.Line != 5 || duplicate.FirstLine != 2 || duplicate.Value != "example.com" {
+		t.Errorf("unexpected duplicate entry: %+v", duplicate)
+	}
+}
+
+func TestParser_ParseContext_Spans(t *testing.T) {
+	exporter := tracingtest.Exporter(t)
+	parser := NewParser()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	member, _ := w.Create("dump.csv")
+	member.Write([]byte("id;url;date\n1;example.com;2023-01-01\n2;invalid..domain;2023-01-02\n"))
+	w.Close()
+
+	if _, _, err := parser.ParseContext(context.Background(), &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	unzip := tracingtest.Find(spans, "unzip")
+	parse := tracingtest.Find(spans, "parse")
+	if unzip == nil || parse == nil {
+		t.Fatalf("expected unzip and parse spans, got %d spans", len(spans))
+	}
+	if parse.Parent.SpanID() != unzip.SpanContext.SpanID() {
+		t.Error("expected the member parse under the unzip span")
+	}
+
+	attrs := attribute.NewSet(parse.Attributes...)
+	if v, _ := attrs.Value(tracing.AcceptedKey); v.AsInt64() != 1 {
+		t.Errorf("expected 1 accepted entry, got %d", v.AsInt64())
+	}
+	if v, _ := attrs.Value(tracing.RejectedKey); v.AsInt64() != 1 {
+		t.Errorf("expected 1 rejected entry, got %d", v.AsInt64())
+	}
+}
+
+func TestParser_Parse_IngestReportEncoding(t *testing.T) {
+	parser := NewParser()
+
+	// "Решение" in Windows-1251 followed by a valid entry
+	csvData := []byte("id;url;date;\xd0\xe5\xf8\xe5\xed\xe8\xe5\n1;example.com;2023-01-01;x\n")
+
+	_, report, err := parser.Parse(bytes.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Encoding != encodingWindows1251 {
+		t.Errorf("expected %s, got %s", encodingWindows1251, report.Encoding)
+	}
+}
+
+func TestIngestState_HashCollision(t *testing.T) {
+	state := &ingestState{
+		registry: domain.NewRegistry(),
+		report:   domain.NewIngestReport("csv"),
+		seed:     maphash.MakeSeed(),
+		seen:     make(map[uint32]int32),
+		collided: make(map[string]int32),
+	}
+
+	add := func(line int, value string) {
+		entry, _ := domain.NewRegistryEntry(domain.BlockingTypeDomain, value)
+		state.registry.AddEntry(entry)
+		state.line = line
+		state.markSeen(value)
+	}
+
+	add(2, "first.com")
+
+	// Pretend second.com hashes to the slot taken by first.com
+	state.seen[state.hash("second.com")] = 0
+	if _, ok := state.firstSeen("second.com"); ok {
+		t.Fatal("colliding value must not be reported as seen")
+	}
+
+	add(3, "second.com")
+	if line, ok := state.firstSeen("second.com"); !ok || line != 3 {
+		t.Errorf("expected second.com first seen on line 3, got %d (%v)", line, ok)
+	}
+	if line, ok := state.firstSeen("first.com"); !ok || line != 2 {
+		t.Errorf("expected first.com first seen on line 2, got %d (%v)", line, ok)
+	}
+}
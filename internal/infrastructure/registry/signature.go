@@ -0,0 +1,432 @@
+package registry
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// SignatureSuffix is the file name suffix of detached signatures shipped next
+// to dump members (dump.xml -> dump.xml.sig)
+const SignatureSuffix = ".sig"
+
+var (
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidData          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidSigningTime   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+
+	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+
+	oidRSAEncryption   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidSHA256WithRSA   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}
+	oidSHA384WithRSA   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 12}
+	oidSHA512WithRSA   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 13}
+	oidECPublicKey     = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidECDSAWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	oidECDSAWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 3}
+	oidECDSAWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 4}
+)
+
+// signerKeyUsages are the extended key usages accepted for dump signers:
+// email protection is the usage of certificates signing CMS content.
+// Certificates without extended key usages are accepted for any usage.
+var signerKeyUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageEmailProtection}
+
+// CMS (RFC 5652) structures needed to verify a detached SignedData blob
+type cmsContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+
+type cmsSignedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	EncapContentInfo cmsContentInfo
+	Certificates     asn1.RawValue   `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue   `asn1:"optional,tag:1"`
+	SignerInfos      []cmsSignerInfo `asn1:"set"`
+}
+
+type cmsSignerInfo struct {
+	Version            int
+	SID                asn1.RawValue
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+	UnsignedAttrs      asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type cmsIssuerAndSerial struct {
+	Issuer asn1.RawValue
+	Serial *big.Int
+}
+
+type cmsAttribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+// SignatureInfo describes a successfully verified detached signature
+type SignatureInfo struct {
+	Member   string
+	Signer   string
+	Serial   string
+	SignedAt time.Time
+}
+
+// SignatureVerifier checks detached CMS (PKCS#7) signatures shipped alongside
+// registry dumps against a bundle of trusted certificates.
+//
+// Only RSA PKCS#1 v1.5 and ECDSA signatures with SHA-2 digests are supported;
+// any other algorithm is rejected with ErrSignatureAlgorithmUnsupported so an
+// unverifiable dump is never treated as authentic.
+type SignatureVerifier struct {
+	roots *x509.CertPool
+	now   func() time.Time
+}
+
+// NewSignatureVerifier creates a verifier trusting the PEM certificates in the
+// given bundle file
+func NewSignatureVerifier(trustBundlePath string) (*SignatureVerifier, error) {
+	data, err := os.ReadFile(trustBundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading trust bundle: %w", err)
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("trust bundle %s contains no PEM certificates", trustBundlePath)
+	}
+
+	return newSignatureVerifier(roots), nil
+}
+
+func newSignatureVerifier(roots *x509.CertPool) *SignatureVerifier {
+	return &SignatureVerifier{
+		roots: roots,
+		now:   time.Now,
+	}
+}
+
+// VerifyArchive verifies every data member of a ZIP dump against its
+// "<member>.sig" sibling. Data that is not a ZIP archive carries no detached
+// signature and is rejected.
+func (v *SignatureVerifier) VerifyArchive(ra io.ReaderAt, size int64) ([]SignatureInfo, error) {
+	reader, err := zip.NewReader(ra, size)
+	if err != nil {
+		return nil, NewSignatureError("", fmt.Errorf("%w: dump is not a signed archive", ErrSignatureMissing))
+	}
+
+	members := make(map[string]*zip.File, len(reader.File))
+	for _, file := range reader.File {
+		members[file.Name] = file
+	}
+
+	var infos []SignatureInfo
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() || strings.HasSuffix(file.Name, SignatureSuffix) {
+			continue
+		}
+
+		sigFile, ok := members[file.Name+SignatureSuffix]
+		if !ok {
+			return nil, NewSignatureError(file.Name, ErrSignatureMissing)
+		}
+
+		info, err := v.verifyMember(file, sigFile)
+		if err != nil {
+			return nil, NewSignatureError(file.Name, err)
+		}
+		info.Member = file.Name
+		infos = append(infos, *info)
+	}
+
+	if len(infos) == 0 {
+		return nil, NewSignatureError("", fmt.Errorf("%w: archive contains no signed members", ErrSignatureMissing))
+	}
+
+	return infos, nil
+}
+
+// verifyMember verifies a single archive member against its signature member
+func (v *SignatureVerifier) verifyMember(file, sigFile *zip.File) (*SignatureInfo, error) {
+	sigReader, err := sigFile.Open()
+	if err != nil {
+		return nil, fmt.Errorf("opening signature: %w", err)
+	}
+	signature, err := io.ReadAll(sigReader)
+	sigReader.Close()
+	if err != nil {
+		return nil, fmt.Errorf("reading signature: %w", err)
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("opening member: %w", err)
+	}
+	defer content.Close()
+
+	return v.Verify(content, signature)
+}
+
+// Verify checks a detached CMS signature over content. The content is
+// streamed through the digest once and never buffered.
+func (v *SignatureVerifier) Verify(content io.Reader, signature []byte) (*SignatureInfo, error) {
+	sd, err := parseSignedData(signature)
+	if err != nil {
+		return nil, err
+	}
+
+	certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+	if err != nil || len(certs) == 0 {
+		return nil, fmt.Errorf("%w: embedded certificates are missing or invalid", ErrSignatureMalformed)
+	}
+
+	// Digest the content once for every algorithm the signers use
+	digests := make(map[crypto.Hash]hash.Hash)
+	writers := make([]io.Writer, 0, len(sd.SignerInfos))
+	for _, si := range sd.SignerInfos {
+		h, err := hashForOID(si.DigestAlgorithm.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := digests[h]; !ok {
+			digests[h] = h.New()
+			writers = append(writers, digests[h])
+		}
+	}
+
+	if _, err := io.Copy(io.MultiWriter(writers...), content); err != nil {
+		return nil, fmt.Errorf("reading signed content: %w", err)
+	}
+
+	var info *SignatureInfo
+	for _, si := range sd.SignerInfos {
+		h, _ := hashForOID(si.DigestAlgorithm.Algorithm)
+		signerInfo, err := v.verifySigner(si, h, digests[h].Sum(nil), certs)
+		if err != nil {
+			return nil, err
+		}
+		if info == nil {
+			info = signerInfo
+		}
+	}
+
+	return info, nil
+}
+
+// verifySigner checks one SignerInfo against the content digest and validates
+// the signer certificate chain against the trust bundle
+func (v *SignatureVerifier) verifySigner(si cmsSignerInfo, h crypto.Hash, contentDigest []byte, certs []*x509.Certificate) (*SignatureInfo, error) {
+	signer, err := findSigner(si.SID, certs)
+	if err != nil {
+		return nil, err
+	}
+
+	signedDigest := contentDigest
+	var signedAt time.Time
+
+	if len(si.SignedAttrs.FullBytes) > 0 {
+		attrs, err := parseAttributes(si.SignedAttrs.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		var messageDigest []byte
+		for _, attr := range attrs {
+			switch {
+			case attr.Type.Equal(oidMessageDigest):
+				if _, err := asn1.Unmarshal(attr.Values.Bytes, &messageDigest); err != nil {
+					return nil, fmt.Errorf("%w: invalid messageDigest attribute", ErrSignatureMalformed)
+				}
+			case attr.Type.Equal(oidContentType):
+				var contentType asn1.ObjectIdentifier
+				if _, err := asn1.Unmarshal(attr.Values.Bytes, &contentType); err != nil || !contentType.Equal(oidData) {
+					return nil, fmt.Errorf("%w: unexpected signed content type", ErrSignatureMalformed)
+				}
+			case attr.Type.Equal(oidSigningTime):
+				if _, err := asn1.Unmarshal(attr.Values.Bytes, &signedAt); err != nil {
+					return nil, fmt.Errorf("%w: invalid signingTime attribute", ErrSignatureMalformed)
+				}
+			}
+		}
+
+		if !bytes.Equal(messageDigest, contentDigest) {
+			return nil, ErrSignatureDigestMismatch
+		}
+
+		// Signed attributes are signed as an explicit SET OF, not as [0]
+		encoded := append([]byte{}, si.SignedAttrs.FullBytes...)
+		encoded[0] = 0x31
+		attrsHash := h.New()
+		attrsHash.Write(encoded)
+		signedDigest = attrsHash.Sum(nil)
+	}
+
+	if err := verifyDigestSignature(signer.PublicKey, si.SignatureAlgorithm.Algorithm, h, signedDigest, si.Signature); err != nil {
+		return nil, err
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs {
+		if cert != signer {
+			intermediates.AddCert(cert)
+		}
+	}
+
+	if signer.KeyUsage != 0 && signer.KeyUsage&(x509.KeyUsageDigitalSignature|x509.KeyUsageContentCommitment) == 0 {
+		return nil, fmt.Errorf("%w: certificate is not allowed to sign", ErrSignatureUntrustedSigner)
+	}
+
+	// The chain is validated now rather than at signingTime, which the signer
+	// chooses and could backdate into the validity of an expired certificate
+	if _, err := signer.Verify(x509.VerifyOptions{
+		Roots:         v.roots,
+		Intermediates: intermediates,
+		CurrentTime:   v.now(),
+		KeyUsages:     signerKeyUsages,
+	}); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSignatureUntrustedSigner, err)
+	}
+
+	return &SignatureInfo{
+		Signer:   signer.Subject.String(),
+		Serial:   signer.SerialNumber.String(),
+		SignedAt: signedAt,
+	}, nil
+}
+
+// parseSignedData decodes a DER or PEM encoded CMS SignedData blob
+func parseSignedData(signature []byte) (*cmsSignedData, error) {
+	if len(signature) == 0 {
+		return nil, ErrSignatureMissing
+	}
+
+	if block, _ := pem.Decode(signature); block != nil {
+		signature = block.Bytes
+	}
+
+	var ci cmsContentInfo
+	if _, err := asn1.Unmarshal(signature, &ci); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSignatureMalformed, err)
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, fmt.Errorf("%w: content type %s is not signedData", ErrSignatureMalformed, ci.ContentType)
+	}
+
+	var sd cmsSignedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSignatureMalformed, err)
+	}
+
+	if len(sd.EncapContentInfo.Content.Bytes) > 0 {
+		return nil, fmt.Errorf("%w: signature embeds its content instead of being detached", ErrSignatureMalformed)
+	}
+	if len(sd.SignerInfos) == 0 {
+		return nil, fmt.Errorf("%w: no signer information", ErrSignatureMalformed)
+	}
+
+	return &sd, nil
+}
+
+// parseAttributes decodes the contents of a SignedAttributes SET
+func parseAttributes(contents []byte) ([]cmsAttribute, error) {
+	var attrs []cmsAttribute
+	for rest := contents; len(rest) > 0; {
+		var attr cmsAttribute
+		var err error
+		rest, err = asn1.Unmarshal(rest, &attr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid signed attributes", ErrSignatureMalformed)
+		}
+		attrs = append(attrs, attr)
+	}
+	return attrs, nil
+}
+
+// findSigner locates the certificate referenced by a SignerIdentifier
+func findSigner(sid asn1.RawValue, certs []*x509.Certificate) (*x509.Certificate, error) {
+	// subjectKeyIdentifier [0] IMPLICIT OCTET STRING
+	if sid.Class == asn1.ClassContextSpecific && sid.Tag == 0 {
+		for _, cert := range certs {
+			if bytes.Equal(cert.SubjectKeyId, sid.Bytes) {
+				return cert, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: signer certificate not included", ErrSignatureMalformed)
+	}
+
+	var ias cmsIssuerAndSerial
+	if _, err := asn1.Unmarshal(sid.FullBytes, &ias); err != nil {
+		return nil, fmt.Errorf("%w: invalid signer identifier", ErrSignatureMalformed)
+	}
+
+	for _, cert := range certs {
+		if cert.SerialNumber.Cmp(ias.Serial) == 0 && bytes.Equal(cert.RawIssuer, ias.Issuer.FullBytes) {
+			return cert, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: signer certificate not included", ErrSignatureMalformed)
+}
+
+// verifyDigestSignature checks a signature over a precomputed digest
+func verifyDigestSignature(pub crypto.PublicKey, sigAlg asn1.ObjectIdentifier, h crypto.Hash, digest, signature []byte) error {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		if !sigAlg.Equal(oidRSAEncryption) && !sigAlg.Equal(oidSHA256WithRSA) &&
+			!sigAlg.Equal(oidSHA384WithRSA) && !sigAlg.Equal(oidSHA512WithRSA) {
+			return fmt.Errorf("%w: %s", ErrSignatureAlgorithmUnsupported, sigAlg)
+		}
+		if err := rsa.VerifyPKCS1v15(key, h, digest, signature); err != nil {
+			return fmt.Errorf("%w: %v", ErrSignatureDigestMismatch, err)
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		if !sigAlg.Equal(oidECPublicKey) && !sigAlg.Equal(oidECDSAWithSHA256) &&
+			!sigAlg.Equal(oidECDSAWithSHA384) && !sigAlg.Equal(oidECDSAWithSHA512) {
+			return fmt.Errorf("%w: %s", ErrSignatureAlgorithmUnsupported, sigAlg)
+		}
+		if !ecdsa.VerifyASN1(key, digest, signature) {
+			return ErrSignatureDigestMismatch
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: public key type %T", ErrSignatureAlgorithmUnsupported, pub)
+	}
+}
+
+// hashForOID maps a digest algorithm identifier to a crypto.Hash
+func hashForOID(oid asn1.ObjectIdentifier) (crypto.Hash, error) {
+	switch {
+	case oid.Equal(oidSHA256):
+		return crypto.SHA256, nil
+	case oid.Equal(oidSHA384):
+		return crypto.SHA384, nil
+	case oid.Equal(oidSHA512):
+		return crypto.SHA512, nil
+	default:
+		return 0, fmt.Errorf("%w: digest %s", ErrSignatureAlgorithmUnsupported, oid)
+	}
+}
@@ -0,0 +1,394 @@
+package registry
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// testSigner holds a CA and a leaf certificate issued by it
+type testSigner struct {
+	ca      *x509.Certificate
+	leaf    *x509.Certificate
+	leafKey *ecdsa.PrivateKey
+
+	// signedAt is the signingTime attribute of signatures, now when zero
+	signedAt time.Time
+}
+
+func newTestSigner(t testing.TB, commonName string) *testSigner {
+	t.Helper()
+	return newTestSignerWithLeaf(t, commonName, nil)
+}
+
+// newTestSignerWithLeaf creates a signer whose leaf template is adjusted by
+// customize before it is issued
+func newTestSignerWithLeaf(t testing.TB, commonName string, customize func(*x509.Certificate)) *testSigner {
+	t.Helper()
+
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName + " CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("creating CA: %v", err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	leafKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	if customize != nil {
+		customize(leafTemplate)
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, ca, &leafKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("creating leaf: %v", err)
+	}
+	leaf, _ := x509.ParseCertificate(leafDER)
+
+	return &testSigner{ca: ca, leaf: leaf, leafKey: leafKey}
+}
+
+// sign produces a detached CMS SignedData blob over content
+func (s *testSigner) sign(t testing.TB, content []byte) []byte {
+	t.Helper()
+
+	digest := sha256.Sum256(content)
+	attr := func(oid asn1.ObjectIdentifier, value interface{}) cmsAttribute {
+		encoded, err := asn1.Marshal(value)
+		if err != nil {
+			t.Fatalf("marshaling attribute: %v", err)
+		}
+		return cmsAttribute{
+			Type:   oid,
+			Values: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: encoded},
+		}
+	}
+	signedAt := s.signedAt
+	if signedAt.IsZero() {
+		signedAt = time.Now()
+	}
+	attrs := []cmsAttribute{
+		attr(oidContentType, oidData),
+		attr(oidMessageDigest, digest[:]),
+		attr(oidSigningTime, signedAt.UTC()),
+	}
+	attrsSet, err := asn1.MarshalWithParams(attrs, "set")
+	if err != nil {
+		t.Fatalf("marshaling attributes: %v", err)
+	}
+	attrsDigest := sha256.Sum256(attrsSet)
+	signature, err := ecdsa.SignASN1(rand.Reader, s.leafKey, attrsDigest[:])
+	if err != nil {
+		t.Fatalf("signing: %v", err)
+	}
+
+	var setContents asn1.RawValue
+	asn1.Unmarshal(attrsSet, &setContents)
+
+	sid, _ := asn1.Marshal(cmsIssuerAndSerial{
+		Issuer: asn1.RawValue{FullBytes: s.leaf.RawIssuer},
+		Serial: s.leaf.SerialNumber,
+	})
+
+	sd := cmsSignedData{
+		Version:          1,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{{Algorithm: oidSHA256}},
+		EncapContentInfo: cmsContentInfo{ContentType: oidData},
+		Certificates: asn1.RawValue{
+			Class:      asn1.ClassContextSpecific,
+			Tag:        0,
+			IsCompound: true,
+			Bytes:      append(append([]byte{}, s.leaf.Raw...), s.ca.Raw...),
+		},
+		SignerInfos: []cmsSignerInfo{{
+			Version:            1,
+			SID:                asn1.RawValue{FullBytes: sid},
+			DigestAlgorithm:    pkix.AlgorithmIdentifier{Algorithm: oidSHA256},
+			SignedAttrs:        asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: setContents.Bytes},
+			SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidECDSAWithSHA256},
+			Signature:          signature,
+		}},
+	}
+	sdDER, err := asn1.Marshal(sd)
+	if err != nil {
+		t.Fatalf("marshaling signed data: %v", err)
+	}
+
+	blob, err := asn1.Marshal(cmsContentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sdDER},
+	})
+	if err != nil {
+		t.Fatalf("marshaling content info: %v", err)
+	}
+	return blob
+}
+
+// writeTrustBundle writes the signer's CA to a PEM file and returns its path
+func (s *testSigner) writeTrustBundle(t testing.TB) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "trust.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.ca.Raw})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("writing trust bundle: %v", err)
+	}
+	return path
+}
+
+// buildArchive creates a ZIP archive from name/content pairs
+func buildArchive(t testing.TB, members map[string][]byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range members {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("creating member: %v", err)
+		}
+		f.Write(content)
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestSignatureVerifier_VerifyArchive(t *testing.T) {
+	signer := newTestSigner(t, "RKN Test Signer")
+	verifier, err := NewSignatureVerifier(signer.writeTrustBundle(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dump := []byte("id;url;date\n1;example.com;2023-01-01\n")
+	other := newTestSigner(t, "Impostor")
+
+	tests := []struct {
+		name    string
+		members map[string][]byte
+		wantErr error
+	}{
+		{
+			name: "valid signature",
+			members: map[string][]byte{
+				"dump.csv":     dump,
+				"dump.csv.sig": signer.sign(t, dump),
+			},
+		},
+		{
+			name: "tampered content",
+			members: map[string][]byte{
+				"dump.csv":     []byte("id;url;date\n1;example.com;2023-01-01\n2;evil.com;2023-01-02\n"),
+				"dump.csv.sig": signer.sign(t, dump),
+			},
+			wantErr: ErrSignatureDigestMismatch,
+		},
+		{
+			name: "missing signature",
+			members: map[string][]byte{
+				"dump.csv": dump,
+			},
+			wantErr: ErrSignatureMissing,
+		},
+		{
+			name: "untrusted signer",
+			members: map[string][]byte{
+				"dump.csv":     dump,
+				"dump.csv.sig": other.sign(t, dump),
+			},
+			wantErr: ErrSignatureUntrustedSigner,
+		},
+		{
+			name: "garbage signature",
+			members: map[string][]byte{
+				"dump.csv":     dump,
+				"dump.csv.sig": []byte("not a signature"),
+			},
+			wantErr: ErrSignatureMalformed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := buildArchive(t, tt.members)
+			infos, err := verifier.VerifyArchive(bytes.NewReader(archive), int64(len(archive)))
+
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(infos) != 1 || infos[0].Member != "dump.csv" {
+					t.Errorf("unexpected signature info: %+v", infos)
+				}
+				if infos[0].SignedAt.IsZero() {
+					t.Error("expected signing time to be reported")
+				}
+				return
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+			if !errors.Is(err, domain.ErrRegistrySignatureInvalid) {
+				t.Errorf("expected error to match domain.ErrRegistrySignatureInvalid, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSignatureVerifier_IgnoresBackdatedSigningTime(t *testing.T) {
+	signer := newTestSigner(t, "RKN Test Signer")
+	// Signed by an expired certificate, claiming a time it was still valid
+	signer.signedAt = time.Now().Add(-30 * time.Minute)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(signer.ca)
+	verifier := newSignatureVerifier(roots)
+	verifier.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+
+	dump := []byte("id;url;date\n1;example.com;2023-01-01\n")
+	_, err := verifier.Verify(bytes.NewReader(dump), signer.sign(t, dump))
+	if !errors.Is(err, ErrSignatureUntrustedSigner) {
+		t.Errorf("expected %v, got %v", ErrSignatureUntrustedSigner, err)
+	}
+}
+
+func TestSignatureVerifier_RejectsCertificatesNotForSigning(t *testing.T) {
+	tests := []struct {
+		name      string
+		customize func(*x509.Certificate)
+		wantErr   bool
+	}{
+		{
+			name: "email protection",
+			customize: func(c *x509.Certificate) {
+				c.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageEmailProtection, x509.ExtKeyUsageClientAuth}
+			},
+		},
+		{
+			name: "server authentication only",
+			customize: func(c *x509.Certificate) {
+				c.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+			},
+			wantErr: true,
+		},
+		{
+			name: "key encipherment only",
+			customize: func(c *x509.Certificate) {
+				c.KeyUsage = x509.KeyUsageKeyEncipherment
+			},
+			wantErr: true,
+		},
+	}
+
+	dump := []byte("id;url;date\n1;example.com;2023-01-01\n")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := newTestSignerWithLeaf(t, "RKN Test Signer", tt.customize)
+			verifier, err := NewSignatureVerifier(signer.writeTrustBundle(t))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			_, err = verifier.Verify(bytes.NewReader(dump), signer.sign(t, dump))
+			if tt.wantErr && !errors.Is(err, ErrSignatureUntrustedSigner) {
+				t.Errorf("expected %v, got %v", ErrSignatureUntrustedSigner, err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestSignatureVerifier_RejectsUnsignedCSV(t *testing.T) {
+	signer := newTestSigner(t, "RKN Test Signer")
+	verifier, err := NewSignatureVerifier(signer.writeTrustBundle(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data := []byte("id;url;date\n1;example.com;2023-01-01")
+	_, err = verifier.VerifyArchive(bytes.NewReader(data), int64(len(data)))
+	if !errors.Is(err, ErrSignatureMissing) {
+		t.Errorf("expected ErrSignatureMissing, got %v", err)
+	}
+}
+
+func TestNewSignatureVerifier_InvalidBundle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.pem")
+	os.WriteFile(path, []byte("no certificates here"), 0o600)
+
+	if _, err := NewSignatureVerifier(path); err == nil {
+		t.Error("expected error for bundle without certificates")
+	}
+
+	if _, err := NewSignatureVerifier(filepath.Join(t.TempDir(), "missing.pem")); err == nil {
+		t.Error("expected error for missing bundle")
+	}
+}
+
+func TestClient_FetchRegistry_RejectsInvalidSignature(t *testing.T) {
+	signer := newTestSigner(t, "RKN Test Signer")
+	verifier, _ := NewSignatureVerifier(signer.writeTrustBundle(t))
+
+	dump := []byte("id;url;date\n1;example.com;2023-01-01\n")
+	signed := buildArchive(t, map[string][]byte{
+		"dump.csv":     dump,
+		"dump.csv.sig": signer.sign(t, dump),
+	})
+	tampered := buildArchive(t, map[string][]byte{
+		"dump.csv":     []byte("id;url;date\n1;evil.com;2023-01-01\n"),
+		"dump.csv.sig": signer.sign(t, dump),
+	})
+
+	client := &Client{
+		sources:  []Source{&mockSource{name: "tampered", data: tampered, healthy: true}},
+		parser:   NewParser(),
+		verifier: verifier,
+		timeout:  30 * time.Second,
+	}
+
+	_, _, err := client.FetchRegistry(t.Context())
+	if !errors.Is(err, domain.ErrRegistrySignatureInvalid) {
+		t.Fatalf("expected signature error, got %v", err)
+	}
+
+	client.sources = []Source{&mockSource{name: "signed", data: signed, healthy: true}}
+	registry, _, err := client.FetchRegistry(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if registry.Size() != 1 {
+		t.Errorf("expected 1 entry, got %d", registry.Size())
+	}
+}
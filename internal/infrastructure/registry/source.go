@@ -2,13 +2,15 @@ package registry
 
 import (
 	"context"
+	"io"
 	"time"
 )
 
 // Source represents a registry data source (GitHub mirror, official API, etc.)
 type Source interface {
-	// Fetch retrieves raw registry data from the source
-	Fetch(ctx context.Context) ([]byte, error)
+	// Fetch opens a stream of raw registry data from the source. The caller
+	// must close the returned reader.
+	Fetch(ctx context.Context) (io.ReadCloser, error)
 
 	// Name returns a human-readable name for the source
 	Name() string
@@ -26,34 +28,50 @@ const (
 
 // SourceConfig holds configuration for a registry source
 type SourceConfig struct {
-	Type       SourceType
-	URL        string
-	Timeout    time.Duration
-	MaxRetries int
-	UserAgent  string
+	// Name tells sources apart in health reports and metrics; sources are
+	// named after their type when empty
+	Name       string        `yaml:"name"`
+	Type       SourceType    `yaml:"type"`
+	URL        string        `yaml:"url"`
+	Timeout    time.Duration `yaml:"timeout"`
+	MaxRetries int           `yaml:"max_retries"`
+	UserAgent  string        `yaml:"user_agent"`
 
 	// RKN API specific configuration
-	RKN RKNConfig `json:"rkn,omitempty"`
+	RKN RKNConfig `json:"rkn,omitempty" yaml:"rkn"`
+}
+
+// SourceName returns the name the source is reported under
+func (c SourceConfig) SourceName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	switch c.Type {
+	case SourceTypeOfficial:
+		return "Official RKN API"
+	default:
+		return string(c.Type)
+	}
 }
 
 // RKNConfig holds RKN API specific configuration
 type RKNConfig struct {
 	// Authentication files (paths or base64 encoded content)
-	RequestFilePath    string `json:"request_file_path,omitempty"`
-	SignatureFilePath  string `json:"signature_file_path,omitempty"`
-	EMCHDFilePath      string `json:"emchd_file_path,omitempty"`
-	EMCHDSignaturePath string `json:"emchd_signature_path,omitempty"`
+	RequestFilePath    string `json:"request_file_path,omitempty" yaml:"request_file_path"`
+	SignatureFilePath  string `json:"signature_file_path,omitempty" yaml:"signature_file_path"`
+	EMCHDFilePath      string `json:"emchd_file_path,omitempty" yaml:"emchd_file_path"`
+	EMCHDSignaturePath string `json:"emchd_signature_path,omitempty" yaml:"emchd_signature_path"`
 
 	// Request configuration
-	DumpFormatVersion string        `json:"dump_format_version,omitempty"`
-	PollInterval      time.Duration `json:"poll_interval,omitempty"`
-	MaxPollAttempts   int           `json:"max_poll_attempts,omitempty"`
+	DumpFormatVersion string        `json:"dump_format_version,omitempty" yaml:"dump_format_version"`
+	PollInterval      time.Duration `json:"poll_interval,omitempty" yaml:"poll_interval"`
+	MaxPollAttempts   int           `json:"max_poll_attempts,omitempty" yaml:"max_poll_attempts"`
 
 	// TLS configuration for client certificates
-	CertFilePath       string `json:"cert_file_path,omitempty"`
-	KeyFilePath        string `json:"key_file_path,omitempty"`
-	CAFilePath         string `json:"ca_file_path,omitempty"`
-	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
+	CertFilePath       string `json:"cert_file_path,omitempty" yaml:"cert_file_path"`
+	KeyFilePath        string `json:"key_file_path,omitempty" yaml:"key_file_path"`
+	CAFilePath         string `json:"ca_file_path,omitempty" yaml:"ca_file_path"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty" yaml:"insecure_skip_verify"`
 }
 
 // DefaultSourceConfigs returns default configurations for known sources
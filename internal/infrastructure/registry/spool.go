@@ -0,0 +1,66 @@
+package registry
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Spool is a temporary file holding a downloaded dump. It lets formats that
+// need random access (ZIP central directory, detached signatures) read the
+// dump more than once without keeping it in memory.
+type Spool struct {
+	file   *os.File
+	size   int64
+	reader *io.SectionReader
+}
+
+// NewSpool copies r into a new temporary file
+func NewSpool(r io.Reader) (*Spool, error) {
+	file, err := os.CreateTemp("", "rkn-dump-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating spool file: %w", err)
+	}
+
+	size, err := io.Copy(file, r)
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, fmt.Errorf("spooling dump: %w", err)
+	}
+
+	return &Spool{
+		file:   file,
+		size:   size,
+		reader: io.NewSectionReader(file, 0, size),
+	}, nil
+}
+
+// Read reads the spooled dump sequentially from the start
+func (s *Spool) Read(p []byte) (int, error) {
+	return s.reader.Read(p)
+}
+
+// ReadAt implements io.ReaderAt
+func (s *Spool) ReadAt(p []byte, off int64) (int, error) {
+	return s.file.ReadAt(p, off)
+}
+
+// Size returns the number of spooled bytes
+func (s *Spool) Size() int64 {
+	return s.size
+}
+
+// Reader returns a new sequential reader positioned at the start of the spool
+func (s *Spool) Reader() io.Reader {
+	return io.NewSectionReader(s.file, 0, s.size)
+}
+
+// Close closes and removes the spool file
+func (s *Spool) Close() error {
+	err := s.file.Close()
+	if removeErr := os.Remove(s.file.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
@@ -0,0 +1,220 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+const (
+	// DefaultBudget bounds the time a substring or regex search may scan for
+	DefaultBudget = 2 * time.Second
+
+	// MaxPatternLength bounds the length of a search pattern
+	MaxPatternLength = 256
+
+	// deadlineCheckInterval is how many entries are scanned between deadline checks
+	deadlineCheckInterval = 4096
+)
+
+// corpus is the searchable form of one registry version
+type corpus struct {
+	version string
+	entries []*domain.RegistryEntry
+	// keys holds the reversed host labels of every entry that has a host,
+	// sorted, with the entry each key belongs to in positions
+	keys      []string
+	positions []int32
+}
+
+// Index answers suffix, substring and regex searches over the current
+// registry. Suffix searches use a sorted index of reversed host labels and
+// never scan; substring and regex searches scan the patterns within a time
+// budget.
+type Index struct {
+	budget time.Duration
+	corpus atomic.Pointer[corpus]
+}
+
+// NewIndex creates an empty search index. A non-positive budget selects
+// DefaultBudget.
+func NewIndex(budget time.Duration) *Index {
+	if budget <= 0 {
+		budget = DefaultBudget
+	}
+
+	idx := &Index{budget: budget}
+	idx.corpus.Store(&corpus{})
+	return idx
+}
+
+// OnRegistryUpdate rebuilds the index for a newly applied registry
+func (idx *Index) OnRegistryUpdate(previous, current *domain.Registry, report *domain.IngestReport) {
+	start := time.Now()
+	idx.Build(current)
+	slog.Info("Search index built", "version", current.Version, "duration", time.Since(start))
+}
+
+// OnRegistryCleared empties the index
+func (idx *Index) OnRegistryCleared() {
+	idx.corpus.Store(&corpus{})
+}
+
+// Build replaces the indexed registry
+func (idx *Index) Build(registry *domain.Registry) {
+	c := &corpus{
+		version:   registry.Version,
+		entries:   registry.Entries,
+		keys:      make([]string, 0, len(registry.Entries)),
+		positions: make([]int32, 0, len(registry.Entries)),
+	}
+
+	for i, entry := range registry.Entries {
+		host := entryHost(entry)
+		if host == "" {
+			continue
+		}
+		c.keys = append(c.keys, reverseLabels(host))
+		c.positions = append(c.positions, int32(i))
+	}
+	sort.Sort(byKey{c})
+
+	idx.corpus.Store(c)
+}
+
+// Search returns the entries matching the query, up to its page size
+func (idx *Index) Search(ctx context.Context, query domain.SearchQuery) (*domain.SearchResult, error) {
+	// Patterns in the registry are lower case; regexes are left alone so
+	// classes such as \S keep their meaning
+	pattern := strings.TrimSpace(query.Pattern)
+	if query.Mode != domain.SearchModeRegex {
+		pattern = strings.ToLower(pattern)
+	}
+	if pattern == "" {
+		return nil, fmt.Errorf("%w: pattern is required", domain.ErrInvalidSearchQuery)
+	}
+	if len(pattern) > MaxPatternLength {
+		return nil, fmt.Errorf("%w: pattern is longer than %d characters", domain.ErrInvalidSearchQuery, MaxPatternLength)
+	}
+
+	c := idx.corpus.Load()
+	result := &domain.SearchResult{
+		Version: c.version,
+		Entries: make([]*domain.RegistryEntry, 0),
+	}
+	limit := query.PageSize()
+
+	switch query.Mode {
+	case domain.SearchModeSuffix:
+		c.suffix(pattern, limit, result)
+		return result, nil
+
+	case domain.SearchModeSubstring:
+		match := func(value string) bool { return strings.Contains(value, pattern) }
+		idx.scan(ctx, c, match, limit, result)
+		return result, nil
+
+	case domain.SearchModeRegex:
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidSearchQuery, err)
+		}
+		idx.scan(ctx, c, re.MatchString, limit, result)
+		return result, nil
+
+	default:
+		return nil, fmt.Errorf("%w: unknown search mode", domain.ErrInvalidSearchQuery)
+	}
+}
+
+// suffix collects the entries at or below a domain from the sorted keys
+func (c *corpus) suffix(pattern string, limit int, result *domain.SearchResult) {
+	prefix := reverseLabels(strings.TrimPrefix(pattern, "*."))
+
+	i := sort.SearchStrings(c.keys, prefix)
+	for ; i < len(c.keys) && strings.HasPrefix(c.keys[i], prefix); i++ {
+		if len(result.Entries) == limit {
+			result.Truncated = true
+			return
+		}
+		result.Entries = append(result.Entries, c.entries[c.positions[i]])
+	}
+}
+
+// scan tests every pattern in registry order until the limit or the time
+// budget is reached
+func (idx *Index) scan(ctx context.Context, c *corpus, match func(string) bool, limit int, result *domain.SearchResult) {
+	ctx, cancel := context.WithTimeout(ctx, idx.budget)
+	defer cancel()
+
+	for i, entry := range c.entries {
+		if i%deadlineCheckInterval == 0 && ctx.Err() != nil {
+			result.TimedOut = true
+			return
+		}
+		if !match(entry.Value()) {
+			continue
+		}
+		if len(result.Entries) == limit {
+			result.Truncated = true
+			return
+		}
+		result.Entries = append(result.Entries, entry)
+	}
+}
+
+// entryHost returns the host an entry blocks, without any wildcard prefix.
+// IP entries have no host.
+func entryHost(entry *domain.RegistryEntry) string {
+	switch entry.Type {
+	case domain.BlockingTypeDomain, domain.BlockingTypeSNI:
+		return entry.Domain
+	case domain.BlockingTypeWildcard:
+		return strings.TrimPrefix(entry.Domain, "*.")
+	case domain.BlockingTypeURLPath:
+		host := entry.URL
+		if i := strings.Index(host, "://"); i >= 0 {
+			host = host[i+3:]
+		}
+		if i := strings.IndexAny(host, "/?#"); i >= 0 {
+			host = host[:i]
+		}
+		if domain.IsValidIP(host) {
+			return ""
+		}
+		return host
+	default:
+		return ""
+	}
+}
+
+// reverseLabels turns "www.example.com" into "com.example.www." so that every
+// domain below a parent shares the parent's key as a prefix. The trailing dot
+// keeps "example.com" from matching "badexample.com".
+func reverseLabels(host string) string {
+	labels := strings.Split(strings.Trim(strings.ToLower(host), "."), ".")
+	var b strings.Builder
+	b.Grow(len(host) + 1)
+	for i := len(labels) - 1; i >= 0; i-- {
+		b.WriteString(labels[i])
+		b.WriteByte('.')
+	}
+	return b.String()
+}
+
+// byKey sorts the keys of a corpus together with their positions
+type byKey struct{ c *corpus }
+
+func (b byKey) Len() int           { return len(b.c.keys) }
+func (b byKey) Less(i, j int) bool { return b.c.keys[i] < b.c.keys[j] }
+func (b byKey) Swap(i, j int) {
+	b.c.keys[i], b.c.keys[j] = b.c.keys[j], b.c.keys[i]
+	b.c.positions[i], b.c.positions[j] = b.c.positions[j], b.c.positions[i]
+}
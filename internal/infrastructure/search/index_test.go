@@ -0,0 +1,134 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// createRegistry creates a registry of the given entries keyed by type
+func createRegistry(entries map[string]domain.BlockingType) *domain.Registry {
+	registry := domain.NewRegistry()
+	registry.Version = "v1"
+	for value, entryType := range entries {
+		entry, _ := domain.NewRegistryEntry(entryType, value)
+		registry.AddEntry(entry)
+	}
+	return registry
+}
+
+func values(result *domain.SearchResult) map[string]bool {
+	found := make(map[string]bool)
+	for _, entry := range result.Entries {
+		found[entry.Value()] = true
+	}
+	return found
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := NewIndex(0)
+	idx.Build(createRegistry(map[string]domain.BlockingType{
+		"mycustomer.ru":             domain.BlockingTypeDomain,
+		"shop.mycustomer.ru":        domain.BlockingTypeDomain,
+		"*.cdn.mycustomer.ru":       domain.BlockingTypeWildcard,
+		"mycustomer.ru/casino":      domain.BlockingTypeURLPath,
+		"notmycustomer.ru":          domain.BlockingTypeDomain,
+		"online-casino.com":         domain.BlockingTypeDomain,
+		"10.0.0.1":                  domain.BlockingTypeIP,
+		"other.org":                 domain.BlockingTypeDomain,
+		"casino-royale.example.org": domain.BlockingTypeDomain,
+	}))
+
+	tests := []struct {
+		name     string
+		query    domain.SearchQuery
+		expected []string
+	}{
+		{
+			name:     "suffix",
+			query:    domain.SearchQuery{Mode: domain.SearchModeSuffix, Pattern: "*.MyCustomer.ru"},
+			expected: []string{"mycustomer.ru", "shop.mycustomer.ru", "*.cdn.mycustomer.ru", "mycustomer.ru/casino"},
+		},
+		{
+			name:     "suffix below wildcard",
+			query:    domain.SearchQuery{Mode: domain.SearchModeSuffix, Pattern: "cdn.mycustomer.ru"},
+			expected: []string{"*.cdn.mycustomer.ru"},
+		},
+		{
+			name:     "substring",
+			query:    domain.SearchQuery{Mode: domain.SearchModeSubstring, Pattern: "casino"},
+			expected: []string{"mycustomer.ru/casino", "online-casino.com", "casino-royale.example.org"},
+		},
+		{
+			name:     "regex",
+			query:    domain.SearchQuery{Mode: domain.SearchModeRegex, Pattern: `^10\.0\.\d+\.\d+$`},
+			expected: []string{"10.0.0.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := idx.Search(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			found := values(result)
+			if len(found) != len(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, found)
+			}
+			for _, value := range tt.expected {
+				if !found[value] {
+					t.Errorf("expected %s in %v", value, found)
+				}
+			}
+			if result.Version != "v1" || result.Truncated || result.TimedOut {
+				t.Errorf("unexpected result flags: %+v", result)
+			}
+		})
+	}
+}
+
+func TestIndex_Search_Limits(t *testing.T) {
+	entries := make(map[string]domain.BlockingType)
+	for i := 0; i < 50; i++ {
+		entries[fmt.Sprintf("site%d.example.com", i)] = domain.BlockingTypeDomain
+	}
+	idx := NewIndex(0)
+	idx.Build(createRegistry(entries))
+
+	for _, mode := range []domain.SearchMode{domain.SearchModeSuffix, domain.SearchModeSubstring} {
+		result, _ := idx.Search(context.Background(), domain.SearchQuery{Mode: mode, Pattern: "example.com", Limit: 10})
+		if len(result.Entries) != 10 || !result.Truncated {
+			t.Errorf("%s: expected 10 truncated results, got %d", mode, len(result.Entries))
+		}
+	}
+
+	slow := NewIndex(time.Nanosecond)
+	slow.Build(createRegistry(entries))
+	time.Sleep(time.Millisecond)
+	result, _ := slow.Search(context.Background(), domain.SearchQuery{Mode: domain.SearchModeRegex, Pattern: "site"})
+	if !result.TimedOut {
+		t.Errorf("expected search to time out, got %d results", len(result.Entries))
+	}
+}
+
+func TestIndex_Search_InvalidQuery(t *testing.T) {
+	idx := NewIndex(0)
+
+	queries := []domain.SearchQuery{
+		{Mode: domain.SearchModeSuffix, Pattern: " "},
+		{Mode: domain.SearchModeRegex, Pattern: "("},
+		{Mode: domain.SearchModeUnknown, Pattern: "example"},
+		{Mode: domain.SearchModeSubstring, Pattern: string(make([]byte, MaxPatternLength+1))},
+	}
+	for _, query := range queries {
+		if _, err := idx.Search(context.Background(), query); !errors.Is(err, domain.ErrInvalidSearchQuery) {
+			t.Errorf("query %+v: expected ErrInvalidSearchQuery, got %v", query, err)
+		}
+	}
+}
@@ -0,0 +1,390 @@
+package snapshot
+
+import (
+	"encoding/gob"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/application"
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/storage"
+)
+
+// Retention bounds how many registry snapshots are kept
+type Retention struct {
+	// MaxSnapshots is the number of snapshots kept, including the current one
+	MaxSnapshots int
+	// MaxAge drops snapshots that were superseded longer ago than this.
+	// Zero keeps snapshots regardless of age.
+	MaxAge time.Duration
+	// MaxLoaded is the number of snapshots kept built for lookups, the least
+	// recently queried being dropped first
+	MaxLoaded int
+}
+
+// DefaultRetention is used when no retention is configured
+var DefaultRetention = Retention{MaxSnapshots: 7, MaxAge: 30 * 24 * time.Hour, MaxLoaded: 2}
+
+// header is written before the registry in a snapshot file so the index can
+// be rebuilt without decoding the entries
+type header struct {
+	Version   string
+	AppliedAt time.Time
+	// Cleared marks the time the registry was cleared, until the next
+	// snapshot was applied
+	Cleared bool
+}
+
+// snapshot is a retained registry version, active from AppliedAt until the
+// next snapshot was applied
+type snapshot struct {
+	header
+	registry *domain.Registry // nil when persisted on disk
+	path     string
+}
+
+// Store retains the registries applied by the scheduler so checks can be
+// answered as of an earlier time or version. Without a snapshot directory the
+// registries are held in memory; with one they are written to disk. Either
+// way only the most recently queried snapshots are kept built for lookups.
+type Store struct {
+	mu        sync.RWMutex
+	retention Retention
+	dir       string
+	snapshots []*snapshot // oldest first
+
+	loadMu sync.Mutex
+	loaded []*loadedSnapshot // most recently queried first
+	builds map[string]*build
+}
+
+type loadedSnapshot struct {
+	version string
+	store   *storage.MemoryStore
+}
+
+// build is a snapshot lookup being built, shared by the queries that wait
+// for it
+type build struct {
+	done  chan struct{}
+	store *storage.MemoryStore
+	err   error
+}
+
+// NewStore creates a snapshot store with the given retention. When
+// snapshotDir is not empty, snapshots are persisted below it and the ones
+// already there are indexed.
+func NewStore(retention Retention, snapshotDir string) (*Store, error) {
+	if retention.MaxSnapshots <= 0 {
+		retention.MaxSnapshots = DefaultRetention.MaxSnapshots
+	}
+	if retention.MaxLoaded <= 0 {
+		retention.MaxLoaded = DefaultRetention.MaxLoaded
+	}
+
+	s := &Store{retention: retention, builds: make(map[string]*build)}
+	if snapshotDir == "" {
+		return s, nil
+	}
+
+	s.dir = filepath.Join(snapshotDir, "snapshots")
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating snapshot directory: %w", err)
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// OnRegistryUpdate retains a newly applied registry
+func (s *Store) OnRegistryUpdate(previous, current *domain.Registry, report *domain.IngestReport) {
+	if err := s.Add(current, time.Now()); err != nil {
+		slog.Error("Failed to retain registry snapshot", "version", current.Version, "error", err)
+	}
+}
+
+// OnRegistryCleared records that no registry is active from now on
+func (s *Store) OnRegistryCleared() {
+	snap := &snapshot{header: header{AppliedAt: time.Now().UTC(), Cleared: true}}
+	if err := s.add(snap, domain.NewRegistry()); err != nil {
+		slog.Error("Failed to retain registry clear", "error", err)
+	}
+}
+
+// Add retains a registry applied at the given time and evicts snapshots
+// outside the retention policy
+func (s *Store) Add(registry *domain.Registry, appliedAt time.Time) error {
+	return s.add(&snapshot{header: header{Version: registry.Version, AppliedAt: appliedAt.UTC()}}, registry)
+}
+
+func (s *Store) add(snap *snapshot, registry *domain.Registry) error {
+	if s.dir == "" {
+		snap.registry = registry
+	} else {
+		snap.path = filepath.Join(s.dir, fmt.Sprintf("%020d.gob", snap.AppliedAt.UnixNano()))
+		if err := persist(snap, registry); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	s.snapshots = append(s.snapshots, snap)
+	evicted := s.evict(time.Now())
+	s.mu.Unlock()
+
+	for _, old := range evicted {
+		s.unload(old.Version)
+		if old.path == "" {
+			continue
+		}
+		if err := os.Remove(old.path); err != nil && !os.IsNotExist(err) {
+			slog.Warn("Failed to remove evicted snapshot", "version", old.Version, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// SnapshotAt returns the registry that was active at the given time
+func (s *Store) SnapshotAt(at time.Time) (application.RegistryLookup, error) {
+	s.mu.RLock()
+	i := sort.Search(len(s.snapshots), func(i int) bool {
+		return s.snapshots[i].AppliedAt.After(at)
+	}) - 1
+	var snap *snapshot
+	var oldest time.Time
+	if i >= 0 {
+		snap = s.snapshots[i]
+	} else if len(s.snapshots) > 0 {
+		oldest = s.snapshots[0].AppliedAt
+	}
+	s.mu.RUnlock()
+
+	if snap == nil {
+		if oldest.IsZero() {
+			return nil, domain.ErrSnapshotNotRetained
+		}
+		return nil, fmt.Errorf("%w: oldest retained snapshot was applied at %s",
+			domain.ErrSnapshotNotRetained, oldest.Format(time.RFC3339))
+	}
+	if snap.Cleared {
+		return nil, fmt.Errorf("%w: the registry was cleared at %s",
+			domain.ErrRegistryNotReady, snap.AppliedAt.Format(time.RFC3339))
+	}
+
+	return s.lookup(snap)
+}
+
+// SnapshotByVersion returns the retained registry with the given version
+func (s *Store) SnapshotByVersion(version string) (application.RegistryLookup, error) {
+	s.mu.RLock()
+	var snap *snapshot
+	for i := len(s.snapshots) - 1; i >= 0; i-- {
+		if !s.snapshots[i].Cleared && s.snapshots[i].Version == version {
+			snap = s.snapshots[i]
+			break
+		}
+	}
+	s.mu.RUnlock()
+
+	if snap == nil {
+		return nil, fmt.Errorf("%w: version %s", domain.ErrSnapshotNotRetained, version)
+	}
+
+	return s.lookup(snap)
+}
+
+// Versions returns the retained snapshot versions, oldest first
+func (s *Store) Versions() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	versions := make([]string, 0, len(s.snapshots))
+	for _, snap := range s.snapshots {
+		if !snap.Cleared {
+			versions = append(versions, snap.Version)
+		}
+	}
+	return versions
+}
+
+// lookup returns a searchable store for a snapshot. Stores are built once
+// per version, outside the lock, with concurrent queries for a version
+// waiting on the same build, and the most recently queried are kept.
+func (s *Store) lookup(snap *snapshot) (application.RegistryLookup, error) {
+	s.loadMu.Lock()
+	for i, loaded := range s.loaded {
+		if loaded.version == snap.Version {
+			copy(s.loaded[1:i+1], s.loaded[:i])
+			s.loaded[0] = loaded
+			s.loadMu.Unlock()
+			return loaded.store, nil
+		}
+	}
+	if b, ok := s.builds[snap.Version]; ok {
+		s.loadMu.Unlock()
+		<-b.done
+		if b.err != nil {
+			return nil, b.err
+		}
+		return b.store, nil
+	}
+	b := &build{done: make(chan struct{})}
+	s.builds[snap.Version] = b
+	s.loadMu.Unlock()
+
+	b.store, b.err = buildLookup(snap)
+
+	s.loadMu.Lock()
+	delete(s.builds, snap.Version)
+	if b.err == nil {
+		s.loaded = append([]*loadedSnapshot{{version: snap.Version, store: b.store}}, s.loaded...)
+		s.loaded = s.loaded[:min(len(s.loaded), s.retention.MaxLoaded)]
+	}
+	s.loadMu.Unlock()
+	close(b.done)
+
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.store, nil
+}
+
+// unload drops the built store of an evicted snapshot version
+func (s *Store) unload(version string) {
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+
+	s.loaded = slices.DeleteFunc(s.loaded, func(loaded *loadedSnapshot) bool {
+		return loaded.version == version
+	})
+}
+
+// buildLookup builds the searchable store of a snapshot, reading it from
+// disk when it is persisted
+func buildLookup(snap *snapshot) (*storage.MemoryStore, error) {
+	registry := snap.registry
+	if registry == nil {
+		var err error
+		if registry, err = read(snap.path); err != nil {
+			return nil, err
+		}
+	}
+
+	store := storage.NewMemoryStore()
+	if err := store.Update(registry); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// evict drops snapshots beyond the retention policy and returns them. The
+// newest snapshot is always kept. Callers must hold mu.
+func (s *Store) evict(now time.Time) []*snapshot {
+	drop := max(len(s.snapshots)-s.retention.MaxSnapshots, 0)
+	if s.retention.MaxAge > 0 {
+		cutoff := now.Add(-s.retention.MaxAge)
+		// A snapshot is expired once its successor was applied before the cutoff
+		for drop < len(s.snapshots)-1 && !s.snapshots[drop+1].AppliedAt.After(cutoff) {
+			drop++
+		}
+	}
+	if drop == 0 {
+		return nil
+	}
+
+	evicted := append([]*snapshot(nil), s.snapshots[:drop]...)
+	s.snapshots = append([]*snapshot(nil), s.snapshots[drop:]...)
+	return evicted
+}
+
+// persist writes a snapshot file atomically through a temporary file
+func persist(snap *snapshot, registry *domain.Registry) error {
+	tmp, err := os.CreateTemp(filepath.Dir(snap.path), "snapshot-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	encoder := gob.NewEncoder(tmp)
+	if err := encoder.Encode(snap.header); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	if err := encoder.Encode(registry); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), snap.path)
+}
+
+// read decodes the registry of a snapshot file
+func read(path string) (*domain.Registry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening snapshot: %w", err)
+	}
+	defer file.Close()
+
+	decoder := gob.NewDecoder(file)
+	var h header
+	if err := decoder.Decode(&h); err != nil {
+		return nil, fmt.Errorf("reading snapshot: %w", err)
+	}
+	var registry domain.Registry
+	if err := decoder.Decode(&registry); err != nil {
+		return nil, fmt.Errorf("reading snapshot: %w", err)
+	}
+
+	return &registry, nil
+}
+
+// load indexes the persisted snapshots from their headers and applies the
+// retention policy to them
+func (s *Store) load() error {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.gob"))
+	if err != nil {
+		return fmt.Errorf("listing snapshots: %w", err)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		h, err := readHeader(path)
+		if err != nil {
+			slog.Warn("Skipping unreadable snapshot", "path", path, "error", err)
+			continue
+		}
+		s.snapshots = append(s.snapshots, &snapshot{header: h, path: path})
+	}
+
+	for _, old := range s.evict(time.Now()) {
+		os.Remove(old.path)
+	}
+
+	return nil
+}
+
+// readHeader decodes only the header of a snapshot file
+func readHeader(path string) (header, error) {
+	var h header
+	file, err := os.Open(path)
+	if err != nil {
+		return h, err
+	}
+	defer file.Close()
+
+	err = gob.NewDecoder(file).Decode(&h)
+	return h, err
+}
@@ -0,0 +1,248 @@
+package snapshot
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// createRegistry creates a versioned registry of domain entries
+func createRegistry(version string, domains ...string) *domain.Registry {
+	registry := domain.NewRegistry()
+	registry.Version = version
+	for _, value := range domains {
+		entry, _ := domain.NewRegistryEntry(domain.BlockingTypeDomain, value)
+		registry.AddEntry(entry)
+	}
+	return registry
+}
+
+func TestStore_SnapshotAt(t *testing.T) {
+	for _, mode := range []string{"memory", "disk"} {
+		t.Run(mode, func(t *testing.T) {
+			dir := ""
+			if mode == "disk" {
+				dir = t.TempDir()
+			}
+
+			store, err := NewStore(Retention{MaxSnapshots: 5}, dir)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			start := time.Now().Add(-time.Hour)
+			store.Add(createRegistry("v1", "old.com"), start)
+			store.Add(createRegistry("v2", "new.com"), start.Add(30*time.Minute))
+
+			tests := []struct {
+				name    string
+				at      time.Time
+				version string
+				blocked string
+				wantErr bool
+			}{
+				{name: "first snapshot", at: start.Add(time.Minute), version: "v1", blocked: "old.com"},
+				{name: "exact apply time", at: start.Add(30 * time.Minute), version: "v2", blocked: "new.com"},
+				{name: "current", at: time.Now(), version: "v2", blocked: "new.com"},
+				{name: "before retention", at: start.Add(-time.Minute), wantErr: true},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					lookup, err := store.SnapshotAt(tt.at)
+					if tt.wantErr {
+						if !errors.Is(err, domain.ErrSnapshotNotRetained) {
+							t.Errorf("expected ErrSnapshotNotRetained, got %v", err)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+
+					if version := lookup.Stats().Version; version != tt.version {
+						t.Errorf("expected version %s, got %s", tt.version, version)
+					}
+					if result := lookup.IsBlocked(tt.blocked); !result.IsBlocked {
+						t.Errorf("expected %s to be blocked in %s", tt.blocked, tt.version)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestStore_SnapshotByVersion(t *testing.T) {
+	store, _ := NewStore(Retention{MaxSnapshots: 5}, "")
+	store.Add(createRegistry("v1", "a.com"), time.Now())
+
+	lookup, err := store.SnapshotByVersion("v1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !lookup.IsBlocked("a.com").IsBlocked {
+		t.Error("expected a.com to be blocked")
+	}
+
+	if _, err := store.SnapshotByVersion("v0"); !errors.Is(err, domain.ErrSnapshotNotRetained) {
+		t.Errorf("expected ErrSnapshotNotRetained, got %v", err)
+	}
+}
+
+func TestStore_Cleared(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := NewStore(Retention{MaxSnapshots: 5}, dir)
+
+	start := time.Now().Add(-time.Hour)
+	store.Add(createRegistry("v1", "a.com"), start)
+	store.OnRegistryCleared()
+
+	reloaded, err := NewStore(Retention{MaxSnapshots: 5}, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, s := range []*Store{store, reloaded} {
+		if _, err := s.SnapshotAt(time.Now()); !errors.Is(err, domain.ErrRegistryNotReady) {
+			t.Errorf("expected ErrRegistryNotReady after the clear, got %v", err)
+		}
+		if lookup, err := s.SnapshotAt(start.Add(time.Minute)); err != nil || !lookup.IsBlocked("a.com").IsBlocked {
+			t.Errorf("expected v1 to answer before the clear, got %v", err)
+		}
+		if versions := s.Versions(); len(versions) != 1 || versions[0] != "v1" {
+			t.Errorf("expected only v1 to be listed, got %v", versions)
+		}
+	}
+}
+
+func TestStore_Retention(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		retention Retention
+		expected  []string
+	}{
+		{name: "by count", retention: Retention{MaxSnapshots: 2}, expected: []string{"v3", "v4"}},
+		// v2 was superseded by v3 before the cutoff, v3 was still active at it
+		{name: "by age", retention: Retention{MaxSnapshots: 10, MaxAge: 36 * time.Hour}, expected: []string{"v3", "v4"}},
+		{name: "newest always kept", retention: Retention{MaxSnapshots: 10, MaxAge: time.Minute}, expected: []string{"v4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, _ := NewStore(tt.retention, "")
+			store.Add(createRegistry("v1"), now.Add(-96*time.Hour))
+			store.Add(createRegistry("v2"), now.Add(-72*time.Hour))
+			store.Add(createRegistry("v3"), now.Add(-48*time.Hour))
+			store.Add(createRegistry("v4"), now.Add(-time.Hour))
+
+			versions := store.Versions()
+			if len(versions) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, versions)
+			}
+			for i := range versions {
+				if versions[i] != tt.expected[i] {
+					t.Errorf("expected %v, got %v", tt.expected, versions)
+				}
+			}
+		})
+	}
+}
+
+func TestStore_Persistence(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewStore(Retention{MaxSnapshots: 2}, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	now := time.Now()
+	store.Add(createRegistry("v1", "a.com"), now.Add(-3*time.Hour))
+	store.Add(createRegistry("v2", "b.com"), now.Add(-2*time.Hour))
+	store.Add(createRegistry("v3", "c.com"), now.Add(-time.Hour))
+
+	files, _ := filepath.Glob(filepath.Join(dir, "snapshots", "*.gob"))
+	if len(files) != 2 {
+		t.Errorf("expected evicted snapshot file to be removed, found %d files", len(files))
+	}
+
+	reloaded, err := NewStore(Retention{MaxSnapshots: 2}, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lookup, err := reloaded.SnapshotAt(now.Add(-90 * time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lookup.Stats().Version != "v2" || !lookup.IsBlocked("b.com").IsBlocked {
+		t.Errorf("unexpected reloaded snapshot %s", lookup.Stats().Version)
+	}
+}
+
+func TestStore_LookupCache(t *testing.T) {
+	store, err := NewStore(Retention{MaxSnapshots: 5, MaxLoaded: 2}, t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	start := time.Now().Add(-time.Hour)
+	for i, version := range []string{"v1", "v2", "v3"} {
+		store.Add(createRegistry(version, version+".com"), start.Add(time.Duration(i)*time.Minute))
+	}
+
+	first, _ := store.SnapshotByVersion("v1")
+	second, _ := store.SnapshotByVersion("v2")
+
+	// Alternating between cached versions reuses their stores
+	for range 3 {
+		if lookup, _ := store.SnapshotByVersion("v1"); lookup != first {
+			t.Fatal("expected v1 to be served from the cache")
+		}
+		if lookup, _ := store.SnapshotByVersion("v2"); lookup != second {
+			t.Fatal("expected v2 to be served from the cache")
+		}
+	}
+
+	// A third version evicts the least recently queried one
+	store.SnapshotByVersion("v3")
+	if lookup, _ := store.SnapshotByVersion("v2"); lookup != second {
+		t.Error("expected v2 to stay cached")
+	}
+	if lookup, _ := store.SnapshotByVersion("v1"); lookup == first {
+		t.Error("expected v1 to be rebuilt after eviction")
+	}
+}
+
+func TestStore_ConcurrentLookupsShareBuild(t *testing.T) {
+	store, _ := NewStore(Retention{MaxSnapshots: 5}, t.TempDir())
+	store.Add(createRegistry("v1", "a.com"), time.Now())
+
+	const queries = 16
+	lookups := make(chan any, queries)
+	var wg sync.WaitGroup
+	for range queries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lookup, err := store.SnapshotByVersion("v1")
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			lookups <- lookup
+		}()
+	}
+	wg.Wait()
+	close(lookups)
+
+	first := <-lookups
+	for lookup := range lookups {
+		if lookup != first {
+			t.Fatal("expected concurrent queries to share one store")
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// GetEntry returns the registry entry with the given ID
+func (ms *MemoryStore) GetEntry(id string) (*domain.RegistryEntry, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	i, ok := ms.entryIndex[id]
+	if !ok {
+		return nil, domain.ErrEntryNotFound
+	}
+	return ms.entries[i], nil
+}
+
+// ListEntries returns a page of the entries matching the query, in registry
+// order. The cursor records the registry version and the position to resume
+// from, so pages stay consistent while that version is current.
+func (ms *MemoryStore) ListEntries(query domain.EntryQuery) (*domain.EntryPage, error) {
+	ms.mu.RLock()
+	entries, version := ms.entries, ms.version
+	ms.mu.RUnlock()
+
+	start := 0
+	if query.Cursor != "" {
+		cursorVersion, offset, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cursorVersion != version {
+			return nil, domain.ErrCursorExpired
+		}
+		if offset > len(entries) {
+			return nil, domain.ErrInvalidCursor
+		}
+		start = offset
+	}
+
+	limit := query.PageSize()
+	needle := strings.ToLower(query.Query)
+	page := &domain.EntryPage{
+		Version: version,
+		Entries: make([]*domain.RegistryEntry, 0, min(limit, len(entries)-start)),
+	}
+
+	for i := start; i < len(entries); i++ {
+		entry := entries[i]
+		if query.Type != domain.BlockingTypeUnknown && entry.Type != query.Type {
+			continue
+		}
+		if needle != "" && !strings.Contains(entry.Value(), needle) {
+			continue
+		}
+
+		if len(page.Entries) == limit {
+			page.NextCursor = encodeCursor(version, i)
+			break
+		}
+		page.Entries = append(page.Entries, entry)
+	}
+
+	return page, nil
+}
+
+// encodeCursor builds an opaque cursor for a position in a registry version
+func encodeCursor(version string, offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(version + ":" + strconv.Itoa(offset)))
+}
+
+// decodeCursor reverses encodeCursor
+func decodeCursor(cursor string) (string, int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", 0, domain.ErrInvalidCursor
+	}
+
+	sep := strings.LastIndexByte(string(raw), ':')
+	if sep < 0 {
+		return "", 0, domain.ErrInvalidCursor
+	}
+	offset, err := strconv.Atoi(string(raw[sep+1:]))
+	if err != nil || offset < 0 {
+		return "", 0, domain.ErrInvalidCursor
+	}
+
+	return string(raw[:sep]), offset, nil
+}
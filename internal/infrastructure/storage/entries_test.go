@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// createIdentifiedRegistry creates a registry of the large test entries with IDs
+func createIdentifiedRegistry(version string, size int) *domain.Registry {
+	registry := createLargeTestRegistry(size)
+	registry.Version = version
+	for i, entry := range registry.Entries {
+		entry.ID = fmt.Sprintf("%d-1", i)
+	}
+	return registry
+}
+
+func TestMemoryStore_GetEntry(t *testing.T) {
+	store := NewMemoryStore()
+	store.Update(createIdentifiedRegistry("v1", 8))
+
+	entry, err := store.GetEntry("2-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.Type != domain.BlockingTypeIP || entry.Value() != generateIP(2) {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+
+	if _, err := store.GetEntry("missing"); !errors.Is(err, domain.ErrEntryNotFound) {
+		t.Errorf("expected ErrEntryNotFound, got %v", err)
+	}
+
+	store.Clear()
+	if _, err := store.GetEntry("2-1"); !errors.Is(err, domain.ErrEntryNotFound) {
+		t.Errorf("expected cleared store to have no entries, got %v", err)
+	}
+}
+
+func TestMemoryStore_ListEntries(t *testing.T) {
+	store := NewMemoryStore()
+	store.Update(createIdentifiedRegistry("v1", 100))
+
+	t.Run("pages cover every entry once", func(t *testing.T) {
+		seen := make(map[string]bool)
+		query := domain.EntryQuery{Limit: 30}
+		pages := 0
+		for {
+			page, err := store.ListEntries(query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			pages++
+			for _, entry := range page.Entries {
+				if seen[entry.ID] {
+					t.Fatalf("entry %s returned twice", entry.ID)
+				}
+				seen[entry.ID] = true
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			query.Cursor = page.NextCursor
+		}
+		if len(seen) != 100 || pages != 4 {
+			t.Errorf("expected 100 entries over 4 pages, got %d over %d", len(seen), pages)
+		}
+	})
+
+	t.Run("filters by type and query", func(t *testing.T) {
+		page, err := store.ListEntries(domain.EntryQuery{Type: domain.BlockingTypeWildcard, Query: "DOMAIN1"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// *.domain1.com, *.domain13.com and *.domain17.com
+		for _, entry := range page.Entries {
+			if entry.Type != domain.BlockingTypeWildcard {
+				t.Errorf("unexpected entry type %s", entry.Type)
+			}
+		}
+		if len(page.Entries) != 3 || page.NextCursor != "" {
+			t.Errorf("expected 3 wildcard entries on one page, got %d", len(page.Entries))
+		}
+	})
+
+	t.Run("cursor expires with the registry version", func(t *testing.T) {
+		page, _ := store.ListEntries(domain.EntryQuery{Limit: 10})
+
+		other := NewMemoryStore()
+		other.Update(createIdentifiedRegistry("v2", 100))
+		if _, err := other.ListEntries(domain.EntryQuery{Cursor: page.NextCursor}); !errors.Is(err, domain.ErrCursorExpired) {
+			t.Errorf("expected ErrCursorExpired, got %v", err)
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		for _, cursor := range []string{"not base64!", encodeCursor("v1", 1000), "djE"} {
+			if _, err := store.ListEntries(domain.EntryQuery{Cursor: cursor}); !errors.Is(err, domain.ErrInvalidCursor) {
+				t.Errorf("cursor %q: expected ErrInvalidCursor, got %v", cursor, err)
+			}
+		}
+	})
+}
@@ -1,15 +1,26 @@
 package storage
 
 import (
+	"context"
+	"net/netip"
 	"net/url"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
 	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/metrics"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/tracing"
 )
 
+// tracer records registry lookups
+var tracer = otel.Tracer("github.com/kerim-dauren/rkn-checker/internal/infrastructure/storage")
+
 type MemoryStore struct {
 	mu sync.RWMutex
 
@@ -17,12 +28,21 @@ type MemoryStore struct {
 	wildcards   *RadixTree
 	ips         map[string]*domain.BlockingRule
 	urlPatterns map[string][]*domain.BlockingRule
+	subnets     *SubnetTrie
 
 	bloom *BloomFilter
-
-	lastUpdate time.Time
-	entryCount int64
-	version    string
+	// bloomItems is how many items were added to the bloom filter
+	bloomItems uint64
+
+	// entries keeps the registry entries in registry order for browsing,
+	// indexed by ID
+	entries    []*domain.RegistryEntry
+	entryIndex map[string]int32
+
+	lastUpdate   time.Time
+	entryCount   int64
+	countsByType map[domain.BlockingType]int64
+	version      string
 }
 
 func NewMemoryStore() *MemoryStore {
@@ -31,54 +51,147 @@ func NewMemoryStore() *MemoryStore {
 		wildcards:   NewRadixTree(),
 		ips:         make(map[string]*domain.BlockingRule),
 		urlPatterns: make(map[string][]*domain.BlockingRule),
+		subnets:     NewSubnetTrie(),
 		bloom:       NewBloomFilter(1000000, 0.01),
+		entryIndex:  make(map[string]int32),
 		lastUpdate:  time.Now(),
 	}
 }
 
 func (ms *MemoryStore) IsBlocked(normalizedURL string) *domain.BlockingResult {
+	return ms.IsBlockedContext(context.Background(), normalizedURL)
+}
+
+// IsBlockedContext is IsBlocked recording the lookup as a span under ctx,
+// with a child span for each stage run: bloom, exact, wildcard, path and
+// subnet
+func (ms *MemoryStore) IsBlockedContext(ctx context.Context, normalizedURL string) *domain.BlockingResult {
 	if normalizedURL == "" {
 		return domain.NewBlockingResult(false, normalizedURL, nil)
 	}
 
+	ctx, span := tracer.Start(ctx, "MemoryStore.IsBlocked")
+	defer span.End()
+
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 
-	// Check bloom filter for exact match or potential wildcard matches
-	bloomCheckPassed := ms.bloom.Contains(normalizedURL)
+	result := ms.lookup(ctx, normalizedURL)
+
+	span.SetAttributes(tracing.RegistryVersionKey.String(ms.version))
+	span.SetAttributes(tracing.ResultAttributes(result)...)
+	return result
+}
+
+// lookup runs the lookup stages in order. The caller must hold the read lock.
+func (ms *MemoryStore) lookup(ctx context.Context, normalizedURL string) *domain.BlockingResult {
+	stage := startStage(ctx, "bloom")
+	bloomCheckPassed := ms.bloomContains(normalizedURL)
+	stage.SetAttributes(tracing.BloomPassedKey.Bool(bloomCheckPassed))
+	stage.End()
+
 	if !bloomCheckPassed {
-		// Also check for potential wildcard matches in bloom filter
-		parts := strings.Split(normalizedURL, ".")
-		for i := 1; i < len(parts); i++ {
-			suffix := strings.Join(parts[i:], ".")
-			if ms.bloom.Contains(suffix) {
-				bloomCheckPassed = true
-				break
-			}
-		}
+		metrics.BloomReject.Inc()
+		return ms.matchSubnet(ctx, normalizedURL)
 	}
+	metrics.BloomPass.Inc()
 
-	if !bloomCheckPassed {
-		return domain.NewBlockingResult(false, normalizedURL, nil)
+	stage = startStage(ctx, "exact")
+	rule := ms.matchExact(normalizedURL)
+	stage.End()
+	if rule != nil {
+		return domain.NewBlockingResult(true, normalizedURL, rule)
 	}
 
-	if rule, exists := ms.domains[normalizedURL]; exists {
+	stage = startStage(ctx, "wildcard")
+	rule = ms.matchWildcard(normalizedURL)
+	stage.End()
+	if rule != nil {
 		return domain.NewBlockingResult(true, normalizedURL, rule)
 	}
 
-	if rule, exists := ms.ips[normalizedURL]; exists {
+	stage = startStage(ctx, "path")
+	rule = ms.matchPath(normalizedURL)
+	stage.End()
+	if rule != nil {
 		return domain.NewBlockingResult(true, normalizedURL, rule)
 	}
 
+	metrics.BloomFalsePositives.Inc()
+	return ms.matchSubnet(ctx, normalizedURL)
+}
+
+// startStage starts the span of a lookup stage. Stages of untraced lookups
+// get a no-op span, keeping the hot path free of span allocations.
+func startStage(ctx context.Context, name string) trace.Span {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return noop.Span{}
+	}
+	_, span := tracer.Start(ctx, name)
+	return span
+}
+
+// bloomContains checks the bloom filter for the URL or any of its parent
+// domains, which wildcard rules may cover
+func (ms *MemoryStore) bloomContains(normalizedURL string) bool {
+	if ms.bloom.Contains(normalizedURL) {
+		return true
+	}
+
+	parts := strings.Split(normalizedURL, ".")
+	for i := 1; i < len(parts); i++ {
+		suffix := strings.Join(parts[i:], ".")
+		if ms.bloom.Contains(suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchExact returns the domain or IP rule matching the URL exactly
+func (ms *MemoryStore) matchExact(normalizedURL string) *domain.BlockingRule {
+	if rule, exists := ms.domains[normalizedURL]; exists {
+		return rule
+	}
+
+	if rule, exists := ms.ips[normalizedURL]; exists {
+		return rule
+	}
+
+	return nil
+}
+
+// matchWildcard returns the wildcard rule covering the URL
+func (ms *MemoryStore) matchWildcard(normalizedURL string) *domain.BlockingRule {
 	if value, exists := ms.wildcards.MatchesWildcard(normalizedURL); exists {
 		if rule, ok := value.(*domain.BlockingRule); ok {
-			return domain.NewBlockingResult(true, normalizedURL, rule)
+			return rule
 		}
 	}
+	return nil
+}
 
+// matchPath returns the URL path rule matching the URL
+func (ms *MemoryStore) matchPath(normalizedURL string) *domain.BlockingRule {
 	if patterns, exists := ms.urlPatterns[normalizedURL]; exists {
 		for _, rule := range patterns {
 			if rule.Matches(&domain.URL{}) {
+				return rule
+			}
+		}
+	}
+	return nil
+}
+
+// matchSubnet checks an IP address against the subnet entries. The caller
+// must hold the read lock.
+func (ms *MemoryStore) matchSubnet(ctx context.Context, normalizedURL string) *domain.BlockingResult {
+	stage := startStage(ctx, "subnet")
+	defer stage.End()
+
+	if ms.subnets.Size() > 0 {
+		if addr, err := netip.ParseAddr(normalizedURL); err == nil {
+			if rule := ms.subnets.Match(addr); rule != nil {
 				return domain.NewBlockingResult(true, normalizedURL, rule)
 			}
 		}
@@ -96,14 +209,27 @@ func (ms *MemoryStore) Update(registry *domain.Registry) error {
 	newWildcards := NewRadixTree()
 	newIPs := make(map[string]*domain.BlockingRule)
 	newURLPatterns := make(map[string][]*domain.BlockingRule)
+	newSubnets := NewSubnetTrie()
 	newBloom := NewBloomFilter(uint64(len(registry.Entries)), 0.01)
+	newEntryIndex := make(map[string]int32, len(registry.Entries))
+	newCounts := make(map[domain.BlockingType]int64)
+	var bloomItems uint64
+
+	for i, entry := range registry.Entries {
+		if entry.ID != "" {
+			newEntryIndex[entry.ID] = int32(i)
+		}
+		newCounts[entry.Type]++
 
-	for _, entry := range registry.Entries {
 		rule, err := entry.ToBlockingRule()
 		if err != nil {
 			continue
 		}
 
+		if entry.Type != domain.BlockingTypeSubnet {
+			bloomItems++
+		}
+
 		switch entry.Type {
 		case domain.BlockingTypeDomain:
 			newDomains[entry.Domain] = rule
@@ -131,6 +257,11 @@ func (ms *MemoryStore) Update(registry *domain.Registry) error {
 		case domain.BlockingTypeSNI:
 			newDomains[entry.Domain] = rule
 			newBloom.Add(entry.Domain)
+
+		case domain.BlockingTypeSubnet:
+			if prefix, err := netip.ParsePrefix(entry.IP); err == nil {
+				newSubnets.Insert(prefix, rule)
+			}
 		}
 	}
 
@@ -141,9 +272,14 @@ func (ms *MemoryStore) Update(registry *domain.Registry) error {
 	ms.wildcards = newWildcards
 	ms.ips = newIPs
 	ms.urlPatterns = newURLPatterns
+	ms.subnets = newSubnets
 	ms.bloom = newBloom
+	ms.bloomItems = bloomItems
+	ms.entries = registry.Entries
+	ms.entryIndex = newEntryIndex
 	ms.lastUpdate = time.Now()
 	ms.version = registry.Version
+	ms.countsByType = newCounts
 
 	atomic.StoreInt64(&ms.entryCount, int64(len(registry.Entries)))
 
@@ -154,6 +290,11 @@ func (ms *MemoryStore) Stats() StoreStats {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 
+	countsByType := make(map[domain.BlockingType]int64, len(ms.countsByType))
+	for blockingType, count := range ms.countsByType {
+		countsByType[blockingType] = count
+	}
+
 	return StoreStats{
 		TotalEntries:    atomic.LoadInt64(&ms.entryCount),
 		DomainEntries:   int64(len(ms.domains)),
@@ -163,6 +304,9 @@ func (ms *MemoryStore) Stats() StoreStats {
 		LastUpdate:      ms.lastUpdate,
 		Version:         ms.version,
 		BloomFilterSize: ms.bloom.Size(),
+		CountsByType:    countsByType,
+
+		BloomFalsePositiveRate: ms.bloom.EstimatedFalsePositiveRate(ms.bloomItems),
 	}
 }
 
@@ -174,7 +318,12 @@ func (ms *MemoryStore) Clear() {
 	ms.wildcards.Clear()
 	ms.ips = make(map[string]*domain.BlockingRule)
 	ms.urlPatterns = make(map[string][]*domain.BlockingRule)
+	ms.subnets = NewSubnetTrie()
 	ms.bloom.Clear()
+	ms.bloomItems = 0
+	ms.entries = nil
+	ms.countsByType = nil
+	ms.entryIndex = make(map[string]int32)
 
 	atomic.StoreInt64(&ms.entryCount, 0)
 	ms.lastUpdate = time.Now()
@@ -189,6 +338,11 @@ type StoreStats struct {
 	LastUpdate      time.Time
 	Version         string
 	BloomFilterSize uint64
+	// CountsByType counts the registry entries of each blocking type
+	CountsByType map[domain.BlockingType]int64
+	// BloomFalsePositiveRate estimates the bloom filter false positive rate
+	// from its size and the items added
+	BloomFalsePositiveRate float64
 }
 
 // GetLastUpdateTime returns the time of the last update
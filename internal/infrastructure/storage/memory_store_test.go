@@ -1,11 +1,17 @@
 package storage
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"testing"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/tracing"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/tracing/tracingtest"
 )
 
 func TestNewMemoryStore(t *testing.T) {
@@ -49,6 +55,8 @@ func TestMemoryStore_IsBlocked(t *testing.T) {
 		{"blocked domain", "blocked.com", true},
 		{"wildcard match", "sub.wildcard.com", true},
 		{"blocked IP", "192.168.1.100", true},
+		{"IP in blocked subnet", "10.20.3.4", true},
+		{"IP outside blocked subnet", "10.21.0.1", false},
 		{"not blocked", "safe.com", false},
 		{"empty URL", "", false},
 	}
@@ -68,6 +76,57 @@ func TestMemoryStore_IsBlocked(t *testing.T) {
 	}
 }
 
+func TestMemoryStore_IsBlockedContext_Spans(t *testing.T) {
+	exporter := tracingtest.Exporter(t)
+
+	store := NewMemoryStore()
+	registry := createTestRegistry()
+	registry.Version = "20240601T090000.000Z"
+	store.Update(registry)
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "check")
+	result := store.IsBlockedContext(ctx, "sub.wildcard.com")
+	parent.End()
+
+	if !result.IsBlocked {
+		t.Fatal("expected the wildcard match to be blocked")
+	}
+
+	spans := exporter.GetSpans()
+	lookup := tracingtest.Find(spans, "MemoryStore.IsBlocked")
+	if lookup == nil {
+		t.Fatalf("expected a lookup span, got %d spans", len(spans))
+	}
+	if lookup.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Error("expected the lookup span under the caller span")
+	}
+
+	attrs := attribute.NewSet(lookup.Attributes...)
+	if v, _ := attrs.Value(tracing.MatchTypeKey); v.AsString() != "wildcard" {
+		t.Errorf("expected match type wildcard, got %q", v.AsString())
+	}
+	if v, _ := attrs.Value(tracing.RegistryVersionKey); v.AsString() != registry.Version {
+		t.Errorf("expected registry version %s, got %q", registry.Version, v.AsString())
+	}
+
+	// The lookup stops at the first stage that matches
+	for _, stage := range []string{"bloom", "exact", "wildcard"} {
+		span := tracingtest.Find(spans, stage)
+		if span == nil {
+			t.Errorf("expected a %s stage span", stage)
+			continue
+		}
+		if span.Parent.SpanID() != lookup.SpanContext.SpanID() {
+			t.Errorf("expected the %s stage under the lookup span", stage)
+		}
+	}
+	for _, stage := range []string{"path", "subnet"} {
+		if tracingtest.Find(spans, stage) != nil {
+			t.Errorf("expected no %s stage after a wildcard match", stage)
+		}
+	}
+}
+
 func TestMemoryStore_Concurrent(t *testing.T) {
 	store := NewMemoryStore()
 	registry := createLargeTestRegistry(10000)
@@ -167,6 +226,10 @@ func createTestRegistry() *domain.Registry {
 	ipEntry, _ := domain.NewRegistryEntry(domain.BlockingTypeIP, "192.168.1.100")
 	registry.AddEntry(ipEntry)
 
+	// Add subnet entries
+	subnetEntry, _ := domain.NewRegistryEntry(domain.BlockingTypeSubnet, "10.20.0.0/16")
+	registry.AddEntry(subnetEntry)
+
 	return registry
 }
 
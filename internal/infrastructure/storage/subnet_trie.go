@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"net/netip"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// SubnetTrie is a binary trie over address bits holding subnet rules, so an
+// address is matched in at most as many steps as it has bits, however many
+// subnets there are. IPv4 and IPv6 subnets are kept apart.
+type SubnetTrie struct {
+	v4   *subnetNode
+	v6   *subnetNode
+	size int
+}
+
+type subnetNode struct {
+	children [2]*subnetNode
+	rule     *domain.BlockingRule
+}
+
+func NewSubnetTrie() *SubnetTrie {
+	return &SubnetTrie{}
+}
+
+// Insert adds the rule of a subnet. The first rule of a subnet is kept.
+func (t *SubnetTrie) Insert(prefix netip.Prefix, rule *domain.BlockingRule) {
+	prefix = prefix.Masked()
+	addr := prefix.Addr()
+
+	root := &t.v6
+	if addr.Is4() {
+		root = &t.v4
+	}
+	if *root == nil {
+		*root = &subnetNode{}
+	}
+
+	node := *root
+	bytes := addr.AsSlice()
+	for i := range prefix.Bits() {
+		bit := bitAt(bytes, i)
+		if node.children[bit] == nil {
+			node.children[bit] = &subnetNode{}
+		}
+		node = node.children[bit]
+	}
+
+	if node.rule == nil {
+		node.rule = rule
+		t.size++
+	}
+}
+
+// Match returns the rule of the most specific subnet containing addr
+func (t *SubnetTrie) Match(addr netip.Addr) *domain.BlockingRule {
+	addr = addr.Unmap()
+	node := t.v6
+	if addr.Is4() {
+		node = t.v4
+	}
+
+	var match *domain.BlockingRule
+	bytes := addr.AsSlice()
+	for i := 0; node != nil; i++ {
+		if node.rule != nil {
+			match = node.rule
+		}
+		if i == len(bytes)*8 {
+			break
+		}
+		node = node.children[bitAt(bytes, i)]
+	}
+
+	return match
+}
+
+// Size returns the number of subnets in the trie
+func (t *SubnetTrie) Size() int {
+	return t.size
+}
+
+// bitAt returns bit i of an address, counting from the most significant
+func bitAt(bytes []byte, i int) int {
+	return int(bytes[i/8]>>(7-i%8)) & 1
+}
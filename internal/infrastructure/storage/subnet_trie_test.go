@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"fmt"
+	"net/netip"
+	"testing"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+func TestSubnetTrie_Match(t *testing.T) {
+	trie := NewSubnetTrie()
+	for _, subnet := range []string{"10.0.0.0/8", "10.20.0.0/16", "192.168.1.0/24", "2001:db8::/32", "0.0.0.0/0"} {
+		rule, _ := domain.NewBlockingRule(domain.BlockingTypeSubnet, subnet)
+		trie.Insert(netip.MustParsePrefix(subnet), rule)
+	}
+	// A duplicate subnet keeps the first rule
+	trie.Insert(netip.MustParsePrefix("10.20.0.0/16"), nil)
+
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"10.20.3.4", "10.20.0.0/16"},
+		{"10.21.0.1", "10.0.0.0/8"},
+		{"192.168.1.255", "192.168.1.0/24"},
+		{"::ffff:192.168.1.7", "192.168.1.0/24"},
+		{"8.8.8.8", "0.0.0.0/0"},
+		{"2001:db8::1", "2001:db8::/32"},
+		{"2001:db9::1", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			rule := trie.Match(netip.MustParseAddr(tt.addr))
+			got := ""
+			if rule != nil {
+				got = rule.Pattern
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	if trie.Size() != 5 {
+		t.Errorf("expected 5 subnets, got %d", trie.Size())
+	}
+}
+
+func BenchmarkSubnetTrie_Match(b *testing.B) {
+	trie := NewSubnetTrie()
+	for i := range 100000 {
+		subnet := fmt.Sprintf("10.%d.%d.0/24", i/256%256, i%256)
+		rule, _ := domain.NewBlockingRule(domain.BlockingTypeSubnet, subnet)
+		trie.Insert(netip.MustParsePrefix(subnet), rule)
+	}
+	addr := netip.MustParseAddr("172.16.0.1")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		trie.Match(addr)
+	}
+}
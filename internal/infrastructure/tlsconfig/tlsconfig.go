@@ -0,0 +1,202 @@
+// Package tlsconfig builds the TLS configuration of the servers from
+// certificate files, reloading them when they change on disk.
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// reloadInterval is how often the certificate files are checked for changes
+const reloadInterval = 10 * time.Second
+
+// Config locates the certificate files and sets the protocol requirements
+type Config struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile holds the CAs client certificates are verified against,
+	// enabling mutual TLS
+	ClientCAFile string
+	// RequireClientCert rejects handshakes without a verified client
+	// certificate instead of only verifying the ones presented
+	RequireClientCert bool
+	// MinVersion is the lowest TLS version accepted: "1.2" or "1.3"
+	MinVersion string
+}
+
+// ParseVersion parses a TLS version of the form "1.2"
+func ParseVersion(version string) (uint16, error) {
+	switch version {
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version %q, must be 1.2 or 1.3", version)
+	}
+}
+
+// Reloader serves the certificates of its files, reloading them when their
+// modification time or size changes. A failed reload keeps the certificates
+// loaded before.
+type Reloader struct {
+	cfg        Config
+	minVersion uint16
+
+	current atomic.Pointer[loaded]
+}
+
+// loaded is a consistent set of certificates and the file states they were
+// read from
+type loaded struct {
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	stamps    []fileStamp
+}
+
+// fileStamp identifies a version of a file
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// New loads the certificates of cfg
+func New(cfg Config) (*Reloader, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.New("TLS requires a certificate and a key file")
+	}
+	if cfg.RequireClientCert && cfg.ClientCAFile == "" {
+		return nil, errors.New("requiring client certificates needs a client CA file")
+	}
+	if cfg.MinVersion == "" {
+		cfg.MinVersion = "1.2"
+	}
+	minVersion, err := ParseVersion(cfg.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reloader{cfg: cfg, minVersion: minVersion}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// files lists the files the certificates are read from
+func (r *Reloader) files() []string {
+	files := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		files = append(files, r.cfg.ClientCAFile)
+	}
+	return files
+}
+
+// Reload reads the certificate files
+func (r *Reloader) Reload() error {
+	var stamps []fileStamp
+	for _, file := range r.files() {
+		stamp, err := stat(file)
+		if err != nil {
+			return err
+		}
+		stamps = append(stamps, stamp)
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("reading client CA file: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificate found in client CA file %s", r.cfg.ClientCAFile)
+		}
+	}
+
+	r.current.Store(&loaded{cert: &cert, clientCAs: clientCAs, stamps: stamps})
+	return nil
+}
+
+// changed reports whether a certificate file changed since it was loaded
+func (r *Reloader) changed() bool {
+	stamps := r.current.Load().stamps
+	for i, file := range r.files() {
+		stamp, err := stat(file)
+		if err != nil || stamp != stamps[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func stat(file string) (fileStamp, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return fileStamp{}, fmt.Errorf("reading TLS file: %w", err)
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// Run reloads the certificates whenever their files change, until ctx is
+// done
+func (r *Reloader) Run(ctx context.Context) {
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				slog.Error("Failed to reload TLS certificates, keeping the current ones", "error", err)
+				continue
+			}
+			slog.Info("TLS certificates reloaded", "cert_file", r.cfg.CertFile)
+		}
+	}
+}
+
+// ServerConfig returns a server TLS configuration negotiating nextProtos
+// with ALPN. Every handshake uses the certificates loaded last.
+func (r *Reloader) ServerConfig(nextProtos ...string) *tls.Config {
+	clientAuth := tls.NoClientCert
+	if r.cfg.ClientCAFile != "" {
+		clientAuth = tls.VerifyClientCertIfGiven
+		if r.cfg.RequireClientCert {
+			clientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	return &tls.Config{
+		MinVersion: r.minVersion,
+		NextProtos: nextProtos,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			current := r.current.Load()
+			return &tls.Config{
+				MinVersion:   r.minVersion,
+				NextProtos:   nextProtos,
+				Certificates: []tls.Certificate{*current.cert},
+				ClientAuth:   clientAuth,
+				ClientCAs:    current.clientCAs,
+			}, nil
+		},
+	}
+}
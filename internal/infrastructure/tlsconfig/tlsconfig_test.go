@@ -0,0 +1,204 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// issue creates a certificate for name signed by parent, or self-signed when
+// parent is nil
+func issue(t *testing.T, name string, isCA bool, parent *tls.Certificate) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := template, any(key)
+	if parent != nil {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, key.Public(), signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+// writePair writes cert and its key as PEM files
+func writePair(t *testing.T, cert tls.Certificate, certFile, keyFile string) {
+	t.Helper()
+
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// handshake connects a client presenting clientCert, if any, and returns the
+// server certificate it saw and the client chains the server verified
+func handshake(t *testing.T, server *tls.Config, roots *x509.CertPool, clientCert *tls.Certificate) (*x509.Certificate, [][]*x509.Certificate, error) {
+	t.Helper()
+
+	// Over TCP rather than a pipe, so that alerts sent after a TLS 1.3
+	// client finished its handshake do not block on an idle reader
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	clientConn, err := net.Dial("tcp", lis.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clientConn.Close()
+	serverConn, err := lis.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer serverConn.Close()
+
+	client := &tls.Config{RootCAs: roots, ServerName: "rkn-checker.internal", NextProtos: []string{"h2"}}
+	if clientCert != nil {
+		// Sent even when not issued by a CA the server asks for
+		client.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return clientCert, nil
+		}
+	}
+
+	done := make(chan error, 1)
+	var verified [][]*x509.Certificate
+	go func() {
+		conn := tls.Server(serverConn, server)
+		err := conn.Handshake()
+		verified = conn.ConnectionState().VerifiedChains
+		done <- err
+	}()
+
+	conn := tls.Client(clientConn, client)
+	clientErr := conn.Handshake()
+	serverErr := <-done
+	if clientErr != nil {
+		return nil, nil, clientErr
+	}
+	if serverErr != nil {
+		return nil, nil, serverErr
+	}
+	return conn.ConnectionState().PeerCertificates[0], verified, nil
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	caFile := filepath.Join(dir, "ca.crt")
+
+	ca := issue(t, "rkn-checker CA", true, nil)
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Certificate[0]}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	first := issue(t, "rkn-checker.internal", false, &ca)
+	writePair(t, first, certFile, keyFile)
+
+	reloader, err := New(Config{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile, MinVersion: "1.3"})
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	server := reloader.ServerConfig("h2")
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Leaf)
+
+	client := issue(t, "billing", false, &ca)
+	served, verified, err := handshake(t, server, roots, &client)
+	if err != nil {
+		t.Fatalf("unexpected handshake error: %v", err)
+	}
+	if !served.Equal(first.Leaf) {
+		t.Error("expected the loaded certificate to be served")
+	}
+	if len(verified) == 0 || verified[0][0].Subject.CommonName != "billing" {
+		t.Error("expected the client certificate to be verified")
+	}
+
+	if _, _, err := handshake(t, server, roots, nil); err != nil {
+		t.Errorf("expected client certificates to be optional, got %v", err)
+	}
+	stranger := issue(t, "stranger", false, nil)
+	if _, _, err := handshake(t, server, roots, &stranger); err == nil {
+		t.Error("expected a client certificate of another CA to be rejected")
+	}
+
+	if reloader.changed() {
+		t.Fatal("expected no change before the files are rewritten")
+	}
+	second := issue(t, "rkn-checker.internal", false, &ca)
+	writePair(t, second, certFile, keyFile)
+	// Guard against file systems with coarse modification times
+	os.Chtimes(certFile, time.Now(), time.Now().Add(time.Minute))
+	if !reloader.changed() {
+		t.Fatal("expected the rewritten files to be noticed")
+	}
+	if err := reloader.Reload(); err != nil {
+		t.Fatalf("Reload() unexpected error: %v", err)
+	}
+	if served, _, err := handshake(t, server, roots, nil); err != nil || !served.Equal(second.Leaf) {
+		t.Errorf("expected the reloaded certificate to be served, got %v", err)
+	}
+
+	if err := os.WriteFile(keyFile, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloader.Reload(); err == nil {
+		t.Error("expected an error for an invalid key")
+	}
+	if served, _, err := handshake(t, server, roots, nil); err != nil || !served.Equal(second.Leaf) {
+		t.Errorf("expected a failed reload to keep the current certificate, got %v", err)
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	tests := map[string]Config{
+		"missing key":            {CertFile: "tls.crt"},
+		"client cert without CA": {CertFile: "tls.crt", KeyFile: "tls.key", RequireClientCert: true},
+		"unsupported version":    {CertFile: "tls.crt", KeyFile: "tls.key", MinVersion: "1.1"},
+		"missing files":          {CertFile: "/nonexistent/tls.crt", KeyFile: "/nonexistent/tls.key"},
+	}
+	for name, cfg := range tests {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Package tracing exports OpenTelemetry traces over OTLP and defines the span
+// attributes shared by the instrumented layers. Spans are started from the
+// global tracer provider, which stays a no-op until Setup installs an exporter.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// ServiceName identifies the service in exported traces
+const ServiceName = "rkn-checker"
+
+// Span attributes recorded by the instrumented layers
+const (
+	RegistryVersionKey = attribute.Key("rkn.registry.version")
+	RegistrySizeKey    = attribute.Key("rkn.registry.size")
+	BlockedKey         = attribute.Key("rkn.blocked")
+	MatchTypeKey       = attribute.Key("rkn.match.type")
+	BloomPassedKey     = attribute.Key("rkn.bloom.passed")
+	SourceKey          = attribute.Key("rkn.source")
+	FormatKey          = attribute.Key("rkn.dump.format")
+	DumpSizeKey        = attribute.Key("rkn.dump.size")
+	EncodingKey        = attribute.Key("rkn.dump.encoding")
+	RowsKey            = attribute.Key("rkn.dump.rows")
+	AcceptedKey        = attribute.Key("rkn.dump.accepted")
+	RejectedKey        = attribute.Key("rkn.dump.rejected")
+	AttemptKey         = attribute.Key("rkn.update.attempt")
+)
+
+// Config holds the trace export settings
+type Config struct {
+	// Endpoint is the host:port of the OTLP gRPC collector
+	Endpoint string
+	// Insecure disables TLS to the collector
+	Insecure bool
+	// SampleRatio is the fraction of new traces sampled; traces started
+	// upstream follow the sampling decision of their parent
+	SampleRatio float64
+}
+
+// Setup exports traces to the OTLP collector of cfg and propagates W3C trace
+// context. The returned function flushes pending spans and stops the export.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	options := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		options = append(options, otlptracegrpc.WithInsecure())
+	}
+
+	exporter, err := otlptracegrpc.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(),
+		resource.NewSchemaless(semconv.ServiceName(ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("creating trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	SetPropagator()
+
+	return provider.Shutdown, nil
+}
+
+// SetPropagator propagates W3C trace context and baggage, so traces started
+// upstream continue through the service even when export is disabled
+func SetPropagator() {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// ResultAttributes describes the outcome of a check
+func ResultAttributes(result *domain.BlockingResult) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{BlockedKey.Bool(result.IsBlocked)}
+	if result.IsBlocked {
+		attrs = append(attrs, MatchTypeKey.String(result.Reason.String()))
+	}
+	if result.RegistryVersion != "" {
+		attrs = append(attrs, RegistryVersionKey.String(result.RegistryVersion))
+	}
+	return attrs
+}
+
+// RecordError marks span as failed with err
+func RecordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,66 @@
+// Package tracingtest records the spans started by tests in memory
+package tracingtest
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/tracing"
+)
+
+var (
+	installOnce sync.Once
+	exporter    = tracetest.NewInMemoryExporter()
+	// recording samples spans only while a test holds the exporter, keeping
+	// the other tests of the binary untraced
+	recording atomic.Bool
+)
+
+// Exporter returns an empty in-memory exporter receiving every span ended
+// during the test. Tracers obtained from the global provider stay bound to
+// the first provider installed, so it is installed once per test binary.
+func Exporter(t testing.TB) *tracetest.InMemoryExporter {
+	installOnce.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(
+			sdktrace.WithSyncer(exporter),
+			sdktrace.WithSampler(sampler{})))
+		tracing.SetPropagator()
+	})
+
+	exporter.Reset()
+	recording.Store(true)
+	t.Cleanup(func() {
+		recording.Store(false)
+		exporter.Reset()
+	})
+	return exporter
+}
+
+// sampler samples every span while a test is recording
+type sampler struct{}
+
+func (sampler) ShouldSample(params sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if recording.Load() {
+		return sdktrace.AlwaysSample().ShouldSample(params)
+	}
+	return sdktrace.NeverSample().ShouldSample(params)
+}
+
+func (sampler) Description() string {
+	return "tracingtest"
+}
+
+// Find returns the first recorded span with the given name, or nil
+func Find(spans tracetest.SpanStubs, name string) *tracetest.SpanStub {
+	for i := range spans {
+		if spans[i].Name == name {
+			return &spans[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package updater
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// GuardConfig holds the guardrails a fetched registry must pass before it
+// replaces the current one. Zero values disable the respective check.
+type GuardConfig struct {
+	MaxShrinkRatio float64  `yaml:"max_shrink_ratio"` // Largest allowed relative drop in entries (0.3 = 30%)
+	MaxGrowthRatio float64  `yaml:"max_growth_ratio"` // Largest allowed relative rise in entries (1.0 = 100%)
+	MinEntries     int      `yaml:"min_entries"`      // Smallest acceptable registry size
+	Canaries       []string `yaml:"canaries"`         // Values that must be present in every registry
+}
+
+// GuardViolation describes why a fetched registry was quarantined
+type GuardViolation struct {
+	Reason string
+}
+
+func (e *GuardViolation) Error() string {
+	return fmt.Sprintf("%s: %s", domain.ErrRegistryQuarantined, e.Reason)
+}
+
+func (e *GuardViolation) Unwrap() error {
+	return domain.ErrRegistryQuarantined
+}
+
+// checkGuards validates a candidate registry against the current store size
+func (c GuardConfig) checkGuards(candidate *domain.Registry, currentSize int) error {
+	size := candidate.Size()
+
+	if c.MinEntries > 0 && size < c.MinEntries {
+		return &GuardViolation{Reason: fmt.Sprintf("registry has %d entries, minimum is %d", size, c.MinEntries)}
+	}
+
+	// Relative limits need a baseline, so the very first load skips them
+	if currentSize > 0 {
+		change := float64(size-currentSize) / float64(currentSize)
+		if c.MaxShrinkRatio > 0 && -change > c.MaxShrinkRatio {
+			return &GuardViolation{Reason: fmt.Sprintf("registry shrank by %.1f%% (%d -> %d entries), limit is %.1f%%",
+				-change*100, currentSize, size, c.MaxShrinkRatio*100)}
+		}
+		if c.MaxGrowthRatio > 0 && change > c.MaxGrowthRatio {
+			return &GuardViolation{Reason: fmt.Sprintf("registry grew by %.1f%% (%d -> %d entries), limit is %.1f%%",
+				change*100, currentSize, size, c.MaxGrowthRatio*100)}
+		}
+	}
+
+	if missing := c.missingCanaries(candidate); len(missing) > 0 {
+		return &GuardViolation{Reason: fmt.Sprintf("canary entries missing: %s", strings.Join(missing, ", "))}
+	}
+
+	return nil
+}
+
+// missingCanaries returns the canaries that have no matching registry entry
+func (c GuardConfig) missingCanaries(candidate *domain.Registry) []string {
+	if len(c.Canaries) == 0 {
+		return nil
+	}
+
+	pending := make(map[string]bool, len(c.Canaries))
+	for _, canary := range c.Canaries {
+		pending[strings.ToLower(strings.TrimSpace(canary))] = true
+	}
+
+	for _, entry := range candidate.Entries {
+		if pending[entry.Value()] {
+			delete(pending, entry.Value())
+			if len(pending) == 0 {
+				return nil
+			}
+		}
+	}
+
+	var missing []string
+	for _, canary := range c.Canaries {
+		if pending[strings.ToLower(strings.TrimSpace(canary))] {
+			missing = append(missing, canary)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,103 @@
+package updater
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+// createSizedRegistry creates a registry with size distinct domain entries
+func createSizedRegistry(size int, extra ...string) *domain.Registry {
+	registry := domain.NewRegistry()
+	for i := 0; i < size; i++ {
+		entry, _ := domain.NewRegistryEntry(domain.BlockingTypeDomain, fmt.Sprintf("blocked%d.com", i))
+		registry.AddEntry(entry)
+	}
+	for _, value := range extra {
+		entry, _ := domain.NewRegistryEntry(domain.BlockingTypeDomain, value)
+		registry.AddEntry(entry)
+	}
+	return registry
+}
+
+func TestGuardConfig_checkGuards(t *testing.T) {
+	tests := []struct {
+		name        string
+		guard       GuardConfig
+		candidate   *domain.Registry
+		currentSize int
+		wantErr     bool
+	}{
+		{
+			name:        "disabled guards accept anything",
+			guard:       GuardConfig{},
+			candidate:   createSizedRegistry(1),
+			currentSize: 1000,
+		},
+		{
+			name:        "shrink within limit",
+			guard:       GuardConfig{MaxShrinkRatio: 0.3},
+			candidate:   createSizedRegistry(80),
+			currentSize: 100,
+		},
+		{
+			name:        "shrink beyond limit",
+			guard:       GuardConfig{MaxShrinkRatio: 0.3},
+			candidate:   createSizedRegistry(10),
+			currentSize: 100,
+			wantErr:     true,
+		},
+		{
+			name:        "growth beyond limit",
+			guard:       GuardConfig{MaxGrowthRatio: 1.0},
+			candidate:   createSizedRegistry(250),
+			currentSize: 100,
+			wantErr:     true,
+		},
+		{
+			name:      "first load skips relative limits",
+			guard:     GuardConfig{MaxShrinkRatio: 0.3, MaxGrowthRatio: 1.0},
+			candidate: createSizedRegistry(10),
+		},
+		{
+			name:      "below minimum size",
+			guard:     GuardConfig{MinEntries: 50},
+			candidate: createSizedRegistry(10),
+			wantErr:   true,
+		},
+		{
+			name:      "canaries present",
+			guard:     GuardConfig{Canaries: []string{"Canary.com", "blocked1.com"}},
+			candidate: createSizedRegistry(5, "canary.com"),
+		},
+		{
+			name:      "canary missing",
+			guard:     GuardConfig{Canaries: []string{"canary.com"}},
+			candidate: createSizedRegistry(5),
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.guard.checkGuards(tt.candidate, tt.currentSize)
+
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var violation *GuardViolation
+			if !errors.As(err, &violation) || violation.Reason == "" {
+				t.Fatalf("expected guard violation with a reason, got %v", err)
+			}
+			if !errors.Is(err, domain.ErrRegistryQuarantined) {
+				t.Errorf("expected error to match domain.ErrRegistryQuarantined, got %v", err)
+			}
+		})
+	}
+}
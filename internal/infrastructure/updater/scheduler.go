@@ -2,16 +2,27 @@ package updater
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"sort"
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/metrics"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/tracing"
 )
 
+// tracer records registry updates
+var tracer = otel.Tracer("github.com/kerim-dauren/rkn-checker/internal/infrastructure/updater")
+
 // RegistryClient represents the interface for fetching registry data
 type RegistryClient interface {
-	FetchRegistry(ctx context.Context) (*domain.Registry, error)
+	FetchRegistry(ctx context.Context) (*domain.Registry, *domain.IngestReport, error)
 }
 
 // RegistryStore represents the interface for storing registry data
@@ -19,6 +30,32 @@ type RegistryStore interface {
 	Update(registry *domain.Registry) error
 	GetLastUpdateTime() time.Time
 	Size() int
+	Clear()
+}
+
+// UpdateListener is notified after a registry has been applied to the store.
+// previous is nil for the first registry applied since startup.
+type UpdateListener interface {
+	OnRegistryUpdate(previous, current *domain.Registry, report *domain.IngestReport)
+}
+
+// ClearListener is implemented by update listeners that keep state derived
+// from the applied registry, which they drop when the registry is cleared
+type ClearListener interface {
+	OnRegistryCleared()
+}
+
+// EventListener is notified of failed updates and of registry sources that
+// become unhealthy
+type EventListener interface {
+	OnUpdateFailed(status Status)
+	OnSourceUnhealthy(source string)
+}
+
+// SourceHealthReporter is implemented by registry clients that can report
+// the health of their sources
+type SourceHealthReporter interface {
+	GetHealthStatus(ctx context.Context) map[string]bool
 }
 
 // Scheduler manages automatic registry updates
@@ -32,28 +69,47 @@ type Scheduler struct {
 	maxRetries    int
 	retryDelay    time.Duration
 	updateTimeout time.Duration
+	guard         GuardConfig
+
+	// applyMu serializes store updates from the update loop and admin overrides
+	applyMu sync.Mutex
 
 	// State
 	mu                  sync.RWMutex
 	running             bool
+	paused              bool
 	lastUpdate          time.Time
 	lastError           error
 	consecutiveFailures int
 	totalUpdates        int
 	successfulUpdates   int
+	lastIngestReport    *domain.IngestReport
+	quarantine          *quarantine
+	current             *domain.Registry
+	listeners           []UpdateListener
+	eventListeners      []EventListener
+	sourceHealth        map[string]bool
 
 	// Control channels
-	stopCh    chan struct{}
-	triggerCh chan struct{}
-	doneCh    chan struct{}
+	stopCh     chan struct{}
+	triggerCh  chan struct{}
+	intervalCh chan struct{}
+	doneCh     chan struct{}
 }
 
 // Config holds configuration for the update scheduler
 type Config struct {
-	Interval      time.Duration // How often to update (e.g., 48 hours)
-	MaxRetries    int           // Maximum retry attempts per update
-	RetryDelay    time.Duration // Delay between retries
-	UpdateTimeout time.Duration // Timeout for each update operation
+	Interval      time.Duration `yaml:"interval"`       // How often to update (e.g., 48 hours)
+	MaxRetries    int           `yaml:"max_retries"`    // Maximum retry attempts per update
+	RetryDelay    time.Duration `yaml:"retry_delay"`    // Delay between retries
+	UpdateTimeout time.Duration `yaml:"update_timeout"` // Timeout for each update operation
+	Guard         GuardConfig   `yaml:"guard"`          // Guardrails checked before a registry is applied
+}
+
+// quarantine holds a fetched registry that tripped a guardrail
+type quarantine struct {
+	registry *domain.Registry
+	info     domain.QuarantinedUpdate
 }
 
 // DefaultConfig returns sensible default configuration
@@ -75,12 +131,33 @@ func NewScheduler(client RegistryClient, store RegistryStore, config Config) *Sc
 		maxRetries:    config.MaxRetries,
 		retryDelay:    config.RetryDelay,
 		updateTimeout: config.UpdateTimeout,
+		guard:         config.Guard,
+		sourceHealth:  make(map[string]bool),
 		stopCh:        make(chan struct{}),
 		triggerCh:     make(chan struct{}, 1),
+		intervalCh:    make(chan struct{}, 1),
 		doneCh:        make(chan struct{}),
 	}
 }
 
+// AddListener registers a listener for applied registry updates. Listeners
+// run synchronously in the update path, in registration order.
+func (s *Scheduler) AddListener(listener UpdateListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.listeners = append(s.listeners, listener)
+}
+
+// AddEventListener registers a listener for failed updates and unhealthy
+// sources. Event listeners run synchronously in the update path.
+func (s *Scheduler) AddEventListener(listener EventListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.eventListeners = append(s.eventListeners, listener)
+}
+
 // Start begins the update scheduler
 func (s *Scheduler) Start(ctx context.Context) error {
 	s.mu.Lock()
@@ -113,7 +190,7 @@ func (s *Scheduler) Stop() error {
 	return nil
 }
 
-// TriggerUpdate triggers an immediate update
+// TriggerUpdate triggers an immediate update, even while updates are paused
 func (s *Scheduler) TriggerUpdate() {
 	select {
 	case s.triggerCh <- struct{}{}:
@@ -122,6 +199,86 @@ func (s *Scheduler) TriggerUpdate() {
 	}
 }
 
+// SetInterval changes how often scheduled updates run. The next scheduled
+// update runs one new interval from now.
+func (s *Scheduler) SetInterval(interval time.Duration) {
+	s.mu.Lock()
+	s.interval = interval
+	s.mu.Unlock()
+
+	select {
+	case s.intervalCh <- struct{}{}:
+	default:
+		// Ticker reset already pending
+	}
+}
+
+// Interval returns how often scheduled updates run
+func (s *Scheduler) Interval() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.interval
+}
+
+// Pause stops scheduled updates until Resume is called. Triggered updates
+// still run.
+func (s *Scheduler) Pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.paused = true
+}
+
+// Resume restarts scheduled updates
+func (s *Scheduler) Resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.paused = false
+}
+
+// IsPaused reports whether scheduled updates are paused
+func (s *Scheduler) IsPaused() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.paused
+}
+
+// ClearRegistry removes every entry from the store and notifies the
+// listeners implementing ClearListener. The next applied update is treated
+// as the first since startup.
+func (s *Scheduler) ClearRegistry() {
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+
+	s.store.Clear()
+
+	s.mu.Lock()
+	s.current = nil
+	listeners := s.listeners
+	s.mu.Unlock()
+
+	for _, listener := range listeners {
+		if cleared, ok := listener.(ClearListener); ok {
+			cleared.OnRegistryCleared()
+		}
+	}
+
+	slog.Warn("Registry cleared")
+}
+
+// SourceHealth returns the health of each registry source, or nil when the
+// client does not report source health
+func (s *Scheduler) SourceHealth(ctx context.Context) map[string]bool {
+	reporter, ok := s.client.(SourceHealthReporter)
+	if !ok {
+		return nil
+	}
+	return reporter.GetHealthStatus(ctx)
+}
+
 // run is the main scheduler loop
 func (s *Scheduler) run(ctx context.Context) {
 	defer close(s.doneCh)
@@ -131,7 +288,7 @@ func (s *Scheduler) run(ctx context.Context) {
 		s.mu.Unlock()
 	}()
 
-	ticker := time.NewTicker(s.interval)
+	ticker := time.NewTicker(s.Interval())
 	defer ticker.Stop()
 
 	// Perform initial update
@@ -144,9 +301,15 @@ func (s *Scheduler) run(ctx context.Context) {
 		case <-s.stopCh:
 			return
 		case <-ticker.C:
+			if s.IsPaused() {
+				slog.Info("Scheduled registry update skipped, updates are paused")
+				continue
+			}
 			s.performUpdate(ctx)
 		case <-s.triggerCh:
 			s.performUpdate(ctx)
+		case <-s.intervalCh:
+			ticker.Reset(s.Interval())
 		}
 	}
 }
@@ -157,73 +320,257 @@ func (s *Scheduler) performUpdate(ctx context.Context) {
 	s.totalUpdates++
 	s.mu.Unlock()
 
-	updateCtx, cancel := context.WithTimeout(ctx, s.updateTimeout)
+	defer s.checkSources(ctx)
+
+	start := time.Now()
+	result := metrics.ResultFailure
+	defer func() {
+		metrics.UpdateDuration.WithLabelValues(result).Observe(time.Since(start).Seconds())
+	}()
+
+	updateCtx, span := tracer.Start(ctx, "Scheduler.Update")
+	defer span.End()
+
+	updateCtx, cancel := context.WithTimeout(updateCtx, s.updateTimeout)
 	defer cancel()
 
+	report, err := s.retryUpdate(updateCtx)
+	if err != nil {
+		tracing.RecordError(span, err)
+		s.recordFailure(err)
+		return
+	}
+
+	result = metrics.ResultSuccess
+	s.recordSuccess(report)
+}
+
+// retryUpdate runs update attempts with exponential backoff until one
+// succeeds, fails for good or the retries run out
+func (s *Scheduler) retryUpdate(ctx context.Context) (*domain.IngestReport, error) {
+	span := trace.SpanFromContext(ctx)
+
 	var lastErr error
 	for attempt := 0; attempt < s.maxRetries; attempt++ {
 		if attempt > 0 {
 			// Exponential backoff
 			delay := s.retryDelay * time.Duration(1<<uint(attempt-1))
 			select {
-			case <-updateCtx.Done():
-				s.recordFailure(updateCtx.Err())
-				return
+			case <-ctx.Done():
+				return nil, ctx.Err()
 			case <-time.After(delay):
 			}
 		}
 
-		err := s.executeUpdate(updateCtx)
+		metrics.UpdateAttempts.Inc()
+		span.SetAttributes(tracing.AttemptKey.Int(attempt + 1))
+		report, err := s.executeUpdate(ctx)
 		if err == nil {
-			s.recordSuccess()
-			return
+			return report, nil
+		}
+
+		// A dump that fails signature verification is never applied, and
+		// retrying would only download the same rejected data again
+		if errors.Is(err, domain.ErrRegistrySignatureInvalid) {
+			return nil, fmt.Errorf("registry rejected: %w", err)
+		}
+
+		// A quarantined registry waits for the next scheduled update or an
+		// explicit admin override
+		if errors.Is(err, domain.ErrRegistryQuarantined) {
+			return nil, err
 		}
 
 		lastErr = err
 	}
 
-	s.recordFailure(fmt.Errorf("all retry attempts failed, last error: %w", lastErr))
+	return nil, fmt.Errorf("all retry attempts failed, last error: %w", lastErr)
 }
 
 // executeUpdate performs a single update attempt
-func (s *Scheduler) executeUpdate(ctx context.Context) error {
+func (s *Scheduler) executeUpdate(ctx context.Context) (*domain.IngestReport, error) {
 	// Fetch new registry data
-	registry, err := s.client.FetchRegistry(ctx)
+	registry, report, err := s.client.FetchRegistry(ctx)
 	if err != nil {
-		return fmt.Errorf("fetching registry: %w", err)
+		return nil, fmt.Errorf("fetching registry: %w", err)
 	}
 
 	// Validate registry
 	if registry.Size() == 0 {
-		return fmt.Errorf("received empty registry")
+		return nil, fmt.Errorf("received empty registry")
 	}
 
+	trace.SpanFromContext(ctx).SetAttributes(
+		tracing.RegistryVersionKey.String(registry.Version),
+		tracing.RegistrySizeKey.Int(registry.Size()))
+
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+
+	currentSize := s.store.Size()
+	if err := s.guard.checkGuards(registry, currentSize); err != nil {
+		s.quarantineUpdate(registry, report, currentSize, err)
+		return nil, err
+	}
+
+	if err := s.applyRegistry(ctx, registry, report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// applyRegistry replaces the store contents and notifies listeners; callers
+// must hold applyMu
+func (s *Scheduler) applyRegistry(ctx context.Context, registry *domain.Registry, report *domain.IngestReport) error {
+	_, span := tracer.Start(ctx, "swap", trace.WithAttributes(
+		tracing.RegistryVersionKey.String(registry.Version),
+		tracing.RegistrySizeKey.Int(registry.Size())))
+
 	// Update store atomically
-	if err := s.store.Update(registry); err != nil {
+	err := s.store.Update(registry)
+	if err != nil {
+		tracing.RecordError(span, err)
+	}
+	span.End()
+	if err != nil {
 		return fmt.Errorf("updating store: %w", err)
 	}
 
+	s.mu.Lock()
+	previous := s.current
+	s.current = registry
+	listeners := s.listeners
+	s.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener.OnRegistryUpdate(previous, registry, report)
+	}
+
+	return nil
+}
+
+// quarantineUpdate keeps a registry that tripped a guard for later review
+func (s *Scheduler) quarantineUpdate(registry *domain.Registry, report *domain.IngestReport, currentSize int, err error) {
+	var violation *GuardViolation
+	reason := err.Error()
+	if errors.As(err, &violation) {
+		reason = violation.Reason
+	}
+
+	slog.Warn("Registry update quarantined",
+		"reason", reason,
+		"current_size", currentSize,
+		"candidate_size", registry.Size())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.quarantine = &quarantine{
+		registry: registry,
+		info: domain.QuarantinedUpdate{
+			Reason:        reason,
+			QuarantinedAt: time.Now(),
+			CurrentSize:   currentSize,
+			CandidateSize: registry.Size(),
+			Report:        report,
+		},
+	}
+}
+
+// Quarantined returns the update currently held back by a guardrail, or nil
+func (s *Scheduler) Quarantined() *domain.QuarantinedUpdate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.quarantinedInfo()
+}
+
+// ApplyQuarantined force-applies the quarantined registry, bypassing guardrails
+func (s *Scheduler) ApplyQuarantined() error {
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+
+	s.mu.RLock()
+	held := s.quarantine
+	s.mu.RUnlock()
+
+	if held == nil {
+		return domain.ErrNoQuarantinedUpdate
+	}
+
+	if err := s.applyRegistry(context.Background(), held.registry, held.info.Report); err != nil {
+		return err
+	}
+
+	slog.Warn("Quarantined registry update force-applied",
+		"reason", held.info.Reason,
+		"size", held.registry.Size())
+
+	s.recordSuccess(held.info.Report)
 	return nil
 }
 
 // recordSuccess records a successful update
-func (s *Scheduler) recordSuccess() {
+func (s *Scheduler) recordSuccess(report *domain.IngestReport) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	s.lastUpdate = time.Now()
+	s.lastIngestReport = report
+	s.quarantine = nil
 	s.lastError = nil
 	s.consecutiveFailures = 0
 	s.successfulUpdates++
 }
 
-// recordFailure records a failed update
+// recordFailure records a failed update and notifies event listeners
 func (s *Scheduler) recordFailure(err error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	metrics.UpdateFailures.Inc()
 
+	s.mu.Lock()
 	s.lastError = err
 	s.consecutiveFailures++
+	listeners := s.eventListeners
+	s.mu.Unlock()
+
+	if len(listeners) == 0 {
+		return
+	}
+
+	status := s.GetStatus()
+	for _, listener := range listeners {
+		listener.OnUpdateFailed(status)
+	}
+}
+
+// checkSources notifies event listeners of sources that were healthy, or not
+// yet checked, and are now reported unhealthy by the client
+func (s *Scheduler) checkSources(ctx context.Context) {
+	reporter, ok := s.client.(SourceHealthReporter)
+	if !ok {
+		return
+	}
+	health := reporter.GetHealthStatus(ctx)
+
+	s.mu.Lock()
+	var unhealthy []string
+	for source, healthy := range health {
+		if wasHealthy, seen := s.sourceHealth[source]; !healthy && (wasHealthy || !seen) {
+			unhealthy = append(unhealthy, source)
+		}
+		s.sourceHealth[source] = healthy
+	}
+	listeners := s.eventListeners
+	s.mu.Unlock()
+
+	sort.Strings(unhealthy)
+	for _, source := range unhealthy {
+		slog.Warn("Registry source became unhealthy", "source", source)
+		for _, listener := range listeners {
+			listener.OnSourceUnhealthy(source)
+		}
+	}
 }
 
 // GetStatus returns the current scheduler status
@@ -233,6 +580,7 @@ func (s *Scheduler) GetStatus() Status {
 
 	return Status{
 		Running:             s.running,
+		Paused:              s.paused,
 		LastUpdate:          s.lastUpdate,
 		LastError:           s.lastError,
 		ConsecutiveFailures: s.consecutiveFailures,
@@ -240,11 +588,35 @@ func (s *Scheduler) GetStatus() Status {
 		SuccessfulUpdates:   s.successfulUpdates,
 		NextUpdate:          s.getNextUpdateTime(),
 		RegistrySize:        s.store.Size(),
+		LastIngestReport:    s.lastIngestReport,
+		Quarantined:         s.quarantinedInfo(),
 	}
 }
 
-// getNextUpdateTime calculates when the next update should occur
+// quarantinedInfo returns the quarantine details; callers must hold s.mu
+func (s *Scheduler) quarantinedInfo() *domain.QuarantinedUpdate {
+	if s.quarantine == nil {
+		return nil
+	}
+	info := s.quarantine.info
+	return &info
+}
+
+// LastIngestReport returns the ingest report of the last applied update, or
+// nil if no update has been applied yet
+func (s *Scheduler) LastIngestReport() *domain.IngestReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.lastIngestReport
+}
+
+// getNextUpdateTime calculates when the next update should occur; it is
+// zero while updates are paused
 func (s *Scheduler) getNextUpdateTime() time.Time {
+	if s.paused {
+		return time.Time{}
+	}
 	if s.lastUpdate.IsZero() {
 		return time.Now()
 	}
@@ -261,8 +633,9 @@ func (s *Scheduler) IsHealthy() bool {
 		return false
 	}
 
-	// Consider unhealthy if no successful update in too long
-	if !s.lastUpdate.IsZero() && time.Since(s.lastUpdate) > s.interval*2 {
+	// Consider unhealthy if no successful update in too long, unless updates
+	// were paused on purpose; the registry age bounds how long that may last
+	if !s.paused && !s.lastUpdate.IsZero() && time.Since(s.lastUpdate) > s.interval*2 {
 		return false
 	}
 
@@ -272,6 +645,7 @@ func (s *Scheduler) IsHealthy() bool {
 // Status represents the current state of the scheduler
 type Status struct {
 	Running             bool
+	Paused              bool
 	LastUpdate          time.Time
 	LastError           error
 	ConsecutiveFailures int
@@ -279,6 +653,8 @@ type Status struct {
 	SuccessfulUpdates   int
 	NextUpdate          time.Time
 	RegistrySize        int
+	LastIngestReport    *domain.IngestReport
+	Quarantined         *domain.QuarantinedUpdate
 }
 
 // SuccessRate returns the success rate as a percentage
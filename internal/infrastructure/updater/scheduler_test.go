@@ -3,11 +3,17 @@ package updater
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
 	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/tracing"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/tracing/tracingtest"
 )
 
 // createTestRegistry creates a registry with one entry for testing
@@ -21,19 +27,20 @@ func createTestRegistry() *domain.Registry {
 // mockRegistryClient is a test implementation
 type mockRegistryClient struct {
 	registry  *domain.Registry
+	report    *domain.IngestReport
 	err       error
 	callCount int
 	mu        sync.Mutex
 }
 
-func (m *mockRegistryClient) FetchRegistry(ctx context.Context) (*domain.Registry, error) {
+func (m *mockRegistryClient) FetchRegistry(ctx context.Context) (*domain.Registry, *domain.IngestReport, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.callCount++
 	if m.err != nil {
-		return nil, m.err
+		return nil, nil, m.err
 	}
-	return m.registry, nil
+	return m.registry, m.report, nil
 }
 
 // mockRegistryStore is a test implementation
@@ -72,6 +79,12 @@ func (m *mockRegistryStore) Size() int {
 	return m.registry.Size()
 }
 
+func (m *mockRegistryStore) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.registry = nil
+}
+
 func (m *mockRegistryStore) GetUpdateCount() int {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -213,6 +226,43 @@ func TestScheduler_PerformUpdate_Success(t *testing.T) {
 	}
 }
 
+func TestScheduler_PerformUpdate_RecordsIngestReport(t *testing.T) {
+	report := domain.NewIngestReport("csv")
+	report.AddAccepted(domain.BlockingTypeDomain)
+	report.AddRejected(3, "invalid..domain", "unrecognized entry format")
+
+	client := &mockRegistryClient{
+		registry: createTestRegistry(),
+		report:   report,
+	}
+	store := &mockRegistryStore{}
+
+	scheduler := NewScheduler(client, store, Config{
+		Interval:      1 * time.Hour,
+		MaxRetries:    1,
+		RetryDelay:    10 * time.Millisecond,
+		UpdateTimeout: 1 * time.Second,
+	})
+
+	if scheduler.LastIngestReport() != nil {
+		t.Fatal("expected no ingest report before the first update")
+	}
+
+	scheduler.performUpdate(context.Background())
+
+	if got := scheduler.GetStatus().LastIngestReport; got != report {
+		t.Errorf("expected status to carry the ingest report, got %+v", got)
+	}
+
+	// A failed update keeps the report of the last applied one
+	client.err = errors.New("network error")
+	scheduler.performUpdate(context.Background())
+
+	if got := scheduler.LastIngestReport(); got != report {
+		t.Errorf("expected last applied ingest report to be kept, got %+v", got)
+	}
+}
+
 func TestScheduler_PerformUpdate_ClientError(t *testing.T) {
 	client := &mockRegistryClient{
 		err: errors.New("client error"),
@@ -310,6 +360,209 @@ func TestScheduler_PerformUpdate_EmptyRegistry(t *testing.T) {
 	}
 }
 
+func TestScheduler_PerformUpdate_SignatureRejected(t *testing.T) {
+	client := &mockRegistryClient{
+		err: fmt.Errorf("fetching: %w", domain.ErrRegistrySignatureInvalid),
+	}
+	store := &mockRegistryStore{}
+
+	config := Config{
+		Interval:      1 * time.Hour,
+		MaxRetries:    3,
+		RetryDelay:    1 * time.Millisecond,
+		UpdateTimeout: 1 * time.Second,
+	}
+
+	scheduler := NewScheduler(client, store, config)
+	scheduler.performUpdate(context.Background())
+
+	status := scheduler.GetStatus()
+	if !errors.Is(status.LastError, domain.ErrRegistrySignatureInvalid) {
+		t.Errorf("expected signature error, got %v", status.LastError)
+	}
+
+	// Rejected dumps are not retried
+	if client.callCount != 1 {
+		t.Errorf("expected 1 client call, got %d", client.callCount)
+	}
+
+	if store.GetUpdateCount() != 0 {
+		t.Errorf("expected no store updates, got %d", store.GetUpdateCount())
+	}
+}
+
+func TestScheduler_PerformUpdate_Quarantine(t *testing.T) {
+	current := createSizedRegistry(100)
+	truncated := createSizedRegistry(10)
+
+	client := &mockRegistryClient{registry: truncated, report: domain.NewIngestReport("csv")}
+	store := &mockRegistryStore{registry: current}
+
+	scheduler := NewScheduler(client, store, Config{
+		Interval:      1 * time.Hour,
+		MaxRetries:    3,
+		RetryDelay:    10 * time.Millisecond,
+		UpdateTimeout: 1 * time.Second,
+		Guard:         GuardConfig{MaxShrinkRatio: 0.3},
+	})
+
+	scheduler.performUpdate(context.Background())
+
+	// Quarantine is not retried and keeps the old registry
+	if client.callCount != 1 {
+		t.Errorf("expected 1 fetch attempt, got %d", client.callCount)
+	}
+	if store.GetUpdateCount() != 0 || store.Size() != 100 {
+		t.Fatalf("expected current registry to be kept, store has %d entries", store.Size())
+	}
+
+	status := scheduler.GetStatus()
+	if !errors.Is(status.LastError, domain.ErrRegistryQuarantined) {
+		t.Errorf("expected quarantine error, got %v", status.LastError)
+	}
+	if status.Quarantined == nil {
+		t.Fatal("expected quarantined update in status")
+	}
+	if status.Quarantined.CurrentSize != 100 || status.Quarantined.CandidateSize != 10 || status.Quarantined.Reason == "" {
+		t.Errorf("unexpected quarantine info: %+v", status.Quarantined)
+	}
+
+	// Admin override applies the held registry
+	if err := scheduler.ApplyQuarantined(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store.Size() != 10 {
+		t.Errorf("expected quarantined registry to be applied, store has %d entries", store.Size())
+	}
+	if scheduler.Quarantined() != nil {
+		t.Error("expected quarantine to be cleared after apply")
+	}
+	if scheduler.LastIngestReport() != client.report {
+		t.Error("expected ingest report of applied registry")
+	}
+
+	if err := scheduler.ApplyQuarantined(); !errors.Is(err, domain.ErrNoQuarantinedUpdate) {
+		t.Errorf("expected ErrNoQuarantinedUpdate, got %v", err)
+	}
+}
+
+// recordingListener records the registries it is notified about
+type recordingListener struct {
+	previous []*domain.Registry
+	current  []*domain.Registry
+	cleared  int
+}
+
+func (l *recordingListener) OnRegistryCleared() {
+	l.cleared++
+}
+
+func (l *recordingListener) OnRegistryUpdate(previous, current *domain.Registry, report *domain.IngestReport) {
+	l.previous = append(l.previous, previous)
+	l.current = append(l.current, current)
+}
+
+func TestScheduler_Listeners(t *testing.T) {
+	first := createSizedRegistry(10)
+	second := createSizedRegistry(11)
+
+	client := &mockRegistryClient{registry: first}
+	listener := &recordingListener{}
+
+	scheduler := NewScheduler(client, &mockRegistryStore{}, Config{
+		Interval:      1 * time.Hour,
+		MaxRetries:    1,
+		RetryDelay:    10 * time.Millisecond,
+		UpdateTimeout: 1 * time.Second,
+	})
+	scheduler.AddListener(listener)
+
+	scheduler.performUpdate(context.Background())
+	client.registry = second
+	scheduler.performUpdate(context.Background())
+
+	// A failed update does not notify listeners
+	client.err = errors.New("network error")
+	scheduler.performUpdate(context.Background())
+
+	if len(listener.current) != 2 {
+		t.Fatalf("expected 2 notifications, got %d", len(listener.current))
+	}
+	if listener.previous[0] != nil || listener.current[0] != first {
+		t.Error("expected first notification to have no previous registry")
+	}
+	if listener.previous[1] != first || listener.current[1] != second {
+		t.Error("expected second notification to carry both registries")
+	}
+}
+
+// healthReportingClient reports the health of its sources
+type healthReportingClient struct {
+	mockRegistryClient
+	health map[string]bool
+}
+
+func (c *healthReportingClient) GetHealthStatus(ctx context.Context) map[string]bool {
+	return c.health
+}
+
+// recordingEventListener records failed updates and unhealthy sources
+type recordingEventListener struct {
+	failures  []Status
+	unhealthy []string
+}
+
+func (l *recordingEventListener) OnUpdateFailed(status Status) {
+	l.failures = append(l.failures, status)
+}
+
+func (l *recordingEventListener) OnSourceUnhealthy(source string) {
+	l.unhealthy = append(l.unhealthy, source)
+}
+
+func TestScheduler_EventListeners(t *testing.T) {
+	client := &healthReportingClient{
+		mockRegistryClient: mockRegistryClient{err: errors.New("network error")},
+		health:             map[string]bool{"official": false, "mirror": true},
+	}
+	listener := &recordingEventListener{}
+
+	scheduler := NewScheduler(client, &mockRegistryStore{}, Config{
+		Interval:      1 * time.Hour,
+		MaxRetries:    1,
+		RetryDelay:    10 * time.Millisecond,
+		UpdateTimeout: 1 * time.Second,
+	})
+	scheduler.AddEventListener(listener)
+
+	scheduler.performUpdate(context.Background())
+	if len(listener.failures) != 1 || listener.failures[0].LastError == nil || listener.failures[0].ConsecutiveFailures != 1 {
+		t.Fatalf("expected one failure notification with the last error, got %+v", listener.failures)
+	}
+
+	// A source that stays unhealthy is reported once
+	scheduler.performUpdate(context.Background())
+	if len(listener.unhealthy) != 1 || listener.unhealthy[0] != "official" {
+		t.Fatalf("expected official to be reported unhealthy once, got %v", listener.unhealthy)
+	}
+
+	// A successful update is not a failure, and a source that recovered is
+	// reported again when it turns unhealthy
+	client.err = nil
+	client.registry = createTestRegistry()
+	client.health = map[string]bool{"official": true, "mirror": false}
+	scheduler.performUpdate(context.Background())
+	client.health = map[string]bool{"official": false, "mirror": false}
+	scheduler.performUpdate(context.Background())
+
+	if len(listener.failures) != 2 {
+		t.Errorf("expected 2 failure notifications, got %d", len(listener.failures))
+	}
+	if len(listener.unhealthy) != 3 || listener.unhealthy[1] != "mirror" || listener.unhealthy[2] != "official" {
+		t.Errorf("expected mirror and then official to be reported, got %v", listener.unhealthy)
+	}
+}
+
 func TestScheduler_TriggerUpdate(t *testing.T) {
 	client := &mockRegistryClient{
 		registry: createTestRegistry(),
@@ -375,6 +628,16 @@ func TestScheduler_IsHealthy(t *testing.T) {
 	if scheduler.IsHealthy() {
 		t.Error("scheduler should not be healthy with very old last update")
 	}
+
+	// Paused updates are not overdue
+	scheduler.Pause()
+	if !scheduler.IsHealthy() {
+		t.Error("scheduler should be healthy while updates are paused")
+	}
+	scheduler.Resume()
+	if scheduler.IsHealthy() {
+		t.Error("scheduler should not be healthy with very old last update once resumed")
+	}
 }
 
 func TestScheduler_PeriodicUpdates(t *testing.T) {
@@ -407,6 +670,136 @@ func TestScheduler_PeriodicUpdates(t *testing.T) {
 	}
 }
 
+func TestScheduler_SetInterval(t *testing.T) {
+	client := &mockRegistryClient{
+		registry: createTestRegistry(),
+	}
+	store := &mockRegistryStore{}
+
+	config := Config{
+		Interval:      time.Hour,
+		MaxRetries:    1,
+		RetryDelay:    1 * time.Millisecond,
+		UpdateTimeout: 1 * time.Second,
+	}
+
+	scheduler := NewScheduler(client, store, config)
+
+	ctx := context.Background()
+	if err := scheduler.Start(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer scheduler.Stop()
+
+	// Only the initial update runs with the hourly interval
+	time.Sleep(50 * time.Millisecond)
+	if count := store.GetUpdateCount(); count != 1 {
+		t.Fatalf("expected 1 update before the interval changes, got %d", count)
+	}
+
+	scheduler.SetInterval(20 * time.Millisecond)
+	if scheduler.Interval() != 20*time.Millisecond {
+		t.Errorf("expected interval 20ms, got %v", scheduler.Interval())
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if count := store.GetUpdateCount(); count < 3 {
+		t.Errorf("expected scheduled updates at the new interval, got %d updates", count)
+	}
+}
+
+func TestScheduler_PauseResume(t *testing.T) {
+	client := &mockRegistryClient{
+		registry: createTestRegistry(),
+	}
+	store := &mockRegistryStore{}
+
+	config := Config{
+		Interval:      20 * time.Millisecond,
+		MaxRetries:    1,
+		RetryDelay:    1 * time.Millisecond,
+		UpdateTimeout: 1 * time.Second,
+	}
+
+	scheduler := NewScheduler(client, store, config)
+	scheduler.Pause()
+
+	if status := scheduler.GetStatus(); !status.Paused || !status.NextUpdate.IsZero() {
+		t.Errorf("expected a paused status without a next update, got %+v", status)
+	}
+
+	if err := scheduler.Start(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer scheduler.Stop()
+
+	// Only the initial update runs while paused
+	time.Sleep(100 * time.Millisecond)
+	if count := store.GetUpdateCount(); count != 1 {
+		t.Fatalf("expected only the initial update while paused, got %d", count)
+	}
+
+	// Triggered updates still run
+	scheduler.TriggerUpdate()
+	time.Sleep(50 * time.Millisecond)
+	if count := store.GetUpdateCount(); count != 2 {
+		t.Fatalf("expected the triggered update while paused, got %d updates", count)
+	}
+
+	scheduler.Resume()
+	time.Sleep(100 * time.Millisecond)
+	if count := store.GetUpdateCount(); count < 3 {
+		t.Errorf("expected scheduled updates after resuming, got %d updates", count)
+	}
+}
+
+func TestScheduler_ClearRegistry(t *testing.T) {
+	client := &mockRegistryClient{
+		registry: createTestRegistry(),
+	}
+	store := &mockRegistryStore{}
+	listener := &recordingListener{}
+
+	scheduler := NewScheduler(client, store, Config{
+		Interval:      time.Hour,
+		MaxRetries:    1,
+		RetryDelay:    time.Millisecond,
+		UpdateTimeout: time.Second,
+	})
+	scheduler.AddListener(listener)
+
+	scheduler.performUpdate(context.Background())
+	scheduler.ClearRegistry()
+
+	if size := scheduler.GetStatus().RegistrySize; size != 0 {
+		t.Errorf("expected an empty registry after clearing, got %d entries", size)
+	}
+	if listener.cleared != 1 {
+		t.Errorf("expected the listener to be told of the clear once, got %d", listener.cleared)
+	}
+
+	// The update after clearing has no previous registry
+	scheduler.performUpdate(context.Background())
+	if len(listener.previous) != 2 || listener.previous[1] != nil {
+		t.Errorf("expected no previous registry after clearing, got %v", listener.previous)
+	}
+}
+
+func TestScheduler_SourceHealth(t *testing.T) {
+	plain := NewScheduler(&mockRegistryClient{}, &mockRegistryStore{}, DefaultConfig())
+	if health := plain.SourceHealth(context.Background()); health != nil {
+		t.Errorf("expected no source health from a client without it, got %v", health)
+	}
+
+	client := &healthReportingClient{health: map[string]bool{"official": true, "mirror": false}}
+	scheduler := NewScheduler(client, &mockRegistryStore{}, DefaultConfig())
+
+	health := scheduler.SourceHealth(context.Background())
+	if len(health) != 2 || !health["official"] || health["mirror"] {
+		t.Errorf("unexpected source health %v", health)
+	}
+}
+
 func TestScheduler_ContextCancellation(t *testing.T) {
 	client := &mockRegistryClient{
 		registry: createTestRegistry(),
@@ -489,3 +882,47 @@ func TestStatus_SuccessRate(t *testing.T) {
 		})
 	}
 }
+
+func TestScheduler_PerformUpdate_Spans(t *testing.T) {
+	exporter := tracingtest.Exporter(t)
+
+	registry := createTestRegistry()
+	registry.Version = "20240601T090000.000Z"
+	client := &mockRegistryClient{registry: registry}
+	scheduler := NewScheduler(client, &mockRegistryStore{}, Config{
+		Interval:      1 * time.Hour,
+		MaxRetries:    1,
+		RetryDelay:    10 * time.Millisecond,
+		UpdateTimeout: 1 * time.Second,
+	})
+
+	scheduler.performUpdate(context.Background())
+
+	spans := exporter.GetSpans()
+	update := tracingtest.Find(spans, "Scheduler.Update")
+	if update == nil {
+		t.Fatalf("expected an update span, got %d spans", len(spans))
+	}
+	attrs := attribute.NewSet(update.Attributes...)
+	if v, _ := attrs.Value(tracing.RegistryVersionKey); v.AsString() != registry.Version {
+		t.Errorf("expected registry version %s, got %q", registry.Version, v.AsString())
+	}
+	if v, _ := attrs.Value(tracing.AttemptKey); v.AsInt64() != 1 {
+		t.Errorf("expected one attempt, got %d", v.AsInt64())
+	}
+
+	swap := tracingtest.Find(spans, "swap")
+	if swap == nil || swap.Parent.SpanID() != update.SpanContext.SpanID() {
+		t.Error("expected a swap span under the update span")
+	}
+
+	// A failed update marks the span as failed
+	exporter.Reset()
+	client.err = errors.New("network error")
+	scheduler.performUpdate(context.Background())
+
+	update = tracingtest.Find(exporter.GetSpans(), "Scheduler.Update")
+	if update == nil || update.Status.Code != codes.Error {
+		t.Error("expected the failed update span to record the error")
+	}
+}
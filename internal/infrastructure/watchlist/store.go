@@ -0,0 +1,219 @@
+package watchlist
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+const (
+	// FileName is the file the watchlist is persisted to inside the snapshot directory
+	FileName = "watchlist.gob"
+
+	// MaxEvents is the number of most recent watch events kept
+	MaxEvents = 10000
+)
+
+// snapshot is the persisted form of the watchlist
+type snapshot struct {
+	Items       []*domain.WatchItem
+	Events      []*domain.WatchEvent
+	NextEventID int64
+}
+
+// Store keeps the watched targets and the events raised for them. Every
+// change is written through to disk when a snapshot directory is set.
+type Store struct {
+	mu          sync.RWMutex
+	path        string
+	items       map[string]*domain.WatchItem
+	events      []*domain.WatchEvent
+	nextEventID int64
+}
+
+// NewStore creates a watchlist store. When snapshotDir is not empty the
+// watchlist is persisted below it and the one already there is loaded.
+func NewStore(snapshotDir string) (*Store, error) {
+	s := &Store{
+		items:       make(map[string]*domain.WatchItem),
+		nextEventID: 1,
+	}
+	if snapshotDir == "" {
+		return s, nil
+	}
+
+	if err := os.MkdirAll(snapshotDir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating snapshot directory: %w", err)
+	}
+	s.path = filepath.Join(snapshotDir, FileName)
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Items returns copies of all watch items, oldest first
+func (s *Store) Items() []*domain.WatchItem {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	items := make([]*domain.WatchItem, 0, len(s.items))
+	for _, item := range s.items {
+		copied := *item
+		items = append(items, &copied)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].CreatedAt.Before(items[j].CreatedAt)
+		}
+		return items[i].ID < items[j].ID
+	})
+	return items
+}
+
+// Item returns a copy of the watch item with the given ID
+func (s *Store) Item(id string) (*domain.WatchItem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	item, ok := s.items[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrWatchItemNotFound, id)
+	}
+	copied := *item
+	return &copied, nil
+}
+
+// PutItems adds or replaces watch items
+func (s *Store) PutItems(items ...*domain.WatchItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, item := range items {
+		copied := *item
+		s.items[item.ID] = &copied
+	}
+	return s.save()
+}
+
+// DeleteItem removes a watch item. Its events are kept.
+func (s *Store) DeleteItem(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.items[id]; !ok {
+		return fmt.Errorf("%w: %s", domain.ErrWatchItemNotFound, id)
+	}
+	delete(s.items, id)
+	return s.save()
+}
+
+// AddEvents appends events, assigning their IDs, and drops the oldest events
+// beyond MaxEvents
+func (s *Store) AddEvents(events []*domain.WatchEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, event := range events {
+		event.ID = s.nextEventID
+		s.nextEventID++
+		copied := *event
+		s.events = append(s.events, &copied)
+	}
+	if excess := len(s.events) - MaxEvents; excess > 0 {
+		s.events = append([]*domain.WatchEvent(nil), s.events[excess:]...)
+	}
+	return s.save()
+}
+
+// Events returns the events after query.After, oldest first
+func (s *Store) Events(query domain.WatchEventQuery) []*domain.WatchEvent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	limit := query.PageSize()
+	start := sort.Search(len(s.events), func(i int) bool { return s.events[i].ID > query.After })
+
+	events := make([]*domain.WatchEvent, 0)
+	for _, event := range s.events[start:] {
+		if query.ItemID != "" && event.ItemID != query.ItemID {
+			continue
+		}
+		copied := *event
+		events = append(events, &copied)
+		if len(events) == limit {
+			break
+		}
+	}
+	return events
+}
+
+// save writes the watchlist to disk; callers must hold the lock
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), "watchlist-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating watchlist file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	items := make([]*domain.WatchItem, 0, len(s.items))
+	for _, item := range s.items {
+		items = append(items, item)
+	}
+
+	err = gob.NewEncoder(tmp).Encode(snapshot{Items: items, Events: s.events, NextEventID: s.nextEventID})
+	if err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing watchlist: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing watchlist: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// load reads the persisted watchlist, if there is one
+func (s *Store) load() error {
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("opening watchlist: %w", err)
+	}
+	defer file.Close()
+
+	var snap snapshot
+	if err := gob.NewDecoder(file).Decode(&snap); err != nil {
+		return fmt.Errorf("reading watchlist: %w", err)
+	}
+
+	for _, item := range snap.Items {
+		s.items[item.ID] = item
+	}
+	s.events = snap.Events
+	if snap.NextEventID > s.nextEventID {
+		s.nextEventID = snap.NextEventID
+	}
+	return nil
+}
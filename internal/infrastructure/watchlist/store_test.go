@@ -0,0 +1,91 @@
+package watchlist
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+func TestStore_Persistence(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	now := time.Now()
+	store.PutItems(
+		&domain.WatchItem{ID: "b", Target: "b.com", CreatedAt: now.Add(time.Minute)},
+		&domain.WatchItem{ID: "a", Target: "a.com", CreatedAt: now, Blocked: true, Rule: "a.com", RuleType: domain.BlockingTypeDomain},
+	)
+	events := []*domain.WatchEvent{
+		{ItemID: "a", Target: "a.com", Kind: domain.WatchEventBecameBlocked},
+		{ItemID: "b", Target: "b.com", Kind: domain.WatchEventBecameUnblocked},
+	}
+	store.AddEvents(events)
+	if events[0].ID != 1 || events[1].ID != 2 {
+		t.Errorf("expected event IDs to be assigned, got %d and %d", events[0].ID, events[1].ID)
+	}
+	if err := store.DeleteItem("b"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reloaded, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	items := reloaded.Items()
+	if len(items) != 1 || items[0].ID != "a" || !items[0].Blocked {
+		t.Errorf("unexpected reloaded items %+v", items)
+	}
+	if _, err := reloaded.Item("b"); !errors.Is(err, domain.ErrWatchItemNotFound) {
+		t.Errorf("expected ErrWatchItemNotFound, got %v", err)
+	}
+
+	// Event IDs continue after a restart
+	next := []*domain.WatchEvent{{ItemID: "a", Kind: domain.WatchEventBecameUnblocked}}
+	reloaded.AddEvents(next)
+	if next[0].ID != 3 {
+		t.Errorf("expected event ID 3, got %d", next[0].ID)
+	}
+}
+
+func TestStore_Events(t *testing.T) {
+	store, _ := NewStore("")
+	for i := 0; i < 5; i++ {
+		itemID := "a"
+		if i%2 == 1 {
+			itemID = "b"
+		}
+		store.AddEvents([]*domain.WatchEvent{{ItemID: itemID}})
+	}
+
+	tests := []struct {
+		name     string
+		query    domain.WatchEventQuery
+		expected []int64
+	}{
+		{name: "all", query: domain.WatchEventQuery{}, expected: []int64{1, 2, 3, 4, 5}},
+		{name: "after", query: domain.WatchEventQuery{After: 3}, expected: []int64{4, 5}},
+		{name: "by item", query: domain.WatchEventQuery{ItemID: "b"}, expected: []int64{2, 4}},
+		{name: "limit", query: domain.WatchEventQuery{Limit: 2}, expected: []int64{1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := store.Events(tt.query)
+			if len(events) != len(tt.expected) {
+				t.Fatalf("expected %v, got %d events", tt.expected, len(events))
+			}
+			for i, event := range events {
+				if event.ID != tt.expected[i] {
+					t.Errorf("expected %v, got event %d at %d", tt.expected, event.ID, i)
+				}
+			}
+		})
+	}
+}
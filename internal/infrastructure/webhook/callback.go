@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/egress"
+)
+
+// watchPayload is the JSON body posted to watch callback URLs, the same
+// object the watch events API lists
+type watchPayload struct {
+	ID       int64  `json:"id"`
+	ItemID   string `json:"item_id"`
+	Target   string `json:"target"`
+	Kind     string `json:"kind"`
+	Rule     string `json:"rule,omitempty"`
+	RuleType string `json:"rule_type,omitempty"`
+	Version  string `json:"version"`
+	At       string `json:"at"`
+}
+
+func newWatchPayload(event *domain.WatchEvent) watchPayload {
+	payload := watchPayload{
+		ID:      event.ID,
+		ItemID:  event.ItemID,
+		Target:  event.Target,
+		Kind:    event.Kind.String(),
+		Rule:    event.Rule,
+		Version: event.Version,
+		At:      event.At.Format(time.RFC3339),
+	}
+	if event.Rule != "" {
+		payload.RuleType = event.RuleType.String()
+	}
+	return payload
+}
+
+// CheckCallback rejects watch callback URLs that deliveries would be
+// refused for
+func (d *Dispatcher) CheckCallback(callbackURL string) error {
+	parsed, err := url.Parse(callbackURL)
+	if err != nil {
+		return err
+	}
+	return d.checkDestination(parsed)
+}
+
+// Notify queues watch events for their callback URLs. They are retried and
+// dead-lettered like subscription deliveries, but not signed, since a
+// callback has no secret.
+func (d *Dispatcher) Notify(ctx context.Context, callbacks []domain.WatchCallback) error {
+	now := time.Now()
+
+	deliveries := make([]*domain.WebhookDelivery, 0, len(callbacks))
+	for _, callback := range callbacks {
+		id, err := newID()
+		if err != nil {
+			return err
+		}
+		deliveries = append(deliveries, &domain.WebhookDelivery{
+			ID:  id,
+			URL: callback.URL,
+			Event: domain.WebhookEvent{
+				ID:         id,
+				Type:       domain.WebhookEventWatchChanged,
+				OccurredAt: callback.Event.At,
+				Watch:      callback.Event,
+			},
+			CreatedAt:     now,
+			NextAttemptAt: now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	if err := d.store.Enqueue(deliveries...); err != nil {
+		return fmt.Errorf("queueing watch callbacks: %w", err)
+	}
+	d.notify()
+	return nil
+}
+
+// checkDestination rejects URLs on addresses that are not public, unless
+// private networks are allowed
+func (d *Dispatcher) checkDestination(target *url.URL) error {
+	if d.config.AllowPrivateNetworks {
+		return nil
+	}
+	return egress.CheckURL(context.Background(), target)
+}
@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+func TestDispatcher_NotifyWatchCallbacks(t *testing.T) {
+	receiver := newTestReceiver(t, 1)
+	store, _ := NewStore("")
+	dispatcher := startDispatcher(t, store)
+
+	event := &domain.WatchEvent{
+		ID:       7,
+		ItemID:   "a",
+		Target:   "example.com",
+		Kind:     domain.WatchEventBecameBlocked,
+		Rule:     "*.example.com",
+		RuleType: domain.BlockingTypeWildcard,
+		Version:  "v2",
+		At:       time.Now(),
+	}
+	if err := dispatcher.Notify(context.Background(), []domain.WatchCallback{{URL: receiver.server.URL + "/hook", Event: event}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The first attempt fails and is retried
+	got := receiver.wait(t, 2)
+	if got[0].header.Get(DeliveryHeader) != got[1].header.Get(DeliveryHeader) {
+		t.Error("expected a retry of the same callback")
+	}
+	if got[1].header.Get(EventHeader) != "watch.changed" || got[1].header.Get(SignatureHeader) != "" {
+		t.Errorf("expected an unsigned watch.changed request, got %v", got[1].header)
+	}
+
+	var payload watchPayload
+	if err := json.Unmarshal(got[1].body, &payload); err != nil {
+		t.Fatalf("failed to decode callback: %v", err)
+	}
+	if payload.ID != 7 || payload.Kind != "became_blocked" || payload.RuleType != "wildcard" || payload.Target != "example.com" {
+		t.Errorf("unexpected payload %s", got[1].body)
+	}
+	waitFor(t, func() bool { return len(store.Pending()) == 0 })
+}
+
+func TestDispatcher_ReplayWatchCallback(t *testing.T) {
+	receiver := newTestReceiver(t, 3)
+	store, _ := NewStore("")
+	dispatcher := startDispatcher(t, store)
+
+	event := &domain.WatchEvent{ID: 1, ItemID: "a", Target: "example.com", Kind: domain.WatchEventBecameUnblocked, At: time.Now()}
+	dispatcher.Notify(context.Background(), []domain.WatchCallback{{URL: receiver.server.URL, Event: event}})
+
+	waitFor(t, func() bool { return len(dispatcher.DeadLetters()) == 1 })
+	dead := dispatcher.DeadLetters()[0]
+	if dead.SubscriptionID != "" || dead.Event.Type != domain.WebhookEventWatchChanged {
+		t.Errorf("expected a dead watch callback, got %+v", dead)
+	}
+
+	if _, err := dispatcher.Replay(dead.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	receiver.wait(t, 4)
+	waitFor(t, func() bool { return len(store.Pending()) == 0 })
+}
+
+func TestDispatcher_CheckCallback(t *testing.T) {
+	store, _ := NewStore("")
+	config := testConfig()
+	config.AllowPrivateNetworks = false
+	dispatcher := NewDispatcher(store, config)
+
+	for _, callback := range []string{"http://127.0.0.1/hook", "http://169.254.169.254/", "http://192.168.0.10/hook"} {
+		if err := dispatcher.CheckCallback(callback); err == nil {
+			t.Errorf("%s: expected the callback to be rejected", callback)
+		}
+	}
+	if err := dispatcher.CheckCallback("https://93.184.216.34/hook"); err != nil {
+		t.Errorf("expected a public callback to be accepted, got %v", err)
+	}
+}
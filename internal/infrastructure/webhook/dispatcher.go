@@ -0,0 +1,498 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/egress"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/updater"
+)
+
+// Config holds the delivery settings of a dispatcher
+type Config struct {
+	Timeout        time.Duration // Timeout of one delivery attempt
+	MaxAttempts    int           // Attempts before a delivery is dead-lettered
+	InitialBackoff time.Duration // Delay before the first retry
+	MaxBackoff     time.Duration // Upper bound of the doubling retry delay
+	Workers        int           // Deliveries attempted at once
+	// AllowPrivateNetworks permits subscriptions to loopback, link-local
+	// and private addresses, for receivers on the same host or network
+	AllowPrivateNetworks bool
+}
+
+// flushInterval is how often delivery outcomes are written to the store
+const flushInterval = time.Second
+
+// DefaultConfig returns sensible default delivery settings
+func DefaultConfig() Config {
+	return Config{
+		Timeout:        10 * time.Second,
+		MaxAttempts:    8,
+		InitialBackoff: 30 * time.Second,
+		MaxBackoff:     time.Hour,
+		Workers:        4,
+	}
+}
+
+// eventPayload is the JSON body posted to subscriptions
+type eventPayload struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	OccurredAt string `json:"occurred_at"`
+	Data       any    `json:"data"`
+}
+
+type registryUpdatedData struct {
+	Version string         `json:"version"`
+	Size    int            `json:"size"`
+	Counts  map[string]int `json:"counts"`
+}
+
+type updateFailedData struct {
+	Error               string `json:"error"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+}
+
+type sourceUnhealthyData struct {
+	Source string `json:"source"`
+}
+
+// Dispatcher turns scheduler events into signed webhook deliveries. Pending
+// deliveries are kept in the store and retried with exponential backoff
+// until they succeed or run out of attempts and are dead-lettered. A fixed
+// number of workers make the attempts, so a slow endpoint holds up one
+// worker rather than the delivery loop.
+type Dispatcher struct {
+	store  *Store
+	client *http.Client
+	config Config
+	wake   chan struct{}
+	queue  chan *domain.WebhookDelivery
+
+	mu       sync.Mutex
+	inFlight map[string]bool // deliveries handed to a worker
+}
+
+// NewDispatcher creates a dispatcher. Zero config values fall back to
+// DefaultConfig.
+func NewDispatcher(store *Store, config Config) *Dispatcher {
+	defaults := DefaultConfig()
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = defaults.InitialBackoff
+	}
+	if config.MaxBackoff < config.InitialBackoff {
+		config.MaxBackoff = max(defaults.MaxBackoff, config.InitialBackoff)
+	}
+	if config.Workers <= 0 {
+		config.Workers = defaults.Workers
+	}
+
+	return &Dispatcher{
+		store:    store,
+		client:   egress.NewClient(config.Timeout, config.AllowPrivateNetworks),
+		config:   config,
+		wake:     make(chan struct{}, 1),
+		queue:    make(chan *domain.WebhookDelivery, config.Workers),
+		inFlight: make(map[string]bool),
+	}
+}
+
+// OnRegistryUpdate publishes a registry.updated event
+func (d *Dispatcher) OnRegistryUpdate(previous, current *domain.Registry, report *domain.IngestReport) {
+	d.Publish(domain.WebhookEvent{
+		Type:    domain.WebhookEventRegistryUpdated,
+		Version: current.Version,
+		Size:    current.Size(),
+		Counts:  current.CountsByType(),
+	})
+}
+
+// OnUpdateFailed publishes a registry.update_failed event
+func (d *Dispatcher) OnUpdateFailed(status updater.Status) {
+	event := domain.WebhookEvent{
+		Type:                domain.WebhookEventRegistryUpdateFailed,
+		ConsecutiveFailures: status.ConsecutiveFailures,
+	}
+	if status.LastError != nil {
+		event.Error = status.LastError.Error()
+	}
+	d.Publish(event)
+}
+
+// OnSourceUnhealthy publishes a source.unhealthy event
+func (d *Dispatcher) OnSourceUnhealthy(source string) {
+	d.Publish(domain.WebhookEvent{
+		Type:   domain.WebhookEventSourceUnhealthy,
+		Source: source,
+	})
+}
+
+// Publish queues an event for every subscription that wants it
+func (d *Dispatcher) Publish(event domain.WebhookEvent) {
+	id, err := newID()
+	if err != nil {
+		slog.Error("Failed to publish webhook event", "type", event.Type, "error", err)
+		return
+	}
+	event.ID = id
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	var deliveries []*domain.WebhookDelivery
+	for _, subscription := range d.store.Subscriptions() {
+		if !subscription.Wants(event.Type) {
+			continue
+		}
+		id, err := newID()
+		if err != nil {
+			slog.Error("Failed to publish webhook event", "type", event.Type, "error", err)
+			return
+		}
+		deliveries = append(deliveries, &domain.WebhookDelivery{
+			ID:             id,
+			SubscriptionID: subscription.ID,
+			URL:            subscription.URL,
+			Event:          event,
+			CreatedAt:      event.OccurredAt,
+			NextAttemptAt:  event.OccurredAt,
+		})
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+
+	if err := d.store.Enqueue(deliveries...); err != nil {
+		slog.Error("Failed to queue webhook deliveries", "type", event.Type, "error", err)
+		return
+	}
+	d.notify()
+}
+
+// AddSubscription subscribes a URL to the given event types, or to every
+// type when events is empty
+func (d *Dispatcher) AddSubscription(callbackURL, secret string, events []domain.WebhookEventType) (*domain.WebhookSubscription, error) {
+	parsed, err := url.Parse(callbackURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("%w: URL must be an absolute http or https URL", domain.ErrInvalidWebhook)
+	}
+	if err := d.checkDestination(parsed); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidWebhook, err)
+	}
+	if strings.TrimSpace(secret) == "" {
+		return nil, fmt.Errorf("%w: secret is required", domain.ErrInvalidWebhook)
+	}
+	for _, event := range events {
+		if event == domain.WebhookEventUnknown {
+			return nil, fmt.Errorf("%w: unknown event type", domain.ErrInvalidWebhook)
+		}
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := &domain.WebhookSubscription{
+		ID:        id,
+		URL:       callbackURL,
+		Secret:    secret,
+		Events:    events,
+		CreatedAt: time.Now(),
+	}
+	if err := d.store.PutSubscription(subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// Subscriptions returns all subscriptions
+func (d *Dispatcher) Subscriptions() []*domain.WebhookSubscription {
+	return d.store.Subscriptions()
+}
+
+// DeleteSubscription removes a subscription and drops its pending deliveries
+func (d *Dispatcher) DeleteSubscription(id string) error {
+	return d.store.DeleteSubscription(id)
+}
+
+// DeadLetters returns the deliveries that ran out of attempts
+func (d *Dispatcher) DeadLetters() []*domain.WebhookDelivery {
+	return d.store.DeadLetters()
+}
+
+// Replay queues a dead-lettered delivery again with a fresh set of attempts
+func (d *Dispatcher) Replay(id string) (*domain.WebhookDelivery, error) {
+	delivery, err := d.store.Revive(id, func(delivery *domain.WebhookDelivery) {
+		delivery.Attempts = 0
+		delivery.LastError = ""
+		delivery.DeadAt = time.Time{}
+		delivery.NextAttemptAt = time.Now()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	d.notify()
+	return delivery, nil
+}
+
+// Run delivers pending deliveries as they fall due until ctx is done.
+// Delivery outcomes are written to the store in batches, and once more
+// when Run returns.
+func (d *Dispatcher) Run(ctx context.Context) {
+	var workers sync.WaitGroup
+	for range d.config.Workers {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			d.work(ctx)
+		}()
+	}
+	defer func() {
+		workers.Wait()
+		d.flush()
+	}()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	flush := time.NewTicker(flushInterval)
+	defer flush.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-flush.C:
+			d.flush()
+			continue
+		case <-d.wake:
+		case <-timer.C:
+		}
+
+		next := d.dispatchDue()
+		if next.IsZero() {
+			timer.Stop()
+			continue
+		}
+		timer.Reset(time.Until(next))
+	}
+}
+
+// notify wakes the delivery loop
+func (d *Dispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+		// A wake-up is already pending
+	}
+}
+
+// dispatchDue hands due deliveries to the workers until they are all busy,
+// and returns when the next waiting one falls due, or the zero time when
+// none is waiting or the workers are busy; a worker that finishes wakes the
+// loop again
+func (d *Dispatcher) dispatchDue() time.Time {
+	now := time.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, delivery := range d.store.Pending() {
+		if d.inFlight[delivery.ID] {
+			continue
+		}
+		if delivery.NextAttemptAt.After(now) {
+			return delivery.NextAttemptAt
+		}
+		select {
+		case d.queue <- delivery:
+			d.inFlight[delivery.ID] = true
+		default:
+			return time.Time{}
+		}
+	}
+	return time.Time{}
+}
+
+// work attempts the deliveries handed to it until ctx is done
+func (d *Dispatcher) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case delivery := <-d.queue:
+			d.attempt(ctx, delivery)
+
+			d.mu.Lock()
+			delete(d.inFlight, delivery.ID)
+			d.mu.Unlock()
+			d.notify()
+		}
+	}
+}
+
+// flush writes the batched delivery outcomes to the store
+func (d *Dispatcher) flush() {
+	if err := d.store.Flush(); err != nil {
+		slog.Error("Failed to persist webhook deliveries", "error", err)
+	}
+}
+
+// attempt makes one delivery attempt and records its outcome
+func (d *Dispatcher) attempt(ctx context.Context, delivery *domain.WebhookDelivery) {
+	var secret string
+	if delivery.SubscriptionID != "" {
+		subscription, err := d.store.Subscription(delivery.SubscriptionID)
+		if err != nil {
+			// The subscription was removed while the delivery was in flight
+			d.store.Complete(delivery.ID)
+			return
+		}
+		secret = subscription.Secret
+	}
+
+	err := d.send(ctx, secret, delivery)
+	if ctx.Err() != nil {
+		// Shutting down; the delivery stays pending for the next start
+		return
+	}
+	if err == nil {
+		d.store.Complete(delivery.ID)
+		return
+	}
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastError = err.Error()
+
+	if delivery.Attempts >= d.config.MaxAttempts {
+		delivery.DeadAt = now
+		slog.Error("Webhook delivery dead-lettered",
+			"delivery", delivery.ID,
+			"subscription", delivery.SubscriptionID,
+			"type", delivery.Event.Type,
+			"attempts", delivery.Attempts,
+			"error", err)
+		if err := d.store.Kill(delivery); err != nil {
+			slog.Error("Failed to dead-letter webhook delivery", "delivery", delivery.ID, "error", err)
+		}
+		return
+	}
+
+	delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts))
+	slog.Warn("Webhook delivery failed",
+		"delivery", delivery.ID,
+		"subscription", delivery.SubscriptionID,
+		"type", delivery.Event.Type,
+		"attempts", delivery.Attempts,
+		"next_attempt", delivery.NextAttemptAt,
+		"error", err)
+	d.store.Reschedule(delivery)
+}
+
+// backoff returns the delay after the given number of failed attempts,
+// doubling from InitialBackoff up to MaxBackoff
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.config.InitialBackoff
+	for i := 1; i < attempts && delay < d.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.config.MaxBackoff)
+}
+
+// send posts a delivery, signed with secret unless it is a watch callback.
+// Any status other than 2xx is an error.
+func (d *Dispatcher) send(ctx context.Context, secret string, delivery *domain.WebhookDelivery) error {
+	var payload any = newEventPayload(delivery.Event)
+	if delivery.Event.Watch != nil {
+		payload = newWatchPayload(delivery.Event.Watch)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encoding webhook event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating webhook request: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "RKN-Checker/1.0")
+	req.Header.Set(EventHeader, delivery.Event.Type.String())
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	if secret != "" {
+		req.Header.Set(SignatureHeader, Sign(secret, timestamp, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting webhook: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func newEventPayload(event domain.WebhookEvent) eventPayload {
+	payload := eventPayload{
+		ID:         event.ID,
+		Type:       event.Type.String(),
+		OccurredAt: event.OccurredAt.Format(time.RFC3339),
+	}
+
+	switch event.Type {
+	case domain.WebhookEventRegistryUpdated:
+		data := registryUpdatedData{
+			Version: event.Version,
+			Size:    event.Size,
+			Counts:  make(map[string]int, len(event.Counts)),
+		}
+		for blockingType, count := range event.Counts {
+			data.Counts[blockingType.String()] = count
+		}
+		payload.Data = data
+	case domain.WebhookEventRegistryUpdateFailed:
+		payload.Data = updateFailedData{
+			Error:               event.Error,
+			ConsecutiveFailures: event.ConsecutiveFailures,
+		}
+	case domain.WebhookEventSourceUnhealthy:
+		payload.Data = sourceUnhealthyData{Source: event.Source}
+	}
+
+	return payload
+}
+
+// newID returns a random identifier for events, deliveries and subscriptions
+func newID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("generating ID: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,401 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/egress"
+	"github.com/kerim-dauren/rkn-checker/internal/infrastructure/updater"
+)
+
+// receivedWebhook is a request seen by a test receiver
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+// testReceiver is an httptest webhook receiver that fails the first
+// failures requests
+type testReceiver struct {
+	server   *httptest.Server
+	mu       sync.Mutex
+	failures int
+	received []receivedWebhook
+	ch       chan struct{}
+}
+
+func newTestReceiver(t *testing.T, failures int) *testReceiver {
+	receiver := &testReceiver{failures: failures, ch: make(chan struct{}, 100)}
+	receiver.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		receiver.mu.Lock()
+		receiver.received = append(receiver.received, receivedWebhook{header: r.Header.Clone(), body: body})
+		fail := receiver.failures > 0
+		if fail {
+			receiver.failures--
+		}
+		receiver.mu.Unlock()
+
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusNoContent)
+		}
+		receiver.ch <- struct{}{}
+	}))
+	t.Cleanup(receiver.server.Close)
+	return receiver
+}
+
+// wait blocks until the receiver has seen n requests in total
+func (r *testReceiver) wait(t *testing.T, n int) []receivedWebhook {
+	t.Helper()
+	deadline := time.After(5 * time.Second)
+	for {
+		r.mu.Lock()
+		if len(r.received) >= n {
+			received := append([]receivedWebhook(nil), r.received...)
+			r.mu.Unlock()
+			return received
+		}
+		r.mu.Unlock()
+
+		select {
+		case <-r.ch:
+		case <-deadline:
+			t.Fatalf("expected %d webhook requests, got %d", n, len(r.received))
+		}
+	}
+}
+
+func testConfig() Config {
+	return Config{
+		Timeout:        time.Second,
+		MaxAttempts:    3,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     20 * time.Millisecond,
+		// The test receivers listen on loopback
+		AllowPrivateNetworks: true,
+	}
+}
+
+func startDispatcher(t *testing.T, store *Store) *Dispatcher {
+	return startDispatcherWith(t, store, testConfig())
+}
+
+func startDispatcherWith(t *testing.T, store *Store, config Config) *Dispatcher {
+	dispatcher := NewDispatcher(store, config)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		dispatcher.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return dispatcher
+}
+
+// waitFor polls until condition holds
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDispatcher_SignedRegistryUpdated(t *testing.T) {
+	receiver := newTestReceiver(t, 0)
+	store, _ := NewStore("")
+	dispatcher := startDispatcher(t, store)
+
+	subscription, err := dispatcher.AddSubscription(receiver.server.URL, "s3cret", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	registry := domain.NewRegistry()
+	registry.Version = "v2"
+	for _, value := range []string{"a.com", "b.com"} {
+		entry, _ := domain.NewRegistryEntry(domain.BlockingTypeDomain, value)
+		registry.AddEntry(entry)
+	}
+	entry, _ := domain.NewRegistryEntry(domain.BlockingTypeIP, "1.2.3.4")
+	registry.AddEntry(entry)
+
+	dispatcher.OnRegistryUpdate(nil, registry, nil)
+
+	got := receiver.wait(t, 1)[0]
+	if got.header.Get(EventHeader) != "registry.updated" {
+		t.Errorf("expected registry.updated event header, got %q", got.header.Get(EventHeader))
+	}
+	if !Verify(subscription.Secret, got.header.Get(TimestampHeader), got.header.Get(SignatureHeader), got.body, time.Minute, time.Now()) {
+		t.Error("expected a valid signature")
+	}
+	if Verify("other", got.header.Get(TimestampHeader), got.header.Get(SignatureHeader), got.body, time.Minute, time.Now()) {
+		t.Error("expected the signature to depend on the secret")
+	}
+
+	var payload struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			Version string         `json:"version"`
+			Size    int            `json:"size"`
+			Counts  map[string]int `json:"counts"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(got.body, &payload); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	if payload.ID == "" || payload.Type != "registry.updated" || payload.Data.Version != "v2" || payload.Data.Size != 3 {
+		t.Errorf("unexpected payload %s", got.body)
+	}
+	if payload.Data.Counts["domain"] != 2 || payload.Data.Counts["ip"] != 1 {
+		t.Errorf("expected per-type counts, got %v", payload.Data.Counts)
+	}
+
+	waitFor(t, func() bool { return len(store.Pending()) == 0 })
+}
+
+func TestDispatcher_EventFilterAndFailureEvents(t *testing.T) {
+	receiver := newTestReceiver(t, 0)
+	store, _ := NewStore("")
+	dispatcher := startDispatcher(t, store)
+
+	if _, err := dispatcher.AddSubscription(receiver.server.URL, "s3cret",
+		[]domain.WebhookEventType{domain.WebhookEventRegistryUpdateFailed}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dispatcher.OnSourceUnhealthy("official")
+	dispatcher.OnUpdateFailed(updater.Status{LastError: errors.New("all sources failed"), ConsecutiveFailures: 2})
+
+	got := receiver.wait(t, 1)
+	time.Sleep(50 * time.Millisecond)
+	if received := receiver.wait(t, 1); len(received) != 1 {
+		t.Fatalf("expected only the subscribed event, got %d requests", len(received))
+	}
+
+	var payload struct {
+		Type string `json:"type"`
+		Data struct {
+			Error               string `json:"error"`
+			ConsecutiveFailures int    `json:"consecutive_failures"`
+		} `json:"data"`
+	}
+	json.Unmarshal(got[0].body, &payload)
+	if payload.Type != "registry.update_failed" || payload.Data.Error != "all sources failed" || payload.Data.ConsecutiveFailures != 2 {
+		t.Errorf("unexpected payload %s", got[0].body)
+	}
+}
+
+func TestDispatcher_RetriesWithBackoff(t *testing.T) {
+	receiver := newTestReceiver(t, 2)
+	store, _ := NewStore("")
+	dispatcher := startDispatcher(t, store)
+
+	dispatcher.AddSubscription(receiver.server.URL, "s3cret", nil)
+	dispatcher.OnSourceUnhealthy("official")
+
+	got := receiver.wait(t, 3)
+	if got[0].header.Get(DeliveryHeader) != got[2].header.Get(DeliveryHeader) {
+		t.Error("expected retries of the same delivery")
+	}
+	waitFor(t, func() bool { return len(store.Pending()) == 0 })
+	if dead := dispatcher.DeadLetters(); len(dead) != 0 {
+		t.Errorf("expected no dead letters, got %d", len(dead))
+	}
+}
+
+func TestDispatcher_DeadLetterAndReplay(t *testing.T) {
+	receiver := newTestReceiver(t, 3)
+	store, _ := NewStore("")
+	dispatcher := startDispatcher(t, store)
+
+	dispatcher.AddSubscription(receiver.server.URL, "s3cret", nil)
+	dispatcher.OnSourceUnhealthy("official")
+
+	receiver.wait(t, 3)
+	waitFor(t, func() bool { return len(dispatcher.DeadLetters()) == 1 })
+
+	dead := dispatcher.DeadLetters()[0]
+	if dead.Attempts != 3 || dead.LastError == "" || dead.DeadAt.IsZero() {
+		t.Errorf("expected a dead letter after 3 attempts, got %+v", dead)
+	}
+
+	if _, err := dispatcher.Replay("missing"); !errors.Is(err, domain.ErrDeliveryNotFound) {
+		t.Errorf("expected ErrDeliveryNotFound, got %v", err)
+	}
+
+	replayed, err := dispatcher.Replay(dead.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if replayed.Attempts != 0 || !replayed.DeadAt.IsZero() {
+		t.Errorf("expected a reset delivery, got %+v", replayed)
+	}
+
+	got := receiver.wait(t, 4)
+	if got[3].header.Get(DeliveryHeader) != dead.ID {
+		t.Error("expected the replayed delivery to keep its ID")
+	}
+	waitFor(t, func() bool { return len(store.Pending()) == 0 })
+	if len(dispatcher.DeadLetters()) != 0 {
+		t.Error("expected the dead letter to be gone after a successful replay")
+	}
+}
+
+func TestDispatcher_SlowEndpointDoesNotBlockOthers(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(slow.Close)
+	t.Cleanup(func() { close(release) })
+	receiver := newTestReceiver(t, 0)
+
+	store, _ := NewStore("")
+	config := testConfig()
+	config.Timeout = time.Minute
+	dispatcher := startDispatcherWith(t, store, config)
+
+	dispatcher.AddSubscription(slow.URL, "s3cret", nil)
+	dispatcher.AddSubscription(receiver.server.URL, "s3cret", nil)
+
+	dispatcher.OnSourceUnhealthy("official")
+	receiver.wait(t, 1)
+	dispatcher.OnSourceUnhealthy("mirror")
+	receiver.wait(t, 2)
+}
+
+func TestDispatcher_ResumesPersistedDeliveries(t *testing.T) {
+	receiver := newTestReceiver(t, 0)
+	dir := t.TempDir()
+
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Published while no delivery loop is running, as if the process
+	// stopped before delivering
+	dispatcher := NewDispatcher(store, testConfig())
+	dispatcher.AddSubscription(receiver.server.URL, "s3cret", nil)
+	dispatcher.OnSourceUnhealthy("official")
+
+	reopened, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reopened.Pending()) != 1 {
+		t.Fatalf("expected 1 persisted pending delivery, got %d", len(reopened.Pending()))
+	}
+
+	startDispatcher(t, reopened)
+	receiver.wait(t, 1)
+	waitFor(t, func() bool { return len(reopened.Pending()) == 0 })
+}
+
+func TestDispatcher_AddSubscriptionValidation(t *testing.T) {
+	store, _ := NewStore("")
+	dispatcher := NewDispatcher(store, testConfig())
+
+	tests := []struct {
+		name   string
+		url    string
+		secret string
+		events []domain.WebhookEventType
+	}{
+		{"Relative URL", "/hooks", "s3cret", nil},
+		{"Unsupported scheme", "ftp://example.com", "s3cret", nil},
+		{"Missing secret", "https://example.com/hooks", " ", nil},
+		{"Unknown event", "https://example.com/hooks", "s3cret", []domain.WebhookEventType{domain.WebhookEventUnknown}},
+		{"Loopback", "http://127.0.0.1:8080/hooks", "s3cret", nil},
+		{"Localhost", "http://localhost/hooks", "s3cret", nil},
+		{"Cloud metadata", "http://169.254.169.254/latest/meta-data", "s3cret", nil},
+		{"Private network", "https://10.0.0.5/hooks", "s3cret", nil},
+		{"IPv6 loopback", "http://[::1]/hooks", "s3cret", nil},
+	}
+
+	config := testConfig()
+	config.AllowPrivateNetworks = false
+	dispatcher = NewDispatcher(store, config)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := dispatcher.AddSubscription(tt.url, tt.secret, tt.events); !errors.Is(err, domain.ErrInvalidWebhook) {
+				t.Errorf("expected ErrInvalidWebhook, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDispatcher_RefusesPrivateAddressesWhenDialling(t *testing.T) {
+	receiver := newTestReceiver(t, 0)
+	store, _ := NewStore("")
+	// Stored directly, as if the name had resolved to a public address
+	// when the subscription was made
+	store.PutSubscription(&domain.WebhookSubscription{ID: "sub", URL: receiver.server.URL, Secret: "s3cret"})
+
+	config := testConfig()
+	config.AllowPrivateNetworks = false
+	config.MaxAttempts = 1
+	dispatcher := startDispatcherWith(t, store, config)
+
+	dispatcher.OnSourceUnhealthy("official")
+
+	waitFor(t, func() bool { return len(dispatcher.DeadLetters()) == 1 })
+	if dead := dispatcher.DeadLetters()[0]; !strings.Contains(dead.LastError, egress.ErrForbiddenAddress.Error()) {
+		t.Errorf("expected the connection to be refused, got %q", dead.LastError)
+	}
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	if len(receiver.received) != 0 {
+		t.Errorf("expected no request to reach the receiver, got %d", len(receiver.received))
+	}
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	dispatcher := NewDispatcher(nil, Config{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second})
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, want := range expected {
+		if got := dispatcher.backoff(i + 1); got != want {
+			t.Errorf("attempt %d: expected %v, got %v", i+1, want, got)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	now := time.Unix(1700000000, 0)
+	signature := Sign("s3cret", now.Unix(), body)
+
+	if !Verify("s3cret", "1700000000", signature, body, time.Minute, now) {
+		t.Error("expected a valid signature")
+	}
+	if Verify("s3cret", "1700000000", signature, []byte(`{"id":"2"}`), time.Minute, now) {
+		t.Error("expected a changed body to fail verification")
+	}
+	if Verify("s3cret", "1700000000", signature, body, time.Minute, now.Add(time.Hour)) {
+		t.Error("expected an old timestamp to fail verification")
+	}
+	if Verify("s3cret", "nope", signature, body, 0, now) {
+		t.Error("expected an invalid timestamp to fail verification")
+	}
+}
@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// Headers set on every webhook request
+const (
+	EventHeader     = "X-RKN-Event"
+	DeliveryHeader  = "X-RKN-Delivery"
+	TimestampHeader = "X-RKN-Timestamp"
+	SignatureHeader = "X-RKN-Signature"
+)
+
+// signaturePrefix names the algorithm in the signature header
+const signaturePrefix = "sha256="
+
+// Sign returns the signature header value for a request body sent at the
+// given Unix timestamp: the hex HMAC-SHA256 of "<timestamp>.<body>" keyed
+// with the subscription secret
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and timestamp headers of a received webhook.
+// Requests signed more than tolerance away from now are rejected to limit
+// replays; a zero tolerance skips that check.
+func Verify(secret, timestamp, signature string, body []byte, tolerance time.Duration, now time.Time) bool {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if tolerance > 0 {
+		if skew := now.Sub(time.Unix(ts, 0)); skew > tolerance || skew < -tolerance {
+			return false
+		}
+	}
+	return hmac.Equal([]byte(Sign(secret, ts, body)), []byte(signature))
+}
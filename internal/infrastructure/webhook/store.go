@@ -0,0 +1,320 @@
+package webhook
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+const (
+	// FileName is the file webhooks are persisted to inside the snapshot directory
+	FileName = "webhooks.gob"
+
+	// MaxDeadLetters is the number of most recent dead-lettered deliveries kept
+	MaxDeadLetters = 1000
+)
+
+// snapshot is the persisted form of the webhook store
+type snapshot struct {
+	Subscriptions []*domain.WebhookSubscription
+	Pending       []*domain.WebhookDelivery
+	Dead          []*domain.WebhookDelivery
+}
+
+// Store keeps webhook subscriptions, the deliveries still to be made and the
+// dead-lettered ones. They are written to disk when a snapshot directory is
+// set, so pending deliveries survive a restart. Changes are written through,
+// except completed and rescheduled attempts, which are written on the next
+// Flush or other change so a backlog does not rewrite the file per attempt.
+type Store struct {
+	mu            sync.RWMutex
+	path          string
+	subscriptions map[string]*domain.WebhookSubscription
+	pending       map[string]*domain.WebhookDelivery
+	dead          []*domain.WebhookDelivery
+	dirty         bool // unsaved completed or rescheduled attempts
+}
+
+// NewStore creates a webhook store. When snapshotDir is not empty the store
+// is persisted below it and the one already there is loaded.
+func NewStore(snapshotDir string) (*Store, error) {
+	s := &Store{
+		subscriptions: make(map[string]*domain.WebhookSubscription),
+		pending:       make(map[string]*domain.WebhookDelivery),
+	}
+	if snapshotDir == "" {
+		return s, nil
+	}
+
+	if err := os.MkdirAll(snapshotDir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating snapshot directory: %w", err)
+	}
+	s.path = filepath.Join(snapshotDir, FileName)
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Subscriptions returns copies of all subscriptions, oldest first
+func (s *Store) Subscriptions() []*domain.WebhookSubscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	subscriptions := make([]*domain.WebhookSubscription, 0, len(s.subscriptions))
+	for _, subscription := range s.subscriptions {
+		copied := *subscription
+		subscriptions = append(subscriptions, &copied)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if !subscriptions[i].CreatedAt.Equal(subscriptions[j].CreatedAt) {
+			return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+		}
+		return subscriptions[i].ID < subscriptions[j].ID
+	})
+	return subscriptions
+}
+
+// Subscription returns a copy of the subscription with the given ID
+func (s *Store) Subscription(id string) (*domain.WebhookSubscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	subscription, ok := s.subscriptions[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrWebhookNotFound, id)
+	}
+	copied := *subscription
+	return &copied, nil
+}
+
+// PutSubscription adds or replaces a subscription
+func (s *Store) PutSubscription(subscription *domain.WebhookSubscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *subscription
+	s.subscriptions[subscription.ID] = &copied
+	return s.save()
+}
+
+// DeleteSubscription removes a subscription together with its pending
+// deliveries. Its dead letters are kept.
+func (s *Store) DeleteSubscription(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subscriptions[id]; !ok {
+		return fmt.Errorf("%w: %s", domain.ErrWebhookNotFound, id)
+	}
+	delete(s.subscriptions, id)
+	for deliveryID, delivery := range s.pending {
+		if delivery.SubscriptionID == id {
+			delete(s.pending, deliveryID)
+		}
+	}
+	return s.save()
+}
+
+// Enqueue adds deliveries to the pending queue
+func (s *Store) Enqueue(deliveries ...*domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, delivery := range deliveries {
+		copied := *delivery
+		s.pending[delivery.ID] = &copied
+	}
+	return s.save()
+}
+
+// Pending returns copies of the pending deliveries, earliest attempt first
+func (s *Store) Pending() []*domain.WebhookDelivery {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pending := make([]*domain.WebhookDelivery, 0, len(s.pending))
+	for _, delivery := range s.pending {
+		copied := *delivery
+		pending = append(pending, &copied)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if !pending[i].NextAttemptAt.Equal(pending[j].NextAttemptAt) {
+			return pending[i].NextAttemptAt.Before(pending[j].NextAttemptAt)
+		}
+		return pending[i].ID < pending[j].ID
+	})
+	return pending
+}
+
+// Complete removes a delivered delivery from the pending queue. The change
+// is written on the next Flush.
+func (s *Store) Complete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.pending[id]; !ok {
+		return
+	}
+	delete(s.pending, id)
+	s.dirty = true
+}
+
+// Reschedule records a failed attempt of a pending delivery. It is ignored
+// when the delivery is no longer pending. The change is written on the next
+// Flush.
+func (s *Store) Reschedule(delivery *domain.WebhookDelivery) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.pending[delivery.ID]; !ok {
+		return
+	}
+	copied := *delivery
+	s.pending[delivery.ID] = &copied
+	s.dirty = true
+}
+
+// Flush writes completed and rescheduled attempts that are not saved yet
+func (s *Store) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+	return s.save()
+}
+
+// Kill moves a pending delivery to the dead letters and drops the oldest
+// dead letters beyond MaxDeadLetters
+func (s *Store) Kill(delivery *domain.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.pending[delivery.ID]; !ok {
+		return nil
+	}
+	delete(s.pending, delivery.ID)
+
+	copied := *delivery
+	s.dead = append(s.dead, &copied)
+	if excess := len(s.dead) - MaxDeadLetters; excess > 0 {
+		s.dead = append([]*domain.WebhookDelivery(nil), s.dead[excess:]...)
+	}
+	return s.save()
+}
+
+// DeadLetters returns copies of the dead-lettered deliveries, oldest first
+func (s *Store) DeadLetters() []*domain.WebhookDelivery {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	dead := make([]*domain.WebhookDelivery, 0, len(s.dead))
+	for _, delivery := range s.dead {
+		copied := *delivery
+		dead = append(dead, &copied)
+	}
+	return dead
+}
+
+// Revive moves a dead letter back to the pending queue through reset, which
+// prepares it for a fresh round of attempts. Deliveries to a removed
+// subscription cannot be revived; watch callbacks have none.
+func (s *Store) Revive(id string, reset func(*domain.WebhookDelivery)) (*domain.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, delivery := range s.dead {
+		if delivery.ID != id {
+			continue
+		}
+		if _, ok := s.subscriptions[delivery.SubscriptionID]; !ok && delivery.SubscriptionID != "" {
+			return nil, fmt.Errorf("%w: %s", domain.ErrWebhookNotFound, delivery.SubscriptionID)
+		}
+
+		s.dead = append(s.dead[:i:i], s.dead[i+1:]...)
+		reset(delivery)
+		s.pending[delivery.ID] = delivery
+		if err := s.save(); err != nil {
+			return nil, err
+		}
+
+		copied := *delivery
+		return &copied, nil
+	}
+	return nil, fmt.Errorf("%w: %s", domain.ErrDeliveryNotFound, id)
+}
+
+// save writes the store to disk; callers must hold the lock
+func (s *Store) save() error {
+	if s.path == "" {
+		s.dirty = false
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), "webhooks-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating webhook file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	snap := snapshot{Dead: s.dead}
+	for _, subscription := range s.subscriptions {
+		snap.Subscriptions = append(snap.Subscriptions, subscription)
+	}
+	for _, delivery := range s.pending {
+		snap.Pending = append(snap.Pending, delivery)
+	}
+
+	if err := gob.NewEncoder(tmp).Encode(snap); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing webhooks: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing webhooks: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+// load reads the persisted store, if there is one
+func (s *Store) load() error {
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("opening webhooks: %w", err)
+	}
+	defer file.Close()
+
+	var snap snapshot
+	if err := gob.NewDecoder(file).Decode(&snap); err != nil {
+		return fmt.Errorf("reading webhooks: %w", err)
+	}
+
+	for _, subscription := range snap.Subscriptions {
+		s.subscriptions[subscription.ID] = subscription
+	}
+	for _, delivery := range snap.Pending {
+		s.pending[delivery.ID] = delivery
+	}
+	s.dead = snap.Dead
+	return nil
+}
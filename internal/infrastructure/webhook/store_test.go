@@ -0,0 +1,113 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kerim-dauren/rkn-checker/internal/domain"
+)
+
+func TestStore_PersistsSubscriptionsAndDeadLetters(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	subscription := &domain.WebhookSubscription{
+		ID:        "sub",
+		URL:       "https://example.com/hooks",
+		Secret:    "s3cret",
+		Events:    []domain.WebhookEventType{domain.WebhookEventRegistryUpdated},
+		CreatedAt: time.Now(),
+	}
+	store.PutSubscription(subscription)
+
+	delivery := &domain.WebhookDelivery{
+		ID:             "d1",
+		SubscriptionID: "sub",
+		Event:          domain.WebhookEvent{ID: "e1", Type: domain.WebhookEventRegistryUpdated, Version: "v1"},
+	}
+	store.Enqueue(delivery)
+	delivery.Attempts = 8
+	delivery.DeadAt = time.Now()
+	store.Kill(delivery)
+
+	reopened, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := reopened.Subscription("sub")
+	if err != nil || got.Secret != "s3cret" || !got.Wants(domain.WebhookEventRegistryUpdated) || got.Wants(domain.WebhookEventSourceUnhealthy) {
+		t.Errorf("expected the subscription to be restored, got %+v (%v)", got, err)
+	}
+	if len(reopened.Pending()) != 0 {
+		t.Errorf("expected no pending deliveries, got %d", len(reopened.Pending()))
+	}
+	dead := reopened.DeadLetters()
+	if len(dead) != 1 || dead[0].Attempts != 8 || dead[0].Event.Version != "v1" {
+		t.Errorf("expected the dead letter to be restored, got %+v", dead)
+	}
+}
+
+func TestStore_DeleteSubscriptionDropsPending(t *testing.T) {
+	store, _ := NewStore("")
+	store.PutSubscription(&domain.WebhookSubscription{ID: "sub"})
+	store.Enqueue(&domain.WebhookDelivery{ID: "d1", SubscriptionID: "sub"})
+
+	if err := store.DeleteSubscription("sub"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.Pending()) != 0 {
+		t.Error("expected pending deliveries of a deleted subscription to be dropped")
+	}
+	if err := store.DeleteSubscription("sub"); !errors.Is(err, domain.ErrWebhookNotFound) {
+		t.Errorf("expected ErrWebhookNotFound, got %v", err)
+	}
+}
+
+func TestStore_ReviveRequiresSubscription(t *testing.T) {
+	store, _ := NewStore("")
+	store.PutSubscription(&domain.WebhookSubscription{ID: "sub"})
+	delivery := &domain.WebhookDelivery{ID: "d1", SubscriptionID: "sub"}
+	store.Enqueue(delivery)
+	store.Kill(delivery)
+	store.DeleteSubscription("sub")
+
+	_, err := store.Revive("d1", func(*domain.WebhookDelivery) {})
+	if !errors.Is(err, domain.ErrWebhookNotFound) {
+		t.Errorf("expected ErrWebhookNotFound, got %v", err)
+	}
+	if len(store.DeadLetters()) != 1 {
+		t.Error("expected the dead letter to be kept")
+	}
+}
+
+func TestStore_FlushBatchesAttempts(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := NewStore(dir)
+	store.PutSubscription(&domain.WebhookSubscription{ID: "sub"})
+	store.Enqueue(
+		&domain.WebhookDelivery{ID: "d1", SubscriptionID: "sub"},
+		&domain.WebhookDelivery{ID: "d2", SubscriptionID: "sub"},
+	)
+
+	store.Complete("d1")
+	store.Reschedule(&domain.WebhookDelivery{ID: "d2", SubscriptionID: "sub", Attempts: 1})
+
+	reopened, _ := NewStore(dir)
+	if len(reopened.Pending()) != 2 {
+		t.Fatalf("expected attempts to wait for a flush, got %d pending", len(reopened.Pending()))
+	}
+
+	if err := store.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reopened, _ = NewStore(dir)
+	pending := reopened.Pending()
+	if len(pending) != 1 || pending[0].ID != "d2" || pending[0].Attempts != 1 {
+		t.Errorf("expected the flushed attempts to be persisted, got %+v", pending)
+	}
+}
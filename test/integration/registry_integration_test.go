@@ -175,7 +175,7 @@ func TestRegistryIntegration_SourceFailover(t *testing.T) {
 
 	// Fetch registry (should fail)
 	ctx := context.Background()
-	_, err = client.FetchRegistry(ctx)
+	_, _, err = client.FetchRegistry(ctx)
 	if err == nil {
 		t.Error("expected fetch to fail with failing server")
 	}
@@ -226,7 +226,7 @@ func TestRegistryIntegration_SourceFailover(t *testing.T) {
 	}
 
 	// Fetch registry (should succeed)
-	fetchedRegistry, err := workingClient.FetchRegistry(ctx)
+	fetchedRegistry, _, err := workingClient.FetchRegistry(ctx)
 	if err != nil {
 		t.Fatalf("failed to fetch registry from working server: %v", err)
 	}
@@ -426,7 +426,7 @@ func BenchmarkIntegrationWorkflow(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		// Fetch and update
 		ctx := context.Background()
-		reg, err := client.FetchRegistry(ctx)
+		reg, _, err := client.FetchRegistry(ctx)
 		if err != nil {
 			b.Fatal(err)
 		}